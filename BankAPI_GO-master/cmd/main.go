@@ -13,13 +13,15 @@ import (
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
-	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 	"github.com/therealadik/bank-api/internal/config"
 	"github.com/therealadik/bank-api/internal/db"
+	"github.com/therealadik/bank-api/internal/docs"
+	"github.com/therealadik/bank-api/internal/email"
 	"github.com/therealadik/bank-api/internal/handler"
 	"github.com/therealadik/bank-api/internal/middleware"
 	"github.com/therealadik/bank-api/internal/repository"
+	"github.com/therealadik/bank-api/internal/router"
 	"github.com/therealadik/bank-api/internal/service"
 )
 
@@ -54,6 +56,19 @@ func main() {
 	dbCfg := config.LoadDB()
 	jwtCfg := config.LoadJWT()
 	cryptoCfg := config.LoadCrypto()
+	paymentCfg := config.LoadPayment()
+	twoFactorCfg := config.LoadTwoFactor()
+	loginGuardCfg := config.LoadLoginGuard()
+	smtpCfg := config.LoadSMTP()
+	passwordResetCfg := config.LoadPasswordReset()
+	emailVerificationCfg := config.LoadEmailVerification()
+	scheduledTransferCfg := config.LoadScheduledTransfer()
+	transferLimitsCfg := config.LoadTransferLimits()
+	fraudCfg := config.LoadFraud()
+	idempotencyCfg := config.LoadIdempotency()
+	webhookCfg := config.LoadWebhook()
+	streamCfg := config.LoadStream()
+	creditOverdueCfg := config.LoadCreditOverdue()
 
 	// Подключение к БД и миграции
 	dsn := db.BuildDSN(dbCfg)
@@ -68,51 +83,141 @@ func main() {
 
 	// Инициализация репозиториев
 	userRepo := repository.NewUserRepository(pool)
+	tokenRepo := repository.NewTokenRepository(pool)
 	accountRepo := repository.NewAccountRepository(pool)
 	transactionRepo := repository.NewTransactionRepository(pool)
 	cardRepo := repository.NewCardRepository(pool)
+	paymentRepo := repository.NewPaymentRepository(pool)
+	disputeRepo := repository.NewDisputeRepository(pool)
+	signingKeyRepo := repository.NewSigningKeyRepository(pool)
+	twoFactorRepo := repository.NewTwoFactorRepository(pool)
+	loginThrottleRepo := repository.NewLoginThrottleRepository(pool)
+	securityEventRepo := repository.NewSecurityEventRepository(pool)
+	passwordResetRepo := repository.NewPasswordResetRepository(pool)
+	auditRepo := repository.NewAuditRepository(pool)
+	scheduledTransferRepo := repository.NewScheduledTransferRepository(pool)
+	transferLimitRepo := repository.NewTransferLimitRepository(pool)
+	fraudRepo := repository.NewFraudRepository(pool)
+	idempotencyRepo := repository.NewIdempotencyRepository(pool)
+	webhookRepo := repository.NewWebhookRepository(pool)
+	paymentScheduleRepo := repository.NewPaymentScheduleRepository(pool)
+
+	// Отправка писем (без SMTP_HOST письма пишутся в лог)
+	mailSender := email.NewSender(smtpCfg, logger)
+
+	// Ключи подписи JWT: создаем первый ключ при необходимости и загружаем действующие
+	keyManager, err := service.NewKeyManager(signingKeyRepo, pool, jwtCfg, cryptoCfg.PGPKey)
+	if err != nil {
+		logger.Fatalf("Ошибка настройки подписи JWT: %v", err)
+	}
+	if err := keyManager.Init(ctx); err != nil {
+		logger.Fatalf("Ошибка инициализации ключей подписи JWT: %v", err)
+	}
 
 	// Инициализация сервисов
-	authService := service.NewAuthService(userRepo, jwtCfg)
-	accountService := service.NewAccountService(accountRepo, transactionRepo)
-	cardService := service.NewCardService(cardRepo, pool, cryptoCfg.HMACKey)
+	auditService := service.NewAuditService(auditRepo, pool)
+	securityNotifier := service.NewEmailSecurityNotifier(mailSender)
+	loginGuard := service.NewLoginGuard(loginThrottleRepo, securityEventRepo, userRepo, securityNotifier,
+		loginGuardCfg, logger)
+	twoFactorService := service.NewTwoFactorService(twoFactorRepo, userRepo, loginGuard, twoFactorCfg,
+		cryptoCfg.PGPKey)
+	emailVerificationService := service.NewEmailVerificationService(userRepo, mailSender, emailVerificationCfg,
+		cryptoCfg.HMACKey, logger)
+	authService := service.NewAuthService(userRepo, tokenRepo, twoFactorService, loginGuard, emailVerificationService,
+		auditService, keyManager, jwtCfg, twoFactorCfg)
+	userService := service.NewUserService(userRepo, emailVerificationService)
+	passwordService := service.NewPasswordService(userRepo, tokenRepo, passwordResetRepo, mailSender,
+		passwordResetCfg, logger)
+	transferLimitService := service.NewTransferLimitService(transferLimitRepo, accountRepo, userRepo, auditService, pool,
+		transferLimitsCfg)
+	webhookService := service.NewWebhookService(webhookRepo, auditService, pool, cryptoCfg.PGPKey, webhookCfg)
+	fraudService := service.NewFraudService(fraudRepo, accountRepo, transactionRepo, paymentRepo, cardRepo, auditService,
+		webhookService, pool, fraudCfg, paymentCfg)
+	accountService := service.NewAccountService(userRepo, accountRepo, transactionRepo, transferLimitService,
+		fraudService, auditService, webhookService, pool)
+	scheduledTransferService := service.NewScheduledTransferService(scheduledTransferRepo, userRepo, accountService,
+		auditService, mailSender, pool, scheduledTransferCfg)
+	cardService := service.NewCardService(cardRepo, accountRepo, transactionRepo, paymentRepo, disputeRepo, userRepo,
+		securityNotifier, fraudService, auditService, webhookService, pool, cryptoCfg.HMACKey, paymentCfg)
+	adminService := service.NewAdminService(userRepo, tokenRepo, accountRepo, cardRepo, transactionRepo, auditService,
+		pool)
+
+	idempotencyService := service.NewIdempotencyService(idempotencyRepo, idempotencyCfg)
+	streamService := service.NewStreamService(pool, streamCfg)
+	creditOverdueService := service.NewCreditOverdueService(paymentScheduleRepo, webhookService, pool,
+		creditOverdueCfg)
 
 	// Инициализация обработчиков
 	authHandler := handler.NewAuthHandler(authService, logger)
-	accountHandler := handler.NewAccountHandler(accountService, logger)
+	accountHandler := handler.NewAccountHandler(accountService, twoFactorService, logger)
+	transferLimitHandler := handler.NewTransferLimitHandler(transferLimitService, logger)
+	scheduledTransferHandler := handler.NewScheduledTransferHandler(scheduledTransferService, twoFactorService, logger)
+	twoFactorHandler := handler.NewTwoFactorHandler(twoFactorService, logger)
+	userHandler := handler.NewUserHandler(userService, logger)
+	passwordHandler := handler.NewPasswordHandler(passwordService, logger)
+	emailVerificationHandler := handler.NewEmailVerificationHandler(emailVerificationService, logger)
 	cardHandler := handler.NewCardHandler(cardService, logger)
+	disputeHandler := handler.NewDisputeHandler(cardService, logger)
+	jwksHandler := handler.NewJWKSHandler(keyManager, logger)
+	adminHandler := handler.NewAdminHandler(adminService, logger)
+	auditHandler := handler.NewAuditHandler(auditService, logger)
+	fraudHandler := handler.NewFraudHandler(fraudService, logger)
+	docsHandler := handler.NewDocsHandler(logger)
+	webhookHandler := handler.NewWebhookHandler(webhookService, logger)
+	streamHandler := handler.NewStreamHandler(streamService, accountService, authService, streamCfg, logger)
 
 	// JWT middleware
 	jwtMiddleware := middleware.NewJWTMiddleware(authService, logger)
+	roleMiddleware := middleware.NewRoleMiddleware(logger)
+	verifiedEmailMiddleware := middleware.NewVerifiedEmailMiddleware(userRepo, logger)
+	idempotencyMiddleware := middleware.NewIdempotencyMiddleware(idempotencyService, logger)
 
 	// Настройка маршрутизатора
-	r := mux.NewRouter().PathPrefix("/api").Subrouter()
-
-	// Публичные маршруты (без аутентификации)
-	r.HandleFunc("/register", authHandler.Register).Methods(http.MethodPost)
-	r.HandleFunc("/login", authHandler.Login).Methods(http.MethodPost)
-
-	// Защищенные маршруты (с проверкой JWT)
-	apiRouter := r.PathPrefix("").Subrouter()
-	apiRouter.Use(jwtMiddleware.Middleware)
-
-	// Маршруты для счетов
-	apiRouter.HandleFunc("/accounts", accountHandler.CreateAccount).Methods(http.MethodPost)
-	apiRouter.HandleFunc("/accounts", accountHandler.GetAccounts).Methods(http.MethodGet)
-	apiRouter.HandleFunc("/accounts/{id}/balance", accountHandler.UpdateBalance).Methods(http.MethodPatch)
-	apiRouter.HandleFunc("/accounts/{id}/transactions", accountHandler.GetTransactions).Methods(http.MethodGet)
-	apiRouter.HandleFunc("/transfer", accountHandler.Transfer).Methods(http.MethodPost)
+	root := router.New(router.Handlers{
+		Auth:              authHandler,
+		Account:           accountHandler,
+		TransferLimit:     transferLimitHandler,
+		ScheduledTransfer: scheduledTransferHandler,
+		TwoFactor:         twoFactorHandler,
+		User:              userHandler,
+		Password:          passwordHandler,
+		EmailVerification: emailVerificationHandler,
+		Card:              cardHandler,
+		Dispute:           disputeHandler,
+		JWKS:              jwksHandler,
+		Admin:             adminHandler,
+		Audit:             auditHandler,
+		Fraud:             fraudHandler,
+		Docs:              docsHandler,
+		Webhook:           webhookHandler,
+		Stream:            streamHandler,
+	}, router.Middlewares{
+		JWT:           jwtMiddleware,
+		Role:          roleMiddleware,
+		VerifiedEmail: verifiedEmailMiddleware,
+		Idempotency:   idempotencyMiddleware,
+	})
+
+	// Каждый маршрут должен быть описан в спецификации OpenAPI, и наоборот
+	if err := docs.Verify(root); err != nil {
+		logger.Fatalf("%v", err)
+	}
 
-	// Маршруты для карт
-	apiRouter.HandleFunc("/cards", cardHandler.CreateCard).Methods(http.MethodPost)
-	apiRouter.HandleFunc("/cards", cardHandler.GetCards).Methods(http.MethodGet)
-	apiRouter.HandleFunc("/cards/{id}", cardHandler.GetCardDetails).Methods(http.MethodGet)
-	apiRouter.HandleFunc("/payments", cardHandler.ProcessPayment).Methods(http.MethodPost)
+	// Фоновые задачи
+	bgCtx, stopBackground := context.WithCancel(ctx)
+	defer stopBackground()
+	go cardService.RunHoldExpiry(bgCtx, logger)
+	go keyManager.RunRotation(bgCtx, logger)
+	go scheduledTransferService.RunExecution(bgCtx, logger)
+	go idempotencyService.RunCleanup(bgCtx, logger)
+	go webhookService.RunDelivery(bgCtx, logger)
+	go creditOverdueService.RunOverdueCheck(bgCtx, logger)
+	go streamService.Run(bgCtx, logger)
 
 	// Настройка сервера
 	srv := &http.Server{
 		Addr:         fmt.Sprintf(":%s", "8080"),
-		Handler:      r,
+		Handler:      root,
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
@@ -131,6 +236,7 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	logger.Info("Завершение работы сервера...")
+	stopBackground()
 
 	// Ожидание завершения текущих запросов
 	ctxShutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -0,0 +1,17 @@
+package config
+
+import "time"
+
+// CreditOverdueConfig содержит настройки поиска просроченных платежей по кредитам
+type CreditOverdueConfig struct {
+	CheckInterval time.Duration // Периодичность поиска просроченных платежей
+	BatchSize     int           // Число платежей, обрабатываемых в одной транзакции
+}
+
+// LoadCreditOverdue загружает конфигурацию поиска просрочек из переменных окружения
+func LoadCreditOverdue() CreditOverdueConfig {
+	return CreditOverdueConfig{
+		CheckInterval: getDuration("CREDIT_OVERDUE_CHECK_INTERVAL", time.Hour),
+		BatchSize:     getInt("CREDIT_OVERDUE_BATCH_SIZE", 100),
+	}
+}
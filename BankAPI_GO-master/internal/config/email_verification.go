@@ -0,0 +1,19 @@
+package config
+
+import "time"
+
+// EmailVerificationConfig содержит настройки подтверждения email
+type EmailVerificationConfig struct {
+	TokenTTL       time.Duration // Срок действия ссылки подтверждения
+	ResendInterval time.Duration // Минимальный интервал между письмами подтверждения
+	URL            string        // Адрес подтверждения, к которому добавляется токен
+}
+
+// LoadEmailVerification загружает конфигурацию подтверждения email из переменных окружения
+func LoadEmailVerification() EmailVerificationConfig {
+	return EmailVerificationConfig{
+		TokenTTL:       getDuration("EMAIL_VERIFICATION_TTL", 24*time.Hour),
+		ResendInterval: getDuration("EMAIL_VERIFICATION_RESEND_INTERVAL", time.Minute),
+		URL:            getEnv("EMAIL_VERIFICATION_URL", "http://localhost:8080/api/email/verify?token="),
+	}
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+)
+
+// FraudConfig содержит настройки антифрод-проверки переводов и платежей по картам
+type FraudConfig struct {
+	ReviewThreshold int // Сумма баллов, при которой операция задерживается до решения администратора
+
+	HistoryWindow        time.Duration   // Период истории для оценки обычной суммы операций
+	HistoryMinOperations int             // Минимум операций в истории для правила необычной суммы
+	UnusualAmountFactor  decimal.Decimal // Во сколько раз сумма должна превышать среднюю
+
+	NewRecipientsWindow time.Duration   // Окно подсчета новых получателей
+	NewRecipientsMax    int             // Число новых получателей в окне, при котором срабатывает правило
+	LargeTransferAmount decimal.Decimal // Сумма крупного перевода для правила первого перевода получателю
+
+	CVVFailuresWindow time.Duration // Окно подсчета неудачных проверок CVV
+	CVVFailuresMax    int           // Число неудачных проверок CVV в окне, при котором срабатывает правило
+}
+
+// LoadFraud загружает конфигурацию антифрод-проверки из переменных окружения
+func LoadFraud() FraudConfig {
+	return FraudConfig{
+		ReviewThreshold:      getInt("FRAUD_REVIEW_THRESHOLD", 60),
+		HistoryWindow:        getDuration("FRAUD_HISTORY_WINDOW", 90*24*time.Hour),
+		HistoryMinOperations: getInt("FRAUD_HISTORY_MIN_OPERATIONS", 3),
+		UnusualAmountFactor:  getDecimal("FRAUD_UNUSUAL_AMOUNT_FACTOR", "5"),
+		NewRecipientsWindow:  getDuration("FRAUD_NEW_RECIPIENTS_WINDOW", time.Hour),
+		NewRecipientsMax:     getInt("FRAUD_NEW_RECIPIENTS_MAX", 3),
+		LargeTransferAmount:  getDecimal("FRAUD_LARGE_TRANSFER_AMOUNT", "100000"),
+		CVVFailuresWindow:    getDuration("FRAUD_CVV_FAILURES_WINDOW", 10*time.Minute),
+		CVVFailuresMax:       getInt("FRAUD_CVV_FAILURES_MAX", 2),
+	}
+}
+
+// getDecimal получает положительное число из переменной окружения
+func getDecimal(key, defaultValue string) decimal.Decimal {
+	value := getEnv(key, defaultValue)
+	amount, err := decimal.NewFromString(value)
+	if err != nil || !amount.IsPositive() {
+		logrus.Warnf("Неверное значение %s=%q, используется %s", key, value, defaultValue)
+		return decimal.RequireFromString(defaultValue)
+	}
+	return amount
+}
@@ -0,0 +1,17 @@
+package config
+
+import "time"
+
+// IdempotencyConfig содержит настройки ключей идемпотентности
+type IdempotencyConfig struct {
+	TTL             time.Duration // Сколько хранится ответ на запрос с ключом
+	CleanupInterval time.Duration // Период удаления устаревших ключей
+}
+
+// LoadIdempotency загружает конфигурацию ключей идемпотентности из переменных окружения
+func LoadIdempotency() IdempotencyConfig {
+	return IdempotencyConfig{
+		TTL:             getDuration("IDEMPOTENCY_TTL", 24*time.Hour),
+		CleanupInterval: getDuration("IDEMPOTENCY_CLEANUP_INTERVAL", time.Hour),
+	}
+}
@@ -1,24 +1,28 @@
 package config
 
 import (
-	"os"
 	"time"
 )
 
 // JWTConfig содержит настройки для JWT-токенов
 type JWTConfig struct {
-	Secret    string
-	ExpiresIn time.Duration
+	Issuer           string
+	SigningAlgorithm string        // Алгоритм подписи: RS256 или EdDSA
+	ExpiresIn        time.Duration // Срок жизни access-токена
+	RefreshExpiresIn time.Duration // Срок жизни refresh-токена и сессии
+	RotationInterval time.Duration // Период ротации ключей подписи
+	KeyCheckInterval time.Duration // Периодичность проверки необходимости ротации и обновления ключей
+	StreamTicketTTL  time.Duration // Срок жизни одноразового билета для подключения к потоку изменений
 }
 
 func LoadJWT() JWTConfig {
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		secret = "default-bank-api-jwt-secret-key"
-	}
-
 	return JWTConfig{
-		Secret:    secret,
-		ExpiresIn: 24 * time.Hour,
+		Issuer:           getEnv("JWT_ISSUER", "bank-api"),
+		SigningAlgorithm: getEnv("JWT_SIGNING_ALG", "RS256"),
+		ExpiresIn:        getDuration("JWT_ACCESS_TTL", 15*time.Minute),
+		RefreshExpiresIn: getDuration("JWT_REFRESH_TTL", 30*24*time.Hour),
+		RotationInterval: getDuration("JWT_KEY_ROTATION_INTERVAL", 30*24*time.Hour),
+		KeyCheckInterval: getDuration("JWT_KEY_CHECK_INTERVAL", 5*time.Minute),
+		StreamTicketTTL:  getDuration("JWT_STREAM_TICKET_TTL", 30*time.Second),
 	}
 }
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LoginGuardConfig содержит настройки защиты входа от перебора паролей
+type LoginGuardConfig struct {
+	FreeAttempts       int           // Число неудачных попыток без задержки
+	BaseDelay          time.Duration // Задержка после первой попытки сверх бесплатных, удваивается с каждой следующей
+	MaxDelay           time.Duration // Максимальная задержка между попытками
+	LockoutThreshold   int           // Число неудачных попыток для аккаунта, после которого вход блокируется
+	IPLockoutThreshold int           // Число неудачных попыток с одного IP, после которого вход блокируется
+	LockoutDuration    time.Duration // Длительность временной блокировки
+	FailureWindow      time.Duration // Через это время без ошибок счетчик попыток сбрасывается
+}
+
+// LoadLoginGuard загружает конфигурацию защиты входа из переменных окружения
+func LoadLoginGuard() LoginGuardConfig {
+	return LoginGuardConfig{
+		FreeAttempts:       getInt("LOGIN_FREE_ATTEMPTS", 3),
+		BaseDelay:          getDuration("LOGIN_BASE_DELAY", time.Second),
+		MaxDelay:           getDuration("LOGIN_MAX_DELAY", 5*time.Minute),
+		LockoutThreshold:   getInt("LOGIN_LOCKOUT_THRESHOLD", 10),
+		IPLockoutThreshold: getInt("LOGIN_IP_LOCKOUT_THRESHOLD", 50),
+		LockoutDuration:    getDuration("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+		FailureWindow:      getDuration("LOGIN_FAILURE_WINDOW", time.Hour),
+	}
+}
+
+// getInt получает положительное целое из переменной окружения или возвращает значение по умолчанию
+func getInt(key string, defaultValue int) int {
+	value := getEnv(key, "")
+	if value == "" {
+		return defaultValue
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		logrus.Warnf("Неверное значение %s=%q, используется %d", key, value, defaultValue)
+		return defaultValue
+	}
+	return n
+}
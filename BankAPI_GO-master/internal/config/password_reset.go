@@ -0,0 +1,17 @@
+package config
+
+import "time"
+
+// PasswordResetConfig содержит настройки восстановления пароля
+type PasswordResetConfig struct {
+	TokenTTL time.Duration // Срок действия токена сброса пароля
+	URL      string        // Адрес страницы сброса пароля, к которому добавляется токен
+}
+
+// LoadPasswordReset загружает конфигурацию восстановления пароля из переменных окружения
+func LoadPasswordReset() PasswordResetConfig {
+	return PasswordResetConfig{
+		TokenTTL: getDuration("PASSWORD_RESET_TTL", time.Hour),
+		URL:      getEnv("PASSWORD_RESET_URL", "http://localhost:8080/reset-password?token="),
+	}
+}
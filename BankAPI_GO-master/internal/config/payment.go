@@ -0,0 +1,38 @@
+package config
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// PaymentConfig содержит настройки двухфазных платежей по картам
+type PaymentConfig struct {
+	HoldTTL        time.Duration // Срок жизни холда до автоматического снятия
+	ExpiryInterval time.Duration // Периодичность проверки истекших холдов
+	CVVMaxAttempts int           // Число неверных CVV подряд, после которого карта блокируется
+}
+
+// LoadPayment загружает конфигурацию платежей из переменных окружения
+func LoadPayment() PaymentConfig {
+	return PaymentConfig{
+		HoldTTL:        getDuration("PAYMENT_HOLD_TTL", 7*24*time.Hour),
+		ExpiryInterval: getDuration("PAYMENT_HOLD_EXPIRY_INTERVAL", 10*time.Minute),
+		CVVMaxAttempts: getInt("CARD_CVV_MAX_ATTEMPTS", 3),
+	}
+}
+
+// getDuration получает длительность из переменной окружения или возвращает значение по умолчанию
+func getDuration(key string, defaultValue time.Duration) time.Duration {
+	value := getEnv(key, "")
+	if value == "" {
+		return defaultValue
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		logrus.Warnf("Неверное значение %s=%q, используется %s", key, value, defaultValue)
+		return defaultValue
+	}
+	return d
+}
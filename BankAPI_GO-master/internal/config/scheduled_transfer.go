@@ -0,0 +1,21 @@
+package config
+
+import "time"
+
+// ScheduledTransferConfig содержит настройки исполнения отложенных и регулярных переводов
+type ScheduledTransferConfig struct {
+	PollInterval  time.Duration // Периодичность поиска переводов, которые пора исполнить
+	BatchSize     int           // Число переводов, исполняемых за один проход
+	MaxRetries    int           // Число повторов исполнения при нехватке средств
+	RetryInterval time.Duration // Интервал между повторами
+}
+
+// LoadScheduledTransfer загружает конфигурацию регулярных переводов из переменных окружения
+func LoadScheduledTransfer() ScheduledTransferConfig {
+	return ScheduledTransferConfig{
+		PollInterval:  getDuration("SCHEDULED_TRANSFER_POLL_INTERVAL", time.Minute),
+		BatchSize:     getInt("SCHEDULED_TRANSFER_BATCH_SIZE", 100),
+		MaxRetries:    getInt("SCHEDULED_TRANSFER_MAX_RETRIES", 3),
+		RetryInterval: getDuration("SCHEDULED_TRANSFER_RETRY_INTERVAL", time.Hour),
+	}
+}
@@ -0,0 +1,21 @@
+package config
+
+// SMTPConfig содержит настройки отправки писем
+type SMTPConfig struct {
+	Host     string // Пустой хост - письма пишутся в лог
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// LoadSMTP загружает конфигурацию SMTP из переменных окружения
+func LoadSMTP() SMTPConfig {
+	return SMTPConfig{
+		Host:     getEnv("SMTP_HOST", ""),
+		Port:     getInt("SMTP_PORT", 587),
+		Username: getEnv("SMTP_USERNAME", ""),
+		Password: getEnv("SMTP_PASSWORD", ""),
+		From:     getEnv("SMTP_FROM", "noreply@bank-api.local"),
+	}
+}
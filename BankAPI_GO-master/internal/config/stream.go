@@ -0,0 +1,23 @@
+package config
+
+import "time"
+
+// StreamConfig содержит настройки потока изменений счетов (Server-Sent Events)
+type StreamConfig struct {
+	HeartbeatInterval     time.Duration // Периодичность комментария-пинга, чтобы соединение не закрывали прокси
+	BufferSize            int           // Число событий, которые ждут отправки медленному клиенту
+	MaxConnectionsPerUser int           // Число одновременных потоков одного пользователя
+	ReconnectDelay        time.Duration // Задержка перед повторным подключением к PostgreSQL для LISTEN
+	SessionCheckInterval  time.Duration // Периодичность проверки, что сессия открытого потока не отозвана
+}
+
+// LoadStream загружает конфигурацию потока изменений из переменных окружения
+func LoadStream() StreamConfig {
+	return StreamConfig{
+		HeartbeatInterval:     getDuration("STREAM_HEARTBEAT_INTERVAL", 15*time.Second),
+		BufferSize:            getInt("STREAM_BUFFER_SIZE", 64),
+		MaxConnectionsPerUser: getInt("STREAM_MAX_CONNECTIONS_PER_USER", 5),
+		ReconnectDelay:        getDuration("STREAM_RECONNECT_DELAY", 5*time.Second),
+		SessionCheckInterval:  getDuration("STREAM_SESSION_CHECK_INTERVAL", 30*time.Second),
+	}
+}
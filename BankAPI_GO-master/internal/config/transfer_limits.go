@@ -0,0 +1,54 @@
+package config
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/therealadik/bank-api/internal/models/transfer"
+)
+
+// TransferLimitsConfig содержит лимиты исходящих переводов и списаний пользователя по умолчанию.
+// Администратор может переопределить их для пользователя или установить для отдельного счета.
+type TransferLimitsConfig struct {
+	Defaults transfer.Limits
+
+	PreviewMax    int           // Число запросов предпросмотра получателя в окне
+	PreviewWindow time.Duration // Окно подсчета запросов предпросмотра получателя
+}
+
+// LoadTransferLimits загружает лимиты по умолчанию из переменных окружения.
+// Значение "0" или пустая строка снимает соответствующий лимит.
+func LoadTransferLimits() TransferLimitsConfig {
+	cfg := TransferLimitsConfig{
+		Defaults: transfer.Limits{
+			SingleMax:  getLimitDecimal("TRANSFER_LIMIT_SINGLE", "600000"),
+			DailyMax:   getLimitDecimal("TRANSFER_LIMIT_DAILY", "1000000"),
+			MonthlyMax: getLimitDecimal("TRANSFER_LIMIT_MONTHLY", "5000000"),
+		},
+		PreviewMax:    getInt("TRANSFER_PREVIEW_LIMIT", 30),
+		PreviewWindow: getDuration("TRANSFER_PREVIEW_WINDOW", time.Hour),
+	}
+
+	if value := getEnv("TRANSFER_LIMIT_HOURLY_COUNT", "20"); value != "0" && value != "" {
+		count := getInt("TRANSFER_LIMIT_HOURLY_COUNT", 20)
+		cfg.Defaults.HourlyCount = &count
+	}
+
+	return cfg
+}
+
+// getLimitDecimal получает сумму лимита из переменной окружения; "0" или пустая строка — лимита нет
+func getLimitDecimal(key, defaultValue string) decimal.NullDecimal {
+	value := getEnv(key, defaultValue)
+	if value == "" || value == "0" {
+		return decimal.NullDecimal{}
+	}
+
+	amount, err := decimal.NewFromString(value)
+	if err != nil || !amount.IsPositive() {
+		logrus.Warnf("Неверное значение %s=%q, используется %s", key, value, defaultValue)
+		amount = decimal.RequireFromString(defaultValue)
+	}
+	return decimal.NewNullDecimal(amount)
+}
@@ -0,0 +1,34 @@
+package config
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+)
+
+// TwoFactorConfig содержит настройки двухфакторной аутентификации (TOTP)
+type TwoFactorConfig struct {
+	Issuer       string              // Название сервиса в приложении-аутентификаторе
+	ChallengeTTL time.Duration       // Срок жизни токена второго шага входа
+	StepUpAmount decimal.NullDecimal // Сумма операции, выше которой требуется свежий TOTP-код (не задана - не требуется)
+}
+
+// LoadTwoFactor загружает конфигурацию 2FA из переменных окружения
+func LoadTwoFactor() TwoFactorConfig {
+	cfg := TwoFactorConfig{
+		Issuer:       getEnv("TOTP_ISSUER", "Bank API"),
+		ChallengeTTL: getDuration("TOTP_CHALLENGE_TTL", 5*time.Minute),
+	}
+
+	if value := getEnv("TOTP_STEP_UP_AMOUNT", ""); value != "" {
+		amount, err := decimal.NewFromString(value)
+		if err != nil || amount.IsNegative() {
+			logrus.Warnf("Неверное значение TOTP_STEP_UP_AMOUNT=%q, подтверждение операций отключено", value)
+		} else {
+			cfg.StepUpAmount = decimal.NewNullDecimal(amount)
+		}
+	}
+
+	return cfg
+}
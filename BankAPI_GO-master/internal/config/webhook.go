@@ -0,0 +1,51 @@
+package config
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// WebhookConfig содержит настройки доставки вебхуков
+type WebhookConfig struct {
+	PollInterval   time.Duration // Периодичность поиска доставок, которые пора выполнить
+	BatchSize      int           // Число доставок за один проход
+	Timeout        time.Duration // Таймаут запроса к получателю
+	MaxAttempts    int           // Число попыток, после которого доставка считается неудачной
+	RetryBaseDelay time.Duration // Задержка перед первым повтором; далее удваивается
+	RetryMaxDelay  time.Duration // Максимальная задержка между повторами
+
+	// AllowPrivateNetworks разрешает адреса http:// и доставку в локальные и частные сети.
+	// Нужен только для разработки и тестов: в рабочей среде вебхук мог бы обращаться
+	// к внутренним сервисам банка.
+	AllowPrivateNetworks bool
+}
+
+// LoadWebhook загружает конфигурацию вебхуков из переменных окружения
+func LoadWebhook() WebhookConfig {
+	return WebhookConfig{
+		PollInterval:         getDuration("WEBHOOK_POLL_INTERVAL", 10*time.Second),
+		BatchSize:            getInt("WEBHOOK_BATCH_SIZE", 50),
+		Timeout:              getDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+		MaxAttempts:          getInt("WEBHOOK_MAX_ATTEMPTS", 8),
+		RetryBaseDelay:       getDuration("WEBHOOK_RETRY_BASE_DELAY", 30*time.Second),
+		RetryMaxDelay:        getDuration("WEBHOOK_RETRY_MAX_DELAY", 6*time.Hour),
+		AllowPrivateNetworks: getBool("WEBHOOK_ALLOW_PRIVATE_NETWORKS", false),
+	}
+}
+
+// getBool получает логическое значение из переменной окружения или возвращает значение по умолчанию
+func getBool(key string, defaultValue bool) bool {
+	value := getEnv(key, "")
+	if value == "" {
+		return defaultValue
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		logrus.Warnf("Неверное значение %s=%q, используется %t", key, value, defaultValue)
+		return defaultValue
+	}
+	return b
+}
@@ -0,0 +1,184 @@
+// Package cron разбирает cron-выражения из пяти полей
+// (минута, час, день месяца, месяц, день недели) и вычисляет моменты срабатывания.
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidExpression возвращается для некорректного cron-выражения
+var ErrInvalidExpression = errors.New("неверное cron-выражение")
+
+// maxSearchYears ограничивает поиск следующего срабатывания (например, для 30 февраля)
+const maxSearchYears = 5
+
+// Schedule разобранное cron-выражение. Каждое поле — битовая маска допустимых значений.
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+	domStar, dowStar              bool // Поле начинается с "*" ("*" или "*/n")
+}
+
+type bounds struct {
+	min, max int
+}
+
+var (
+	minuteBounds = bounds{0, 59}
+	hourBounds   = bounds{0, 23}
+	domBounds    = bounds{1, 31}
+	monthBounds  = bounds{1, 12}
+	dowBounds    = bounds{0, 7} // 0 и 7 — воскресенье
+)
+
+// Parse разбирает выражение вида "30 9 * * 1-5". Поддерживаются "*", списки через запятую,
+// диапазоны "a-b" и шаги "*/n", "a-b/n", "a/n".
+func Parse(expr string) (*Schedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w: ожидается 5 полей, получено %d", ErrInvalidExpression, len(fields))
+	}
+
+	var s Schedule
+	var err error
+	if s.minute, err = parseField(fields[0], minuteBounds); err != nil {
+		return nil, err
+	}
+	if s.hour, err = parseField(fields[1], hourBounds); err != nil {
+		return nil, err
+	}
+	if s.dom, err = parseField(fields[2], domBounds); err != nil {
+		return nil, err
+	}
+	if s.month, err = parseField(fields[3], monthBounds); err != nil {
+		return nil, err
+	}
+	if s.dow, err = parseField(fields[4], dowBounds); err != nil {
+		return nil, err
+	}
+
+	// Воскресенье может быть задано как 7
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	s.domStar = strings.HasPrefix(fields[2], "*")
+	s.dowStar = strings.HasPrefix(fields[4], "*")
+
+	return &s, nil
+}
+
+// Next возвращает первый момент срабатывания строго после after
+// (с точностью до минуты, в часовом поясе after). Нулевое время — если
+// срабатываний в обозримом будущем нет.
+func (s *Schedule) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(maxSearchYears, 0, 0)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+
+	return time.Time{}
+}
+
+// dayMatches проверяет день месяца и день недели так же, как Vixie cron. Если оба поля
+// заданы явно (не начинаются с "*"), достаточно совпадения любого из них: "0 0 1 * 1" —
+// первое число и каждый понедельник. Если хотя бы одно поле начинается с "*", включая
+// шаг "*/n", должны совпасть оба: "0 0 */2 * 1" — понедельники, приходящиеся на нечетные числа.
+func (s *Schedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// parseField разбирает одно поле выражения в битовую маску
+func parseField(field string, b bounds) (uint64, error) {
+	var mask uint64
+	for _, part := range strings.Split(field, ",") {
+		bits, err := parseRange(part, b)
+		if err != nil {
+			return 0, err
+		}
+		mask |= bits
+	}
+	return mask, nil
+}
+
+// parseRange разбирает элемент списка: "*", "a", "a-b" с необязательным шагом "/n"
+func parseRange(part string, b bounds) (uint64, error) {
+	rangePart, stepPart, hasStep := strings.Cut(part, "/")
+
+	step := 1
+	if hasStep {
+		n, err := strconv.Atoi(stepPart)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("%w: неверный шаг %q", ErrInvalidExpression, part)
+		}
+		step = n
+	}
+
+	var start, end int
+	switch {
+	case rangePart == "*":
+		start, end = b.min, b.max
+	case strings.Contains(rangePart, "-"):
+		lo, hi, _ := strings.Cut(rangePart, "-")
+		var err error
+		if start, err = parseValue(lo, b); err != nil {
+			return 0, err
+		}
+		if end, err = parseValue(hi, b); err != nil {
+			return 0, err
+		}
+		if start > end {
+			return 0, fmt.Errorf("%w: пустой диапазон %q", ErrInvalidExpression, part)
+		}
+	default:
+		v, err := parseValue(rangePart, b)
+		if err != nil {
+			return 0, err
+		}
+		start, end = v, v
+		if hasStep {
+			end = b.max // "a/n" — от a до конца диапазона с шагом n
+		}
+	}
+
+	var mask uint64
+	for v := start; v <= end; v += step {
+		mask |= 1 << uint(v)
+	}
+	return mask, nil
+}
+
+// parseValue разбирает число и проверяет, что оно в допустимых границах
+func parseValue(s string, b bounds) (int, error) {
+	v, err := strconv.Atoi(s)
+	if err != nil || v < b.min || v > b.max {
+		return 0, fmt.Errorf("%w: значение %q вне диапазона %d-%d", ErrInvalidExpression, s, b.min, b.max)
+	}
+	return v, nil
+}
@@ -0,0 +1,192 @@
+package cron
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// bits возвращает битовую маску из перечисленных значений
+func bits(values ...int) uint64 {
+	var mask uint64
+	for _, v := range values {
+		mask |= 1 << uint(v)
+	}
+	return mask
+}
+
+// span возвращает битовую маску значений от start до end с шагом step
+func span(start, end, step int) uint64 {
+	var mask uint64
+	for v := start; v <= end; v += step {
+		mask |= 1 << uint(v)
+	}
+	return mask
+}
+
+func TestParseField(t *testing.T) {
+	tests := []struct {
+		name  string
+		field string
+		b     bounds
+		want  uint64
+	}{
+		{"любое значение", "*", minuteBounds, span(0, 59, 1)},
+		{"одно значение", "7", hourBounds, bits(7)},
+		{"список", "1,5,10", minuteBounds, bits(1, 5, 10)},
+		{"диапазон", "10-12", hourBounds, bits(10, 11, 12)},
+		{"список диапазонов", "1-3,20-22", domBounds, bits(1, 2, 3, 20, 21, 22)},
+		{"шаг от начала", "*/20", minuteBounds, bits(0, 20, 40)},
+		{"шаг по дням месяца", "*/10", domBounds, bits(1, 11, 21, 31)},
+		{"шаг в диапазоне", "10-30/10", minuteBounds, bits(10, 20, 30)},
+		{"шаг от значения", "5/15", minuteBounds, bits(5, 20, 35, 50)},
+		{"шаг больше диапазона", "3-5/10", hourBounds, bits(3)},
+		{"границы", "0,59", minuteBounds, bits(0, 59)},
+		{"месяцы", "1-12/3", monthBounds, bits(1, 4, 7, 10)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseField(tt.field, tt.b)
+			if err != nil {
+				t.Fatalf("parseField(%q): %v", tt.field, err)
+			}
+			if got != tt.want {
+				t.Fatalf("parseField(%q) = %b, ожидалось %b", tt.field, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		expr             string
+		dom, dow         uint64
+		domStar, dowStar bool
+	}{
+		{"0 0 * * *", span(1, 31, 1), span(0, 7, 1), true, true},
+		{"0 0 */2 * *", span(1, 31, 2), span(0, 7, 1), true, true},
+		{"0 0 */2 * 1", span(1, 31, 2), bits(1), true, false},
+		{"0 0 1-31 * 1", span(1, 31, 1), bits(1), false, false},
+		{"0 0 1 * */2", bits(1), bits(0, 2, 4, 6), false, true},
+		{"0 0 * * 7", span(1, 31, 1), bits(0, 7), true, false},
+		{"0 0 * * 5-7", span(1, 31, 1), bits(0, 5, 6, 7), true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.expr, err)
+			}
+			if s.dom != tt.dom || s.dow != tt.dow {
+				t.Fatalf("дни месяца %b, дни недели %b; ожидалось %b, %b", s.dom, s.dow, tt.dom, tt.dow)
+			}
+			if s.domStar != tt.domStar || s.dowStar != tt.dowStar {
+				t.Fatalf("domStar=%v dowStar=%v, ожидалось %v %v", s.domStar, s.dowStar, tt.domStar, tt.dowStar)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	exprs := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * 32 * *",
+		"* * * 0 *",
+		"* * * 13 *",
+		"* * * * 8",
+		"-1 * * * *",
+		"a * * * *",
+		"*/0 * * * *",
+		"*/-5 * * * *",
+		"*/x * * * *",
+		"5-1 * * * *",
+		"1- * * * *",
+		"1-5-7 * * * *",
+		"1,,2 * * * *",
+		"@daily",
+	}
+
+	for _, expr := range exprs {
+		t.Run(expr, func(t *testing.T) {
+			if _, err := Parse(expr); !errors.Is(err, ErrInvalidExpression) {
+				t.Fatalf("Parse(%q) = %v, ожидалась ErrInvalidExpression", expr, err)
+			}
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	date := func(year int, month time.Month, day, hour, min int) time.Time {
+		return time.Date(year, month, day, hour, min, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name  string
+		expr  string
+		after time.Time
+		want  time.Time
+	}{
+		{"строго после", "30 9 * * *", date(2026, 10, 19, 9, 30), date(2026, 10, 20, 9, 30)},
+		{"секунды отбрасываются", "30 9 * * *", date(2026, 10, 19, 9, 29).Add(30 * time.Second), date(2026, 10, 19, 9, 30)},
+		{"шаг минут", "*/15 * * * *", date(2026, 10, 19, 10, 7), date(2026, 10, 19, 10, 15)},
+		{"шаг минут через год", "*/15 * * * *", date(2026, 12, 31, 23, 50), date(2027, 1, 1, 0, 0)},
+		{"рабочие дни через выходные", "30 9 * * 1-5", date(2026, 10, 16, 10, 0), date(2026, 10, 19, 9, 30)},
+		{"первое число через год", "0 0 1 * *", date(2026, 12, 15, 12, 0), date(2027, 1, 1, 0, 0)},
+		{"последняя минута года", "59 23 31 12 *", date(2026, 12, 31, 23, 59), date(2027, 12, 31, 23, 59)},
+		{"31 число пропускает короткий месяц", "0 12 31 * *", date(2026, 4, 1, 0, 0), date(2026, 5, 31, 12, 0)},
+		{"29 февраля в високосном году", "0 0 29 2 *", date(2026, 3, 1, 0, 0), date(2028, 2, 29, 0, 0)},
+		{"шаг по месяцам через год", "0 0 1 */5 *", date(2026, 11, 2, 0, 0), date(2027, 1, 1, 0, 0)},
+		{"воскресенье как 7", "0 0 * * 7", date(2026, 10, 19, 0, 0), date(2026, 10, 25, 0, 0)},
+		{"воскресенье как 0", "0 0 * * 0", date(2026, 10, 19, 0, 0), date(2026, 10, 25, 0, 0)},
+		{"шаг по дням месяца через месяц", "0 0 */10 * *", date(2026, 10, 31, 0, 0), date(2026, 11, 1, 0, 0)},
+		// Оба поля заданы явно: достаточно совпадения любого
+		{"день месяца или понедельник", "0 0 1 * 1", date(2026, 10, 2, 0, 0), date(2026, 10, 5, 0, 0)},
+		{"день месяца раньше понедельника", "0 0 1 * 1", date(2026, 10, 26, 0, 0), date(2026, 11, 1, 0, 0)},
+		// Поле со "*/n" не ограничивает дни само по себе: должны совпасть оба поля
+		{"нечетный понедельник", "0 0 */2 * 1", date(2026, 10, 19, 0, 0), date(2026, 11, 9, 0, 0)},
+		{"первое число в четный день недели", "0 0 1 * */2", date(2026, 10, 2, 0, 0), date(2026, 11, 1, 0, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.expr, err)
+			}
+			if got := s.Next(tt.after); !got.Equal(tt.want) {
+				t.Fatalf("Next(%s) = %s, ожидалось %s", tt.after, got, tt.want)
+			}
+		})
+	}
+}
+
+// Выражение без срабатываний возвращает нулевое время
+func TestNextNever(t *testing.T) {
+	s, err := Parse("0 0 30 2 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Next(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)); !got.IsZero() {
+		t.Fatalf("Next = %s, ожидалось нулевое время", got)
+	}
+}
+
+// Следующее срабатывание вычисляется в часовом поясе after
+func TestNextLocation(t *testing.T) {
+	loc := time.FixedZone("UTC+3", 3*60*60)
+	s, err := Parse("0 9 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := s.Next(time.Date(2026, 10, 19, 10, 0, 0, 0, loc))
+	if want := time.Date(2026, 10, 20, 9, 0, 0, 0, loc); !got.Equal(want) || got.Location() != loc {
+		t.Fatalf("Next = %s, ожидалось %s", got, want)
+	}
+}
@@ -0,0 +1,152 @@
+// Package docs содержит спецификацию OpenAPI 3 и проверку ее соответствия
+// зарегистрированным маршрутам.
+package docs
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// Spec спецификация API в формате OpenAPI 3 (JSON)
+//
+//go:embed openapi.json
+var Spec []byte
+
+// operationMethods методы HTTP, которые могут быть описаны в элементе paths
+var operationMethods = []string{
+	http.MethodGet, http.MethodPut, http.MethodPost, http.MethodDelete,
+	http.MethodOptions, http.MethodHead, http.MethodPatch, http.MethodTrace,
+}
+
+// Operation метод и путь маршрута в нотации OpenAPI (например, GET /api/cards/{id})
+type Operation struct {
+	Method string
+	Path   string
+}
+
+func (o Operation) String() string {
+	return o.Method + " " + o.Path
+}
+
+// Mismatch расхождение между маршрутизатором и спецификацией
+type Mismatch struct {
+	// Undocumented маршруты, которых нет в спецификации
+	Undocumented []Operation
+	// Stale операции спецификации, для которых нет маршрута
+	Stale []Operation
+}
+
+func (m *Mismatch) Error() string {
+	var parts []string
+	if len(m.Undocumented) > 0 {
+		parts = append(parts, "не описаны в спецификации: "+joinOperations(m.Undocumented))
+	}
+	if len(m.Stale) > 0 {
+		parts = append(parts, "описаны в спецификации, но не зарегистрированы: "+joinOperations(m.Stale))
+	}
+	return "спецификация OpenAPI не соответствует маршрутам: " + strings.Join(parts, "; ")
+}
+
+func joinOperations(ops []Operation) string {
+	s := make([]string, len(ops))
+	for i, op := range ops {
+		s[i] = op.String()
+	}
+	return strings.Join(s, ", ")
+}
+
+// Verify сверяет маршруты router со спецификацией в обе стороны: каждый маршрут
+// должен быть описан, и каждая описанная операция должна быть зарегистрирована.
+// Возвращает *Mismatch при расхождении.
+func Verify(router *mux.Router) error {
+	documented, err := specOperations()
+	if err != nil {
+		return err
+	}
+	registered, err := routerOperations(router)
+	if err != nil {
+		return err
+	}
+
+	mismatch := &Mismatch{}
+	for op := range registered {
+		if !documented[op] {
+			mismatch.Undocumented = append(mismatch.Undocumented, op)
+		}
+	}
+	for op := range documented {
+		if !registered[op] {
+			mismatch.Stale = append(mismatch.Stale, op)
+		}
+	}
+	if len(mismatch.Undocumented) == 0 && len(mismatch.Stale) == 0 {
+		return nil
+	}
+	sortOperations(mismatch.Undocumented)
+	sortOperations(mismatch.Stale)
+	return mismatch
+}
+
+// specOperations возвращает операции, описанные в спецификации
+func specOperations() (map[Operation]bool, error) {
+	var spec struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(Spec, &spec); err != nil {
+		return nil, fmt.Errorf("ошибка разбора спецификации OpenAPI: %w", err)
+	}
+
+	ops := make(map[Operation]bool)
+	for path, item := range spec.Paths {
+		for key := range item {
+			method := strings.ToUpper(key)
+			// Кроме операций элемент пути может содержать parameters, summary и т.п.
+			if slices.Contains(operationMethods, method) {
+				ops[Operation{Method: method, Path: path}] = true
+			}
+		}
+	}
+	return ops, nil
+}
+
+// routerOperations возвращает маршруты с обработчиками и методами.
+// Префиксы подмаршрутизаторов без собственных обработчиков пропускаются.
+func routerOperations(router *mux.Router) (map[Operation]bool, error) {
+	ops := make(map[Operation]bool)
+	err := router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		if route.GetHandler() == nil {
+			return nil
+		}
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return fmt.Errorf("маршрут %s зарегистрирован без методов", path)
+		}
+		for _, method := range methods {
+			ops[Operation{Method: method, Path: path}] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ops, nil
+}
+
+func sortOperations(ops []Operation) {
+	slices.SortFunc(ops, func(a, b Operation) int {
+		if c := strings.Compare(a.Path, b.Path); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Method, b.Method)
+	})
+}
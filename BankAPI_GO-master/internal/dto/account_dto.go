@@ -8,38 +8,76 @@ import (
 
 // CreateAccountRequest запрос на создание счета
 type CreateAccountRequest struct {
-	Currency account.Currency `json:"currency"`
+	Currency account.Currency `json:"currency" binding:"required"`
 }
 
 // UpdateBalanceRequest запрос на пополнение/списание
 type UpdateBalanceRequest struct {
-	Amount decimal.Decimal `json:"amount"`
+	Amount decimal.Decimal `json:"amount" binding:"required,money"`
 }
 
-// TransferRequest запрос на перевод между счетами
+// TransferRecipientRequest получатель перевода: ровно одно из полей.
+// to_account_id допускается только для собственных счетов.
+type TransferRecipientRequest struct {
+	ToAccountID     int64  `json:"to_account_id,omitempty" binding:"omitempty,min=1"`
+	ToAccountNumber string `json:"to_account_number,omitempty"`
+	ToEmail         string `json:"to_email,omitempty" binding:"omitempty,email"`
+}
+
+// TransferRequest запрос на перевод
 type TransferRequest struct {
-	FromAccountID int64           `json:"from_account_id"`
-	ToAccountID   int64           `json:"to_account_id"`
-	Amount        decimal.Decimal `json:"amount"`
+	FromAccountID int64 `json:"from_account_id" binding:"required,min=1"`
+	TransferRecipientRequest
+	Amount decimal.Decimal `json:"amount" binding:"amount"`
+}
+
+// TransferResponse результат перевода. Статус pending_review означает, что перевод
+// задержан антифрод-проверкой: сумма удерживается до решения администратора.
+type TransferResponse struct {
+	Status        string `json:"status"`
+	TransactionID int64  `json:"transaction_id"`
+}
+
+// TransferPreviewRequest запрос на проверку получателя перед переводом
+type TransferPreviewRequest struct {
+	FromAccountID int64 `json:"from_account_id" binding:"required,min=1"`
+	TransferRecipientRequest
+}
+
+// TransferPreviewResponse замаскированные данные получателя перевода
+type TransferPreviewResponse struct {
+	RecipientName string           `json:"recipient_name"`
+	AccountNumber string           `json:"account_number"`
+	Currency      account.Currency `json:"currency"`
 }
 
 // AccountResponse ответ со счетом
 type AccountResponse struct {
-	ID        int64            `json:"id"`
-	UserID    int64            `json:"user_id"`
-	Balance   decimal.Decimal  `json:"balance"`
-	Currency  account.Currency `json:"currency"`
-	CreatedAt string           `json:"created_at"`
+	ID               int64            `json:"id"`
+	Number           string           `json:"number"`
+	UserID           int64            `json:"user_id"`
+	Balance          decimal.Decimal  `json:"balance"`
+	AvailableBalance decimal.Decimal  `json:"available_balance"`
+	Currency         account.Currency `json:"currency"`
+	Status           account.Status   `json:"status"`
+	CreatedAt        string           `json:"created_at"`
+	ClosedAt         *string          `json:"closed_at,omitempty"`
+}
+
+// CloseAccountRequest запрос на закрытие счета
+type CloseAccountRequest struct {
+	TransferToAccountID *int64 `json:"transfer_to_account_id,omitempty" binding:"omitempty,min=1"` // Счет для перевода остатка
 }
 
 // TransactionResponse ответ с транзакцией
 type TransactionResponse struct {
-	ID        int64              `json:"id"`
-	AccountID int64              `json:"account_id"`
-	Amount    decimal.Decimal    `json:"amount"`
-	Type      transaction.Type   `json:"type"`
-	Status    transaction.Status `json:"status"`
-	CreatedAt string             `json:"created_at"`
+	ID         int64              `json:"id"`
+	AccountID  int64              `json:"account_id"`
+	Amount     decimal.Decimal    `json:"amount"`
+	Type       transaction.Type   `json:"type"`
+	Status     transaction.Status `json:"status"`
+	OriginalID *int64             `json:"original_transaction_id,omitempty"` // Исходная транзакция возврата
+	CreatedAt  string             `json:"created_at"`
 }
 
 // AccountsListResponse список счетов
@@ -47,6 +85,12 @@ type AccountsListResponse struct {
 	Accounts []AccountResponse `json:"accounts"`
 }
 
+// StreamReauthEvent событие reauth потока изменений: поток закрывается,
+// для переподключения нужен действующий токен или новый билет
+type StreamReauthEvent struct {
+	Reason string `json:"reason"` // TOKEN_EXPIRED или SESSION_REVOKED
+}
+
 // TransactionListResponse список транзакций
 type TransactionListResponse struct {
 	Transactions []TransactionResponse `json:"transactions"`
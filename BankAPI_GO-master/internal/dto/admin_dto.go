@@ -0,0 +1,50 @@
+package dto
+
+import "github.com/therealadik/bank-api/internal/models"
+
+// AdminUserResponse данные пользователя для администратора
+type AdminUserResponse struct {
+	ProfileResponse
+	Accounts []AccountResponse `json:"accounts"`
+	Cards    []CardResponse    `json:"cards"`
+}
+
+// ChangeRoleRequest запрос администратора на изменение роли пользователя
+type ChangeRoleRequest struct {
+	Role models.Role `json:"role" binding:"required"`
+}
+
+// AdminActionRequest запрос на административное действие с обязательной причиной
+type AdminActionRequest struct {
+	Reason string `json:"reason" binding:"max=500"`
+}
+
+// AuditEntryResponse запись журнала аудита
+type AuditEntryResponse struct {
+	ID         int64              `json:"id"`
+	ActorID    *int64             `json:"actor_id"`
+	Action     models.AuditAction `json:"action"`
+	TargetType string             `json:"target_type,omitempty"`
+	TargetID   *int64             `json:"target_id,omitempty"`
+	RequestID  string             `json:"request_id,omitempty"`
+	IP         string             `json:"ip,omitempty"`
+	UserAgent  string             `json:"user_agent,omitempty"`
+	Details    string             `json:"details,omitempty"`
+	CreatedAt  string             `json:"created_at"`
+	PrevHash   string             `json:"prev_hash"`
+	Hash       string             `json:"hash"`
+}
+
+// AuditListResponse страница журнала аудита (от новых записей к старым).
+// Следующая страница запрашивается с before_id = NextBeforeID; пустая страница - конец журнала.
+type AuditListResponse struct {
+	Entries      []AuditEntryResponse `json:"entries"`
+	NextBeforeID *int64               `json:"next_before_id,omitempty"`
+}
+
+// AuditVerifyResponse результат проверки цепочки журнала аудита
+type AuditVerifyResponse struct {
+	Valid      bool   `json:"valid"`
+	Checked    int64  `json:"checked"`
+	BrokenAtID *int64 `json:"broken_at_id,omitempty"`
+}
@@ -3,16 +3,92 @@ package dto
 // RegisterRequest - запрос на регистрацию пользователя
 type RegisterRequest struct {
 	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=6"`
+	Username string `json:"username" binding:"required,min=3,max=32"`
+	Password string `json:"password" binding:"required,min=8,max=72"`
 }
 
 // LoginRequest - запрос на аутентификацию пользователя
 type LoginRequest struct {
 	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required"`
+	Password string `json:"password" binding:"required,max=72"`
 }
 
-// AuthResponse - ответ с токеном аутентификации
+// RefreshRequest - запрос на обновление пары токенов
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// AuthResponse - ответ с токенами аутентификации.
+// Если у пользователя включена 2FA, вместо токенов возвращается токен второго шага входа.
 type AuthResponse struct {
-	Token string `json:"token"`
+	Token             string `json:"token,omitempty"`         // Access-токен
+	RefreshToken      string `json:"refresh_token,omitempty"` // Одноразовый refresh-токен
+	TokenType         string `json:"token_type,omitempty"`
+	TwoFactorRequired bool   `json:"two_factor_required,omitempty"`
+	ChallengeToken    string `json:"challenge_token,omitempty"` // Токен для /login/2fa
+	ExpiresIn         int64  `json:"expires_in"`                // Срок жизни выданного токена в секундах
+}
+
+// TwoFactorLoginRequest - второй шаг входа: код TOTP или код восстановления
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required,max=32"`
+}
+
+// TwoFactorCodeRequest - запрос, подтверждаемый кодом TOTP
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required,max=32"`
+}
+
+// TwoFactorEnrollResponse - данные для настройки приложения-аутентификатора
+type TwoFactorEnrollResponse struct {
+	Secret          string `json:"secret"`           // Base32-секрет для ручного ввода
+	ProvisioningURI string `json:"provisioning_uri"` // otpauth:// URI для QR-кода
+}
+
+// RecoveryCodesResponse - одноразовые коды восстановления (показываются один раз)
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// ProfileResponse - профиль текущего пользователя
+type ProfileResponse struct {
+	ID               int64  `json:"id"`
+	Email            string `json:"email"`
+	Username         string `json:"username"`
+	Role             string `json:"role"`
+	TwoFactorEnabled bool   `json:"two_factor_enabled"`
+	EmailVerified    bool   `json:"email_verified"`
+	CreatedAt        string `json:"created_at"`
+	UpdatedAt        string `json:"updated_at"`
+}
+
+// UpdateProfileRequest - изменение профиля; отсутствующие поля не меняются
+type UpdateProfileRequest struct {
+	Username *string `json:"username,omitempty" binding:"omitempty,min=3,max=32"`
+	Email    *string `json:"email,omitempty" binding:"omitempty,email"`
+}
+
+// ChangePasswordRequest - смена пароля текущим пользователем
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required,max=72"`
+	NewPassword     string `json:"new_password" binding:"required,min=8,max=72"`
+}
+
+// ForgotPasswordRequest - запрос письма для сброса пароля
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordRequest - установка нового пароля по токену из письма
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=8,max=72"`
+}
+
+// StreamTicketResponse - одноразовый билет для подключения к потоку изменений счетов
+// (GET /api/accounts/stream?ticket=...)
+type StreamTicketResponse struct {
+	Ticket    string `json:"ticket"`
+	ExpiresIn int64  `json:"expires_in"` // Срок жизни билета в секундах
 }
@@ -1,14 +1,22 @@
 package dto
 
+import (
+	"github.com/shopspring/decimal"
+	"github.com/therealadik/bank-api/internal/models"
+	"github.com/therealadik/bank-api/internal/models/payment"
+)
+
 // CreateCardRequest запрос на создание новой карты
 type CreateCardRequest struct {
-	PGPKey string `json:"pgp_key"`
+	AccountID int64  `json:"account_id" binding:"required,min=1"`
+	PGPKey    string `json:"pgp_key" binding:"required,max=32768"`
 }
 
 // CreateCardResponse ответ с данными созданной карты
 type CreateCardResponse struct {
 	ID         int64  `json:"id"`
 	UserID     int64  `json:"user_id"`
+	AccountID  int64  `json:"account_id"`
 	CreatedAt  string `json:"created_at"`
 	CardNumber string `json:"card_number"`
 	Expire     string `json:"expire"`
@@ -17,9 +25,11 @@ type CreateCardResponse struct {
 
 // CardResponse ответ с базовыми данными карты (без секретных данных)
 type CardResponse struct {
-	ID        int64  `json:"id"`
-	UserID    int64  `json:"user_id"`
-	CreatedAt string `json:"created_at"`
+	ID        int64             `json:"id"`
+	UserID    int64             `json:"user_id"`
+	AccountID *int64            `json:"account_id"`
+	Status    models.CardStatus `json:"status"`
+	CreatedAt string            `json:"created_at"`
 }
 
 // CardDetailsResponse ответ с деталями карты
@@ -36,15 +46,58 @@ type CardListResponse struct {
 
 // CardPaymentRequest запрос на оплату картой
 type CardPaymentRequest struct {
-	CardID int64  `json:"card_id"`
-	Amount string `json:"amount"`
-	CVV    string `json:"cvv"`
-	PGPKey string `json:"pgp_key"`
+	CardID           int64           `json:"card_id" binding:"required,min=1"`
+	Amount           decimal.Decimal `json:"amount" binding:"amount"`
+	CVV              string          `json:"cvv" binding:"required,len=3,digits"`
+	PGPKey           string          `json:"pgp_key" binding:"required,max=32768"`
+	MerchantCategory string          `json:"merchant_category,omitempty" binding:"omitempty,len=4,digits"` // MCC торговца
 }
 
 // CardPaymentResponse ответ на запрос оплаты
 type CardPaymentResponse struct {
-	Success     bool   `json:"success"`
-	PaymentID   string `json:"payment_id,omitempty"`
-	Description string `json:"description,omitempty"`
+	Success     bool            `json:"success"`
+	PaymentID   string          `json:"payment_id,omitempty"`
+	Status      payment.Status  `json:"status,omitempty"`
+	Amount      decimal.Decimal `json:"amount"`
+	ExpiresAt   string          `json:"expires_at,omitempty"`
+	Description string          `json:"description,omitempty"`
+}
+
+// CapturePaymentRequest запрос на списание авторизованного платежа
+// (без суммы списывается вся сумма холда)
+type CapturePaymentRequest struct {
+	Amount decimal.NullDecimal `json:"amount" binding:"omitempty,amount"`
+}
+
+// RefundPaymentRequest запрос на возврат по платежу
+// (без суммы возвращается весь еще не возвращенный остаток)
+type RefundPaymentRequest struct {
+	Amount decimal.NullDecimal `json:"amount" binding:"omitempty,amount"`
+}
+
+// RefundPaymentResponse ответ на запрос возврата
+type RefundPaymentResponse struct {
+	PaymentID           string          `json:"payment_id"`
+	Status              payment.Status  `json:"status"`
+	Amount              decimal.Decimal `json:"amount"`
+	RefundedAmount      decimal.Decimal `json:"refunded_amount"`
+	RefundTransactionID int64           `json:"refund_transaction_id"`
+	RefundAmount        decimal.Decimal `json:"refund_amount"`
+}
+
+// CardLimitRequest запрос на установку лимитов карты (null - без ограничения)
+type CardLimitRequest struct {
+	PerTransaction    decimal.NullDecimal `json:"per_transaction" binding:"omitempty,amount"`
+	Daily             decimal.NullDecimal `json:"daily" binding:"omitempty,amount"`
+	Monthly           decimal.NullDecimal `json:"monthly" binding:"omitempty,amount"`
+	BlockedCategories []string            `json:"blocked_categories" binding:"max=100"`
+}
+
+// CardLimitResponse ответ с лимитами карты
+type CardLimitResponse struct {
+	CardID            int64               `json:"card_id"`
+	PerTransaction    decimal.NullDecimal `json:"per_transaction"`
+	Daily             decimal.NullDecimal `json:"daily"`
+	Monthly           decimal.NullDecimal `json:"monthly"`
+	BlockedCategories []string            `json:"blocked_categories"`
 }
@@ -0,0 +1,37 @@
+package dto
+
+import (
+	"github.com/shopspring/decimal"
+	"github.com/therealadik/bank-api/internal/models/payment"
+)
+
+// OpenDisputeRequest запрос на открытие спора по платежу
+// (без суммы оспаривается весь еще не возвращенный остаток)
+type OpenDisputeRequest struct {
+	Amount decimal.NullDecimal `json:"amount" binding:"omitempty,amount"`
+	Reason string              `json:"reason" binding:"max=1000"`
+}
+
+// UpdateDisputeStatusRequest запрос сотрудника поддержки на смену статуса спора
+type UpdateDisputeStatusRequest struct {
+	Status  payment.DisputeStatus `json:"status" binding:"required"`
+	Comment string                `json:"comment" binding:"max=1000"`
+}
+
+// DisputeResponse ответ со спором
+type DisputeResponse struct {
+	ID                  int64                 `json:"id"`
+	PaymentID           int64                 `json:"payment_id"`
+	Amount              decimal.Decimal       `json:"amount"`
+	Reason              string                `json:"reason"`
+	Status              payment.DisputeStatus `json:"status"`
+	ResolutionComment   string                `json:"resolution_comment,omitempty"`
+	RefundTransactionID *int64                `json:"refund_transaction_id,omitempty"`
+	CreatedAt           string                `json:"created_at"`
+	UpdatedAt           string                `json:"updated_at"`
+}
+
+// DisputeListResponse список споров
+type DisputeListResponse struct {
+	Disputes []DisputeResponse `json:"disputes"`
+}
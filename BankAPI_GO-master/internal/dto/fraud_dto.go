@@ -0,0 +1,30 @@
+package dto
+
+import (
+	"github.com/shopspring/decimal"
+	"github.com/therealadik/bank-api/internal/models/fraud"
+)
+
+// FraudReviewResponse операция, задержанная антифрод-проверкой
+type FraudReviewResponse struct {
+	ID            int64           `json:"id"`
+	Kind          fraud.Kind      `json:"kind"`
+	UserID        int64           `json:"user_id"`
+	AccountID     int64           `json:"account_id"`
+	ToAccountID   *int64          `json:"to_account_id,omitempty"`
+	TransactionID *int64          `json:"transaction_id,omitempty"`
+	PaymentID     *int64          `json:"payment_id,omitempty"`
+	Amount        decimal.Decimal `json:"amount"`
+	Score         int             `json:"score"`
+	Reasons       []fraud.Reason  `json:"reasons"`
+	Status        fraud.Status    `json:"status"`
+	ReviewedBy    *int64          `json:"reviewed_by,omitempty"`
+	Comment       string          `json:"comment,omitempty"`
+	ReviewedAt    *string         `json:"reviewed_at,omitempty"`
+	CreatedAt     string          `json:"created_at"`
+}
+
+// FraudReviewListResponse список задержанных операций
+type FraudReviewListResponse struct {
+	Reviews []FraudReviewResponse `json:"reviews"`
+}
@@ -0,0 +1,18 @@
+package dto
+
+// JWK открытый ключ в формате JSON Web Key (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`   // Модуль RSA
+	E   string `json:"e,omitempty"`   // Экспонента RSA
+	Crv string `json:"crv,omitempty"` // Кривая OKP
+	X   string `json:"x,omitempty"`   // Открытый ключ OKP
+}
+
+// JWKSResponse набор открытых ключей для проверки токенов
+type JWKSResponse struct {
+	Keys []JWK `json:"keys"`
+}
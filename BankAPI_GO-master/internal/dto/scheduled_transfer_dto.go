@@ -0,0 +1,58 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/therealadik/bank-api/internal/models/transfer"
+)
+
+// CreateScheduledTransferRequest запрос на создание отложенного или регулярного перевода
+type CreateScheduledTransferRequest struct {
+	FromAccountID int64 `json:"from_account_id" binding:"required,min=1"`
+	TransferRecipientRequest
+	Amount    decimal.Decimal    `json:"amount" binding:"amount"`
+	Frequency transfer.Frequency `json:"frequency" binding:"required"`
+	Cron      string             `json:"cron,omitempty" binding:"max=100"` // Для frequency=CRON, пять полей, UTC
+	StartAt   *time.Time         `json:"start_at,omitempty"`               // RFC 3339; по умолчанию — сейчас
+	EndAt     *time.Time         `json:"end_at,omitempty"`
+}
+
+// ScheduledTransferResponse ответ с регулярным переводом
+type ScheduledTransferResponse struct {
+	ID              int64              `json:"id"`
+	FromAccountID   int64              `json:"from_account_id"`
+	ToAccountID     *int64             `json:"to_account_id,omitempty"`
+	ToAccountNumber *string            `json:"to_account_number,omitempty"`
+	ToEmail         *string            `json:"to_email,omitempty"`
+	Amount          decimal.Decimal    `json:"amount"`
+	Frequency       transfer.Frequency `json:"frequency"`
+	Cron            *string            `json:"cron,omitempty"`
+	StartAt         string             `json:"start_at"`
+	EndAt           *string            `json:"end_at,omitempty"`
+	NextRunAt       string             `json:"next_run_at"`
+	Attempts        int                `json:"attempts"`
+	Status          transfer.Status    `json:"status"`
+	LastError       *string            `json:"last_error,omitempty"`
+	CreatedAt       string             `json:"created_at"`
+}
+
+// ScheduledTransferListResponse список регулярных переводов
+type ScheduledTransferListResponse struct {
+	ScheduledTransfers []ScheduledTransferResponse `json:"scheduled_transfers"`
+}
+
+// ScheduledTransferRunResponse ответ с попыткой исполнения перевода
+type ScheduledTransferRunResponse struct {
+	ID          int64              `json:"id"`
+	ScheduledAt string             `json:"scheduled_at"`
+	Attempt     int                `json:"attempt"`
+	Status      transfer.RunStatus `json:"status"`
+	Error       *string            `json:"error,omitempty"`
+	CreatedAt   string             `json:"created_at"`
+}
+
+// ScheduledTransferRunListResponse история исполнений перевода
+type ScheduledTransferRunListResponse struct {
+	Runs []ScheduledTransferRunResponse `json:"runs"`
+}
@@ -0,0 +1,48 @@
+package dto
+
+import (
+	"github.com/shopspring/decimal"
+	"github.com/therealadik/bank-api/internal/models/transfer"
+)
+
+// TransferLimitsRequest индивидуальные лимиты. Незаданное поле наследует лимит по умолчанию,
+// 0 запрещает операции.
+type TransferLimitsRequest struct {
+	SingleMax   decimal.NullDecimal `json:"single_max" binding:"omitempty,money,min=0"`
+	DailyMax    decimal.NullDecimal `json:"daily_max" binding:"omitempty,money,min=0"`
+	MonthlyMax  decimal.NullDecimal `json:"monthly_max" binding:"omitempty,money,min=0"`
+	HourlyCount *int                `json:"hourly_count" binding:"omitempty,min=0,max=10000"`
+}
+
+// TransferLimitsResponse значения лимитов. null — лимит не установлен.
+type TransferLimitsResponse struct {
+	SingleMax   *decimal.Decimal `json:"single_max"`
+	DailyMax    *decimal.Decimal `json:"daily_max"`
+	MonthlyMax  *decimal.Decimal `json:"monthly_max"`
+	HourlyCount *int             `json:"hourly_count"`
+}
+
+// LimitUsageResponse действующие лимиты и их использование
+type LimitUsageResponse struct {
+	Scope       transfer.LimitScope    `json:"scope"`
+	Limits      TransferLimitsResponse `json:"limits"`
+	DailyUsed   decimal.Decimal        `json:"daily_used"`
+	MonthlyUsed decimal.Decimal        `json:"monthly_used"`
+	HourlyCount int                    `json:"hourly_count"`
+}
+
+// AccountLimitsResponse лимиты счета и пользователя
+type AccountLimitsResponse struct {
+	AccountID int64                `json:"account_id"`
+	Limits    []LimitUsageResponse `json:"limits"`
+}
+
+// TransferLimitOverrideResponse индивидуальные и действующие лимиты пользователя или счета
+type TransferLimitOverrideResponse struct {
+	Scope     transfer.LimitScope     `json:"scope"`
+	OwnerID   int64                   `json:"owner_id"`
+	Override  *TransferLimitsResponse `json:"override"` // null — индивидуальные лимиты не заданы
+	Effective TransferLimitsResponse  `json:"effective"`
+	UpdatedBy *int64                  `json:"updated_by,omitempty"`
+	UpdatedAt *string                 `json:"updated_at,omitempty"`
+}
@@ -0,0 +1,59 @@
+package dto
+
+import "github.com/therealadik/bank-api/internal/models/webhook"
+
+// CreateWebhookRequest запрос на регистрацию вебхука
+type CreateWebhookRequest struct {
+	URL    string          `json:"url" binding:"required,max=2048"`  // Публичный адрес https://
+	Events []webhook.Event `json:"events" binding:"required,max=10"` // transaction.completed, card.payment, credit.overdue
+}
+
+// WebhookResponse ответ с вебхуком
+type WebhookResponse struct {
+	ID        int64           `json:"id"`
+	URL       string          `json:"url"`
+	Events    []webhook.Event `json:"events"`
+	Secret    string          `json:"secret,omitempty"` // Только в ответе на создание
+	CreatedAt string          `json:"created_at"`
+}
+
+// WebhookListResponse список вебхуков
+type WebhookListResponse struct {
+	Webhooks []WebhookResponse `json:"webhooks"`
+}
+
+// WebhookDeliveryResponse ответ с доставкой события
+type WebhookDeliveryResponse struct {
+	ID             int64                  `json:"id"`
+	WebhookID      int64                  `json:"webhook_id"`
+	EventID        string                 `json:"event_id"`
+	EventType      webhook.Event          `json:"event_type"`
+	Status         webhook.DeliveryStatus `json:"status"`
+	Attempts       int                    `json:"attempts"`
+	NextAttemptAt  *string                `json:"next_attempt_at,omitempty"` // Для доставок в статусе PENDING
+	LastStatusCode *int                   `json:"last_status_code,omitempty"`
+	LastError      *string                `json:"last_error,omitempty"`
+	CreatedAt      string                 `json:"created_at"`
+	DeliveredAt    *string                `json:"delivered_at,omitempty"`
+}
+
+// WebhookDeliveryListResponse последние доставки вебхука
+type WebhookDeliveryListResponse struct {
+	Deliveries []WebhookDeliveryResponse `json:"deliveries"`
+}
+
+// WebhookAttemptResponse ответ с попыткой доставки
+type WebhookAttemptResponse struct {
+	Attempt    int     `json:"attempt"`
+	StatusCode *int    `json:"status_code,omitempty"`
+	Error      *string `json:"error,omitempty"`
+	DurationMs int     `json:"duration_ms"`
+	CreatedAt  string  `json:"created_at"`
+}
+
+// WebhookDeliveryDetailsResponse доставка с телом запроса и журналом попыток
+type WebhookDeliveryDetailsResponse struct {
+	WebhookDeliveryResponse
+	Payload    string                   `json:"payload"` // Тело запроса, которое подписывается и отправляется
+	AttemptLog []WebhookAttemptResponse `json:"attempt_log"`
+}
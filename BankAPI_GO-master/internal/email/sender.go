@@ -0,0 +1,75 @@
+package email
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"github.com/therealadik/bank-api/internal/config"
+	"gopkg.in/gomail.v2"
+)
+
+// Message письмо пользователю
+type Message struct {
+	To      string
+	Subject string
+	Body    string // Текст письма (text/plain)
+}
+
+// Sender отправляет письма пользователям
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// SMTPSender отправляет письма через SMTP-сервер
+type SMTPSender struct {
+	dialer *gomail.Dialer
+	from   string
+}
+
+// NewSMTPSender создает отправителя писем через SMTP
+func NewSMTPSender(cfg config.SMTPConfig) *SMTPSender {
+	return &SMTPSender{
+		dialer: gomail.NewDialer(cfg.Host, cfg.Port, cfg.Username, cfg.Password),
+		from:   cfg.From,
+	}
+}
+
+// Send отправляет письмо
+func (s *SMTPSender) Send(_ context.Context, msg Message) error {
+	m := gomail.NewMessage()
+	m.SetHeader("From", s.from)
+	m.SetHeader("To", msg.To)
+	m.SetHeader("Subject", msg.Subject)
+	m.SetBody("text/plain", msg.Body)
+
+	return s.dialer.DialAndSend(m)
+}
+
+// LogSender пишет письма в лог вместо отправки. Используется локально,
+// когда SMTP не настроен.
+type LogSender struct {
+	logger *logrus.Logger
+}
+
+// NewLogSender создает отправителя, пишущего письма в лог
+func NewLogSender(logger *logrus.Logger) *LogSender {
+	return &LogSender{logger: logger}
+}
+
+// Send пишет письмо в лог
+func (s *LogSender) Send(_ context.Context, msg Message) error {
+	s.logger.WithFields(logrus.Fields{
+		"to":      msg.To,
+		"subject": msg.Subject,
+	}).Info("Письмо (SMTP не настроен): " + msg.Body)
+	return nil
+}
+
+// NewSender создает SMTP-отправителя или, если SMTP_HOST не задан, отправителя в лог
+func NewSender(cfg config.SMTPConfig, logger *logrus.Logger) Sender {
+	if cfg.Host == "" {
+		logger.Warn("SMTP не настроен, письма будут выводиться в лог")
+		return NewLogSender(logger)
+	}
+	return NewSMTPSender(cfg)
+}
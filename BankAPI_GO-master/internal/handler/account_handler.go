@@ -11,18 +11,23 @@ import (
 	"github.com/therealadik/bank-api/internal/dto"
 	"github.com/therealadik/bank-api/internal/middleware"
 	"github.com/therealadik/bank-api/internal/models/account"
+	"github.com/therealadik/bank-api/internal/models/transaction"
+	"github.com/therealadik/bank-api/internal/problem"
 	"github.com/therealadik/bank-api/internal/service"
 )
 
 type AccountHandler struct {
-	accountService *service.AccountService
-	logger         *logrus.Logger
+	accountService   *service.AccountService
+	twoFactorService *service.TwoFactorService
+	logger           *logrus.Logger
 }
 
-func NewAccountHandler(accountService *service.AccountService, logger *logrus.Logger) *AccountHandler {
+func NewAccountHandler(accountService *service.AccountService, twoFactorService *service.TwoFactorService,
+	logger *logrus.Logger) *AccountHandler {
 	return &AccountHandler{
-		accountService: accountService,
-		logger:         logger,
+		accountService:   accountService,
+		twoFactorService: twoFactorService,
+		logger:           logger,
 	}
 }
 
@@ -32,22 +37,20 @@ func (h *AccountHandler) CreateAccount(w http.ResponseWriter, r *http.Request) {
 	userID, err := middleware.GetUserID(r.Context())
 	if err != nil {
 		h.logger.Errorf("Ошибка получения userID из контекста: %v", err)
-		http.Error(w, "Ошибка авторизации", http.StatusUnauthorized)
+		problem.Write(w, r, problem.UNAUTHORIZED)
 		return
 	}
 
 	// Декодируем запрос
 	var req dto.CreateAccountRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.logger.Errorf("Ошибка декодирования запроса: %v", err)
-		http.Error(w, "Неверный формат запроса", http.StatusBadRequest)
+	if !decodeJSON(w, r, h.logger, &req) {
 		return
 	}
 
 	// Проверяем валютный код (пока только RUB)
 	if req.Currency != account.RUB {
 		h.logger.Warnf("Попытка создать счет в неподдерживаемой валюте: %s", req.Currency)
-		http.Error(w, "Поддерживается только валюта RUB", http.StatusBadRequest)
+		problem.Write(w, r, problem.UNSUPPORTED_CURRENCY)
 		return
 	}
 
@@ -55,18 +58,12 @@ func (h *AccountHandler) CreateAccount(w http.ResponseWriter, r *http.Request) {
 	newAccount, err := h.accountService.CreateAccount(r.Context(), userID, req.Currency)
 	if err != nil {
 		h.logger.Errorf("Ошибка создания счета: %v", err)
-		http.Error(w, "Не удалось создать счет", http.StatusInternalServerError)
+		problem.Write(w, r, problem.INTERNAL_ERROR)
 		return
 	}
 
 	// Формируем ответ
-	resp := dto.AccountResponse{
-		ID:        newAccount.ID,
-		UserID:    newAccount.UserID,
-		Balance:   newAccount.Balance,
-		Currency:  newAccount.Currency,
-		CreatedAt: newAccount.CreatedAt.Format("2006-01-02T15:04:05Z"),
-	}
+	resp := toAccountResponse(newAccount)
 
 	// Отправляем ответ
 	w.Header().Set("Content-Type", "application/json")
@@ -82,7 +79,7 @@ func (h *AccountHandler) GetAccounts(w http.ResponseWriter, r *http.Request) {
 	userID, err := middleware.GetUserID(r.Context())
 	if err != nil {
 		h.logger.Errorf("Ошибка получения userID из контекста: %v", err)
-		http.Error(w, "Ошибка авторизации", http.StatusUnauthorized)
+		problem.Write(w, r, problem.UNAUTHORIZED)
 		return
 	}
 
@@ -90,7 +87,7 @@ func (h *AccountHandler) GetAccounts(w http.ResponseWriter, r *http.Request) {
 	accounts, err := h.accountService.GetAccountsByUserID(r.Context(), userID)
 	if err != nil {
 		h.logger.Errorf("Ошибка получения счетов: %v", err)
-		http.Error(w, "Не удалось получить счета", http.StatusInternalServerError)
+		problem.Write(w, r, problem.INTERNAL_ERROR)
 		return
 	}
 
@@ -100,13 +97,7 @@ func (h *AccountHandler) GetAccounts(w http.ResponseWriter, r *http.Request) {
 	}
 
 	for _, acc := range accounts {
-		resp.Accounts = append(resp.Accounts, dto.AccountResponse{
-			ID:        acc.ID,
-			UserID:    acc.UserID,
-			Balance:   acc.Balance,
-			Currency:  acc.Currency,
-			CreatedAt: acc.CreatedAt.Format("2006-01-02T15:04:05Z"),
-		})
+		resp.Accounts = append(resp.Accounts, toAccountResponse(acc))
 	}
 
 	// Отправляем ответ
@@ -122,7 +113,7 @@ func (h *AccountHandler) UpdateBalance(w http.ResponseWriter, r *http.Request) {
 	userID, err := middleware.GetUserID(r.Context())
 	if err != nil {
 		h.logger.Errorf("Ошибка получения userID из контекста: %v", err)
-		http.Error(w, "Ошибка авторизации", http.StatusUnauthorized)
+		problem.Write(w, r, problem.UNAUTHORIZED)
 		return
 	}
 
@@ -131,30 +122,25 @@ func (h *AccountHandler) UpdateBalance(w http.ResponseWriter, r *http.Request) {
 	accountID, err := strconv.ParseInt(vars["id"], 10, 64)
 	if err != nil {
 		h.logger.Warnf("Неверный формат ID счета: %v", err)
-		http.Error(w, "Неверный ID счета", http.StatusBadRequest)
+		problem.Write(w, r, problem.INVALID_ACCOUNT_ID)
 		return
 	}
 
 	// Декодируем запрос
 	var req dto.UpdateBalanceRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.logger.Errorf("Ошибка декодирования запроса: %v", err)
-		http.Error(w, "Неверный формат запроса", http.StatusBadRequest)
+	if !decodeJSON(w, r, h.logger, &req) {
+		return
+	}
+
+	// Крупное списание требует подтверждения кодом TOTP
+	if req.Amount.IsNegative() && !checkStepUp(w, r, h.twoFactorService, h.logger, userID, req.Amount.Abs()) {
 		return
 	}
 
 	// Обновляем баланс
 	err = h.accountService.UpdateBalance(r.Context(), accountID, userID, req.Amount)
 	if err != nil {
-		// Определяем тип ошибки для возврата подходящего HTTP-статуса
-		switch {
-		case errors.Is(err, service.ErrInsufficientFunds):
-			h.logger.Warnf("Недостаточно средств для операции: %v", err)
-			http.Error(w, "Недостаточно средств", http.StatusBadRequest)
-		default:
-			h.logger.Errorf("Ошибка обновления баланса: %v", err)
-			http.Error(w, "Не удалось обновить баланс", http.StatusInternalServerError)
-		}
+		writeError(w, r, h.logger, err, "Ошибка обновления баланса")
 		return
 	}
 
@@ -162,18 +148,12 @@ func (h *AccountHandler) UpdateBalance(w http.ResponseWriter, r *http.Request) {
 	updatedAccount, err := h.accountService.GetAccountByID(r.Context(), accountID, userID)
 	if err != nil {
 		h.logger.Errorf("Ошибка получения обновленного счета: %v", err)
-		http.Error(w, "Операция выполнена, но не удалось получить данные счета", http.StatusInternalServerError)
+		problem.Write(w, r, problem.ACCOUNT_RELOAD_FAILED)
 		return
 	}
 
 	// Формируем ответ
-	resp := dto.AccountResponse{
-		ID:        updatedAccount.ID,
-		UserID:    updatedAccount.UserID,
-		Balance:   updatedAccount.Balance,
-		Currency:  updatedAccount.Currency,
-		CreatedAt: updatedAccount.CreatedAt.Format("2006-01-02T15:04:05Z"),
-	}
+	resp := toAccountResponse(updatedAccount)
 
 	// Отправляем ответ
 	w.Header().Set("Content-Type", "application/json")
@@ -188,54 +168,151 @@ func (h *AccountHandler) Transfer(w http.ResponseWriter, r *http.Request) {
 	userID, err := middleware.GetUserID(r.Context())
 	if err != nil {
 		h.logger.Errorf("Ошибка получения userID из контекста: %v", err)
-		http.Error(w, "Ошибка авторизации", http.StatusUnauthorized)
+		problem.Write(w, r, problem.UNAUTHORIZED)
 		return
 	}
 
 	// Декодируем запрос
 	var req dto.TransferRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.logger.Errorf("Ошибка декодирования запроса: %v", err)
-		http.Error(w, "Неверный формат запроса", http.StatusBadRequest)
+	if !decodeJSON(w, r, h.logger, &req) {
+		return
+	}
+
+	// Крупный перевод требует подтверждения кодом TOTP
+	if !checkStepUp(w, r, h.twoFactorService, h.logger, userID, req.Amount) {
 		return
 	}
 
 	// Выполняем перевод
-	err = h.accountService.Transfer(r.Context(), req.FromAccountID, req.ToAccountID, userID, req.Amount)
+	outgoing, err := h.accountService.Transfer(r.Context(), req.FromAccountID,
+		toTransferTarget(req.TransferRecipientRequest), userID, req.Amount)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка выполнения перевода")
+		return
+	}
+
+	// Подозрительный перевод задержан до решения администратора, средства удерживаются
+	resp := dto.TransferResponse{Status: "success", TransactionID: outgoing.ID}
+	status := http.StatusOK
+	if outgoing.Status == transaction.PENDING {
+		h.logger.Infof("Перевод со счета %d задержан антифрод-проверкой (транзакция %d)", req.FromAccountID, outgoing.ID)
+		resp.Status = "pending_review"
+		status = http.StatusAccepted
+	}
+
+	// Отправляем ответ
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger.Errorf("Ошибка кодирования ответа: %v", err)
+	}
+}
+
+// PreviewTransfer обработчик для проверки получателя перед переводом:
+// возвращает замаскированное имя получателя и номер счета зачисления
+func (h *AccountHandler) PreviewTransfer(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		h.logger.Errorf("Ошибка получения userID из контекста: %v", err)
+		problem.Write(w, r, problem.UNAUTHORIZED)
+		return
+	}
+
+	var req dto.TransferPreviewRequest
+	if !decodeJSON(w, r, h.logger, &req) {
+		return
+	}
+
+	preview, err := h.accountService.PreviewRecipient(r.Context(), req.FromAccountID, userID,
+		toTransferTarget(req.TransferRecipientRequest))
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка выполнения перевода")
+		return
+	}
+
+	resp := dto.TransferPreviewResponse{
+		RecipientName: preview.Name,
+		AccountNumber: account.MaskNumber(preview.Account.Number),
+		Currency:      preview.Account.Currency,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger.Errorf("Ошибка кодирования ответа: %v", err)
+	}
+}
+
+// CloseAccount обработчик для закрытия счета. Остаток переводится
+// на счет transfer_to_account_id, поэтому крупный остаток требует кода TOTP.
+func (h *AccountHandler) CloseAccount(w http.ResponseWriter, r *http.Request) {
+	// Получаем userID из контекста
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		h.logger.Errorf("Ошибка получения userID из контекста: %v", err)
+		problem.Write(w, r, problem.UNAUTHORIZED)
+		return
+	}
+
+	// Получаем ID счета из URL
+	accountID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		h.logger.Warnf("Неверный формат ID счета: %v", err)
+		problem.Write(w, r, problem.INVALID_ACCOUNT_ID)
+		return
+	}
+
+	// Тело запроса необязательно: счет с нулевым остатком закрывается без него
+	var req dto.CloseAccountRequest
+	if !decodeOptionalJSON(w, r, h.logger, &req) {
+		return
+	}
+
+	acc, err := h.accountService.GetAccountByID(r.Context(), accountID, userID)
+	if err != nil {
+		h.writeCloseError(w, r, err)
+		return
+	}
+
+	// Перевод остатка подтверждается так же, как обычный перевод
+	if req.TransferToAccountID != nil && !checkStepUp(w, r, h.twoFactorService, h.logger, userID, acc.Balance) {
+		return
+	}
+
+	closed, err := h.accountService.CloseAccount(r.Context(), accountID, userID, req.TransferToAccountID)
 	if err != nil {
-		// Определяем тип ошибки
-		switch {
-		case errors.Is(err, service.ErrInsufficientFunds):
-			h.logger.Warnf("Недостаточно средств для перевода: %v", err)
-			http.Error(w, "Недостаточно средств", http.StatusBadRequest)
-		case errors.Is(err, service.ErrSameAccount):
-			h.logger.Warnf("Попытка перевода на тот же счет: %v", err)
-			http.Error(w, "Нельзя переводить на тот же счет", http.StatusBadRequest)
-		case errors.Is(err, service.ErrNegativeAmount):
-			h.logger.Warnf("Попытка перевода отрицательной суммы: %v", err)
-			http.Error(w, "Сумма перевода должна быть положительной", http.StatusBadRequest)
-		default:
-			h.logger.Errorf("Ошибка выполнения перевода: %v", err)
-			http.Error(w, "Не удалось выполнить перевод", http.StatusInternalServerError)
-		}
+		h.writeCloseError(w, r, err)
 		return
 	}
 
-	// Отправляем успешный ответ
+	h.logger.Infof("Пользователь %d закрыл счет %d", userID, closed.ID)
+
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(map[string]string{"status": "success"}); err != nil {
+	if err := json.NewEncoder(w).Encode(toAccountResponse(closed)); err != nil {
 		h.logger.Errorf("Ошибка кодирования ответа: %v", err)
 	}
 }
 
+// writeCloseError отправляет ответ с ошибкой закрытия счета
+func (h *AccountHandler) writeCloseError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, service.ErrSameAccount):
+		h.logger.Warnf("Попытка перевести остаток на закрываемый счет: %v", err)
+		problem.Write(w, r, problem.CLOSE_TARGET_SAME_ACCOUNT)
+	case errors.Is(err, service.ErrAccountClosed):
+		h.logger.Warnf("Попытка закрыть закрытый счет: %v", err)
+		problem.Write(w, r, problem.ACCOUNT_ALREADY_CLOSED)
+	default:
+		writeError(w, r, h.logger, err, "Ошибка закрытия счета")
+	}
+}
+
 // GetTransactions обработчик для получения списка транзакций по счету
 func (h *AccountHandler) GetTransactions(w http.ResponseWriter, r *http.Request) {
 	// Получаем userID из контекста
 	userID, err := middleware.GetUserID(r.Context())
 	if err != nil {
 		h.logger.Errorf("Ошибка получения userID из контекста: %v", err)
-		http.Error(w, "Ошибка авторизации", http.StatusUnauthorized)
+		problem.Write(w, r, problem.UNAUTHORIZED)
 		return
 	}
 
@@ -244,15 +321,14 @@ func (h *AccountHandler) GetTransactions(w http.ResponseWriter, r *http.Request)
 	accountID, err := strconv.ParseInt(vars["id"], 10, 64)
 	if err != nil {
 		h.logger.Warnf("Неверный формат ID счета: %v", err)
-		http.Error(w, "Неверный ID счета", http.StatusBadRequest)
+		problem.Write(w, r, problem.INVALID_ACCOUNT_ID)
 		return
 	}
 
 	// Получаем транзакции
 	transactions, err := h.accountService.GetTransactionsByAccountID(r.Context(), accountID, userID)
 	if err != nil {
-		h.logger.Errorf("Ошибка получения транзакций: %v", err)
-		http.Error(w, "Не удалось получить транзакции", http.StatusInternalServerError)
+		writeError(w, r, h.logger, err, "Ошибка получения транзакций")
 		return
 	}
 
@@ -263,12 +339,13 @@ func (h *AccountHandler) GetTransactions(w http.ResponseWriter, r *http.Request)
 
 	for _, tx := range transactions {
 		resp.Transactions = append(resp.Transactions, dto.TransactionResponse{
-			ID:        tx.ID,
-			AccountID: tx.AccountID,
-			Amount:    tx.Amount,
-			Type:      tx.Type,
-			Status:    tx.Status,
-			CreatedAt: tx.CreatedAt.Format("2006-01-02T15:04:05Z"),
+			ID:         tx.ID,
+			AccountID:  tx.AccountID,
+			Amount:     tx.Amount,
+			Type:       tx.Type,
+			Status:     tx.Status,
+			OriginalID: tx.OriginalID,
+			CreatedAt:  tx.CreatedAt.Format("2006-01-02T15:04:05Z"),
 		})
 	}
 
@@ -278,3 +355,31 @@ func (h *AccountHandler) GetTransactions(w http.ResponseWriter, r *http.Request)
 		h.logger.Errorf("Ошибка кодирования ответа: %v", err)
 	}
 }
+
+// toTransferTarget формирует получателя перевода из запроса
+func toTransferTarget(req dto.TransferRecipientRequest) service.TransferTarget {
+	return service.TransferTarget{
+		AccountID:     req.ToAccountID,
+		AccountNumber: req.ToAccountNumber,
+		Email:         req.ToEmail,
+	}
+}
+
+// toAccountResponse формирует ответ со счетом
+func toAccountResponse(acc *account.Account) dto.AccountResponse {
+	resp := dto.AccountResponse{
+		ID:               acc.ID,
+		Number:           acc.Number,
+		UserID:           acc.UserID,
+		Balance:          acc.Balance,
+		AvailableBalance: acc.AvailableBalance,
+		Currency:         acc.Currency,
+		Status:           acc.Status,
+		CreatedAt:        acc.CreatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+	if acc.ClosedAt != nil {
+		closedAt := acc.ClosedAt.Format("2006-01-02T15:04:05Z")
+		resp.ClosedAt = &closedAt
+	}
+	return resp
+}
@@ -0,0 +1,293 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/therealadik/bank-api/internal/dto"
+	"github.com/therealadik/bank-api/internal/middleware"
+	"github.com/therealadik/bank-api/internal/models"
+	"github.com/therealadik/bank-api/internal/models/account"
+	"github.com/therealadik/bank-api/internal/models/transaction"
+	"github.com/therealadik/bank-api/internal/problem"
+	"github.com/therealadik/bank-api/internal/service"
+)
+
+// AdminHandler обработчик административного API
+type AdminHandler struct {
+	adminService *service.AdminService
+	logger       *logrus.Logger
+}
+
+// NewAdminHandler создает обработчик административного API
+func NewAdminHandler(adminService *service.AdminService, logger *logrus.Logger) *AdminHandler {
+	return &AdminHandler{
+		adminService: adminService,
+		logger:       logger,
+	}
+}
+
+// FindUser обработчик для поиска пользователя по email или username
+// (параметры запроса email или username)
+func (h *AdminHandler) FindUser(w http.ResponseWriter, r *http.Request) {
+	actorID, ok := h.actorID(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	details, err := h.adminService.FindUser(r.Context(), actorID, query.Get("email"), query.Get("username"))
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка административного действия")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, toAdminUserResponse(details))
+}
+
+// GetUser обработчик для получения пользователя с его счетами и картами
+func (h *AdminHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	actorID, ok := h.actorID(w, r)
+	if !ok {
+		return
+	}
+
+	userID, ok := h.pathID(w, r, problem.INVALID_USER_ID)
+	if !ok {
+		return
+	}
+
+	details, err := h.adminService.GetUser(r.Context(), actorID, userID)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка административного действия")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, toAdminUserResponse(details))
+}
+
+// ChangeRole обработчик для изменения роли пользователя
+func (h *AdminHandler) ChangeRole(w http.ResponseWriter, r *http.Request) {
+	actorID, ok := h.actorID(w, r)
+	if !ok {
+		return
+	}
+
+	userID, ok := h.pathID(w, r, problem.INVALID_USER_ID)
+	if !ok {
+		return
+	}
+
+	var req dto.ChangeRoleRequest
+	if !decodeJSON(w, r, h.logger, &req) {
+		return
+	}
+
+	user, err := h.adminService.ChangeRole(r.Context(), actorID, userID, req.Role)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка административного действия")
+		return
+	}
+
+	h.logger.Infof("Администратор %d назначил пользователю %d роль %s", actorID, userID, user.Role)
+	h.writeJSON(w, http.StatusOK, toProfileResponse(user))
+}
+
+// GetUserTransactions обработчик для получения транзакций по всем счетам пользователя
+func (h *AdminHandler) GetUserTransactions(w http.ResponseWriter, r *http.Request) {
+	actorID, ok := h.actorID(w, r)
+	if !ok {
+		return
+	}
+
+	userID, ok := h.pathID(w, r, problem.INVALID_USER_ID)
+	if !ok {
+		return
+	}
+
+	transactions, err := h.adminService.GetUserTransactions(r.Context(), actorID, userID)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка административного действия")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, toTransactionListResponse(transactions))
+}
+
+// GetAccountTransactions обработчик для получения транзакций по счету
+func (h *AdminHandler) GetAccountTransactions(w http.ResponseWriter, r *http.Request) {
+	actorID, ok := h.actorID(w, r)
+	if !ok {
+		return
+	}
+
+	accountID, ok := h.pathID(w, r, problem.INVALID_ACCOUNT_ID)
+	if !ok {
+		return
+	}
+
+	transactions, err := h.adminService.GetAccountTransactions(r.Context(), actorID, accountID)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка административного действия")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, toTransactionListResponse(transactions))
+}
+
+// FreezeAccount обработчик для заморозки счета
+func (h *AdminHandler) FreezeAccount(w http.ResponseWriter, r *http.Request) {
+	h.changeAccountStatus(w, r, h.adminService.FreezeAccount)
+}
+
+// UnfreezeAccount обработчик для снятия заморозки со счета
+func (h *AdminHandler) UnfreezeAccount(w http.ResponseWriter, r *http.Request) {
+	h.changeAccountStatus(w, r, h.adminService.UnfreezeAccount)
+}
+
+// BlockCard обработчик для блокировки карты
+func (h *AdminHandler) BlockCard(w http.ResponseWriter, r *http.Request) {
+	h.changeCardStatus(w, r, h.adminService.BlockCard)
+}
+
+// UnblockCard обработчик для разблокировки карты
+func (h *AdminHandler) UnblockCard(w http.ResponseWriter, r *http.Request) {
+	h.changeCardStatus(w, r, h.adminService.UnblockCard)
+}
+
+// changeAccountStatus разбирает запрос на смену статуса счета и выполняет ее
+func (h *AdminHandler) changeAccountStatus(w http.ResponseWriter, r *http.Request,
+	change func(ctx context.Context, actorID, accountID int64, reason string) (*account.Account, error)) {
+	actorID, ok := h.actorID(w, r)
+	if !ok {
+		return
+	}
+
+	accountID, ok := h.pathID(w, r, problem.INVALID_ACCOUNT_ID)
+	if !ok {
+		return
+	}
+
+	var req dto.AdminActionRequest
+	if !decodeJSON(w, r, h.logger, &req) {
+		return
+	}
+
+	acc, err := change(r.Context(), actorID, accountID, req.Reason)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка административного действия")
+		return
+	}
+
+	h.logger.Infof("Администратор %d перевел счет %d в статус %s", actorID, acc.ID, acc.Status)
+	h.writeJSON(w, http.StatusOK, toAccountResponse(acc))
+}
+
+// changeCardStatus разбирает запрос на смену статуса карты и выполняет ее
+func (h *AdminHandler) changeCardStatus(w http.ResponseWriter, r *http.Request,
+	change func(ctx context.Context, actorID, cardID int64, reason string) (*models.Card, error)) {
+	actorID, ok := h.actorID(w, r)
+	if !ok {
+		return
+	}
+
+	cardID, ok := h.pathID(w, r, problem.INVALID_CARD_ID)
+	if !ok {
+		return
+	}
+
+	var req dto.AdminActionRequest
+	if !decodeJSON(w, r, h.logger, &req) {
+		return
+	}
+
+	card, err := change(r.Context(), actorID, cardID, req.Reason)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка административного действия")
+		return
+	}
+
+	h.logger.Infof("Администратор %d перевел карту %d в статус %s", actorID, card.ID, card.Status)
+	h.writeJSON(w, http.StatusOK, toCardResponse(card))
+}
+
+// actorID извлекает ID сотрудника, выполняющего действие
+func (h *AdminHandler) actorID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	actorID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		h.logger.Errorf("Ошибка получения userID из контекста: %v", err)
+		problem.Write(w, r, problem.UNAUTHORIZED)
+		return 0, false
+	}
+	return actorID, true
+}
+
+// pathID извлекает ID объекта из URL
+func (h *AdminHandler) pathID(w http.ResponseWriter, r *http.Request, code problem.Code) (int64, bool) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		h.logger.Warnf("Неверный формат ID: %v", err)
+		problem.Write(w, r, code)
+		return 0, false
+	}
+	return id, true
+}
+
+// writeJSON отправляет JSON-ответ с указанным статусом
+func (h *AdminHandler) writeJSON(w http.ResponseWriter, status int, resp any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger.Errorf("Ошибка кодирования ответа: %v", err)
+	}
+}
+
+// toAdminUserResponse формирует ответ с пользователем, его счетами и картами
+func toAdminUserResponse(details *service.AdminUserDetails) dto.AdminUserResponse {
+	resp := dto.AdminUserResponse{
+		ProfileResponse: toProfileResponse(details.User),
+		Accounts:        make([]dto.AccountResponse, 0, len(details.Accounts)),
+		Cards:           make([]dto.CardResponse, 0, len(details.Cards)),
+	}
+	for _, acc := range details.Accounts {
+		resp.Accounts = append(resp.Accounts, toAccountResponse(acc))
+	}
+	for _, card := range details.Cards {
+		resp.Cards = append(resp.Cards, toCardResponse(card))
+	}
+	return resp
+}
+
+// toCardResponse формирует ответ с картой без секретных данных
+func toCardResponse(card *models.Card) dto.CardResponse {
+	return dto.CardResponse{
+		ID:        card.ID,
+		UserID:    card.UserID,
+		AccountID: card.AccountID,
+		Status:    card.Status,
+		CreatedAt: card.CreatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+}
+
+// toTransactionListResponse формирует ответ со списком транзакций
+func toTransactionListResponse(transactions []*transaction.Transaction) dto.TransactionListResponse {
+	resp := dto.TransactionListResponse{
+		Transactions: make([]dto.TransactionResponse, 0, len(transactions)),
+	}
+	for _, tx := range transactions {
+		resp.Transactions = append(resp.Transactions, dto.TransactionResponse{
+			ID:         tx.ID,
+			AccountID:  tx.AccountID,
+			Amount:     tx.Amount,
+			Type:       tx.Type,
+			Status:     tx.Status,
+			OriginalID: tx.OriginalID,
+			CreatedAt:  tx.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		})
+	}
+	return resp
+}
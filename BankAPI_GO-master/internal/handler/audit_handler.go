@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/therealadik/bank-api/internal/dto"
+	"github.com/therealadik/bank-api/internal/middleware"
+	"github.com/therealadik/bank-api/internal/models"
+	"github.com/therealadik/bank-api/internal/problem"
+	"github.com/therealadik/bank-api/internal/service"
+)
+
+// invalidFilterParam ошибка разбора параметра фильтра журнала (значение — имя параметра)
+type invalidFilterParam string
+
+func (p invalidFilterParam) Error() string {
+	return "неверный параметр фильтра: " + string(p)
+}
+
+// AuditHandler обработчик просмотра журнала аудита
+type AuditHandler struct {
+	auditService *service.AuditService
+	logger       *logrus.Logger
+}
+
+// NewAuditHandler создает обработчик журнала аудита
+func NewAuditHandler(auditService *service.AuditService, logger *logrus.Logger) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// Query обработчик для просмотра журнала аудита с фильтрами
+// (actor_id, action, target_type, target_id, request_id, from, to в RFC 3339, before_id, limit)
+func (h *AuditHandler) Query(w http.ResponseWriter, r *http.Request) {
+	actorID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		h.logger.Errorf("Ошибка получения userID из контекста: %v", err)
+		problem.Write(w, r, problem.UNAUTHORIZED)
+		return
+	}
+
+	filter, err := parseAuditFilter(r.URL.Query())
+	if err != nil {
+		h.logger.Warnf("Неверный фильтр журнала аудита: %v", err)
+		var param invalidFilterParam
+		errors.As(err, &param)
+		problem.New(problem.INVALID_QUERY_PARAMETER).With("parameter", string(param)).Write(w, r)
+		return
+	}
+
+	entries, err := h.auditService.Query(r.Context(), actorID, filter)
+	if err != nil {
+		h.logger.Errorf("Ошибка чтения журнала аудита: %v", err)
+		problem.Write(w, r, problem.INTERNAL_ERROR)
+		return
+	}
+
+	resp := dto.AuditListResponse{
+		Entries: make([]dto.AuditEntryResponse, 0, len(entries)),
+	}
+	for _, e := range entries {
+		resp.Entries = append(resp.Entries, toAuditEntryResponse(e))
+	}
+	if len(entries) > 0 {
+		next := entries[len(entries)-1].ID
+		resp.NextBeforeID = &next
+	}
+
+	h.writeJSON(w, http.StatusOK, resp)
+}
+
+// Verify обработчик для проверки целостности цепочки журнала аудита
+func (h *AuditHandler) Verify(w http.ResponseWriter, r *http.Request) {
+	result, err := h.auditService.Verify(r.Context())
+	if err != nil {
+		h.logger.Errorf("Ошибка проверки журнала аудита: %v", err)
+		problem.Write(w, r, problem.INTERNAL_ERROR)
+		return
+	}
+
+	if !result.Valid {
+		h.logger.Errorf("Нарушена целостность журнала аудита на записи %d", *result.BrokenAtID)
+	}
+
+	h.writeJSON(w, http.StatusOK, dto.AuditVerifyResponse{
+		Valid:      result.Valid,
+		Checked:    result.Checked,
+		BrokenAtID: result.BrokenAtID,
+	})
+}
+
+// writeJSON отправляет JSON-ответ с указанным статусом
+func (h *AuditHandler) writeJSON(w http.ResponseWriter, status int, resp any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger.Errorf("Ошибка кодирования ответа: %v", err)
+	}
+}
+
+// parseAuditFilter разбирает параметры фильтра журнала аудита
+func parseAuditFilter(q url.Values) (models.AuditFilter, error) {
+	filter := models.AuditFilter{
+		Action:     models.AuditAction(q.Get("action")),
+		TargetType: q.Get("target_type"),
+		RequestID:  q.Get("request_id"),
+	}
+
+	var err error
+	if filter.ActorID, err = parseOptionalID(q, "actor_id"); err != nil {
+		return filter, err
+	}
+	if filter.TargetID, err = parseOptionalID(q, "target_id"); err != nil {
+		return filter, err
+	}
+	if filter.BeforeID, err = parseOptionalID(q, "before_id"); err != nil {
+		return filter, err
+	}
+	if filter.From, err = parseOptionalTime(q, "from"); err != nil {
+		return filter, err
+	}
+	if filter.To, err = parseOptionalTime(q, "to"); err != nil {
+		return filter, err
+	}
+
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			return filter, invalidFilterParam("limit")
+		}
+		filter.Limit = limit
+	}
+
+	return filter, nil
+}
+
+// parseOptionalID разбирает необязательный числовой параметр
+func parseOptionalID(q url.Values, name string) (*int64, error) {
+	v := q.Get(name)
+	if v == "" {
+		return nil, nil
+	}
+	id, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return nil, invalidFilterParam(name)
+	}
+	return &id, nil
+}
+
+// parseOptionalTime разбирает необязательный параметр времени в RFC 3339
+func parseOptionalTime(q url.Values, name string) (*time.Time, error) {
+	v := q.Get(name)
+	if v == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil, invalidFilterParam(name)
+	}
+	return &t, nil
+}
+
+// toAuditEntryResponse формирует ответ с записью журнала аудита
+func toAuditEntryResponse(e *models.AuditEntry) dto.AuditEntryResponse {
+	return dto.AuditEntryResponse{
+		ID:         e.ID,
+		ActorID:    e.ActorID,
+		Action:     e.Action,
+		TargetType: e.TargetType,
+		TargetID:   e.TargetID,
+		RequestID:  e.RequestID,
+		IP:         e.IP,
+		UserAgent:  e.UserAgent,
+		Details:    e.Details,
+		CreatedAt:  e.CreatedAt.UTC().Format("2006-01-02T15:04:05Z"),
+		PrevHash:   e.PrevHash,
+		Hash:       e.Hash,
+	}
+}
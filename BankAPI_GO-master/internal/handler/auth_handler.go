@@ -2,11 +2,14 @@ package handler
 
 import (
 	"encoding/json"
-	"errors"
 	"net/http"
 
 	"github.com/sirupsen/logrus"
 	"github.com/therealadik/bank-api/internal/dto"
+	"github.com/therealadik/bank-api/internal/i18n"
+	"github.com/therealadik/bank-api/internal/middleware"
+	"github.com/therealadik/bank-api/internal/problem"
+	"github.com/therealadik/bank-api/internal/requestmeta"
 	"github.com/therealadik/bank-api/internal/service"
 )
 
@@ -32,98 +35,173 @@ func NewAuthHandler(authService service.AuthService, logger *logrus.Logger) *Aut
 // @Produce json
 // @Param request body dto.RegisterRequest true "Данные для регистрации"
 // @Success 201 {string} string "Пользователь успешно зарегистрирован"
-// @Failure 400 {string} string "Ошибка валидации данных"
-// @Failure 409 {string} string "Пользователь с таким email или username уже существует"
-// @Failure 500 {string} string "Внутренняя ошибка сервера"
+// @Failure 400 {object} problem.Problem "Ошибка валидации данных"
+// @Failure 409 {object} problem.Problem "Email или username уже заняты"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
 // @Router /register [post]
 func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	var req dto.RegisterRequest
 
 	// Декодирование тела запроса
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.logger.WithError(err).Warn("Ошибка декодирования запроса регистрации")
-		http.Error(w, "Неверный формат запроса", http.StatusBadRequest)
+	if !decodeJSON(w, r, h.logger, &req) {
 		return
 	}
 
 	// Регистрация пользователя
 	userID, err := h.authService.Register(r.Context(), req)
 	if err != nil {
-		h.logger.WithError(err).Warn("Ошибка при регистрации пользователя")
-
-		if errors.Is(err, service.ErrUserExists) {
-			http.Error(w, "Пользователь с таким email или username уже существует", http.StatusConflict)
-			return
-		}
-
-		http.Error(w, "Ошибка при регистрации пользователя", http.StatusInternalServerError)
+		writeError(w, r, h.logger, err, "Ошибка при регистрации пользователя")
 		return
 	}
 
 	// Успешный ответ
 	w.WriteHeader(http.StatusCreated)
 	response := map[string]interface{}{
-		"message": "Пользователь успешно зарегистрирован",
+		"message": i18n.T(i18n.FromRequest(r), i18n.MSG_USER_REGISTERED),
 		"user_id": userID,
 	}
 
 	if err := json.NewEncoder(w).Encode(response); err != nil {
 		h.logger.WithError(err).Error("Ошибка при формировании ответа")
-		http.Error(w, "Ошибка сервера", http.StatusInternalServerError)
+		problem.Write(w, r, problem.INTERNAL_ERROR)
 		return
 	}
 }
 
 // Login обрабатывает запрос на вход в систему
 // @Summary Вход в систему
-// @Description Аутентифицирует пользователя и возвращает JWT токен
+// @Description Аутентифицирует пользователя и возвращает access- и refresh-токены
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param request body dto.LoginRequest true "Данные для входа"
-// @Success 200 {object} dto.AuthResponse "JWT токен"
-// @Failure 400 {string} string "Ошибка валидации данных"
-// @Failure 401 {string} string "Неверные учетные данные"
-// @Failure 500 {string} string "Внутренняя ошибка сервера"
+// @Success 200 {object} dto.AuthResponse "Пара токенов"
+// @Failure 400 {object} problem.Problem "Ошибка валидации данных"
+// @Failure 401 {object} problem.Problem "Неверные учетные данные"
+// @Failure 429 {object} problem.Problem "Слишком много неудачных попыток, см. заголовок Retry-After"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
 // @Router /login [post]
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	var req dto.LoginRequest
 
 	// Декодирование тела запроса
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.logger.WithError(err).Warn("Ошибка декодирования запроса авторизации")
-		http.Error(w, "Неверный формат запроса", http.StatusBadRequest)
+	if !decodeJSON(w, r, h.logger, &req) {
+		return
+	}
+
+	// Аутентификация и получение токенов
+	response, err := h.authService.Login(r.Context(), req, requestmeta.ClientIP(r))
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка при авторизации пользователя")
+		return
+	}
+
+	// Формирование и отправка ответа
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.WithError(err).Error("Ошибка при формировании ответа авторизации")
+		problem.Write(w, r, problem.INTERNAL_ERROR)
+		return
+	}
+}
+
+// Refresh обрабатывает запрос на обновление пары токенов
+// @Summary Обновление токенов
+// @Description Обменивает одноразовый refresh-токен на новую пару access- и refresh-токенов
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.RefreshRequest true "Refresh-токен"
+// @Success 200 {object} dto.AuthResponse "Новая пара токенов"
+// @Failure 400 {object} problem.Problem "Ошибка валидации данных"
+// @Failure 401 {object} problem.Problem "Неверный или просроченный refresh-токен"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /token/refresh [post]
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req dto.RefreshRequest
+
+	// Декодирование тела запроса
+	if !decodeJSON(w, r, h.logger, &req) {
+		return
+	}
+
+	response, err := h.authService.Refresh(r.Context(), req.RefreshToken)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка при обновлении токена")
 		return
 	}
 
-	// Базовая валидация
-	if req.Email == "" || req.Password == "" {
-		http.Error(w, "Email и пароль обязательны", http.StatusBadRequest)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.WithError(err).Error("Ошибка при формировании ответа обновления токена")
+		problem.Write(w, r, problem.INTERNAL_ERROR)
 		return
 	}
+}
 
-	// Аутентификация и получение токена
-	token, err := h.authService.Login(r.Context(), req)
+// Logout обрабатывает запрос на выход из системы
+// @Summary Выход из системы
+// @Description Отзывает текущий access-токен и все refresh-токены его сессии
+// @Tags auth
+// @Security BearerAuth
+// @Success 204 "Сессия завершена"
+// @Failure 401 {object} problem.Problem "Требуется авторизация"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /logout [post]
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	claims, err := middleware.GetTokenClaims(r.Context())
 	if err != nil {
-		h.logger.WithError(err).Warn("Ошибка при авторизации пользователя")
+		h.logger.WithError(err).Error("Ошибка получения токена из контекста")
+		problem.Write(w, r, problem.UNAUTHORIZED)
+		return
+	}
 
-		if errors.Is(err, service.ErrInvalidCredentials) {
-			http.Error(w, "Неверный email или пароль", http.StatusUnauthorized)
-			return
-		}
+	if err := h.authService.Logout(r.Context(), claims); err != nil {
+		h.logger.WithError(err).Error("Ошибка при выходе из системы")
+		problem.Write(w, r, problem.INTERNAL_ERROR)
+		return
+	}
 
-		http.Error(w, "Ошибка авторизации", http.StatusInternalServerError)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// LoginTwoFactor обрабатывает второй шаг входа для пользователей с 2FA
+// @Summary Второй шаг входа (2FA)
+// @Description Обменивает токен второго шага и код TOTP (или код восстановления) на пару токенов
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.TwoFactorLoginRequest true "Токен второго шага и код"
+// @Success 200 {object} dto.AuthResponse "Пара токенов"
+// @Failure 400 {object} problem.Problem "Ошибка валидации данных"
+// @Failure 401 {object} problem.Problem "Неверный код или просроченный токен второго шага"
+// @Failure 429 {object} problem.Problem "Слишком много неудачных попыток, см. заголовок Retry-After"
+// @Failure 500 {object} problem.Problem "Внутренняя ошибка сервера"
+// @Router /login/2fa [post]
+func (h *AuthHandler) LoginTwoFactor(w http.ResponseWriter, r *http.Request) {
+	var req dto.TwoFactorLoginRequest
+
+	// Декодирование тела запроса
+	if !decodeJSON(w, r, h.logger, &req) {
+		return
+	}
+
+	response, err := h.authService.LoginTwoFactor(r.Context(), req.ChallengeToken, req.Code)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка второго шага входа")
 		return
 	}
 
-	// Формирование и отправка ответа
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	response := dto.AuthResponse{Token: token}
 
 	if err := json.NewEncoder(w).Encode(response); err != nil {
 		h.logger.WithError(err).Error("Ошибка при формировании ответа авторизации")
-		http.Error(w, "Ошибка сервера", http.StatusInternalServerError)
+		problem.Write(w, r, problem.INTERNAL_ERROR)
 		return
 	}
 }
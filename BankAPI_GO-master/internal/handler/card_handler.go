@@ -4,12 +4,15 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
-	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 	"github.com/therealadik/bank-api/internal/dto"
+	"github.com/therealadik/bank-api/internal/i18n"
 	"github.com/therealadik/bank-api/internal/middleware"
+	"github.com/therealadik/bank-api/internal/models"
+	"github.com/therealadik/bank-api/internal/models/payment"
+	"github.com/therealadik/bank-api/internal/problem"
 	"github.com/therealadik/bank-api/internal/service"
 )
 
@@ -31,30 +34,20 @@ func (h *CardHandler) CreateCard(w http.ResponseWriter, r *http.Request) {
 	userID, err := middleware.GetUserID(r.Context())
 	if err != nil {
 		h.logger.Errorf("Ошибка получения userID из контекста: %v", err)
-		http.Error(w, "Ошибка авторизации", http.StatusUnauthorized)
+		problem.Write(w, r, problem.UNAUTHORIZED)
 		return
 	}
 
 	// Декодируем запрос
 	var req dto.CreateCardRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.logger.Errorf("Ошибка декодирования запроса: %v", err)
-		http.Error(w, "Неверный формат запроса", http.StatusBadRequest)
-		return
-	}
-
-	// Проверяем наличие PGP ключа
-	if req.PGPKey == "" {
-		h.logger.Warn("Отсутствует PGP ключ")
-		http.Error(w, "PGP ключ обязателен", http.StatusBadRequest)
+	if !decodeJSON(w, r, h.logger, &req) {
 		return
 	}
 
 	// Создаем карту
-	card, cardDetails, err := h.cardService.CreateCard(r.Context(), userID, req.PGPKey)
+	card, cardDetails, err := h.cardService.CreateCard(r.Context(), userID, req.AccountID, req.PGPKey)
 	if err != nil {
-		h.logger.Errorf("Ошибка создания карты: %v", err)
-		http.Error(w, "Не удалось создать карту", http.StatusInternalServerError)
+		writeError(w, r, h.logger, err, "Ошибка создания карты")
 		return
 	}
 
@@ -62,6 +55,7 @@ func (h *CardHandler) CreateCard(w http.ResponseWriter, r *http.Request) {
 	resp := dto.CreateCardResponse{
 		ID:         card.ID,
 		UserID:     card.UserID,
+		AccountID:  req.AccountID,
 		CreatedAt:  card.CreatedAt.Format("2006-01-02T15:04:05Z"),
 		CardNumber: cardDetails["number"],
 		Expire:     cardDetails["expire"],
@@ -82,7 +76,7 @@ func (h *CardHandler) GetCards(w http.ResponseWriter, r *http.Request) {
 	userID, err := middleware.GetUserID(r.Context())
 	if err != nil {
 		h.logger.Errorf("Ошибка получения userID из контекста: %v", err)
-		http.Error(w, "Ошибка авторизации", http.StatusUnauthorized)
+		problem.Write(w, r, problem.UNAUTHORIZED)
 		return
 	}
 
@@ -90,7 +84,7 @@ func (h *CardHandler) GetCards(w http.ResponseWriter, r *http.Request) {
 	cards, err := h.cardService.GetUserCards(r.Context(), userID)
 	if err != nil {
 		h.logger.Errorf("Ошибка получения списка карт: %v", err)
-		http.Error(w, "Не удалось получить список карт", http.StatusInternalServerError)
+		problem.Write(w, r, problem.INTERNAL_ERROR)
 		return
 	}
 
@@ -103,6 +97,8 @@ func (h *CardHandler) GetCards(w http.ResponseWriter, r *http.Request) {
 		resp.Cards = append(resp.Cards, dto.CardResponse{
 			ID:        card.ID,
 			UserID:    card.UserID,
+			AccountID: card.AccountID,
+			Status:    card.Status,
 			CreatedAt: card.CreatedAt.Format("2006-01-02T15:04:05Z"),
 		})
 	}
@@ -120,7 +116,7 @@ func (h *CardHandler) GetCardDetails(w http.ResponseWriter, r *http.Request) {
 	userID, err := middleware.GetUserID(r.Context())
 	if err != nil {
 		h.logger.Errorf("Ошибка получения userID из контекста: %v", err)
-		http.Error(w, "Ошибка авторизации", http.StatusUnauthorized)
+		problem.Write(w, r, problem.UNAUTHORIZED)
 		return
 	}
 
@@ -129,7 +125,7 @@ func (h *CardHandler) GetCardDetails(w http.ResponseWriter, r *http.Request) {
 	cardID, err := strconv.ParseInt(vars["id"], 10, 64)
 	if err != nil {
 		h.logger.Warnf("Неверный формат ID карты: %v", err)
-		http.Error(w, "Неверный ID карты", http.StatusBadRequest)
+		problem.Write(w, r, problem.INVALID_CARD_ID)
 		return
 	}
 
@@ -137,15 +133,14 @@ func (h *CardHandler) GetCardDetails(w http.ResponseWriter, r *http.Request) {
 	pgpKey := r.URL.Query().Get("pgp_key")
 	if pgpKey == "" {
 		h.logger.Warn("Отсутствует PGP ключ в запросе")
-		http.Error(w, "PGP ключ обязателен", http.StatusBadRequest)
+		problem.Write(w, r, problem.PGP_KEY_REQUIRED)
 		return
 	}
 
 	// Получаем детали карты
 	cardDetails, err := h.cardService.GetCardDetails(r.Context(), cardID, userID, pgpKey)
 	if err != nil {
-		h.logger.Errorf("Ошибка получения данных карты: %v", err)
-		http.Error(w, "Не удалось получить данные карты", http.StatusInternalServerError)
+		writeError(w, r, h.logger, err, "Ошибка получения данных карты")
 		return
 	}
 
@@ -163,49 +158,246 @@ func (h *CardHandler) GetCardDetails(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// ProcessPayment обработчик для обработки платежа по карте
+// ProcessPayment обработчик для авторизации платежа по карте (постановка холда)
 func (h *CardHandler) ProcessPayment(w http.ResponseWriter, r *http.Request) {
-	// Для платежа не требуется быть владельцем карты, только корректные данные карты
+	// Платить может только владелец карты. Для чужой или несуществующей карты ответ такой же,
+	// как при неверном CVV. Авторизовавший платеж пользователь затем списывает или отменяет холд.
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		h.logger.Errorf("Ошибка получения userID из контекста: %v", err)
+		problem.Write(w, r, problem.UNAUTHORIZED)
+		return
+	}
 
 	// Декодируем запрос
 	var req dto.CardPaymentRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.logger.Errorf("Ошибка декодирования запроса: %v", err)
-		http.Error(w, "Неверный формат запроса", http.StatusBadRequest)
+	if !decodeJSON(w, r, h.logger, &req) {
+		return
+	}
+
+	// Авторизуем платеж с проверкой данных карты и лимитов
+	p, err := h.cardService.AuthorizePayment(r.Context(), userID, req.CardID, req.CVV, req.PGPKey, req.Amount, req.MerchantCategory)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка обработки платежа")
+		return
+	}
+
+	if p.Status == payment.PENDING_REVIEW {
+		h.logger.Infof("Платеж %d по карте %d задержан антифрод-проверкой", p.ID, p.CardID)
+		h.writePayment(w, r, p, http.StatusAccepted, i18n.MSG_PAYMENT_HELD_FOR_REVIEW)
+		return
+	}
+
+	h.writePayment(w, r, p, http.StatusCreated, i18n.MSG_PAYMENT_AUTHORIZED)
+}
+
+// CapturePayment обработчик для списания средств по авторизованному платежу
+func (h *CardHandler) CapturePayment(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		h.logger.Errorf("Ошибка получения userID из контекста: %v", err)
+		problem.Write(w, r, problem.UNAUTHORIZED)
+		return
+	}
+
+	// Получаем ID платежа из URL
+	paymentID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		h.logger.Warnf("Неверный формат ID платежа: %v", err)
+		problem.Write(w, r, problem.INVALID_PAYMENT_ID)
 		return
 	}
 
-	// Проверяем обязательные поля
-	if req.CardID == 0 || req.CVV == "" || req.Amount == "" || req.PGPKey == "" {
-		h.logger.Warn("Отсутствуют обязательные поля")
-		http.Error(w, "Все поля обязательны", http.StatusBadRequest)
+	// Тело запроса необязательно: без суммы списывается весь холд
+	var req dto.CapturePaymentRequest
+	if !decodeOptionalJSON(w, r, h.logger, &req) {
 		return
 	}
 
-	// Проверяем данные карты для платежа
-	isValid, err := h.cardService.VerifyCardPayment(r.Context(), req.CardID, req.CVV, req.PGPKey)
+	p, err := h.cardService.CapturePayment(r.Context(), userID, paymentID, req.Amount)
 	if err != nil {
-		h.logger.Errorf("Ошибка проверки данных карты: %v", err)
-		http.Error(w, "Ошибка проверки данных карты", http.StatusBadRequest)
+		writeError(w, r, h.logger, err, "Ошибка обработки платежа")
 		return
 	}
 
-	if !isValid {
-		h.logger.Warn("Неверные данные карты")
-		http.Error(w, "Неверные данные карты", http.StatusBadRequest)
+	h.writePayment(w, r, p, http.StatusOK, i18n.MSG_PAYMENT_CAPTURED)
+}
+
+// VoidPayment обработчик для отмены авторизованного платежа
+func (h *CardHandler) VoidPayment(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		h.logger.Errorf("Ошибка получения userID из контекста: %v", err)
+		problem.Write(w, r, problem.UNAUTHORIZED)
 		return
 	}
 
-	paymentID := strconv.FormatInt(time.Now().UnixNano(), 10)
+	// Получаем ID платежа из URL
+	paymentID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		h.logger.Warnf("Неверный формат ID платежа: %v", err)
+		problem.Write(w, r, problem.INVALID_PAYMENT_ID)
+		return
+	}
+
+	p, err := h.cardService.VoidPayment(r.Context(), userID, paymentID)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка обработки платежа")
+		return
+	}
+
+	h.writePayment(w, r, p, http.StatusOK, i18n.MSG_PAYMENT_VOIDED)
+}
+
+// RefundPayment обработчик для полного или частичного возврата по проведенному платежу
+func (h *CardHandler) RefundPayment(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		h.logger.Errorf("Ошибка получения userID из контекста: %v", err)
+		problem.Write(w, r, problem.UNAUTHORIZED)
+		return
+	}
+
+	// Получаем ID платежа из URL
+	paymentID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		h.logger.Warnf("Неверный формат ID платежа: %v", err)
+		problem.Write(w, r, problem.INVALID_PAYMENT_ID)
+		return
+	}
+
+	// Тело запроса необязательно: без суммы возвращается весь остаток
+	var req dto.RefundPaymentRequest
+	if !decodeOptionalJSON(w, r, h.logger, &req) {
+		return
+	}
+
+	p, refundTx, err := h.cardService.RefundPayment(r.Context(), userID, paymentID, req.Amount)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка обработки платежа")
+		return
+	}
+
+	resp := dto.RefundPaymentResponse{
+		PaymentID:           strconv.FormatInt(p.ID, 10),
+		Status:              p.Status,
+		Amount:              p.Amount,
+		RefundedAmount:      p.RefundedAmount,
+		RefundTransactionID: refundTx.ID,
+		RefundAmount:        refundTx.Amount,
+	}
+
+	// Отправляем ответ
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger.Errorf("Ошибка кодирования ответа: %v", err)
+	}
+}
+
+// writePayment отправляет ответ с состоянием платежа и описанием на языке запроса
+func (h *CardHandler) writePayment(w http.ResponseWriter, r *http.Request, p *payment.CardPayment, status int, description i18n.Key) {
 	resp := dto.CardPaymentResponse{
 		Success:     true,
-		PaymentID:   paymentID,
-		Description: "Платеж успешно обработан",
+		PaymentID:   strconv.FormatInt(p.ID, 10),
+		Status:      p.Status,
+		Amount:      p.Amount,
+		Description: i18n.T(i18n.FromRequest(r), description),
+	}
+	if p.Status == payment.AUTHORIZED && p.ExpiresAt != nil {
+		resp.ExpiresAt = p.ExpiresAt.Format("2006-01-02T15:04:05Z")
 	}
 
 	// Отправляем ответ
 	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
 		h.logger.Errorf("Ошибка кодирования ответа: %v", err)
 	}
 }
+
+// GetCardLimit обработчик для получения лимитов карты
+func (h *CardHandler) GetCardLimit(w http.ResponseWriter, r *http.Request) {
+	// Получаем userID из контекста
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		h.logger.Errorf("Ошибка получения userID из контекста: %v", err)
+		problem.Write(w, r, problem.UNAUTHORIZED)
+		return
+	}
+
+	// Получаем ID карты из URL
+	vars := mux.Vars(r)
+	cardID, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		h.logger.Warnf("Неверный формат ID карты: %v", err)
+		problem.Write(w, r, problem.INVALID_CARD_ID)
+		return
+	}
+
+	limit, err := h.cardService.GetCardLimit(r.Context(), cardID, userID)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка работы с лимитами карты")
+		return
+	}
+
+	// Отправляем ответ
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(toCardLimitResponse(limit)); err != nil {
+		h.logger.Errorf("Ошибка кодирования ответа: %v", err)
+	}
+}
+
+// UpdateCardLimit обработчик для установки лимитов и запрещенных категорий карты
+func (h *CardHandler) UpdateCardLimit(w http.ResponseWriter, r *http.Request) {
+	// Получаем userID из контекста
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		h.logger.Errorf("Ошибка получения userID из контекста: %v", err)
+		problem.Write(w, r, problem.UNAUTHORIZED)
+		return
+	}
+
+	// Получаем ID карты из URL
+	vars := mux.Vars(r)
+	cardID, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		h.logger.Warnf("Неверный формат ID карты: %v", err)
+		problem.Write(w, r, problem.INVALID_CARD_ID)
+		return
+	}
+
+	// Декодируем запрос
+	var req dto.CardLimitRequest
+	if !decodeJSON(w, r, h.logger, &req) {
+		return
+	}
+
+	limit, err := h.cardService.SetCardLimit(r.Context(), userID, &models.CardLimit{
+		CardID:            cardID,
+		PerTransaction:    req.PerTransaction,
+		Daily:             req.Daily,
+		Monthly:           req.Monthly,
+		BlockedCategories: req.BlockedCategories,
+	})
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка работы с лимитами карты")
+		return
+	}
+
+	// Отправляем ответ
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(toCardLimitResponse(limit)); err != nil {
+		h.logger.Errorf("Ошибка кодирования ответа: %v", err)
+	}
+}
+
+// toCardLimitResponse формирует ответ с лимитами карты
+func toCardLimitResponse(limit *models.CardLimit) dto.CardLimitResponse {
+	return dto.CardLimitResponse{
+		CardID:            limit.CardID,
+		PerTransaction:    limit.PerTransaction,
+		Daily:             limit.Daily,
+		Monthly:           limit.Monthly,
+		BlockedCategories: limit.BlockedCategories,
+	}
+}
@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/therealadik/bank-api/internal/dto"
+	"github.com/therealadik/bank-api/internal/middleware"
+	"github.com/therealadik/bank-api/internal/models/payment"
+	"github.com/therealadik/bank-api/internal/problem"
+	"github.com/therealadik/bank-api/internal/service"
+)
+
+// DisputeHandler обработчик споров (чарджбэков) по платежам картой
+type DisputeHandler struct {
+	cardService *service.CardService
+	logger      *logrus.Logger
+}
+
+func NewDisputeHandler(cardService *service.CardService, logger *logrus.Logger) *DisputeHandler {
+	return &DisputeHandler{
+		cardService: cardService,
+		logger:      logger,
+	}
+}
+
+// OpenDispute обработчик для открытия спора держателем карты
+func (h *DisputeHandler) OpenDispute(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		h.logger.Errorf("Ошибка получения userID из контекста: %v", err)
+		problem.Write(w, r, problem.UNAUTHORIZED)
+		return
+	}
+
+	// Получаем ID платежа из URL
+	paymentID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		h.logger.Warnf("Неверный формат ID платежа: %v", err)
+		problem.Write(w, r, problem.INVALID_PAYMENT_ID)
+		return
+	}
+
+	// Декодируем запрос
+	var req dto.OpenDisputeRequest
+	if !decodeJSON(w, r, h.logger, &req) {
+		return
+	}
+
+	d, err := h.cardService.OpenDispute(r.Context(), userID, paymentID, req.Amount, req.Reason)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка обработки спора")
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, toDisputeResponse(d))
+}
+
+// GetDisputes обработчик для получения споров, открытых пользователем
+func (h *DisputeHandler) GetDisputes(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		h.logger.Errorf("Ошибка получения userID из контекста: %v", err)
+		problem.Write(w, r, problem.UNAUTHORIZED)
+		return
+	}
+
+	disputes, err := h.cardService.GetUserDisputes(r.Context(), userID)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка обработки спора")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, toDisputeListResponse(disputes))
+}
+
+// GetDisputesForReview обработчик для получения споров сотрудником поддержки
+// (фильтр по статусу через параметр status)
+func (h *DisputeHandler) GetDisputesForReview(w http.ResponseWriter, r *http.Request) {
+	status := payment.DisputeStatus(r.URL.Query().Get("status"))
+
+	disputes, err := h.cardService.GetDisputesByStatus(r.Context(), status)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка обработки спора")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, toDisputeListResponse(disputes))
+}
+
+// UpdateDisputeStatus обработчик для смены статуса спора сотрудником поддержки
+func (h *DisputeHandler) UpdateDisputeStatus(w http.ResponseWriter, r *http.Request) {
+	staffID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		h.logger.Errorf("Ошибка получения userID из контекста: %v", err)
+		problem.Write(w, r, problem.UNAUTHORIZED)
+		return
+	}
+
+	// Получаем ID спора из URL
+	disputeID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		h.logger.Warnf("Неверный формат ID спора: %v", err)
+		problem.Write(w, r, problem.INVALID_DISPUTE_ID)
+		return
+	}
+
+	// Декодируем запрос
+	var req dto.UpdateDisputeStatusRequest
+	if !decodeJSON(w, r, h.logger, &req) {
+		return
+	}
+
+	d, err := h.cardService.UpdateDisputeStatus(r.Context(), staffID, disputeID, req.Status, req.Comment)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка обработки спора")
+		return
+	}
+
+	h.logger.Infof("Сотрудник %d перевел спор %d в статус %s", staffID, d.ID, d.Status)
+	h.writeJSON(w, http.StatusOK, toDisputeResponse(d))
+}
+
+// writeJSON отправляет JSON-ответ с указанным статусом
+func (h *DisputeHandler) writeJSON(w http.ResponseWriter, status int, resp any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger.Errorf("Ошибка кодирования ответа: %v", err)
+	}
+}
+
+// toDisputeResponse формирует ответ со спором
+func toDisputeResponse(d *payment.Dispute) dto.DisputeResponse {
+	return dto.DisputeResponse{
+		ID:                  d.ID,
+		PaymentID:           d.PaymentID,
+		Amount:              d.Amount,
+		Reason:              d.Reason,
+		Status:              d.Status,
+		ResolutionComment:   d.ResolutionComment,
+		RefundTransactionID: d.RefundTransactionID,
+		CreatedAt:           d.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		UpdatedAt:           d.UpdatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+}
+
+// toDisputeListResponse формирует ответ со списком споров
+func toDisputeListResponse(disputes []*payment.Dispute) dto.DisputeListResponse {
+	resp := dto.DisputeListResponse{
+		Disputes: make([]dto.DisputeResponse, 0, len(disputes)),
+	}
+	for _, d := range disputes {
+		resp.Disputes = append(resp.Disputes, toDisputeResponse(d))
+	}
+	return resp
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+	"github.com/therealadik/bank-api/internal/docs"
+)
+
+// swaggerUIVersion версия Swagger UI, загружаемой со CDN
+const swaggerUIVersion = "5.17.14"
+
+// swaggerUIPage страница Swagger UI; спецификация загружается с того же сервера
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="ru">
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>Bank API — документация</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@` + swaggerUIVersion + `/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@` + swaggerUIVersion + `/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.onload = function () {
+      window.ui = SwaggerUIBundle({
+        url: "/api/docs/openapi.json",
+        dom_id: "#swagger-ui",
+        persistAuthorization: true
+      });
+    };
+  </script>
+</body>
+</html>
+`
+
+// DocsHandler отдает спецификацию OpenAPI и Swagger UI
+type DocsHandler struct {
+	logger *logrus.Logger
+}
+
+// NewDocsHandler создает обработчик документации API
+func NewDocsHandler(logger *logrus.Logger) *DocsHandler {
+	return &DocsHandler{
+		logger: logger,
+	}
+}
+
+// GetSpec обрабатывает запрос спецификации OpenAPI
+// @Summary Спецификация OpenAPI
+// @Tags docs
+// @Produce json
+// @Success 200 {object} object
+// @Router /api/docs/openapi.json [get]
+func (h *DocsHandler) GetSpec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	if _, err := w.Write(docs.Spec); err != nil {
+		h.logger.Errorf("Ошибка отправки спецификации OpenAPI: %v", err)
+	}
+}
+
+// GetUI обрабатывает запрос страницы Swagger UI
+// @Summary Swagger UI
+// @Tags docs
+// @Produce html
+// @Success 200 {string} string
+// @Router /api/docs [get]
+func (h *DocsHandler) GetUI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	if _, err := w.Write([]byte(swaggerUIPage)); err != nil {
+		h.logger.Errorf("Ошибка отправки страницы Swagger UI: %v", err)
+	}
+}
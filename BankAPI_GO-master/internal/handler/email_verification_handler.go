@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+	"github.com/therealadik/bank-api/internal/i18n"
+	"github.com/therealadik/bank-api/internal/middleware"
+	"github.com/therealadik/bank-api/internal/problem"
+	"github.com/therealadik/bank-api/internal/service"
+)
+
+// EmailVerificationHandler обработчик подтверждения email
+type EmailVerificationHandler struct {
+	verificationService *service.EmailVerificationService
+	logger              *logrus.Logger
+}
+
+// NewEmailVerificationHandler создает обработчик подтверждения email
+func NewEmailVerificationHandler(verificationService *service.EmailVerificationService,
+	logger *logrus.Logger) *EmailVerificationHandler {
+	return &EmailVerificationHandler{
+		verificationService: verificationService,
+		logger:              logger,
+	}
+}
+
+// Verify обработчик перехода по ссылке подтверждения (токен в параметре token)
+func (h *EmailVerificationHandler) Verify(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		writeMissingFields(w, r, "token")
+		return
+	}
+
+	if err := h.verificationService.Verify(r.Context(), token); err != nil {
+		writeError(w, r, h.logger, err, "Ошибка подтверждения email")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]string{"message": i18n.T(i18n.FromRequest(r), i18n.MSG_EMAIL_VERIFIED)}); err != nil {
+		h.logger.Errorf("Ошибка кодирования ответа: %v", err)
+	}
+}
+
+// Resend обработчик повторной отправки письма подтверждения
+func (h *EmailVerificationHandler) Resend(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		h.logger.Errorf("Ошибка получения userID из контекста: %v", err)
+		problem.Write(w, r, problem.UNAUTHORIZED)
+		return
+	}
+
+	if err := h.verificationService.Resend(r.Context(), userID); err != nil {
+		writeError(w, r, h.logger, err, "Ошибка подтверждения email")
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
@@ -0,0 +1,206 @@
+package handler
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/sirupsen/logrus"
+	"github.com/therealadik/bank-api/internal/models/transfer"
+	"github.com/therealadik/bank-api/internal/problem"
+	"github.com/therealadik/bank-api/internal/repository"
+	"github.com/therealadik/bank-api/internal/service"
+)
+
+// serviceErrors сопоставляет ошибки сервисов с кодами ответов. Порядок важен:
+// конкретные ошибки проверяются раньше категорий service.ErrNotFound и service.ErrForbidden.
+var serviceErrors = []struct {
+	err  error
+	code problem.Code
+}{
+	// Пользователи, вход и пароли
+	{repository.ErrUserNotFound, problem.USER_NOT_FOUND},
+	{service.ErrInvalidEmail, problem.INVALID_EMAIL},
+	{service.ErrInvalidUsername, problem.INVALID_USERNAME},
+	{service.ErrWeakPassword, problem.WEAK_PASSWORD},
+	{service.ErrEmailTaken, problem.EMAIL_TAKEN},
+	{service.ErrUsernameTaken, problem.USERNAME_TAKEN},
+	{service.ErrInvalidCredentials, problem.INVALID_CREDENTIALS},
+	{service.ErrLoginLocked, problem.LOGIN_LOCKED},
+	{service.ErrInvalidRefreshToken, problem.INVALID_REFRESH_TOKEN},
+	{service.ErrInvalidChallenge, problem.INVALID_CHALLENGE},
+	{service.ErrInvalidTOTPCode, problem.INVALID_TOTP_CODE},
+	{service.ErrTOTPRequired, problem.TOTP_REQUIRED},
+	{service.ErrTwoFactorAlreadyEnabled, problem.TWO_FACTOR_ALREADY_ENABLED},
+	{service.ErrTwoFactorNotEnrolled, problem.TWO_FACTOR_NOT_ENROLLED},
+	{service.ErrTwoFactorNotEnabled, problem.TWO_FACTOR_NOT_ENABLED},
+	{service.ErrWrongPassword, problem.WRONG_PASSWORD},
+	{service.ErrSamePassword, problem.SAME_PASSWORD},
+	{service.ErrInvalidResetToken, problem.INVALID_RESET_TOKEN},
+	{service.ErrInvalidVerificationToken, problem.INVALID_VERIFICATION_TOKEN},
+	{service.ErrVerificationTokenExpired, problem.VERIFICATION_TOKEN_EXPIRED},
+	{service.ErrEmailAlreadyVerified, problem.EMAIL_ALREADY_VERIFIED},
+	{service.ErrVerificationTooFrequent, problem.VERIFICATION_TOO_FREQUENT},
+	{service.ErrEmailNotVerified, problem.EMAIL_NOT_VERIFIED},
+
+	// Счета и переводы
+	{service.ErrAccountNotFound, problem.ACCOUNT_NOT_FOUND},
+	{service.ErrAccountNotOwned, problem.ACCOUNT_FORBIDDEN},
+	{service.ErrAccountFrozen, problem.ACCOUNT_FROZEN},
+	{service.ErrAccountClosed, problem.ACCOUNT_CLOSED},
+	{service.ErrAccountHasHolds, problem.ACCOUNT_HAS_HOLDS},
+	{service.ErrCloseTargetNeeded, problem.CLOSE_TARGET_REQUIRED},
+	{service.ErrInsufficientFunds, problem.INSUFFICIENT_FUNDS},
+	{service.ErrSameAccount, problem.SAME_ACCOUNT},
+	{service.ErrNegativeAmount, problem.INVALID_AMOUNT},
+	{service.ErrRecipientRequired, problem.RECIPIENT_REQUIRED},
+	{service.ErrRecipientNotFound, problem.RECIPIENT_NOT_FOUND},
+	{service.ErrPreviewRateLimited, problem.TRANSFER_PREVIEW_RATE_LIMITED},
+	{service.ErrInvalidAccountNumber, problem.INVALID_ACCOUNT_NUMBER},
+	{service.ErrCurrencyMismatch, problem.CURRENCY_MISMATCH},
+	{service.ErrInvalidTransferLimit, problem.INVALID_TRANSFER_LIMIT},
+	{service.ErrScheduledTransferNotFound, problem.SCHEDULED_TRANSFER_NOT_FOUND},
+	{service.ErrScheduledTransferNotActive, problem.SCHEDULED_TRANSFER_NOT_ACTIVE},
+	{service.ErrInvalidFrequency, problem.INVALID_FREQUENCY},
+	{service.ErrInvalidCronExpr, problem.INVALID_CRON_EXPR},
+	{service.ErrScheduleInPast, problem.SCHEDULE_IN_PAST},
+	{service.ErrInvalidScheduleEnd, problem.INVALID_SCHEDULE_END},
+	{service.ErrStreamLimitExceeded, problem.STREAM_LIMIT_EXCEEDED},
+
+	// Карты, платежи и споры
+	{service.ErrCardNotFound, problem.CARD_NOT_FOUND},
+	{service.ErrCardAccessDenied, problem.CARD_FORBIDDEN},
+	{service.ErrCardBlocked, problem.CARD_BLOCKED},
+	{service.ErrCardNotLinked, problem.CARD_NOT_LINKED},
+	{service.ErrCardVerificationFailed, problem.CARD_VERIFICATION_FAILED},
+	{service.ErrInvalidLimit, problem.INVALID_CARD_LIMIT},
+	{service.ErrInvalidMerchantCategory, problem.INVALID_MERCHANT_CATEGORY},
+	{service.ErrMerchantCategoryBlocked, problem.MERCHANT_CATEGORY_BLOCKED},
+	{service.ErrPerTransactionLimitExceeded, problem.PER_TRANSACTION_LIMIT_EXCEEDED},
+	{service.ErrDailyLimitExceeded, problem.DAILY_LIMIT_EXCEEDED},
+	{service.ErrMonthlyLimitExceeded, problem.MONTHLY_LIMIT_EXCEEDED},
+	{service.ErrPaymentNotFound, problem.PAYMENT_NOT_FOUND},
+	{service.ErrPaymentNotAuthorized, problem.PAYMENT_NOT_AUTHORIZED},
+	{service.ErrPaymentNotCaptured, problem.PAYMENT_NOT_CAPTURED},
+	{service.ErrCaptureExceedsHold, problem.CAPTURE_EXCEEDS_HOLD},
+	{service.ErrRefundExceedsCaptured, problem.REFUND_EXCEEDS_CAPTURED},
+	{service.ErrDisputeNotFound, problem.DISPUTE_NOT_FOUND},
+	{service.ErrDisputeAlreadyOpen, problem.DISPUTE_ALREADY_OPEN},
+	{service.ErrDisputeReasonRequired, problem.DISPUTE_REASON_REQUIRED},
+	{service.ErrInvalidDisputeStatus, problem.INVALID_DISPUTE_STATUS},
+	{service.ErrInvalidDisputeTransition, problem.INVALID_DISPUTE_TRANSITION},
+
+	// Администрирование и антифрод-проверка
+	{service.ErrAdminReasonRequired, problem.ADMIN_REASON_REQUIRED},
+	{service.ErrUserLookupQueryNeeded, problem.USER_LOOKUP_QUERY_REQUIRED},
+	{service.ErrInvalidRole, problem.INVALID_ROLE},
+	{service.ErrCannotChangeOwnRole, problem.CANNOT_CHANGE_OWN_ROLE},
+	{service.ErrInvalidAccountStatus, problem.INVALID_ACCOUNT_STATUS},
+	{service.ErrFraudReviewNotFound, problem.FRAUD_REVIEW_NOT_FOUND},
+	{service.ErrFraudReviewResolved, problem.FRAUD_REVIEW_RESOLVED},
+	{service.ErrInvalidFraudReviewStatus, problem.INVALID_FRAUD_REVIEW_STATUS},
+
+	// Вебхуки
+	{service.ErrWebhookNotFound, problem.WEBHOOK_NOT_FOUND},
+	{service.ErrWebhookDeliveryNotFound, problem.WEBHOOK_DELIVERY_NOT_FOUND},
+	{service.ErrInvalidWebhookURL, problem.INVALID_WEBHOOK_URL},
+	{service.ErrInvalidWebhookEvent, problem.INVALID_WEBHOOK_EVENT},
+	{service.ErrWebhookLimitExceeded, problem.WEBHOOK_LIMIT_EXCEEDED},
+	{service.ErrWebhookDeliveryPending, problem.WEBHOOK_DELIVERY_PENDING},
+
+	// Категории: объект, для которого нет отдельного кода
+	{service.ErrNotFound, problem.NOT_FOUND},
+	{service.ErrForbidden, problem.FORBIDDEN},
+	{pgx.ErrNoRows, problem.NOT_FOUND},
+}
+
+// errorCode возвращает код ответа для ошибки сервиса; false, если ошибка неизвестна
+func errorCode(err error) (problem.Code, bool) {
+	for _, e := range serviceErrors {
+		if errors.Is(err, e.err) {
+			return e.code, true
+		}
+	}
+	return "", false
+}
+
+// writeError отправляет ответ с ошибкой сервиса. Известные ошибки записываются в журнал
+// как предупреждения, неизвестные — как ошибки с ответом INTERNAL_ERROR.
+// action описывает операцию для журнала, например «Ошибка выполнения перевода».
+func writeError(w http.ResponseWriter, r *http.Request, logger *logrus.Logger, err error, action string) {
+	var limitErr *service.TransferLimitError
+	if errors.As(err, &limitErr) {
+		logger.Warnf("%s: %v", action, err)
+		transferLimitProblem(w, limitErr).Write(w, r)
+		return
+	}
+
+	code, ok := errorCode(err)
+	if !ok {
+		logger.Errorf("%s: %v", action, err)
+		problem.Write(w, r, problem.INTERNAL_ERROR)
+		return
+	}
+	logger.Warnf("%s: %v", action, err)
+
+	if retryAfter, ok := retryAfter(err); ok {
+		seconds := int(math.Ceil(retryAfter.Seconds()))
+		w.Header().Set("Retry-After", strconv.Itoa(max(seconds, 1)))
+	}
+
+	problem.Write(w, r, code)
+}
+
+// retryAfter возвращает время до следующей попытки для ошибок ограничения частоты
+func retryAfter(err error) (time.Duration, bool) {
+	var locked *service.LoginLockedError
+	if errors.As(err, &locked) {
+		return locked.RetryAfter, true
+	}
+	var previewErr *service.PreviewRateLimitError
+	if errors.As(err, &previewErr) {
+		return previewErr.RetryAfter, true
+	}
+	return 0, false
+}
+
+// NotFound отвечает на запрос к несуществующему маршруту
+func NotFound(w http.ResponseWriter, r *http.Request) {
+	problem.Write(w, r, problem.ROUTE_NOT_FOUND)
+}
+
+// MethodNotAllowed отвечает на запрос с неподдерживаемым методом
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	problem.Write(w, r, problem.METHOD_NOT_ALLOWED)
+}
+
+// transferLimitProblem формирует ответ о превышении лимита переводов с параметрами лимита.
+// Превышение числа операций — ограничение частоты (429 и Retry-After), остальные лимиты — отказ в операции.
+func transferLimitProblem(w http.ResponseWriter, e *service.TransferLimitError) *problem.Problem {
+	code := problem.TRANSFER_RATE_LIMITED
+	switch e.Limit {
+	case transfer.LIMIT_SINGLE:
+		code = problem.TRANSFER_SINGLE_LIMIT_EXCEEDED
+	case transfer.LIMIT_DAILY:
+		code = problem.TRANSFER_DAILY_LIMIT_EXCEEDED
+	case transfer.LIMIT_MONTHLY:
+		code = problem.TRANSFER_MONTHLY_LIMIT_EXCEEDED
+	}
+
+	p := problem.New(code).
+		With("scope", e.Scope).
+		With("limit", e.Limit).
+		With("max", e.Max).
+		With("used", e.Used)
+	if e.ResetsAt != nil {
+		p.With("resets_at", e.ResetsAt.Format("2006-01-02T15:04:05Z"))
+		if code == problem.TRANSFER_RATE_LIMITED {
+			seconds := int(time.Until(*e.ResetsAt).Seconds()) + 1
+			w.Header().Set("Retry-After", strconv.Itoa(max(seconds, 1)))
+		}
+	}
+	return p
+}
@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/therealadik/bank-api/internal/dto"
+	"github.com/therealadik/bank-api/internal/middleware"
+	"github.com/therealadik/bank-api/internal/models/fraud"
+	"github.com/therealadik/bank-api/internal/problem"
+	"github.com/therealadik/bank-api/internal/service"
+)
+
+// FraudHandler обработчик операций, задержанных антифрод-проверкой
+type FraudHandler struct {
+	fraudService *service.FraudService
+	logger       *logrus.Logger
+}
+
+func NewFraudHandler(fraudService *service.FraudService, logger *logrus.Logger) *FraudHandler {
+	return &FraudHandler{
+		fraudService: fraudService,
+		logger:       logger,
+	}
+}
+
+// GetReviews обработчик для получения задержанных операций (параметр status, по умолчанию PENDING)
+func (h *FraudHandler) GetReviews(w http.ResponseWriter, r *http.Request) {
+	status := fraud.Status(r.URL.Query().Get("status"))
+	if status == "" {
+		status = fraud.PENDING
+	}
+
+	reviews, err := h.fraudService.GetReviews(r.Context(), status)
+	if err != nil {
+		h.writeFraudError(w, r, err)
+		return
+	}
+
+	resp := dto.FraudReviewListResponse{
+		Reviews: make([]dto.FraudReviewResponse, 0, len(reviews)),
+	}
+	for _, review := range reviews {
+		resp.Reviews = append(resp.Reviews, toFraudReviewResponse(review))
+	}
+
+	h.writeJSON(w, http.StatusOK, resp)
+}
+
+// Approve обработчик для одобрения задержанной операции
+func (h *FraudHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	h.resolve(w, r, h.fraudService.Approve)
+}
+
+// Reject обработчик для отклонения задержанной операции
+func (h *FraudHandler) Reject(w http.ResponseWriter, r *http.Request) {
+	h.resolve(w, r, h.fraudService.Reject)
+}
+
+// resolve разбирает запрос с решением по задержанной операции и выполняет его
+func (h *FraudHandler) resolve(w http.ResponseWriter, r *http.Request,
+	decide func(ctx context.Context, actorID, reviewID int64, comment string) (*fraud.Review, error)) {
+	actorID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		h.logger.Errorf("Ошибка получения userID из контекста: %v", err)
+		problem.Write(w, r, problem.UNAUTHORIZED)
+		return
+	}
+
+	reviewID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		h.logger.Warnf("Неверный формат ID задержанной операции: %v", err)
+		problem.Write(w, r, problem.INVALID_FRAUD_REVIEW_ID)
+		return
+	}
+
+	var req dto.AdminActionRequest
+	if !decodeJSON(w, r, h.logger, &req) {
+		return
+	}
+
+	review, err := decide(r.Context(), actorID, reviewID, req.Reason)
+	if err != nil {
+		h.writeFraudError(w, r, err)
+		return
+	}
+
+	h.logger.Infof("Администратор %d перевел задержанную операцию %d в статус %s", actorID, review.ID, review.Status)
+	h.writeJSON(w, http.StatusOK, toFraudReviewResponse(review))
+}
+
+// writeFraudError отправляет ответ с ошибкой обработки задержанной операции.
+// Операцию по замороженному, закрытому или удаленному счету можно только отклонить.
+func (h *FraudHandler) writeFraudError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, service.ErrAccountFrozen):
+		h.logger.Warnf("Одобрение операции по замороженному счету: %v", err)
+		problem.Write(w, r, problem.FRAUD_REVIEW_ACCOUNT_FROZEN)
+	case errors.Is(err, service.ErrAccountClosed), errors.Is(err, service.ErrAccountNotFound):
+		h.logger.Warnf("Одобрение операции по закрытому или удаленному счету: %v", err)
+		problem.Write(w, r, problem.FRAUD_REVIEW_ACCOUNT_UNAVAILABLE)
+	default:
+		writeError(w, r, h.logger, err, "Ошибка обработки задержанной операции")
+	}
+}
+
+// writeJSON отправляет JSON-ответ с указанным статусом
+func (h *FraudHandler) writeJSON(w http.ResponseWriter, status int, resp any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger.Errorf("Ошибка кодирования ответа: %v", err)
+	}
+}
+
+// toFraudReviewResponse формирует ответ с задержанной операцией
+func toFraudReviewResponse(review *fraud.Review) dto.FraudReviewResponse {
+	resp := dto.FraudReviewResponse{
+		ID:            review.ID,
+		Kind:          review.Kind,
+		UserID:        review.UserID,
+		AccountID:     review.AccountID,
+		ToAccountID:   review.ToAccountID,
+		TransactionID: review.TransactionID,
+		PaymentID:     review.PaymentID,
+		Amount:        review.Amount,
+		Score:         review.Score,
+		Reasons:       review.Reasons,
+		Status:        review.Status,
+		ReviewedBy:    review.ReviewedBy,
+		Comment:       review.Comment,
+		CreatedAt:     review.CreatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+	if review.ReviewedAt != nil {
+		reviewedAt := review.ReviewedAt.Format("2006-01-02T15:04:05Z")
+		resp.ReviewedAt = &reviewedAt
+	}
+	return resp
+}
@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+	"github.com/therealadik/bank-api/internal/service"
+)
+
+// JWKSHandler публикует открытые ключи подписи JWT
+type JWKSHandler struct {
+	keyManager *service.KeyManager
+	logger     *logrus.Logger
+}
+
+// NewJWKSHandler создает обработчик JWKS
+func NewJWKSHandler(keyManager *service.KeyManager, logger *logrus.Logger) *JWKSHandler {
+	return &JWKSHandler{
+		keyManager: keyManager,
+		logger:     logger,
+	}
+}
+
+// GetJWKS обрабатывает запрос набора открытых ключей
+// @Summary Открытые ключи подписи JWT
+// @Description Возвращает действующие ключи в формате JWK Set (RFC 7517) для проверки токенов по kid
+// @Tags auth
+// @Produce json
+// @Success 200 {object} dto.JWKSResponse
+// @Router /.well-known/jwks.json [get]
+func (h *JWKSHandler) GetJWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	// Ключи ротируются редко, но клиенты должны подхватывать новые kid без долгой задержки
+	w.Header().Set("Cache-Control", "public, max-age=300")
+
+	if err := json.NewEncoder(w).Encode(h.keyManager.JWKS()); err != nil {
+		h.logger.Errorf("Ошибка кодирования JWKS: %v", err)
+	}
+}
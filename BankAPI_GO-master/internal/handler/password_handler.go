@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+	"github.com/therealadik/bank-api/internal/dto"
+	"github.com/therealadik/bank-api/internal/middleware"
+	"github.com/therealadik/bank-api/internal/problem"
+	"github.com/therealadik/bank-api/internal/service"
+)
+
+// PasswordHandler обработчик смены и восстановления пароля
+type PasswordHandler struct {
+	passwordService *service.PasswordService
+	logger          *logrus.Logger
+}
+
+// NewPasswordHandler создает обработчик смены и восстановления пароля
+func NewPasswordHandler(passwordService *service.PasswordService, logger *logrus.Logger) *PasswordHandler {
+	return &PasswordHandler{
+		passwordService: passwordService,
+		logger:          logger,
+	}
+}
+
+// ChangePassword обработчик для смены пароля с проверкой текущего.
+// Все сессии пользователя, включая текущую, завершаются.
+func (h *PasswordHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		h.logger.Errorf("Ошибка получения userID из контекста: %v", err)
+		problem.Write(w, r, problem.UNAUTHORIZED)
+		return
+	}
+
+	var req dto.ChangePasswordRequest
+	if !decodeJSON(w, r, h.logger, &req) {
+		return
+	}
+
+	if err := h.passwordService.ChangePassword(r.Context(), userID, req.CurrentPassword, req.NewPassword); err != nil {
+		writeError(w, r, h.logger, err, "Ошибка работы с паролем")
+		return
+	}
+
+	h.logger.Infof("Пользователь %d сменил пароль", userID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ForgotPassword обработчик для запроса письма со ссылкой сброса пароля.
+// Ответ одинаковый независимо от того, зарегистрирован ли email.
+func (h *PasswordHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req dto.ForgotPasswordRequest
+	if !decodeJSON(w, r, h.logger, &req) {
+		return
+	}
+
+	if err := h.passwordService.RequestReset(r.Context(), req.Email); err != nil {
+		writeError(w, r, h.logger, err, "Ошибка работы с паролем")
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// ResetPassword обработчик для установки нового пароля по токену из письма
+func (h *PasswordHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req dto.ResetPasswordRequest
+	if !decodeJSON(w, r, h.logger, &req) {
+		return
+	}
+
+	if err := h.passwordService.ResetPassword(r.Context(), req.Token, req.NewPassword); err != nil {
+		writeError(w, r, h.logger, err, "Ошибка работы с паролем")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
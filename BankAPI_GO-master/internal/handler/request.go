@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/therealadik/bank-api/internal/i18n"
+	"github.com/therealadik/bank-api/internal/problem"
+	"github.com/therealadik/bank-api/internal/validate"
+)
+
+// maxRequestBodySize ограничение размера тела запроса (с запасом на PGP-ключ)
+const maxRequestBodySize = 64 << 10
+
+// validationMessages описания нарушенных правил проверки полей
+var validationMessages = map[validate.Rule]i18n.Key{
+	validate.REQUIRED:      i18n.VALIDATION_REQUIRED,
+	validate.EMAIL:         i18n.VALIDATION_EMAIL,
+	validate.MIN_LENGTH:    i18n.VALIDATION_MIN_LENGTH,
+	validate.MAX_LENGTH:    i18n.VALIDATION_MAX_LENGTH,
+	validate.MIN:           i18n.VALIDATION_MIN,
+	validate.MAX:           i18n.VALIDATION_MAX,
+	validate.LENGTH:        i18n.VALIDATION_LENGTH,
+	validate.ONEOF:         i18n.VALIDATION_ONEOF,
+	validate.DIGITS:        i18n.VALIDATION_DIGITS,
+	validate.AMOUNT:        i18n.VALIDATION_AMOUNT,
+	validate.MONEY:         i18n.VALIDATION_MONEY,
+	validate.UNKNOWN_FIELD: i18n.VALIDATION_UNKNOWN_FIELD,
+	validate.INVALID_TYPE:  i18n.VALIDATION_INVALID_TYPE,
+}
+
+// invalidField описание ошибки поля в ответе VALIDATION_FAILED
+type invalidField struct {
+	Field   string        `json:"field"`
+	Rule    validate.Rule `json:"rule"`
+	Message string        `json:"message"`
+}
+
+// decodeJSON читает тело запроса в dst и проверяет его по тегам binding.
+// Тело ограничено по размеру, неизвестные поля и данные после JSON-объекта отклоняются.
+// При ошибке отправляет ответ и возвращает false.
+func decodeJSON(w http.ResponseWriter, r *http.Request, logger *logrus.Logger, dst any) bool {
+	return decodeBody(w, r, logger, dst, false)
+}
+
+// decodeOptionalJSON как decodeJSON, но допускает пустое тело: тогда проверяются нулевые значения dst
+func decodeOptionalJSON(w http.ResponseWriter, r *http.Request, logger *logrus.Logger, dst any) bool {
+	return decodeBody(w, r, logger, dst, true)
+}
+
+func decodeBody(w http.ResponseWriter, r *http.Request, logger *logrus.Logger, dst any, optional bool) bool {
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodySize)
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	err := dec.Decode(dst)
+	switch {
+	case optional && errors.Is(err, io.EOF):
+		err = nil
+	case err == nil:
+		// После объекта допустимы только пробельные символы
+		if _, tokenErr := dec.Token(); !errors.Is(tokenErr, io.EOF) {
+			err = errors.New("данные после JSON-объекта")
+		}
+	}
+	if err != nil {
+		logger.Warnf("Неверное тело запроса %s %s: %v", r.Method, r.URL.Path, err)
+		writeDecodeError(w, r, err)
+		return false
+	}
+
+	if err := validate.Struct(dst); err != nil {
+		logger.Warnf("Запрос %s %s не прошел проверку: %v", r.Method, r.URL.Path, err)
+		var fieldErrs validate.Errors
+		errors.As(err, &fieldErrs)
+		writeValidationErrors(w, r, fieldErrs)
+		return false
+	}
+	return true
+}
+
+// writeDecodeError отвечает на ошибку разбора тела запроса. Неизвестное поле и значение
+// неверного типа сообщаются как ошибки полей, остальное — как INVALID_REQUEST_BODY.
+func writeDecodeError(w http.ResponseWriter, r *http.Request, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		problem.New(problem.REQUEST_BODY_TOO_LARGE).With("max_bytes", tooLarge.Limit).Write(w, r)
+		return
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		writeValidationErrors(w, r, validate.Errors{{Field: typeErr.Field, Rule: validate.INVALID_TYPE}})
+		return
+	}
+
+	// encoding/json не экспортирует тип ошибки неизвестного поля, имя поля есть только в тексте
+	if name, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		if field, unquoteErr := strconv.Unquote(name); unquoteErr == nil {
+			writeValidationErrors(w, r, validate.Errors{{Field: field, Rule: validate.UNKNOWN_FIELD}})
+			return
+		}
+	}
+
+	problem.Write(w, r, problem.INVALID_REQUEST_BODY)
+}
+
+// writeValidationErrors отвечает VALIDATION_FAILED со списком ошибок полей на языке запроса
+func writeValidationErrors(w http.ResponseWriter, r *http.Request, errs validate.Errors) {
+	lang := i18n.FromRequest(r)
+	fields := make([]invalidField, len(errs))
+	for i, fe := range errs {
+		var args []any
+		if fe.Param != "" {
+			args = append(args, fe.Param)
+		}
+		fields[i] = invalidField{
+			Field:   fe.Field,
+			Rule:    fe.Rule,
+			Message: i18n.T(lang, validationMessages[fe.Rule], args...),
+		}
+	}
+	problem.New(problem.VALIDATION_FAILED).With("errors", fields).Write(w, r)
+}
+
+// writeMissingFields отвечает VALIDATION_FAILED для незаданных обязательных параметров запроса
+func writeMissingFields(w http.ResponseWriter, r *http.Request, fields ...string) {
+	errs := make(validate.Errors, len(fields))
+	for i, field := range fields {
+		errs[i] = validate.FieldError{Field: field, Rule: validate.REQUIRED}
+	}
+	writeValidationErrors(w, r, errs)
+}
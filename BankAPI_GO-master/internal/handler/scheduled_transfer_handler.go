@@ -0,0 +1,213 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/therealadik/bank-api/internal/dto"
+	"github.com/therealadik/bank-api/internal/middleware"
+	"github.com/therealadik/bank-api/internal/models/transfer"
+	"github.com/therealadik/bank-api/internal/problem"
+	"github.com/therealadik/bank-api/internal/service"
+)
+
+// ScheduledTransferHandler обработчик отложенных и регулярных переводов
+type ScheduledTransferHandler struct {
+	scheduledService *service.ScheduledTransferService
+	twoFactorService *service.TwoFactorService
+	logger           *logrus.Logger
+}
+
+func NewScheduledTransferHandler(scheduledService *service.ScheduledTransferService,
+	twoFactorService *service.TwoFactorService, logger *logrus.Logger) *ScheduledTransferHandler {
+	return &ScheduledTransferHandler{
+		scheduledService: scheduledService,
+		twoFactorService: twoFactorService,
+		logger:           logger,
+	}
+}
+
+// Create обработчик для создания отложенного или регулярного перевода
+func (h *ScheduledTransferHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		h.logger.Errorf("Ошибка получения userID из контекста: %v", err)
+		problem.Write(w, r, problem.UNAUTHORIZED)
+		return
+	}
+
+	var req dto.CreateScheduledTransferRequest
+	if !decodeJSON(w, r, h.logger, &req) {
+		return
+	}
+
+	// Крупный регулярный перевод подтверждается кодом TOTP при создании
+	if !checkStepUp(w, r, h.twoFactorService, h.logger, userID, req.Amount) {
+		return
+	}
+
+	params := service.NewScheduledTransfer{
+		FromAccountID: req.FromAccountID,
+		Target:        toTransferTarget(req.TransferRecipientRequest),
+		Amount:        req.Amount,
+		Frequency:     req.Frequency,
+		CronExpr:      req.Cron,
+		EndAt:         req.EndAt,
+	}
+	if req.StartAt != nil {
+		params.StartAt = *req.StartAt
+	}
+
+	st, err := h.scheduledService.Create(r.Context(), userID, params)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка обработки регулярного перевода")
+		return
+	}
+
+	h.logger.Infof("Пользователь %d создал регулярный перевод %d (%s)", userID, st.ID, st.Frequency)
+	h.writeJSON(w, http.StatusCreated, toScheduledTransferResponse(st))
+}
+
+// GetAll обработчик для получения регулярных переводов пользователя
+func (h *ScheduledTransferHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		h.logger.Errorf("Ошибка получения userID из контекста: %v", err)
+		problem.Write(w, r, problem.UNAUTHORIZED)
+		return
+	}
+
+	transfers, err := h.scheduledService.GetByUserID(r.Context(), userID)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка обработки регулярного перевода")
+		return
+	}
+
+	resp := dto.ScheduledTransferListResponse{
+		ScheduledTransfers: make([]dto.ScheduledTransferResponse, 0, len(transfers)),
+	}
+	for _, st := range transfers {
+		resp.ScheduledTransfers = append(resp.ScheduledTransfers, toScheduledTransferResponse(st))
+	}
+
+	h.writeJSON(w, http.StatusOK, resp)
+}
+
+// Get обработчик для получения регулярного перевода
+func (h *ScheduledTransferHandler) Get(w http.ResponseWriter, r *http.Request) {
+	userID, id, ok := h.parseRequest(w, r)
+	if !ok {
+		return
+	}
+
+	st, err := h.scheduledService.GetByID(r.Context(), userID, id)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка обработки регулярного перевода")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, toScheduledTransferResponse(st))
+}
+
+// GetRuns обработчик для получения истории исполнений регулярного перевода
+func (h *ScheduledTransferHandler) GetRuns(w http.ResponseWriter, r *http.Request) {
+	userID, id, ok := h.parseRequest(w, r)
+	if !ok {
+		return
+	}
+
+	runs, err := h.scheduledService.GetRuns(r.Context(), userID, id)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка обработки регулярного перевода")
+		return
+	}
+
+	resp := dto.ScheduledTransferRunListResponse{
+		Runs: make([]dto.ScheduledTransferRunResponse, 0, len(runs)),
+	}
+	for _, run := range runs {
+		resp.Runs = append(resp.Runs, dto.ScheduledTransferRunResponse{
+			ID:          run.ID,
+			ScheduledAt: run.ScheduledAt.Format("2006-01-02T15:04:05Z"),
+			Attempt:     run.Attempt,
+			Status:      run.Status,
+			Error:       run.Error,
+			CreatedAt:   run.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		})
+	}
+
+	h.writeJSON(w, http.StatusOK, resp)
+}
+
+// Cancel обработчик для отмены регулярного перевода
+func (h *ScheduledTransferHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	userID, id, ok := h.parseRequest(w, r)
+	if !ok {
+		return
+	}
+
+	st, err := h.scheduledService.Cancel(r.Context(), userID, id)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка обработки регулярного перевода")
+		return
+	}
+
+	h.logger.Infof("Пользователь %d отменил регулярный перевод %d", userID, st.ID)
+	h.writeJSON(w, http.StatusOK, toScheduledTransferResponse(st))
+}
+
+// parseRequest извлекает ID пользователя из контекста и ID перевода из URL
+func (h *ScheduledTransferHandler) parseRequest(w http.ResponseWriter, r *http.Request) (int64, int64, bool) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		h.logger.Errorf("Ошибка получения userID из контекста: %v", err)
+		problem.Write(w, r, problem.UNAUTHORIZED)
+		return 0, 0, false
+	}
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		h.logger.Warnf("Неверный формат ID регулярного перевода: %v", err)
+		problem.Write(w, r, problem.INVALID_SCHEDULED_TRANSFER_ID)
+		return 0, 0, false
+	}
+
+	return userID, id, true
+}
+
+// writeJSON отправляет JSON-ответ с указанным статусом
+func (h *ScheduledTransferHandler) writeJSON(w http.ResponseWriter, status int, resp any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger.Errorf("Ошибка кодирования ответа: %v", err)
+	}
+}
+
+// toScheduledTransferResponse формирует ответ с регулярным переводом
+func toScheduledTransferResponse(st *transfer.Scheduled) dto.ScheduledTransferResponse {
+	resp := dto.ScheduledTransferResponse{
+		ID:              st.ID,
+		FromAccountID:   st.FromAccountID,
+		ToAccountID:     st.ToAccountID,
+		ToAccountNumber: st.ToAccountNumber,
+		ToEmail:         st.ToEmail,
+		Amount:          st.Amount,
+		Frequency:       st.Frequency,
+		Cron:            st.CronExpr,
+		StartAt:         st.StartAt.Format("2006-01-02T15:04:05Z"),
+		NextRunAt:       st.NextRunAt.Format("2006-01-02T15:04:05Z"),
+		Attempts:        st.Attempts,
+		Status:          st.Status,
+		LastError:       st.LastError,
+		CreatedAt:       st.CreatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+	if st.EndAt != nil {
+		endAt := st.EndAt.Format("2006-01-02T15:04:05Z")
+		resp.EndAt = &endAt
+	}
+	return resp
+}
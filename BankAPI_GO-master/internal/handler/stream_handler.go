@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/therealadik/bank-api/internal/config"
+	"github.com/therealadik/bank-api/internal/dto"
+	"github.com/therealadik/bank-api/internal/middleware"
+	"github.com/therealadik/bank-api/internal/models/stream"
+	"github.com/therealadik/bank-api/internal/problem"
+	"github.com/therealadik/bank-api/internal/service"
+)
+
+// streamRetry через сколько миллисекунд клиент переподключается после обрыва потока
+const streamRetry = 3000
+
+// Причины события reauth, после которого сервер закрывает поток
+const (
+	reauthTokenExpired   = "TOKEN_EXPIRED"   // Истек срок действия access-токена
+	reauthSessionRevoked = "SESSION_REVOKED" // Токен или сессия отозваны
+)
+
+// StreamHandler обработчик потока изменений счетов (Server-Sent Events)
+type StreamHandler struct {
+	streamService  *service.StreamService
+	accountService *service.AccountService
+	authService    service.AuthService
+	cfg            config.StreamConfig
+	logger         *logrus.Logger
+}
+
+func NewStreamHandler(streamService *service.StreamService, accountService *service.AccountService,
+	authService service.AuthService, cfg config.StreamConfig, logger *logrus.Logger) *StreamHandler {
+	return &StreamHandler{
+		streamService:  streamService,
+		accountService: accountService,
+		authService:    authService,
+		cfg:            cfg,
+		logger:         logger,
+	}
+}
+
+// IssueTicket обработчик выдачи одноразового билета для подключения к потоку из браузера
+// (GET /api/accounts/stream?ticket=...)
+func (h *StreamHandler) IssueTicket(w http.ResponseWriter, r *http.Request) {
+	claims, err := middleware.GetTokenClaims(r.Context())
+	if err != nil {
+		h.logger.Errorf("Ошибка получения данных токена из контекста: %v", err)
+		problem.Write(w, r, problem.UNAUTHORIZED)
+		return
+	}
+
+	resp, err := h.authService.IssueStreamTicket(r.Context(), claims)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка выдачи билета потока")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger.Errorf("Ошибка кодирования ответа: %v", err)
+	}
+}
+
+// Stream обработчик потока изменений счетов пользователя. Сначала отправляется событие accounts
+// с текущим состоянием счетов (как в GET /api/accounts), затем события account — новое состояние
+// счета и transaction — новая или изменившаяся транзакция. Поток действует, пока действует
+// access-токен: по истечении его срока или при отзыве сессии (проверяется раз в
+// STREAM_SESSION_CHECK_INTERVAL) отправляется событие reauth и поток закрывается.
+func (h *StreamHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	claims, err := middleware.GetTokenClaims(r.Context())
+	if err != nil {
+		h.logger.Errorf("Ошибка получения данных токена из контекста: %v", err)
+		problem.Write(w, r, problem.UNAUTHORIZED)
+		return
+	}
+	userID := claims.UserID
+
+	sub, err := h.streamService.Subscribe(userID)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка открытия потока изменений")
+		return
+	}
+	defer h.streamService.Unsubscribe(sub)
+
+	accounts, err := h.accountService.GetAccountsByUserID(r.Context(), userID)
+	if err != nil {
+		h.logger.Errorf("Ошибка получения счетов: %v", err)
+		problem.Write(w, r, problem.INTERNAL_ERROR)
+		return
+	}
+
+	// Поток открыт дольше таймаутов чтения и записи сервера: по истечении таймаута чтения
+	// сервер отменил бы контекст запроса
+	rc := http.NewResponseController(w)
+	if err := errors.Join(rc.SetReadDeadline(time.Time{}), rc.SetWriteDeadline(time.Time{})); err != nil {
+		h.logger.Errorf("Ошибка снятия таймаутов потока: %v", err)
+		problem.Write(w, r, problem.INTERNAL_ERROR)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	snapshot := dto.AccountsListResponse{
+		Accounts: make([]dto.AccountResponse, 0, len(accounts)),
+	}
+	for _, acc := range accounts {
+		snapshot.Accounts = append(snapshot.Accounts, toAccountResponse(acc))
+	}
+	if _, err := fmt.Fprintf(w, "retry: %d\n\n", streamRetry); err != nil {
+		return
+	}
+	if err := h.writeEvent(w, rc, "accounts", snapshot); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(h.cfg.HeartbeatInterval)
+	defer heartbeat.Stop()
+	sessionCheck := time.NewTicker(h.cfg.SessionCheckInterval)
+	defer sessionCheck.Stop()
+	expiry := time.NewTimer(time.Until(claims.ExpiresAt))
+	defer expiry.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-expiry.C:
+			h.writeReauth(w, rc, reauthTokenExpired)
+			return
+		case <-sessionCheck.C:
+			err := h.authService.CheckSession(r.Context(), claims)
+			if errors.Is(err, service.ErrTokenRevoked) {
+				h.logger.Infof("Поток пользователя %d закрыт: сессия отозвана", userID)
+				h.writeReauth(w, rc, reauthSessionRevoked)
+				return
+			}
+			// Сбой проверки не закрывает поток: сессия будет проверена при следующем тике
+			if err != nil {
+				h.logger.Errorf("Ошибка проверки сессии потока пользователя %d: %v", userID, err)
+			}
+		case <-heartbeat.C:
+			// Комментарий не виден клиенту и не дает прокси закрыть простаивающее соединение
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		case event, ok := <-sub.Events():
+			// Подписка закрыта: клиент переподключится и получит актуальное состояние
+			if !ok {
+				return
+			}
+			if err := h.writeStreamEvent(w, rc, event); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// writeStreamEvent отправляет изменение счета или транзакции в формате ответов API
+func (h *StreamHandler) writeStreamEvent(w http.ResponseWriter, rc *http.ResponseController, event stream.Event) error {
+	switch event.Type {
+	case stream.ACCOUNT:
+		return h.writeEvent(w, rc, string(event.Type), toAccountResponse(event.Account))
+	case stream.TRANSACTION:
+		tx := event.Transaction
+		return h.writeEvent(w, rc, string(event.Type), dto.TransactionResponse{
+			ID:         tx.ID,
+			AccountID:  tx.AccountID,
+			Amount:     tx.Amount,
+			Type:       tx.Type,
+			Status:     tx.Status,
+			OriginalID: tx.OriginalID,
+			CreatedAt:  tx.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		})
+	}
+	return nil
+}
+
+// writeReauth сообщает клиенту, что поток закрывается и для переподключения нужен новый токен
+// (или новый билет)
+func (h *StreamHandler) writeReauth(w http.ResponseWriter, rc *http.ResponseController, reason string) {
+	_ = h.writeEvent(w, rc, "reauth", dto.StreamReauthEvent{Reason: reason})
+}
+
+// writeEvent отправляет событие name с данными в JSON
+func (h *StreamHandler) writeEvent(w http.ResponseWriter, rc *http.ResponseController, name string, data any) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		h.logger.Errorf("Ошибка кодирования события %s: %v", name, err)
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", name, b); err != nil {
+		return err
+	}
+	return rc.Flush()
+}
@@ -0,0 +1,198 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/therealadik/bank-api/internal/dto"
+	"github.com/therealadik/bank-api/internal/middleware"
+	"github.com/therealadik/bank-api/internal/models/transfer"
+	"github.com/therealadik/bank-api/internal/problem"
+	"github.com/therealadik/bank-api/internal/service"
+)
+
+// TransferLimitHandler обработчик лимитов переводов
+type TransferLimitHandler struct {
+	limitService *service.TransferLimitService
+	logger       *logrus.Logger
+}
+
+func NewTransferLimitHandler(limitService *service.TransferLimitService, logger *logrus.Logger) *TransferLimitHandler {
+	return &TransferLimitHandler{
+		limitService: limitService,
+		logger:       logger,
+	}
+}
+
+// GetAccountLimits обработчик для получения действующих лимитов счета и пользователя с их использованием
+func (h *TransferLimitHandler) GetAccountLimits(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		h.logger.Errorf("Ошибка получения userID из контекста: %v", err)
+		problem.Write(w, r, problem.UNAUTHORIZED)
+		return
+	}
+
+	accountID, ok := h.pathID(w, r, problem.INVALID_ACCOUNT_ID)
+	if !ok {
+		return
+	}
+
+	usage, err := h.limitService.GetAccountUsage(r.Context(), userID, accountID)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка обработки лимитов переводов")
+		return
+	}
+
+	resp := dto.AccountLimitsResponse{
+		AccountID: accountID,
+		Limits:    make([]dto.LimitUsageResponse, 0, len(usage)),
+	}
+	for _, u := range usage {
+		resp.Limits = append(resp.Limits, dto.LimitUsageResponse{
+			Scope:       u.Scope,
+			Limits:      toTransferLimitsResponse(u.Limits),
+			DailyUsed:   u.DailyUsed,
+			MonthlyUsed: u.MonthlyUsed,
+			HourlyCount: u.HourlyCount,
+		})
+	}
+
+	h.writeJSON(w, http.StatusOK, resp)
+}
+
+// GetUserOverride обработчик для получения индивидуальных лимитов пользователя
+func (h *TransferLimitHandler) GetUserOverride(w http.ResponseWriter, r *http.Request) {
+	h.getOverride(w, r, transfer.SCOPE_USER, problem.INVALID_USER_ID)
+}
+
+// SetUserOverride обработчик для установки индивидуальных лимитов пользователя
+func (h *TransferLimitHandler) SetUserOverride(w http.ResponseWriter, r *http.Request) {
+	h.setOverride(w, r, transfer.SCOPE_USER, problem.INVALID_USER_ID)
+}
+
+// GetAccountOverride обработчик для получения индивидуальных лимитов счета
+func (h *TransferLimitHandler) GetAccountOverride(w http.ResponseWriter, r *http.Request) {
+	h.getOverride(w, r, transfer.SCOPE_ACCOUNT, problem.INVALID_ACCOUNT_ID)
+}
+
+// SetAccountOverride обработчик для установки индивидуальных лимитов счета
+func (h *TransferLimitHandler) SetAccountOverride(w http.ResponseWriter, r *http.Request) {
+	h.setOverride(w, r, transfer.SCOPE_ACCOUNT, problem.INVALID_ACCOUNT_ID)
+}
+
+// getOverride отправляет индивидуальные и действующие лимиты пользователя или счета
+func (h *TransferLimitHandler) getOverride(w http.ResponseWriter, r *http.Request, scope transfer.LimitScope,
+	badIDCode problem.Code) {
+	ownerID, ok := h.pathID(w, r, badIDCode)
+	if !ok {
+		return
+	}
+
+	override, effective, err := h.limitService.GetOverride(r.Context(), scope, ownerID)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка обработки лимитов переводов")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, toTransferLimitOverrideResponse(scope, ownerID, override, effective))
+}
+
+// setOverride разбирает запрос и заменяет индивидуальные лимиты пользователя или счета
+func (h *TransferLimitHandler) setOverride(w http.ResponseWriter, r *http.Request, scope transfer.LimitScope,
+	badIDCode problem.Code) {
+	actorID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		h.logger.Errorf("Ошибка получения userID из контекста: %v", err)
+		problem.Write(w, r, problem.UNAUTHORIZED)
+		return
+	}
+
+	ownerID, ok := h.pathID(w, r, badIDCode)
+	if !ok {
+		return
+	}
+
+	var req dto.TransferLimitsRequest
+	if !decodeJSON(w, r, h.logger, &req) {
+		return
+	}
+
+	override, err := h.limitService.SetOverride(r.Context(), actorID, scope, ownerID, transfer.Limits{
+		SingleMax:   req.SingleMax,
+		DailyMax:    req.DailyMax,
+		MonthlyMax:  req.MonthlyMax,
+		HourlyCount: req.HourlyCount,
+	})
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка обработки лимитов переводов")
+		return
+	}
+
+	_, effective, err := h.limitService.GetOverride(r.Context(), scope, ownerID)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка обработки лимитов переводов")
+		return
+	}
+
+	h.logger.Infof("Администратор %d изменил лимиты переводов %s %d", actorID, scope, ownerID)
+	h.writeJSON(w, http.StatusOK, toTransferLimitOverrideResponse(scope, ownerID, override, effective))
+}
+
+// pathID извлекает ID объекта из URL
+func (h *TransferLimitHandler) pathID(w http.ResponseWriter, r *http.Request, code problem.Code) (int64, bool) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		h.logger.Warnf("Неверный формат ID: %v", err)
+		problem.Write(w, r, code)
+		return 0, false
+	}
+	return id, true
+}
+
+// writeJSON отправляет JSON-ответ с указанным статусом
+func (h *TransferLimitHandler) writeJSON(w http.ResponseWriter, status int, resp any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger.Errorf("Ошибка кодирования ответа: %v", err)
+	}
+}
+
+// toTransferLimitsResponse формирует ответ со значениями лимитов
+func toTransferLimitsResponse(l transfer.Limits) dto.TransferLimitsResponse {
+	value := func(v decimal.NullDecimal) *decimal.Decimal {
+		if !v.Valid {
+			return nil
+		}
+		return &v.Decimal
+	}
+	return dto.TransferLimitsResponse{
+		SingleMax:   value(l.SingleMax),
+		DailyMax:    value(l.DailyMax),
+		MonthlyMax:  value(l.MonthlyMax),
+		HourlyCount: l.HourlyCount,
+	}
+}
+
+// toTransferLimitOverrideResponse формирует ответ с индивидуальными и действующими лимитами
+func toTransferLimitOverrideResponse(scope transfer.LimitScope, ownerID int64, override *transfer.LimitOverride,
+	effective transfer.Limits) dto.TransferLimitOverrideResponse {
+	resp := dto.TransferLimitOverrideResponse{
+		Scope:     scope,
+		OwnerID:   ownerID,
+		Effective: toTransferLimitsResponse(effective),
+	}
+	if override != nil {
+		limits := toTransferLimitsResponse(override.Limits)
+		updatedAt := override.UpdatedAt.Format("2006-01-02T15:04:05Z")
+		resp.Override = &limits
+		resp.UpdatedBy = override.UpdatedBy
+		resp.UpdatedAt = &updatedAt
+	}
+	return resp
+}
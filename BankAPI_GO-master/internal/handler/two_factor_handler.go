@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/therealadik/bank-api/internal/dto"
+	"github.com/therealadik/bank-api/internal/middleware"
+	"github.com/therealadik/bank-api/internal/problem"
+	"github.com/therealadik/bank-api/internal/service"
+)
+
+// TOTPCodeHeader заголовок с кодом TOTP для подтверждения операций на крупную сумму
+const TOTPCodeHeader = "X-TOTP-Code"
+
+// TwoFactorHandler обработчик настройки двухфакторной аутентификации
+type TwoFactorHandler struct {
+	twoFactorService *service.TwoFactorService
+	logger           *logrus.Logger
+}
+
+// NewTwoFactorHandler создает обработчик 2FA
+func NewTwoFactorHandler(twoFactorService *service.TwoFactorService, logger *logrus.Logger) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		twoFactorService: twoFactorService,
+		logger:           logger,
+	}
+}
+
+// Enroll обработчик для начала настройки 2FA: выдает секрет и otpauth URI для QR-кода
+func (h *TwoFactorHandler) Enroll(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		h.logger.Errorf("Ошибка получения userID из контекста: %v", err)
+		problem.Write(w, r, problem.UNAUTHORIZED)
+		return
+	}
+
+	secret, uri, err := h.twoFactorService.Enroll(r.Context(), userID)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка настройки 2FA")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, dto.TwoFactorEnrollResponse{
+		Secret:          secret,
+		ProvisioningURI: uri,
+	})
+}
+
+// Confirm обработчик для включения 2FA по первому коду из приложения
+func (h *TwoFactorHandler) Confirm(w http.ResponseWriter, r *http.Request) {
+	userID, req, ok := h.decodeCodeRequest(w, r)
+	if !ok {
+		return
+	}
+
+	codes, err := h.twoFactorService.Confirm(r.Context(), userID, req.Code)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка настройки 2FA")
+		return
+	}
+
+	h.logger.Infof("Пользователь %d включил двухфакторную аутентификацию", userID)
+	h.writeJSON(w, http.StatusOK, dto.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// Disable обработчик для отключения 2FA (код TOTP или код восстановления)
+func (h *TwoFactorHandler) Disable(w http.ResponseWriter, r *http.Request) {
+	userID, req, ok := h.decodeCodeRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.twoFactorService.Disable(r.Context(), userID, req.Code); err != nil {
+		writeError(w, r, h.logger, err, "Ошибка настройки 2FA")
+		return
+	}
+
+	h.logger.Infof("Пользователь %d отключил двухфакторную аутентификацию", userID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RegenerateRecoveryCodes обработчик для выпуска нового набора кодов восстановления
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	userID, req, ok := h.decodeCodeRequest(w, r)
+	if !ok {
+		return
+	}
+
+	codes, err := h.twoFactorService.RegenerateRecoveryCodes(r.Context(), userID, req.Code)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка настройки 2FA")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, dto.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// decodeCodeRequest извлекает ID пользователя и запрос с кодом подтверждения
+func (h *TwoFactorHandler) decodeCodeRequest(w http.ResponseWriter, r *http.Request) (int64, dto.TwoFactorCodeRequest, bool) {
+	var req dto.TwoFactorCodeRequest
+
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		h.logger.Errorf("Ошибка получения userID из контекста: %v", err)
+		problem.Write(w, r, problem.UNAUTHORIZED)
+		return 0, req, false
+	}
+
+	if !decodeJSON(w, r, h.logger, &req) {
+		return 0, req, false
+	}
+
+	return userID, req, true
+}
+
+// writeJSON отправляет JSON-ответ с указанным статусом
+func (h *TwoFactorHandler) writeJSON(w http.ResponseWriter, status int, resp any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger.Errorf("Ошибка кодирования ответа: %v", err)
+	}
+}
+
+// checkStepUp проверяет код TOTP из заголовка X-TOTP-Code для операции на крупную сумму.
+// Возвращает false, если ответ с ошибкой уже отправлен.
+func checkStepUp(w http.ResponseWriter, r *http.Request, twoFactor *service.TwoFactorService, logger *logrus.Logger,
+	userID int64, amount decimal.Decimal) bool {
+	err := twoFactor.RequireStepUp(r.Context(), userID, amount, r.Header.Get(TOTPCodeHeader))
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, service.ErrTOTPRequired):
+		logger.Warnf("Операция пользователя %d на сумму %s требует кода TOTP", userID, amount)
+		problem.Write(w, r, problem.TOTP_REQUIRED)
+	case errors.Is(err, service.ErrInvalidTOTPCode):
+		logger.Warnf("Неверный код TOTP для операции пользователя %d: %v", userID, err)
+		problem.Write(w, r, problem.STEP_UP_TOTP_INVALID)
+	case errors.Is(err, service.ErrLoginLocked):
+		writeError(w, r, logger, err, "Проверка кода TOTP для операции заблокирована")
+	default:
+		logger.Errorf("Ошибка проверки кода TOTP: %v", err)
+		problem.Write(w, r, problem.INTERNAL_ERROR)
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+	"github.com/therealadik/bank-api/internal/dto"
+	"github.com/therealadik/bank-api/internal/middleware"
+	"github.com/therealadik/bank-api/internal/models"
+	"github.com/therealadik/bank-api/internal/problem"
+	"github.com/therealadik/bank-api/internal/service"
+)
+
+// UserHandler обработчик профиля текущего пользователя
+type UserHandler struct {
+	userService *service.UserService
+	logger      *logrus.Logger
+}
+
+// NewUserHandler создает обработчик профиля
+func NewUserHandler(userService *service.UserService, logger *logrus.Logger) *UserHandler {
+	return &UserHandler{
+		userService: userService,
+		logger:      logger,
+	}
+}
+
+// GetMe обработчик для получения профиля текущего пользователя
+func (h *UserHandler) GetMe(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		h.logger.Errorf("Ошибка получения userID из контекста: %v", err)
+		problem.Write(w, r, problem.UNAUTHORIZED)
+		return
+	}
+
+	user, err := h.userService.GetProfile(r.Context(), userID)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка работы с профилем")
+		return
+	}
+
+	h.writeProfile(w, user)
+}
+
+// UpdateMe обработчик для изменения имени пользователя и email
+func (h *UserHandler) UpdateMe(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		h.logger.Errorf("Ошибка получения userID из контекста: %v", err)
+		problem.Write(w, r, problem.UNAUTHORIZED)
+		return
+	}
+
+	var req dto.UpdateProfileRequest
+	if !decodeJSON(w, r, h.logger, &req) {
+		return
+	}
+
+	if req.Username == nil && req.Email == nil {
+		problem.Write(w, r, problem.NO_FIELDS_TO_UPDATE)
+		return
+	}
+
+	user, err := h.userService.UpdateProfile(r.Context(), userID, req.Username, req.Email)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка работы с профилем")
+		return
+	}
+
+	h.logger.Infof("Пользователь %d обновил профиль", userID)
+	h.writeProfile(w, user)
+}
+
+// writeProfile отправляет профиль пользователя
+func (h *UserHandler) writeProfile(w http.ResponseWriter, user *models.User) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(toProfileResponse(user)); err != nil {
+		h.logger.Errorf("Ошибка кодирования ответа: %v", err)
+	}
+}
+
+// toProfileResponse формирует ответ с профилем пользователя
+func toProfileResponse(user *models.User) dto.ProfileResponse {
+	return dto.ProfileResponse{
+		ID:               user.ID,
+		Email:            user.Email,
+		Username:         user.Username,
+		Role:             string(user.Role),
+		TwoFactorEnabled: user.TwoFactorEnabled,
+		EmailVerified:    user.IsEmailVerified(),
+		CreatedAt:        user.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		UpdatedAt:        user.UpdatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+}
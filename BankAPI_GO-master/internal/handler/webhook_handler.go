@@ -0,0 +1,261 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/therealadik/bank-api/internal/dto"
+	"github.com/therealadik/bank-api/internal/middleware"
+	"github.com/therealadik/bank-api/internal/models/webhook"
+	"github.com/therealadik/bank-api/internal/problem"
+	"github.com/therealadik/bank-api/internal/service"
+)
+
+// WebhookHandler обработчик вебхуков и журнала их доставок
+type WebhookHandler struct {
+	webhookService *service.WebhookService
+	logger         *logrus.Logger
+}
+
+func NewWebhookHandler(webhookService *service.WebhookService, logger *logrus.Logger) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+		logger:         logger,
+	}
+}
+
+// Create обработчик для регистрации вебхука
+func (h *WebhookHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		h.logger.Errorf("Ошибка получения userID из контекста: %v", err)
+		problem.Write(w, r, problem.UNAUTHORIZED)
+		return
+	}
+
+	var req dto.CreateWebhookRequest
+	if !decodeJSON(w, r, h.logger, &req) {
+		return
+	}
+
+	wh, err := h.webhookService.Create(r.Context(), userID, req.URL, req.Events)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка регистрации вебхука")
+		return
+	}
+
+	h.logger.Infof("Пользователь %d зарегистрировал вебхук %d", userID, wh.ID)
+	resp := toWebhookResponse(wh)
+	resp.Secret = wh.Secret
+	h.writeJSON(w, http.StatusCreated, resp)
+}
+
+// GetAll обработчик для получения вебхуков пользователя
+func (h *WebhookHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		h.logger.Errorf("Ошибка получения userID из контекста: %v", err)
+		problem.Write(w, r, problem.UNAUTHORIZED)
+		return
+	}
+
+	webhooks, err := h.webhookService.GetByUserID(r.Context(), userID)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка получения вебхуков")
+		return
+	}
+
+	resp := dto.WebhookListResponse{
+		Webhooks: make([]dto.WebhookResponse, 0, len(webhooks)),
+	}
+	for _, wh := range webhooks {
+		resp.Webhooks = append(resp.Webhooks, toWebhookResponse(wh))
+	}
+
+	h.writeJSON(w, http.StatusOK, resp)
+}
+
+// Get обработчик для получения вебхука
+func (h *WebhookHandler) Get(w http.ResponseWriter, r *http.Request) {
+	userID, id, ok := h.parseRequest(w, r)
+	if !ok {
+		return
+	}
+
+	wh, err := h.webhookService.GetByID(r.Context(), userID, id)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка получения вебхука")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, toWebhookResponse(wh))
+}
+
+// Delete обработчик для удаления вебхука
+func (h *WebhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID, id, ok := h.parseRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.webhookService.Delete(r.Context(), userID, id); err != nil {
+		writeError(w, r, h.logger, err, "Ошибка удаления вебхука")
+		return
+	}
+
+	h.logger.Infof("Пользователь %d удалил вебхук %d", userID, id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetDeliveries обработчик для получения последних доставок вебхука
+func (h *WebhookHandler) GetDeliveries(w http.ResponseWriter, r *http.Request) {
+	userID, id, ok := h.parseRequest(w, r)
+	if !ok {
+		return
+	}
+
+	deliveries, err := h.webhookService.GetDeliveries(r.Context(), userID, id)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка получения доставок вебхука")
+		return
+	}
+
+	resp := dto.WebhookDeliveryListResponse{
+		Deliveries: make([]dto.WebhookDeliveryResponse, 0, len(deliveries)),
+	}
+	for _, d := range deliveries {
+		resp.Deliveries = append(resp.Deliveries, toWebhookDeliveryResponse(d))
+	}
+
+	h.writeJSON(w, http.StatusOK, resp)
+}
+
+// GetDelivery обработчик для получения доставки с журналом попыток
+func (h *WebhookHandler) GetDelivery(w http.ResponseWriter, r *http.Request) {
+	userID, id, deliveryID, ok := h.parseDeliveryRequest(w, r)
+	if !ok {
+		return
+	}
+
+	d, attempts, err := h.webhookService.GetDelivery(r.Context(), userID, id, deliveryID)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка получения доставки вебхука")
+		return
+	}
+
+	resp := dto.WebhookDeliveryDetailsResponse{
+		WebhookDeliveryResponse: toWebhookDeliveryResponse(d),
+		Payload:                 d.Payload,
+		AttemptLog:              make([]dto.WebhookAttemptResponse, 0, len(attempts)),
+	}
+	for _, a := range attempts {
+		resp.AttemptLog = append(resp.AttemptLog, dto.WebhookAttemptResponse{
+			Attempt:    a.Attempt,
+			StatusCode: a.StatusCode,
+			Error:      a.Error,
+			DurationMs: a.DurationMs,
+			CreatedAt:  a.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		})
+	}
+
+	h.writeJSON(w, http.StatusOK, resp)
+}
+
+// Redeliver обработчик для повторной доставки события
+func (h *WebhookHandler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	userID, id, deliveryID, ok := h.parseDeliveryRequest(w, r)
+	if !ok {
+		return
+	}
+
+	d, err := h.webhookService.Redeliver(r.Context(), userID, id, deliveryID)
+	if err != nil {
+		writeError(w, r, h.logger, err, "Ошибка повторной доставки вебхука")
+		return
+	}
+
+	h.logger.Infof("Пользователь %d запросил повторную доставку %d вебхука %d", userID, deliveryID, id)
+	h.writeJSON(w, http.StatusAccepted, toWebhookDeliveryResponse(d))
+}
+
+// parseRequest извлекает ID пользователя из контекста и ID вебхука из URL
+func (h *WebhookHandler) parseRequest(w http.ResponseWriter, r *http.Request) (int64, int64, bool) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		h.logger.Errorf("Ошибка получения userID из контекста: %v", err)
+		problem.Write(w, r, problem.UNAUTHORIZED)
+		return 0, 0, false
+	}
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		h.logger.Warnf("Неверный формат ID вебхука: %v", err)
+		problem.Write(w, r, problem.INVALID_WEBHOOK_ID)
+		return 0, 0, false
+	}
+
+	return userID, id, true
+}
+
+// parseDeliveryRequest дополнительно извлекает из URL ID доставки
+func (h *WebhookHandler) parseDeliveryRequest(w http.ResponseWriter, r *http.Request) (int64, int64, int64, bool) {
+	userID, id, ok := h.parseRequest(w, r)
+	if !ok {
+		return 0, 0, 0, false
+	}
+
+	deliveryID, err := strconv.ParseInt(mux.Vars(r)["delivery_id"], 10, 64)
+	if err != nil {
+		h.logger.Warnf("Неверный формат ID доставки вебхука: %v", err)
+		problem.Write(w, r, problem.INVALID_WEBHOOK_DELIVERY_ID)
+		return 0, 0, 0, false
+	}
+
+	return userID, id, deliveryID, true
+}
+
+// writeJSON отправляет JSON-ответ с указанным статусом
+func (h *WebhookHandler) writeJSON(w http.ResponseWriter, status int, resp any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger.Errorf("Ошибка кодирования ответа: %v", err)
+	}
+}
+
+// toWebhookResponse формирует ответ с вебхуком (без секрета)
+func toWebhookResponse(wh *webhook.Webhook) dto.WebhookResponse {
+	return dto.WebhookResponse{
+		ID:        wh.ID,
+		URL:       wh.URL,
+		Events:    wh.Events,
+		CreatedAt: wh.CreatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+}
+
+// toWebhookDeliveryResponse формирует ответ с доставкой события
+func toWebhookDeliveryResponse(d *webhook.Delivery) dto.WebhookDeliveryResponse {
+	resp := dto.WebhookDeliveryResponse{
+		ID:             d.ID,
+		WebhookID:      d.WebhookID,
+		EventID:        d.EventID,
+		EventType:      d.EventType,
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		LastStatusCode: d.LastStatusCode,
+		LastError:      d.LastError,
+		CreatedAt:      d.CreatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+	if d.Status == webhook.PENDING {
+		nextAttemptAt := d.NextAttemptAt.Format("2006-01-02T15:04:05Z")
+		resp.NextAttemptAt = &nextAttemptAt
+	}
+	if d.DeliveredAt != nil {
+		deliveredAt := d.DeliveredAt.Format("2006-01-02T15:04:05Z")
+		resp.DeliveredAt = &deliveredAt
+	}
+	return resp
+}
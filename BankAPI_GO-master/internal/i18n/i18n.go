@@ -0,0 +1,118 @@
+// Package i18n содержит каталог сообщений для пользователей на русском и английском.
+// Сообщения об ошибках API хранятся по кодам ошибок, тексты писем и ответов — по ключам.
+// Язык выбирается по заголовку Accept-Language; по умолчанию — русский.
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Lang язык сообщений
+type Lang string
+
+const (
+	RU Lang = "ru"
+	EN Lang = "en"
+)
+
+// Default язык по умолчанию: для запросов без Accept-Language и фоновых задач
+const Default = RU
+
+// Key ключ сообщения в каталоге. Для ошибок API ключом служит код ошибки.
+type Key string
+
+// messages каталог сообщений по языкам
+var messages = map[Lang]map[Key]string{
+	RU: ru,
+	EN: en,
+}
+
+// T возвращает сообщение на языке lang, подставляя args через fmt.Sprintf.
+// Если перевода нет, используется язык по умолчанию, а если нет и его — сам ключ.
+func T(lang Lang, key Key, args ...any) string {
+	msg, ok := messages[lang][key]
+	if !ok {
+		msg, ok = messages[Default][key]
+	}
+	if !ok {
+		return string(key)
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// Has сообщает, есть ли ключ в каталоге языка по умолчанию
+func Has(key Key) bool {
+	_, ok := messages[Default][key]
+	return ok
+}
+
+// Parse выбирает поддерживаемый язык по значению Accept-Language с учетом весов q.
+// Учитывается основной тег языка: «en-US» соответствует EN. Если ни один язык
+// не поддерживается, возвращается язык по умолчанию.
+func Parse(header string) Lang {
+	type candidate struct {
+		lang Lang
+		q    float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if name, value, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(name) == "q" {
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+
+		primary, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		lang := Lang(primary)
+		if _, ok := messages[lang]; ok {
+			candidates = append(candidates, candidate{lang: lang, q: q})
+		}
+	}
+
+	if len(candidates) == 0 {
+		return Default
+	}
+	// Стабильная сортировка сохраняет порядок клиента при равных весах
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+	return candidates[0].lang
+}
+
+type contextKey struct{}
+
+// NewContext возвращает контекст с языком сообщений
+func NewContext(ctx context.Context, lang Lang) context.Context {
+	return context.WithValue(ctx, contextKey{}, lang)
+}
+
+// FromContext извлекает язык сообщений; вне HTTP-запроса возвращает язык по умолчанию
+func FromContext(ctx context.Context) Lang {
+	if lang, ok := ctx.Value(contextKey{}).(Lang); ok {
+		return lang
+	}
+	return Default
+}
+
+// FromRequest возвращает язык запроса: из контекста, если его определил middleware,
+// иначе по заголовку Accept-Language
+func FromRequest(r *http.Request) Lang {
+	if lang, ok := r.Context().Value(contextKey{}).(Lang); ok {
+		return lang
+	}
+	return Parse(r.Header.Get("Accept-Language"))
+}
@@ -0,0 +1,48 @@
+package i18n
+
+// Сообщения в успешных ответах API
+const (
+	MSG_USER_REGISTERED         Key = "MSG_USER_REGISTERED"
+	MSG_EMAIL_VERIFIED          Key = "MSG_EMAIL_VERIFIED"
+	MSG_PAYMENT_HELD_FOR_REVIEW Key = "MSG_PAYMENT_HELD_FOR_REVIEW"
+	MSG_PAYMENT_AUTHORIZED      Key = "MSG_PAYMENT_AUTHORIZED"
+	MSG_PAYMENT_CAPTURED        Key = "MSG_PAYMENT_CAPTURED"
+	MSG_PAYMENT_VOIDED          Key = "MSG_PAYMENT_VOIDED"
+)
+
+// Описания ошибок проверки полей запроса
+const (
+	VALIDATION_REQUIRED      Key = "VALIDATION_REQUIRED"
+	VALIDATION_EMAIL         Key = "VALIDATION_EMAIL"
+	VALIDATION_MIN_LENGTH    Key = "VALIDATION_MIN_LENGTH"
+	VALIDATION_MAX_LENGTH    Key = "VALIDATION_MAX_LENGTH"
+	VALIDATION_MIN           Key = "VALIDATION_MIN"
+	VALIDATION_MAX           Key = "VALIDATION_MAX"
+	VALIDATION_LENGTH        Key = "VALIDATION_LENGTH"
+	VALIDATION_ONEOF         Key = "VALIDATION_ONEOF"
+	VALIDATION_DIGITS        Key = "VALIDATION_DIGITS"
+	VALIDATION_AMOUNT        Key = "VALIDATION_AMOUNT"
+	VALIDATION_MONEY         Key = "VALIDATION_MONEY"
+	VALIDATION_UNKNOWN_FIELD Key = "VALIDATION_UNKNOWN_FIELD"
+	VALIDATION_INVALID_TYPE  Key = "VALIDATION_INVALID_TYPE"
+)
+
+// Темы и тексты писем пользователям
+const (
+	EMAIL_LOGIN_LOCKED_SUBJECT      Key = "EMAIL_LOGIN_LOCKED_SUBJECT"
+	EMAIL_LOGIN_LOCKED_BODY         Key = "EMAIL_LOGIN_LOCKED_BODY"
+	EMAIL_CARD_BLOCKED_SUBJECT      Key = "EMAIL_CARD_BLOCKED_SUBJECT"
+	EMAIL_CARD_BLOCKED_BODY         Key = "EMAIL_CARD_BLOCKED_BODY"
+	EMAIL_PASSWORD_RESET_SUBJECT    Key = "EMAIL_PASSWORD_RESET_SUBJECT"
+	EMAIL_PASSWORD_RESET_BODY       Key = "EMAIL_PASSWORD_RESET_BODY"
+	EMAIL_PASSWORD_CHANGED_SUBJECT  Key = "EMAIL_PASSWORD_CHANGED_SUBJECT"
+	EMAIL_PASSWORD_CHANGED_BODY     Key = "EMAIL_PASSWORD_CHANGED_BODY"
+	EMAIL_VERIFICATION_SUBJECT      Key = "EMAIL_VERIFICATION_SUBJECT"
+	EMAIL_VERIFICATION_BODY         Key = "EMAIL_VERIFICATION_BODY"
+	EMAIL_SCHEDULED_FAILED_SUBJECT  Key = "EMAIL_SCHEDULED_FAILED_SUBJECT"
+	EMAIL_SCHEDULED_FAILED_BODY     Key = "EMAIL_SCHEDULED_FAILED_BODY"
+	EMAIL_SCHEDULED_WILL_RETRY      Key = "EMAIL_SCHEDULED_WILL_RETRY"
+	EMAIL_SCHEDULED_STOPPED         Key = "EMAIL_SCHEDULED_STOPPED"
+	EMAIL_SCHEDULED_SKIPPED         Key = "EMAIL_SCHEDULED_SKIPPED"
+	EMAIL_SCHEDULED_SKIPPED_NO_NEXT Key = "EMAIL_SCHEDULED_SKIPPED_NO_NEXT"
+)
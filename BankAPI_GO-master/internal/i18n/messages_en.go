@@ -0,0 +1,173 @@
+package i18n
+
+// en сообщения на английском языке
+var en = map[Key]string{
+	// Ошибки API по кодам
+
+	"INVALID_REQUEST_BODY":            "Malformed request body",
+	"VALIDATION_FAILED":               "Request contains invalid fields",
+	"REQUEST_BODY_TOO_LARGE":          "Request body is too large",
+	"INVALID_QUERY_PARAMETER":         "Invalid query parameter",
+	"INVALID_IDEMPOTENCY_KEY":         "Idempotency key must be 1 to 255 printable ASCII characters",
+	"IDEMPOTENCY_KEY_REUSED":          "Idempotency key has already been used with a different request",
+	"IDEMPOTENCY_REQUEST_IN_PROGRESS": "A request with this idempotency key is still in progress, retry later",
+	"UNAUTHORIZED":                    "Authentication required",
+	"INVALID_TOKEN_FORMAT":            "Invalid token format",
+	"INVALID_TOKEN":                   "Invalid or expired token",
+	"FORBIDDEN":                       "Access denied",
+	"NOT_FOUND":                       "Object not found",
+	"ROUTE_NOT_FOUND":                 "Route not found",
+	"METHOD_NOT_ALLOWED":              "Method not allowed for this route",
+	"INTERNAL_ERROR":                  "Internal server error",
+
+	"INVALID_USER_ID":               "Invalid user ID",
+	"INVALID_ACCOUNT_ID":            "Invalid account ID",
+	"INVALID_CARD_ID":               "Invalid card ID",
+	"INVALID_PAYMENT_ID":            "Invalid payment ID",
+	"INVALID_DISPUTE_ID":            "Invalid dispute ID",
+	"INVALID_SCHEDULED_TRANSFER_ID": "Invalid scheduled transfer ID",
+	"INVALID_FRAUD_REVIEW_ID":       "Invalid operation ID",
+	"INVALID_WEBHOOK_ID":            "Invalid webhook ID",
+	"INVALID_WEBHOOK_DELIVERY_ID":   "Invalid webhook delivery ID",
+
+	"INVALID_CREDENTIALS":        "Invalid email or password",
+	"LOGIN_LOCKED":               "Too many failed login attempts, try again later",
+	"INVALID_REFRESH_TOKEN":      "Invalid or expired refresh token",
+	"INVALID_CHALLENGE":          "Invalid or expired second-step token",
+	"INVALID_TOTP_CODE":          "Invalid verification code",
+	"TOTP_REQUIRED":              "This operation requires a TOTP code in the X-TOTP-Code header",
+	"STEP_UP_TOTP_INVALID":       "Invalid operation confirmation code",
+	"TWO_FACTOR_ALREADY_ENABLED": "Two-factor authentication is already enabled",
+	"TWO_FACTOR_NOT_ENROLLED":    "Request a secret via /2fa/enroll first",
+	"TWO_FACTOR_NOT_ENABLED":     "Two-factor authentication is not enabled",
+
+	"USER_NOT_FOUND":             "User not found",
+	"INVALID_EMAIL":              "Invalid email format",
+	"INVALID_USERNAME":           "Username must be 3 to 32 Latin letters, digits or _ . - characters",
+	"WEAK_PASSWORD":              "Password must be 8 to 72 characters long and contain letters and digits",
+	"EMAIL_TAKEN":                "A user with this email already exists",
+	"USERNAME_TAKEN":             "A user with this username already exists",
+	"NO_FIELDS_TO_UPDATE":        "No fields to update",
+	"WRONG_PASSWORD":             "Current password is incorrect",
+	"SAME_PASSWORD":              "New password must differ from the current one",
+	"INVALID_RESET_TOKEN":        "Password reset link is invalid or expired",
+	"INVALID_VERIFICATION_TOKEN": "Invalid verification link",
+	"VERIFICATION_TOKEN_EXPIRED": "The link has expired, request a new email",
+	"EMAIL_ALREADY_VERIFIED":     "Email is already verified",
+	"VERIFICATION_TOO_FREQUENT":  "The email has already been sent, try again later",
+	"EMAIL_NOT_VERIFIED":         "Verify your email to make transfers and payments",
+
+	"UNSUPPORTED_CURRENCY":            "Only RUB currency is supported",
+	"ACCOUNT_NOT_FOUND":               "Account not found",
+	"ACCOUNT_FORBIDDEN":               "Account does not belong to the user",
+	"ACCOUNT_FROZEN":                  "Account is frozen",
+	"ACCOUNT_CLOSED":                  "Account is closed",
+	"ACCOUNT_ALREADY_CLOSED":          "Account is already closed",
+	"ACCOUNT_HAS_HOLDS":               "Account has pending card payments",
+	"ACCOUNT_RELOAD_FAILED":           "Operation completed, but account data could not be loaded",
+	"CLOSE_TARGET_REQUIRED":           "Account has a balance: specify an account to transfer it to",
+	"CLOSE_TARGET_SAME_ACCOUNT":       "Cannot transfer the balance to the account being closed",
+	"INSUFFICIENT_FUNDS":              "Insufficient funds",
+	"SAME_ACCOUNT":                    "Cannot transfer to the same account",
+	"INVALID_AMOUNT":                  "Amount must be positive",
+	"RECIPIENT_REQUIRED":              "Specify exactly one recipient: to_account_id, to_account_number or to_email",
+	"RECIPIENT_NOT_FOUND":             "Recipient not found",
+	"INVALID_ACCOUNT_NUMBER":          "Invalid account number",
+	"CURRENCY_MISMATCH":               "Account currencies do not match",
+	"INVALID_TRANSFER_LIMIT":          "Limit cannot be negative",
+	"TRANSFER_SINGLE_LIMIT_EXCEEDED":  "Amount exceeds the single operation limit",
+	"TRANSFER_DAILY_LIMIT_EXCEEDED":   "Daily transfer limit exceeded",
+	"TRANSFER_MONTHLY_LIMIT_EXCEEDED": "Monthly transfer limit exceeded",
+	"TRANSFER_RATE_LIMITED":           "Hourly transfer count exceeded",
+	"TRANSFER_PREVIEW_RATE_LIMITED":   "Too many recipient preview requests",
+	"SCHEDULED_TRANSFER_NOT_FOUND":    "Scheduled transfer not found",
+	"SCHEDULED_TRANSFER_NOT_ACTIVE":   "Scheduled transfer is already finished or cancelled",
+	"INVALID_FREQUENCY":               "Unknown frequency: ONCE, DAILY, WEEKLY, MONTHLY or CRON",
+	"INVALID_CRON_EXPR":               "Invalid cron expression: CRON requires a five-field expression, other frequencies must omit it",
+	"SCHEDULE_IN_PAST":                "Transfer start time is in the past",
+	"INVALID_SCHEDULE_END":            "End time must be after the first execution",
+	"STREAM_LIMIT_EXCEEDED":           "Too many open update streams: close unused ones",
+
+	"CARD_NOT_FOUND":                 "Card not found",
+	"CARD_FORBIDDEN":                 "Card does not belong to the user",
+	"CARD_BLOCKED":                   "Card is blocked",
+	"CARD_NOT_LINKED":                "Card is not linked to an account",
+	"CARD_VERIFICATION_FAILED":       "Invalid card details",
+	"PGP_KEY_REQUIRED":               "PGP key is required",
+	"INVALID_CARD_LIMIT":             "Limit must be positive",
+	"INVALID_MERCHANT_CATEGORY":      "Merchant category code must consist of 4 digits",
+	"MERCHANT_CATEGORY_BLOCKED":      "Payments in this category are blocked for the card",
+	"PER_TRANSACTION_LIMIT_EXCEEDED": "Per-transaction limit exceeded",
+	"DAILY_LIMIT_EXCEEDED":           "Daily card limit exceeded",
+	"MONTHLY_LIMIT_EXCEEDED":         "Monthly card limit exceeded",
+	"PAYMENT_NOT_FOUND":              "Payment not found",
+	"PAYMENT_NOT_AUTHORIZED":         "Payment is already captured, voided or expired",
+	"PAYMENT_NOT_CAPTURED":           "Payment is not captured or already fully refunded",
+	"CAPTURE_EXCEEDS_HOLD":           "Capture amount exceeds the held amount",
+	"REFUND_EXCEEDS_CAPTURED":        "Amount exceeds the captured amount of the payment",
+	"DISPUTE_NOT_FOUND":              "Dispute not found",
+	"DISPUTE_ALREADY_OPEN":           "A dispute is already open for this payment",
+	"DISPUTE_REASON_REQUIRED":        "Dispute reason is required",
+	"INVALID_DISPUTE_STATUS":         "Unknown dispute status",
+	"INVALID_DISPUTE_TRANSITION":     "Invalid dispute status transition",
+
+	"ADMIN_REASON_REQUIRED":            "Reason is required",
+	"USER_LOOKUP_QUERY_REQUIRED":       "Specify email or username",
+	"INVALID_ROLE":                     "Unknown role",
+	"CANNOT_CHANGE_OWN_ROLE":           "Cannot change your own role",
+	"INVALID_ACCOUNT_STATUS":           "Invalid account status transition",
+	"FRAUD_REVIEW_NOT_FOUND":           "Held operation not found",
+	"FRAUD_REVIEW_RESOLVED":            "A decision on this operation has already been made",
+	"INVALID_FRAUD_REVIEW_STATUS":      "Unknown status: PENDING, APPROVED or REJECTED",
+	"FRAUD_REVIEW_ACCOUNT_FROZEN":      "Account is frozen: the operation can only be rejected",
+	"FRAUD_REVIEW_ACCOUNT_UNAVAILABLE": "Account is closed or deleted: the operation can only be rejected",
+
+	"WEBHOOK_NOT_FOUND":          "Webhook not found",
+	"WEBHOOK_DELIVERY_NOT_FOUND": "Webhook delivery not found",
+	"INVALID_WEBHOOK_URL":        "Invalid webhook URL: a public https:// URL without credentials is required",
+	"INVALID_WEBHOOK_EVENT":      "Unknown event type: transaction.completed or card.payment",
+	"WEBHOOK_LIMIT_EXCEEDED":     "Too many webhooks for this user",
+	"WEBHOOK_DELIVERY_PENDING":   "Delivery is still in progress: only a finished delivery can be redelivered",
+
+	MSG_USER_REGISTERED:         "User registered successfully",
+	MSG_EMAIL_VERIFIED:          "Email verified",
+	MSG_PAYMENT_HELD_FOR_REVIEW: "Funds are held, the payment is awaiting review",
+	MSG_PAYMENT_AUTHORIZED:      "Funds are held",
+	MSG_PAYMENT_CAPTURED:        "Payment captured successfully",
+	MSG_PAYMENT_VOIDED:          "Payment voided",
+
+	VALIDATION_REQUIRED:      "Required field",
+	VALIDATION_EMAIL:         "Invalid email format",
+	VALIDATION_MIN_LENGTH:    "Must be at least %s characters long",
+	VALIDATION_MAX_LENGTH:    "Must be at most %s characters long",
+	VALIDATION_MIN:           "Must be at least %s",
+	VALIDATION_MAX:           "Must be at most %s",
+	VALIDATION_LENGTH:        "Must be exactly %s characters long",
+	VALIDATION_ONEOF:         "Allowed values: %s",
+	VALIDATION_DIGITS:        "Only digits are allowed",
+	VALIDATION_AMOUNT:        "Amount must be positive, at most 9999999999.99 and have at most two decimal places",
+	VALIDATION_MONEY:         "Amount must be at most 9999999999.99 in absolute value and have at most two decimal places",
+	VALIDATION_UNKNOWN_FIELD: "Unknown field",
+	VALIDATION_INVALID_TYPE:  "Invalid value type",
+
+	EMAIL_LOGIN_LOCKED_SUBJECT: "Sign-in temporarily locked",
+	EMAIL_LOGIN_LOCKED_BODY: "After several failed sign-in attempts, sign-in to your account is locked until %s (UTC).\n\n" +
+		"If this wasn't you, change your password once the lock expires or use password recovery.",
+	EMAIL_CARD_BLOCKED_SUBJECT: "Card blocked",
+	EMAIL_CARD_BLOCKED_BODY: "Card #%d has been blocked: an incorrect CVV was entered several times in a row during payment.\n\n" +
+		"If this wasn't you, contact support. Only a bank employee can unblock the card.",
+	EMAIL_PASSWORD_RESET_SUBJECT: "Password reset",
+	EMAIL_PASSWORD_RESET_BODY: "To reset your password, follow the link: %s\n\n" +
+		"The link is valid for %s. If you did not request a password reset, ignore this email.",
+	EMAIL_PASSWORD_CHANGED_SUBJECT: "Password changed",
+	EMAIL_PASSWORD_CHANGED_BODY:    "Your account password has been changed and all sessions have been terminated. If this wasn't you, contact support.",
+	EMAIL_VERIFICATION_SUBJECT:     "Email verification",
+	EMAIL_VERIFICATION_BODY: "Hello, %s!\n\nTo verify your email, follow the link: %s\n\n" +
+		"The link is valid for %s. Transfers and payments are unavailable until verification.",
+	EMAIL_SCHEDULED_FAILED_SUBJECT:  "Scheduled transfer failed",
+	EMAIL_SCHEDULED_FAILED_BODY:     "Transfer #%d of %s scheduled for %s failed: %s.\n%s",
+	EMAIL_SCHEDULED_WILL_RETRY:      "It will be retried at %s.",
+	EMAIL_SCHEDULED_STOPPED:         "The transfer has been stopped. Create it again once the cause is resolved.",
+	EMAIL_SCHEDULED_SKIPPED:         "This execution was skipped, the next one is scheduled for %s.",
+	EMAIL_SCHEDULED_SKIPPED_NO_NEXT: "This execution was skipped, no further executions are scheduled.",
+}
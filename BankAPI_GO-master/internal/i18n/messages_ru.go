@@ -0,0 +1,173 @@
+package i18n
+
+// ru сообщения на русском языке
+var ru = map[Key]string{
+	// Ошибки API по кодам
+
+	"INVALID_REQUEST_BODY":            "Неверный формат запроса",
+	"VALIDATION_FAILED":               "Запрос содержит ошибки в полях",
+	"REQUEST_BODY_TOO_LARGE":          "Слишком большое тело запроса",
+	"INVALID_QUERY_PARAMETER":         "Неверный параметр запроса",
+	"INVALID_IDEMPOTENCY_KEY":         "Ключ идемпотентности должен содержать от 1 до 255 печатных символов ASCII",
+	"IDEMPOTENCY_KEY_REUSED":          "Ключ идемпотентности уже использован с другим запросом",
+	"IDEMPOTENCY_REQUEST_IN_PROGRESS": "Запрос с этим ключом идемпотентности еще выполняется, повторите позже",
+	"UNAUTHORIZED":                    "Требуется авторизация",
+	"INVALID_TOKEN_FORMAT":            "Неверный формат токена",
+	"INVALID_TOKEN":                   "Неверный или просроченный токен",
+	"FORBIDDEN":                       "Доступ запрещен",
+	"NOT_FOUND":                       "Объект не найден",
+	"ROUTE_NOT_FOUND":                 "Маршрут не найден",
+	"METHOD_NOT_ALLOWED":              "Метод не поддерживается для этого маршрута",
+	"INTERNAL_ERROR":                  "Внутренняя ошибка сервера",
+
+	"INVALID_USER_ID":               "Неверный ID пользователя",
+	"INVALID_ACCOUNT_ID":            "Неверный ID счета",
+	"INVALID_CARD_ID":               "Неверный ID карты",
+	"INVALID_PAYMENT_ID":            "Неверный ID платежа",
+	"INVALID_DISPUTE_ID":            "Неверный ID спора",
+	"INVALID_SCHEDULED_TRANSFER_ID": "Неверный ID регулярного перевода",
+	"INVALID_FRAUD_REVIEW_ID":       "Неверный ID операции",
+	"INVALID_WEBHOOK_ID":            "Неверный ID вебхука",
+	"INVALID_WEBHOOK_DELIVERY_ID":   "Неверный ID доставки вебхука",
+
+	"INVALID_CREDENTIALS":        "Неверный email или пароль",
+	"LOGIN_LOCKED":               "Слишком много неудачных попыток входа, повторите позже",
+	"INVALID_REFRESH_TOKEN":      "Неверный или просроченный refresh-токен",
+	"INVALID_CHALLENGE":          "Неверный или просроченный токен второго шага",
+	"INVALID_TOTP_CODE":          "Неверный код подтверждения",
+	"TOTP_REQUIRED":              "Операция требует подтверждения кодом TOTP в заголовке X-TOTP-Code",
+	"STEP_UP_TOTP_INVALID":       "Неверный код подтверждения операции",
+	"TWO_FACTOR_ALREADY_ENABLED": "Двухфакторная аутентификация уже включена",
+	"TWO_FACTOR_NOT_ENROLLED":    "Сначала получите секрет через /2fa/enroll",
+	"TWO_FACTOR_NOT_ENABLED":     "Двухфакторная аутентификация не включена",
+
+	"USER_NOT_FOUND":             "Пользователь не найден",
+	"INVALID_EMAIL":              "Неверный формат email",
+	"INVALID_USERNAME":           "Имя пользователя должно содержать от 3 до 32 латинских букв, цифр или символов _ . -",
+	"WEAK_PASSWORD":              "Пароль должен содержать от 8 до 72 символов, включая буквы и цифры",
+	"EMAIL_TAKEN":                "Пользователь с таким email уже существует",
+	"USERNAME_TAKEN":             "Пользователь с таким username уже существует",
+	"NO_FIELDS_TO_UPDATE":        "Нет изменяемых полей",
+	"WRONG_PASSWORD":             "Неверный текущий пароль",
+	"SAME_PASSWORD":              "Новый пароль должен отличаться от текущего",
+	"INVALID_RESET_TOKEN":        "Ссылка для сброса пароля недействительна или устарела",
+	"INVALID_VERIFICATION_TOKEN": "Неверная ссылка подтверждения",
+	"VERIFICATION_TOKEN_EXPIRED": "Срок действия ссылки истек, запросите новое письмо",
+	"EMAIL_ALREADY_VERIFIED":     "Email уже подтвержден",
+	"VERIFICATION_TOO_FREQUENT":  "Письмо уже отправлено, повторите позже",
+	"EMAIL_NOT_VERIFIED":         "Подтвердите email, чтобы выполнять переводы и платежи",
+
+	"UNSUPPORTED_CURRENCY":            "Поддерживается только валюта RUB",
+	"ACCOUNT_NOT_FOUND":               "Счет не найден",
+	"ACCOUNT_FORBIDDEN":               "Счет не принадлежит пользователю",
+	"ACCOUNT_FROZEN":                  "Счет заморожен",
+	"ACCOUNT_CLOSED":                  "Счет закрыт",
+	"ACCOUNT_ALREADY_CLOSED":          "Счет уже закрыт",
+	"ACCOUNT_HAS_HOLDS":               "По счету есть незавершенные платежи картой",
+	"ACCOUNT_RELOAD_FAILED":           "Операция выполнена, но не удалось получить данные счета",
+	"CLOSE_TARGET_REQUIRED":           "На счете есть остаток: укажите счет для его перевода",
+	"CLOSE_TARGET_SAME_ACCOUNT":       "Нельзя перевести остаток на закрываемый счет",
+	"INSUFFICIENT_FUNDS":              "Недостаточно средств",
+	"SAME_ACCOUNT":                    "Нельзя переводить на тот же счет",
+	"INVALID_AMOUNT":                  "Сумма должна быть положительной",
+	"RECIPIENT_REQUIRED":              "Укажите ровно одного получателя: to_account_id, to_account_number или to_email",
+	"RECIPIENT_NOT_FOUND":             "Получатель не найден",
+	"INVALID_ACCOUNT_NUMBER":          "Неверный номер счета",
+	"CURRENCY_MISMATCH":               "Валюты счетов не совпадают",
+	"INVALID_TRANSFER_LIMIT":          "Лимит не может быть отрицательным",
+	"TRANSFER_SINGLE_LIMIT_EXCEEDED":  "Сумма операции превышает лимит на одну операцию",
+	"TRANSFER_DAILY_LIMIT_EXCEEDED":   "Превышен суточный лимит переводов",
+	"TRANSFER_MONTHLY_LIMIT_EXCEEDED": "Превышен месячный лимит переводов",
+	"TRANSFER_RATE_LIMITED":           "Превышено число переводов за час",
+	"TRANSFER_PREVIEW_RATE_LIMITED":   "Слишком много запросов предпросмотра получателя",
+	"SCHEDULED_TRANSFER_NOT_FOUND":    "Регулярный перевод не найден",
+	"SCHEDULED_TRANSFER_NOT_ACTIVE":   "Регулярный перевод уже завершен или отменен",
+	"INVALID_FREQUENCY":               "Неизвестная периодичность: ONCE, DAILY, WEEKLY, MONTHLY или CRON",
+	"INVALID_CRON_EXPR":               "Неверное cron-выражение: для CRON нужно выражение из пяти полей, для остальных — не указывать",
+	"SCHEDULE_IN_PAST":                "Время начала перевода в прошлом",
+	"INVALID_SCHEDULE_END":            "Время окончания должно быть позже первого исполнения",
+	"STREAM_LIMIT_EXCEEDED":           "Слишком много открытых потоков изменений: закройте неиспользуемые",
+
+	"CARD_NOT_FOUND":                 "Карта не найдена",
+	"CARD_FORBIDDEN":                 "Карта не принадлежит пользователю",
+	"CARD_BLOCKED":                   "Карта заблокирована",
+	"CARD_NOT_LINKED":                "Карта не привязана к счету",
+	"CARD_VERIFICATION_FAILED":       "Неверные данные карты",
+	"PGP_KEY_REQUIRED":               "PGP ключ обязателен",
+	"INVALID_CARD_LIMIT":             "Лимит должен быть положительным",
+	"INVALID_MERCHANT_CATEGORY":      "Код категории торговца должен состоять из 4 цифр",
+	"MERCHANT_CATEGORY_BLOCKED":      "Оплата в этой категории запрещена для карты",
+	"PER_TRANSACTION_LIMIT_EXCEEDED": "Превышен лимит на одну операцию",
+	"DAILY_LIMIT_EXCEEDED":           "Превышен дневной лимит по карте",
+	"MONTHLY_LIMIT_EXCEEDED":         "Превышен месячный лимит по карте",
+	"PAYMENT_NOT_FOUND":              "Платеж не найден",
+	"PAYMENT_NOT_AUTHORIZED":         "Платеж уже проведен, отменен или истек",
+	"PAYMENT_NOT_CAPTURED":           "Платеж не проведен или уже полностью возвращен",
+	"CAPTURE_EXCEEDS_HOLD":           "Сумма списания превышает заблокированную сумму",
+	"REFUND_EXCEEDS_CAPTURED":        "Сумма превышает списанную по платежу сумму",
+	"DISPUTE_NOT_FOUND":              "Спор не найден",
+	"DISPUTE_ALREADY_OPEN":           "По платежу уже открыт спор",
+	"DISPUTE_REASON_REQUIRED":        "Необходимо указать причину спора",
+	"INVALID_DISPUTE_STATUS":         "Неизвестный статус спора",
+	"INVALID_DISPUTE_TRANSITION":     "Недопустимый переход статуса спора",
+
+	"ADMIN_REASON_REQUIRED":            "Необходимо указать причину",
+	"USER_LOOKUP_QUERY_REQUIRED":       "Необходимо указать email или username",
+	"INVALID_ROLE":                     "Неизвестная роль",
+	"CANNOT_CHANGE_OWN_ROLE":           "Нельзя изменить собственную роль",
+	"INVALID_ACCOUNT_STATUS":           "Недопустимый переход статуса счета",
+	"FRAUD_REVIEW_NOT_FOUND":           "Задержанная операция не найдена",
+	"FRAUD_REVIEW_RESOLVED":            "Решение по операции уже принято",
+	"INVALID_FRAUD_REVIEW_STATUS":      "Неизвестный статус: PENDING, APPROVED или REJECTED",
+	"FRAUD_REVIEW_ACCOUNT_FROZEN":      "Счет заморожен: операцию можно только отклонить",
+	"FRAUD_REVIEW_ACCOUNT_UNAVAILABLE": "Счет закрыт или удален: операцию можно только отклонить",
+
+	"WEBHOOK_NOT_FOUND":          "Вебхук не найден",
+	"WEBHOOK_DELIVERY_NOT_FOUND": "Доставка вебхука не найдена",
+	"INVALID_WEBHOOK_URL":        "Неверный адрес вебхука: нужен публичный адрес https:// без учетных данных",
+	"INVALID_WEBHOOK_EVENT":      "Неизвестный тип события: transaction.completed или card.payment",
+	"WEBHOOK_LIMIT_EXCEEDED":     "Превышено число вебхуков пользователя",
+	"WEBHOOK_DELIVERY_PENDING":   "Доставка еще выполняется: повторить можно завершенную доставку",
+
+	MSG_USER_REGISTERED:         "Пользователь успешно зарегистрирован",
+	MSG_EMAIL_VERIFIED:          "Email подтвержден",
+	MSG_PAYMENT_HELD_FOR_REVIEW: "Средства заблокированы, платеж ожидает проверки",
+	MSG_PAYMENT_AUTHORIZED:      "Средства заблокированы",
+	MSG_PAYMENT_CAPTURED:        "Платеж успешно проведен",
+	MSG_PAYMENT_VOIDED:          "Платеж отменен",
+
+	VALIDATION_REQUIRED:      "Обязательное поле",
+	VALIDATION_EMAIL:         "Неверный формат email",
+	VALIDATION_MIN_LENGTH:    "Минимальная длина: %s",
+	VALIDATION_MAX_LENGTH:    "Максимальная длина: %s",
+	VALIDATION_MIN:           "Минимальное значение: %s",
+	VALIDATION_MAX:           "Максимальное значение: %s",
+	VALIDATION_LENGTH:        "Требуемая длина: %s",
+	VALIDATION_ONEOF:         "Допустимые значения: %s",
+	VALIDATION_DIGITS:        "Допустимы только цифры",
+	VALIDATION_AMOUNT:        "Сумма должна быть положительной, не более 9999999999.99 и не более чем с двумя знаками после запятой",
+	VALIDATION_MONEY:         "Сумма должна быть не более 9999999999.99 по модулю и не более чем с двумя знаками после запятой",
+	VALIDATION_UNKNOWN_FIELD: "Неизвестное поле",
+	VALIDATION_INVALID_TYPE:  "Неверный тип значения",
+
+	EMAIL_LOGIN_LOCKED_SUBJECT: "Вход в аккаунт временно заблокирован",
+	EMAIL_LOGIN_LOCKED_BODY: "Из-за нескольких неудачных попыток входа вход в аккаунт заблокирован до %s (UTC).\n\n" +
+		"Если это были не вы, смените пароль после разблокировки или воспользуйтесь восстановлением пароля.",
+	EMAIL_CARD_BLOCKED_SUBJECT: "Карта заблокирована",
+	EMAIL_CARD_BLOCKED_BODY: "Карта #%d заблокирована: при оплате несколько раз подряд указан неверный CVV.\n\n" +
+		"Если это были не вы, обратитесь в поддержку. Разблокировать карту может сотрудник банка.",
+	EMAIL_PASSWORD_RESET_SUBJECT: "Сброс пароля",
+	EMAIL_PASSWORD_RESET_BODY: "Для сброса пароля перейдите по ссылке: %s\n\n" +
+		"Ссылка действует %s. Если вы не запрашивали сброс пароля, проигнорируйте это письмо.",
+	EMAIL_PASSWORD_CHANGED_SUBJECT: "Пароль изменен",
+	EMAIL_PASSWORD_CHANGED_BODY:    "Пароль от вашего аккаунта был изменен, все сеансы завершены. Если это были не вы, обратитесь в поддержку.",
+	EMAIL_VERIFICATION_SUBJECT:     "Подтверждение email",
+	EMAIL_VERIFICATION_BODY: "Здравствуйте, %s!\n\nДля подтверждения email перейдите по ссылке: %s\n\n" +
+		"Ссылка действует %s. До подтверждения переводы и платежи недоступны.",
+	EMAIL_SCHEDULED_FAILED_SUBJECT:  "Регулярный перевод не выполнен",
+	EMAIL_SCHEDULED_FAILED_BODY:     "Перевод №%d на сумму %s, запланированный на %s, не выполнен: %s.\n%s",
+	EMAIL_SCHEDULED_WILL_RETRY:      "Повторная попытка будет выполнена %s.",
+	EMAIL_SCHEDULED_STOPPED:         "Перевод остановлен. Создайте его заново после устранения причины.",
+	EMAIL_SCHEDULED_SKIPPED:         "Исполнение пропущено, следующее запланировано на %s.",
+	EMAIL_SCHEDULED_SKIPPED_NO_NEXT: "Исполнение пропущено, других исполнений не запланировано.",
+}
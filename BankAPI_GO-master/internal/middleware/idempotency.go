@@ -0,0 +1,140 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+	"github.com/therealadik/bank-api/internal/problem"
+	"github.com/therealadik/bank-api/internal/service"
+)
+
+const (
+	// IdempotencyKeyHeader заголовок с ключом идемпотентности запроса
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader заголовок ответа, повторенного по ключу идемпотентности
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+// maxIdempotentBodySize ограничение тела запроса с ключом идемпотентности (как у обработчиков)
+const maxIdempotentBodySize = 64 << 10
+
+// IdempotencyMiddleware выполняет запросы с заголовком Idempotency-Key не более одного раза
+type IdempotencyMiddleware struct {
+	idempotencyService *service.IdempotencyService
+	logger             *logrus.Logger
+}
+
+// NewIdempotencyMiddleware создает middleware ключей идемпотентности
+func NewIdempotencyMiddleware(idempotencyService *service.IdempotencyService, logger *logrus.Logger) *IdempotencyMiddleware {
+	return &IdempotencyMiddleware{
+		idempotencyService: idempotencyService,
+		logger:             logger,
+	}
+}
+
+// Middleware для запроса с заголовком Idempotency-Key возвращает сохраненный ответ, если
+// такой же запрос пользователя с этим ключом уже выполнен успешно, иначе выполняет запрос
+// и сохраняет ответ. Запросы без заголовка выполняются как обычно.
+// Должен применяться после JWTMiddleware.
+func (m *IdempotencyMiddleware) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		userID, err := GetUserID(r.Context())
+		if err != nil {
+			problem.Write(w, r, problem.UNAUTHORIZED)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentBodySize))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				problem.New(problem.REQUEST_BODY_TOO_LARGE).With("max_bytes", tooLarge.Limit).Write(w, r)
+				return
+			}
+			problem.Write(w, r, problem.INVALID_REQUEST_BODY)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		saved, err := m.idempotencyService.Begin(r.Context(), userID, key, requestHash(r, body))
+		switch {
+		case errors.Is(err, service.ErrInvalidIdempotencyKey):
+			problem.Write(w, r, problem.INVALID_IDEMPOTENCY_KEY)
+			return
+		case errors.Is(err, service.ErrIdempotencyKeyReused):
+			m.logger.Warnf("Пользователь %d повторил ключ идемпотентности с другим запросом %s %s", userID, r.Method, r.URL.Path)
+			problem.Write(w, r, problem.IDEMPOTENCY_KEY_REUSED)
+			return
+		case errors.Is(err, service.ErrIdempotencyRequestInProgress):
+			problem.Write(w, r, problem.IDEMPOTENCY_REQUEST_IN_PROGRESS)
+			return
+		case err != nil:
+			m.logger.WithError(err).Error("Ошибка проверки ключа идемпотентности")
+			problem.Write(w, r, problem.INTERNAL_ERROR)
+			return
+		}
+
+		if saved != nil {
+			if saved.ContentType != "" {
+				w.Header().Set("Content-Type", saved.ContentType)
+			}
+			w.Header().Set(IdempotentReplayedHeader, "true")
+			w.WriteHeader(*saved.StatusCode)
+			if _, err := w.Write(saved.ResponseBody); err != nil {
+				m.logger.Errorf("Ошибка отправки сохраненного ответа: %v", err)
+			}
+			return
+		}
+
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		// Ответ сохраняется и после разрыва соединения клиентом, иначе ключ останется занятым до TTL
+		ctx := context.WithoutCancel(r.Context())
+		if err := m.idempotencyService.Complete(ctx, userID, key, rec.status, w.Header().Get("Content-Type"), rec.body.Bytes()); err != nil {
+			m.logger.WithError(err).Errorf("Ошибка сохранения ответа по ключу идемпотентности пользователя %d", userID)
+		}
+	})
+}
+
+// requestHash хеш метода, пути и тела запроса для сравнения повторов с исходным запросом
+func requestHash(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseRecorder передает ответ клиенту и запоминает статус и тело
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	body        bytes.Buffer
+	wroteHeader bool
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	if !rec.wroteHeader {
+		rec.status = status
+		rec.wroteHeader = true
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	rec.wroteHeader = true
+	rec.body.Write(b)
+	return rec.ResponseWriter.Write(b)
+}
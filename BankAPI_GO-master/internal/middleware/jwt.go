@@ -2,17 +2,22 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strings"
 
 	"github.com/sirupsen/logrus"
+	"github.com/therealadik/bank-api/internal/problem"
 	"github.com/therealadik/bank-api/internal/service"
 )
 
 // Ключ для хранения ID пользователя в контексте
 type contextKey string
 
-const UserIDKey contextKey = "userID"
+const (
+	UserIDKey      contextKey = "userID"
+	TokenClaimsKey contextKey = "tokenClaims"
+)
 
 // JWTMiddleware middleware для проверки JWT-токена
 type JWTMiddleware struct {
@@ -34,36 +39,73 @@ func (m *JWTMiddleware) Middleware(next http.Handler) http.Handler {
 		// Извлечение токена из заголовка Authorization
 		authHeader := r.Header.Get("Authorization")
 		if authHeader == "" {
-			http.Error(w, "Требуется авторизация", http.StatusUnauthorized)
+			problem.Write(w, r, problem.UNAUTHORIZED)
 			return
 		}
 
 		// Формат токена: Bearer <token>
 		const bearerPrefix = "Bearer "
 		if !strings.HasPrefix(authHeader, bearerPrefix) {
-			http.Error(w, "Неверный формат токена", http.StatusUnauthorized)
+			problem.Write(w, r, problem.INVALID_TOKEN_FORMAT)
 			return
 		}
 
 		// Извлечение токена
 		tokenString := strings.TrimPrefix(authHeader, bearerPrefix)
 
-		// Проверка и разбор токена
-		userID, err := m.authService.ParseToken(tokenString)
+		// Проверка и разбор токена, включая проверку отзыва по jti
+		claims, err := m.authService.ValidateToken(r.Context(), tokenString)
 		if err != nil {
 			m.logger.WithError(err).Warn("Ошибка проверки токена")
-			http.Error(w, "Неверный или просроченный токен", http.StatusUnauthorized)
+			problem.Write(w, r, problem.INVALID_TOKEN)
 			return
 		}
 
-		// Добавление ID пользователя в контекст запроса
-		ctx := context.WithValue(r.Context(), UserIDKey, userID)
-		next.ServeHTTP(w, r.WithContext(ctx))
+		serveAuthenticated(w, r, next, claims)
 	})
 }
 
+// StreamMiddleware проверяет доступ к потоку Server-Sent Events: браузерный EventSource
+// не передает заголовок Authorization, поэтому кроме него принимается одноразовый билет
+// в параметре ticket (выдается POST /api/accounts/stream/ticket)
+func (m *JWTMiddleware) StreamMiddleware(next http.Handler) http.Handler {
+	bearer := m.Middleware(next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ticket := r.URL.Query().Get("ticket")
+		if ticket == "" {
+			bearer.ServeHTTP(w, r)
+			return
+		}
+
+		claims, err := m.authService.RedeemStreamTicket(r.Context(), ticket)
+		if err != nil {
+			m.logger.WithError(err).Warn("Ошибка проверки билета потока")
+			problem.Write(w, r, problem.INVALID_TOKEN)
+			return
+		}
+
+		serveAuthenticated(w, r, next, claims)
+	})
+}
+
+// serveAuthenticated добавляет ID пользователя и данные токена в контекст запроса
+func serveAuthenticated(w http.ResponseWriter, r *http.Request, next http.Handler, claims *service.TokenClaims) {
+	ctx := context.WithValue(r.Context(), UserIDKey, claims.UserID)
+	ctx = context.WithValue(ctx, TokenClaimsKey, claims)
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
 // GetUserID извлекает ID пользователя из контекста
 func GetUserID(ctx context.Context) (int64, error) {
 	userID := ctx.Value(UserIDKey).(int64)
 	return userID, nil
 }
+
+// GetTokenClaims извлекает данные access-токена из контекста
+func GetTokenClaims(ctx context.Context) (*service.TokenClaims, error) {
+	claims, ok := ctx.Value(TokenClaimsKey).(*service.TokenClaims)
+	if !ok {
+		return nil, errors.New("данные токена отсутствуют в контексте")
+	}
+	return claims, nil
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/therealadik/bank-api/internal/requestmeta"
+)
+
+// RequestIDHeader заголовок с идентификатором запроса
+const RequestIDHeader = "X-Request-ID"
+
+// maxUserAgentLength ограничение длины User-Agent, сохраняемого в журнал
+const maxUserAgentLength = 512
+
+// requestIDPattern допустимый идентификатор запроса, переданный клиентом
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestID присваивает запросу идентификатор (принимает корректный X-Request-ID клиента
+// или генерирует новый), возвращает его в ответе и сохраняет в контексте вместе с IP и User-Agent
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(RequestIDHeader)
+		if !requestIDPattern.MatchString(requestID) {
+			requestID = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, requestID)
+
+		userAgent := r.UserAgent()
+		if len(userAgent) > maxUserAgentLength {
+			userAgent = strings.ToValidUTF8(userAgent[:maxUserAgentLength], "")
+		}
+
+		ctx := requestmeta.NewContext(r.Context(), requestmeta.Meta{
+			RequestID: requestID,
+			IP:        requestmeta.ClientIP(r),
+			UserAgent: userAgent,
+		})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// newRequestID генерирует случайный идентификатор запроса
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+	"github.com/therealadik/bank-api/internal/models"
+	"github.com/therealadik/bank-api/internal/problem"
+)
+
+// RoleMiddleware middleware для проверки роли пользователя
+type RoleMiddleware struct {
+	logger *logrus.Logger
+}
+
+// NewRoleMiddleware создает новый middleware проверки роли
+func NewRoleMiddleware(logger *logrus.Logger) *RoleMiddleware {
+	return &RoleMiddleware{
+		logger: logger,
+	}
+}
+
+// Require пропускает запрос, только если роль из access-токена входит в список разрешенных.
+// Должен применяться после JWTMiddleware. Изменение роли вступает в силу с новым
+// access-токеном; при смене роли администратором сессии пользователя отзываются.
+func (m *RoleMiddleware) Require(roles ...models.Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, err := GetTokenClaims(r.Context())
+			if err != nil {
+				problem.Write(w, r, problem.UNAUTHORIZED)
+				return
+			}
+
+			for _, role := range roles {
+				if claims.Role == role {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			m.logger.Warnf("Пользователь %d с ролью %s не имеет доступа к %s", claims.UserID, claims.Role, r.URL.Path)
+			problem.Write(w, r, problem.FORBIDDEN)
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+	"github.com/therealadik/bank-api/internal/problem"
+	"github.com/therealadik/bank-api/internal/repository"
+)
+
+// VerifiedEmailMiddleware middleware, пропускающий только пользователей с подтвержденным email
+type VerifiedEmailMiddleware struct {
+	userRepo repository.UserRepository
+	logger   *logrus.Logger
+}
+
+// NewVerifiedEmailMiddleware создает middleware проверки подтверждения email
+func NewVerifiedEmailMiddleware(userRepo repository.UserRepository, logger *logrus.Logger) *VerifiedEmailMiddleware {
+	return &VerifiedEmailMiddleware{
+		userRepo: userRepo,
+		logger:   logger,
+	}
+}
+
+// Middleware отклоняет запрос, если email пользователя не подтвержден.
+// Должен применяться после JWTMiddleware.
+func (m *VerifiedEmailMiddleware) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, err := GetUserID(r.Context())
+		if err != nil {
+			problem.Write(w, r, problem.UNAUTHORIZED)
+			return
+		}
+
+		user, err := m.userRepo.GetByID(r.Context(), userID)
+		if err != nil {
+			m.logger.WithError(err).Error("Ошибка получения пользователя для проверки email")
+			problem.Write(w, r, problem.INTERNAL_ERROR)
+			return
+		}
+
+		if !user.IsEmailVerified() {
+			m.logger.Warnf("Пользователь %d с неподтвержденным email обратился к %s", userID, r.URL.Path)
+			problem.Write(w, r, problem.EMAIL_NOT_VERIFIED)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
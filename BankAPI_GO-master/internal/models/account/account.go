@@ -6,9 +6,13 @@ import (
 )
 
 type Account struct {
-	ID        int64           `db:"id"       json:"id"`
-	UserID    int64           `db:"user_id"  json:"user_id"`
-	Balance   decimal.Decimal `db:"balance"  json:"balance"`
-	Currency  Currency        `db:"currency" json:"currency"`
-	CreatedAt time.Time       `db:"created_at" json:"created_at"`
+	ID               int64           `db:"id"       json:"id"`
+	Number           string          `db:"number"   json:"number"` // Публичный 20-значный номер счета
+	UserID           int64           `db:"user_id"  json:"user_id"`
+	Balance          decimal.Decimal `db:"balance"  json:"balance"`
+	AvailableBalance decimal.Decimal `db:"available_balance" json:"available_balance"` // Баланс за вычетом холдов
+	Currency         Currency        `db:"currency" json:"currency"`
+	Status           Status          `db:"status"   json:"status"`
+	ClosedAt         *time.Time      `db:"closed_at" json:"closed_at"`
+	CreatedAt        time.Time       `db:"created_at" json:"created_at"`
 }
@@ -0,0 +1,67 @@
+package account
+
+import "fmt"
+
+// BankBIK БИК банка. Последние три цифры участвуют в расчете контрольного ключа номера счета.
+const BankBIK = "044525987"
+
+// individualLedger балансовый счет второго порядка для счетов физических лиц
+const individualLedger = "40817"
+
+// NumberLength длина номера счета
+const NumberLength = 20
+
+// currencyCode возвращает цифровой код валюты, используемый в номере счета
+func currencyCode(c Currency) string {
+	switch c {
+	case USD:
+		return "840"
+	case EUR:
+		return "978"
+	default:
+		return "810" // Рубль обозначается в номерах счетов кодом 810
+	}
+}
+
+// GenerateNumber формирует 20-значный номер счета по ID:
+// балансовый счет (5 цифр), код валюты (3), контрольный ключ (1) и лицевой номер (11).
+func GenerateNumber(id int64, currency Currency) string {
+	number := []byte(fmt.Sprintf("%s%s0%011d", individualLedger, currencyCode(currency), id))
+	number[8] = '0' + checkKey(number)
+	return string(number)
+}
+
+// ValidateNumber проверяет формат номера счета и его контрольный ключ
+func ValidateNumber(number string) bool {
+	if len(number) != NumberLength {
+		return false
+	}
+	for i := 0; i < len(number); i++ {
+		if number[i] < '0' || number[i] > '9' {
+			return false
+		}
+	}
+	return checkKey([]byte(number)) == number[8]-'0'
+}
+
+// MaskNumber скрывает середину номера счета, оставляя балансовый счет и последние 4 цифры
+func MaskNumber(number string) string {
+	if len(number) != NumberLength {
+		return number
+	}
+	return number[:5] + "***********" + number[16:]
+}
+
+// checkKey рассчитывает контрольный ключ по методике Банка России:
+// последние три цифры БИК и номер счета с нулем на месте ключа умножаются
+// на весовые коэффициенты 7, 1, 3, ключ равен младшему разряду суммы, умноженному на 3.
+func checkKey(number []byte) byte {
+	weights := [3]int{7, 1, 3}
+	digits := BankBIK[len(BankBIK)-3:] + string(number[:8]) + "0" + string(number[9:])
+
+	sum := 0
+	for i := 0; i < len(digits); i++ {
+		sum += int(digits[i]-'0') * weights[i%3] % 10
+	}
+	return byte(sum % 10 * 3 % 10)
+}
@@ -0,0 +1,95 @@
+package account
+
+import "testing"
+
+func TestGenerateNumber(t *testing.T) {
+	tests := []struct {
+		id       int64
+		currency Currency
+		want     string
+	}{
+		{1, RUB, "40817810000000000001"},
+		{42, RUB, "40817810700000000042"},
+		{123456789, USD, "40817840700123456789"},
+		{99999999999, EUR, "40817978399999999999"},
+	}
+
+	for _, tc := range tests {
+		got := GenerateNumber(tc.id, tc.currency)
+		if got != tc.want {
+			t.Errorf("GenerateNumber(%d, %s) = %s, ожидалось %s", tc.id, tc.currency, got, tc.want)
+		}
+		// По методике Банка России сумма младших разрядов произведений цифр БИК
+		// и номера на веса 7, 1, 3 с верным ключом кратна 10
+		digits := BankBIK[len(BankBIK)-3:] + got
+		sum := 0
+		for i := 0; i < len(digits); i++ {
+			sum += int(digits[i]-'0') * [3]int{7, 1, 3}[i%3] % 10
+		}
+		if sum%10 != 0 {
+			t.Errorf("номер %s: взвешенная сумма %d не кратна 10", got, sum)
+		}
+	}
+}
+
+func TestValidateNumber(t *testing.T) {
+	tests := []struct {
+		name   string
+		number string
+		want   bool
+	}{
+		{"верный номер", "40817810700000000042", true},
+		{"верный номер в долларах", "40817840700123456789", true},
+		{"неверный ключ", "40817810100000000042", false},
+		{"изменен лицевой номер", "40817810700000000043", false},
+		{"изменен код валюты", "40817840700000000042", false},
+		{"короткий номер", "4081781070000000004", false},
+		{"длинный номер", "408178107000000000420", false},
+		{"не цифры", "40817810700000000O42", false},
+		{"пустая строка", "", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := ValidateNumber(tc.number); got != tc.want {
+				t.Fatalf("ValidateNumber(%q) = %v, ожидалось %v", tc.number, got, tc.want)
+			}
+		})
+	}
+}
+
+// Ключ обнаруживает замену любой одной цифры номера
+func TestCheckKeyDetectsSingleDigitErrors(t *testing.T) {
+	number := GenerateNumber(42, RUB)
+	for i := 0; i < NumberLength; i++ {
+		if i == 8 {
+			continue
+		}
+		for d := byte('0'); d <= '9'; d++ {
+			if d == number[i] {
+				continue
+			}
+			changed := []byte(number)
+			changed[i] = d
+			if ValidateNumber(string(changed)) {
+				t.Fatalf("номер %s с измененной цифрой %d принят", changed, i+1)
+			}
+		}
+	}
+}
+
+func TestMaskNumber(t *testing.T) {
+	tests := []struct {
+		number string
+		want   string
+	}{
+		{"40817810700000000042", "40817***********0042"},
+		{"12345", "12345"},
+	}
+
+	for _, tc := range tests {
+		if got := MaskNumber(tc.number); got != tc.want {
+			t.Errorf("MaskNumber(%q) = %q, ожидалось %q", tc.number, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,10 @@
+package account
+
+// Status статус счета
+type Status string
+
+const (
+	ACTIVE Status = "ACTIVE" // Счет открыт
+	FROZEN Status = "FROZEN" // Операции по счету приостановлены администратором
+	CLOSED Status = "CLOSED" // Счет закрыт владельцем, операции невозможны
+)
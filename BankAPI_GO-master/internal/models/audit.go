@@ -0,0 +1,83 @@
+package models
+
+import "time"
+
+// AuditAction действие, записываемое в журнал аудита
+type AuditAction string
+
+const (
+	AUDIT_USER_REGISTERED              AuditAction = "USER_REGISTERED"              // Регистрация пользователя
+	AUDIT_LOGIN_SUCCEEDED              AuditAction = "LOGIN_SUCCEEDED"              // Успешный вход
+	AUDIT_LOGIN_FAILED                 AuditAction = "LOGIN_FAILED"                 // Неверный email или пароль
+	AUDIT_TWO_FACTOR_FAILED            AuditAction = "TWO_FACTOR_FAILED"            // Неверный код второго шага входа
+	AUDIT_LOGOUT                       AuditAction = "LOGOUT"                       // Выход из системы
+	AUDIT_ACCOUNT_CREATED              AuditAction = "ACCOUNT_CREATED"              // Открытие счета
+	AUDIT_BALANCE_CHANGED              AuditAction = "BALANCE_CHANGED"              // Пополнение или списание
+	AUDIT_TRANSFER                     AuditAction = "TRANSFER"                     // Перевод между счетами
+	AUDIT_ACCOUNT_CLOSED               AuditAction = "ACCOUNT_CLOSED"               // Закрытие счета владельцем
+	AUDIT_SCHEDULED_TRANSFER_CREATED   AuditAction = "SCHEDULED_TRANSFER_CREATED"   // Создание регулярного перевода
+	AUDIT_SCHEDULED_TRANSFER_CANCELLED AuditAction = "SCHEDULED_TRANSFER_CANCELLED" // Отмена регулярного перевода
+	AUDIT_OPERATION_HELD               AuditAction = "OPERATION_HELD"               // Операция задержана антифрод-проверкой
+	AUDIT_CARD_ISSUED                  AuditAction = "CARD_ISSUED"                  // Выпуск карты
+	AUDIT_CARD_DETAILS_VIEWED          AuditAction = "CARD_DETAILS_VIEWED"          // Просмотр данных карты
+	AUDIT_CARD_BLOCKED_CVV             AuditAction = "CARD_BLOCKED_CVV"             // Блокировка карты после перебора CVV
+	AUDIT_LOG_VIEWED                   AuditAction = "AUDIT_LOG_VIEWED"             // Просмотр журнала аудита
+	AUDIT_WEBHOOK_CREATED              AuditAction = "WEBHOOK_CREATED"              // Регистрация вебхука
+	AUDIT_WEBHOOK_DELETED              AuditAction = "WEBHOOK_DELETED"              // Удаление вебхука
+
+	// Действия администраторов и сотрудников поддержки
+	ADMIN_VIEW_USER           AuditAction = "VIEW_USER"           // Просмотр данных пользователя
+	ADMIN_CHANGE_ROLE         AuditAction = "CHANGE_ROLE"         // Изменение роли пользователя
+	ADMIN_FREEZE_ACCOUNT      AuditAction = "FREEZE_ACCOUNT"      // Заморозка счета
+	ADMIN_UNFREEZE_ACCOUNT    AuditAction = "UNFREEZE_ACCOUNT"    // Разморозка счета
+	ADMIN_BLOCK_CARD          AuditAction = "BLOCK_CARD"          // Блокировка карты
+	ADMIN_UNBLOCK_CARD        AuditAction = "UNBLOCK_CARD"        // Разблокировка карты
+	ADMIN_VIEW_TRANSACTIONS   AuditAction = "VIEW_TRANSACTIONS"   // Просмотр транзакций
+	ADMIN_SET_TRANSFER_LIMITS AuditAction = "SET_TRANSFER_LIMITS" // Изменение лимитов переводов
+	ADMIN_APPROVE_OPERATION   AuditAction = "APPROVE_OPERATION"   // Одобрение задержанной операции
+	ADMIN_REJECT_OPERATION    AuditAction = "REJECT_OPERATION"    // Отклонение задержанной операции
+	ADMIN_CAPTURE_PAYMENT     AuditAction = "CAPTURE_PAYMENT"     // Списание авторизованного платежа
+	ADMIN_REFUND_PAYMENT      AuditAction = "REFUND_PAYMENT"      // Возврат по платежу
+)
+
+// Типы объектов, над которыми выполняются действия
+const (
+	AuditTargetUser    = "USER"
+	AuditTargetAccount = "ACCOUNT"
+	AuditTargetCard    = "CARD"
+	AuditTargetPayment = "PAYMENT"
+
+	AuditTargetScheduledTransfer = "SCHEDULED_TRANSFER"
+	AuditTargetFraudReview       = "FRAUD_REVIEW"
+	AuditTargetWebhook           = "WEBHOOK"
+)
+
+// AuditEntry запись журнала аудита. Записи связаны в цепочку:
+// PrevHash совпадает с Hash предыдущей записи.
+type AuditEntry struct {
+	ID         int64       `db:"id"          json:"id"`
+	ActorID    *int64      `db:"actor_id"    json:"actor_id"`
+	Action     AuditAction `db:"action"      json:"action"`
+	TargetType string      `db:"target_type" json:"target_type"`
+	TargetID   *int64      `db:"target_id"   json:"target_id"`
+	RequestID  string      `db:"request_id"  json:"request_id"`
+	IP         string      `db:"ip"          json:"ip"`
+	UserAgent  string      `db:"user_agent"  json:"user_agent"`
+	Details    string      `db:"details"     json:"details"`
+	CreatedAt  time.Time   `db:"created_at"  json:"created_at"`
+	PrevHash   string      `db:"prev_hash"   json:"prev_hash"`
+	Hash       string      `db:"hash"        json:"hash"`
+}
+
+// AuditFilter фильтр выборки журнала аудита; нулевые поля не ограничивают выборку
+type AuditFilter struct {
+	ActorID    *int64
+	Action     AuditAction
+	TargetType string
+	TargetID   *int64
+	RequestID  string
+	From       *time.Time
+	To         *time.Time
+	BeforeID   *int64 // Для постраничного просмотра от новых к старым
+	Limit      int
+}
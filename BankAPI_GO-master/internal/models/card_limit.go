@@ -0,0 +1,17 @@
+package models
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+// CardLimit лимиты расходов и запрещенные категории торговцев (MCC) по карте.
+// Пустое значение лимита означает отсутствие ограничения.
+type CardLimit struct {
+	CardID            int64               `db:"card_id"            json:"card_id"`
+	PerTransaction    decimal.NullDecimal `db:"per_transaction"    json:"per_transaction"`
+	Daily             decimal.NullDecimal `db:"daily"              json:"daily"`
+	Monthly           decimal.NullDecimal `db:"monthly"            json:"monthly"`
+	BlockedCategories []string            `db:"blocked_categories" json:"blocked_categories"`
+	UpdatedAt         time.Time           `db:"updated_at"         json:"updated_at"`
+}
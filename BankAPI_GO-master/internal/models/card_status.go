@@ -0,0 +1,9 @@
+package models
+
+// CardStatus статус карты
+type CardStatus string
+
+const (
+	CARD_ACTIVE  CardStatus = "ACTIVE"  // Карта действует
+	CARD_BLOCKED CardStatus = "BLOCKED" // Карта заблокирована администратором или после перебора CVV
+)
@@ -0,0 +1,38 @@
+package fraud
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+// Review операция, задержанная антифрод-проверкой до решения сотрудника.
+// Средства по операции удерживаются холдом, транзакция находится в статусе PENDING.
+type Review struct {
+	ID            int64           `db:"id"             json:"id"`
+	Kind          Kind            `db:"kind"           json:"kind"`
+	UserID        int64           `db:"user_id"        json:"user_id"`
+	AccountID     int64           `db:"account_id"     json:"account_id"`
+	ToAccountID   *int64          `db:"to_account_id"  json:"to_account_id"`  // Счет получателя перевода
+	TransactionID *int64          `db:"transaction_id" json:"transaction_id"` // Транзакция в статусе PENDING
+	PaymentID     *int64          `db:"payment_id"     json:"payment_id"`     // Платеж по карте
+	Amount        decimal.Decimal `db:"amount"         json:"amount"`
+	Score         int             `db:"score"          json:"score"`
+	Reasons       []Reason        `db:"reasons"        json:"reasons"`
+	Status        Status          `db:"status"         json:"status"`
+	ReviewedBy    *int64          `db:"reviewed_by"    json:"reviewed_by"`
+	Comment       string          `db:"comment"        json:"comment"`
+	ReviewedAt    *time.Time      `db:"reviewed_at"    json:"reviewed_at"`
+	CreatedAt     time.Time       `db:"created_at"     json:"created_at"`
+}
+
+// Assessment результат проверки операции правилами
+type Assessment struct {
+	Score   int
+	Reasons []Reason
+}
+
+// Add учитывает сработавшее правило
+func (a *Assessment) Add(reason Reason, weight int) {
+	a.Score += weight
+	a.Reasons = append(a.Reasons, reason)
+}
@@ -0,0 +1,37 @@
+package fraud
+
+// Kind вид задержанной операции
+type Kind string
+
+const (
+	KIND_TRANSFER     Kind = "TRANSFER"     // Перевод
+	KIND_CARD_PAYMENT Kind = "CARD_PAYMENT" // Оплата картой
+)
+
+// Status статус проверки операции
+type Status string
+
+const (
+	PENDING  Status = "PENDING"  // Ожидает решения
+	APPROVED Status = "APPROVED" // Операция проведена
+	REJECTED Status = "REJECTED" // Операция отклонена, холд снят
+)
+
+// IsValid проверяет, что статус проверки известен
+func (s Status) IsValid() bool {
+	switch s {
+	case PENDING, APPROVED, REJECTED:
+		return true
+	}
+	return false
+}
+
+// Reason сработавшее правило антифрод-проверки
+type Reason string
+
+const (
+	REASON_UNUSUAL_AMOUNT       Reason = "UNUSUAL_AMOUNT"       // Сумма намного больше обычной для пользователя
+	REASON_MANY_NEW_RECIPIENTS  Reason = "MANY_NEW_RECIPIENTS"  // Много новых получателей за короткое время
+	REASON_FIRST_LARGE_TRANSFER Reason = "FIRST_LARGE_TRANSFER" // Первый крупный перевод новому получателю
+	REASON_CVV_FAILURES         Reason = "CVV_FAILURES"         // Недавние попытки оплаты с неверным CVV
+)
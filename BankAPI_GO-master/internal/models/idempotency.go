@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// IdempotencyKey ключ идемпотентности запроса пользователя и сохраненный ответ.
+// StatusCode равен nil, пока первый запрос с этим ключом выполняется.
+type IdempotencyKey struct {
+	UserID       int64     `db:"user_id"       json:"user_id"`
+	Key          string    `db:"key"           json:"key"`
+	RequestHash  string    `db:"request_hash"  json:"-"`
+	StatusCode   *int      `db:"status_code"   json:"status_code"`
+	ContentType  string    `db:"content_type"  json:"content_type"`
+	ResponseBody []byte    `db:"response_body" json:"-"`
+	CreatedAt    time.Time `db:"created_at"    json:"created_at"`
+}
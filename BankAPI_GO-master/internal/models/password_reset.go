@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// PasswordResetToken одноразовый токен сброса пароля; в БД хранится только SHA-256 хеш
+type PasswordResetToken struct {
+	ID        int64      `db:"id"         json:"id"`
+	UserID    int64      `db:"user_id"    json:"user_id"`
+	TokenHash string     `db:"token_hash" json:"-"`
+	ExpiresAt time.Time  `db:"expires_at" json:"expires_at"`
+	UsedAt    *time.Time `db:"used_at"    json:"used_at"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+}
@@ -0,0 +1,21 @@
+package payment
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+// Dispute спор (чарджбэк) держателя карты по проведенному платежу
+type Dispute struct {
+	ID                  int64           `db:"id"                    json:"id"`
+	PaymentID           int64           `db:"payment_id"            json:"payment_id"`
+	OpenedBy            int64           `db:"opened_by"             json:"opened_by"`
+	Amount              decimal.Decimal `db:"amount"                json:"amount"`
+	Reason              string          `db:"reason"                json:"reason"`
+	Status              DisputeStatus   `db:"status"                json:"status"`
+	ResolutionComment   string          `db:"resolution_comment"    json:"resolution_comment"`
+	ResolvedBy          *int64          `db:"resolved_by"           json:"resolved_by"`
+	RefundTransactionID *int64          `db:"refund_transaction_id" json:"refund_transaction_id"`
+	CreatedAt           time.Time       `db:"created_at"            json:"created_at"`
+	UpdatedAt           time.Time       `db:"updated_at"            json:"updated_at"`
+}
@@ -0,0 +1,36 @@
+package payment
+
+type DisputeStatus string
+
+const (
+	DISPUTE_OPEN         DisputeStatus = "OPEN"         // Спор открыт держателем карты
+	DISPUTE_UNDER_REVIEW DisputeStatus = "UNDER_REVIEW" // Спор рассматривается поддержкой
+	DISPUTE_ACCEPTED     DisputeStatus = "ACCEPTED"     // Спор удовлетворен, проведен чарджбэк
+	DISPUTE_REJECTED     DisputeStatus = "REJECTED"     // Спор отклонен
+	DISPUTE_CANCELLED    DisputeStatus = "CANCELLED"    // Спор отозван
+)
+
+// disputeTransitions допустимые переходы между статусами спора
+var disputeTransitions = map[DisputeStatus][]DisputeStatus{
+	DISPUTE_OPEN:         {DISPUTE_UNDER_REVIEW, DISPUTE_ACCEPTED, DISPUTE_REJECTED, DISPUTE_CANCELLED},
+	DISPUTE_UNDER_REVIEW: {DISPUTE_ACCEPTED, DISPUTE_REJECTED, DISPUTE_CANCELLED},
+}
+
+// CanTransitionTo проверяет, допустим ли переход спора в указанный статус
+func (s DisputeStatus) CanTransitionTo(next DisputeStatus) bool {
+	for _, allowed := range disputeTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValid проверяет, что статус спора известен
+func (s DisputeStatus) IsValid() bool {
+	switch s {
+	case DISPUTE_OPEN, DISPUTE_UNDER_REVIEW, DISPUTE_ACCEPTED, DISPUTE_REJECTED, DISPUTE_CANCELLED:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,22 @@
+package payment
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+// CardPayment платеж, проведенный по карте
+type CardPayment struct {
+	ID               int64           `db:"id"                json:"id"`
+	CardID           int64           `db:"card_id"           json:"card_id"`
+	AccountID        *int64          `db:"account_id"        json:"account_id"`
+	TransactionID    *int64          `db:"transaction_id"    json:"transaction_id"`
+	InitiatorID      *int64          `db:"initiator_id"      json:"initiator_id"`
+	Amount           decimal.Decimal `db:"amount"            json:"amount"`
+	RefundedAmount   decimal.Decimal `db:"refunded_amount"   json:"refunded_amount"`
+	MerchantCategory string          `db:"merchant_category" json:"merchant_category"`
+	Status           Status          `db:"status"            json:"status"`
+	ExpiresAt        *time.Time      `db:"expires_at"        json:"expires_at"`
+	CreatedAt        time.Time       `db:"created_at"        json:"created_at"`
+	UpdatedAt        time.Time       `db:"updated_at"        json:"updated_at"`
+}
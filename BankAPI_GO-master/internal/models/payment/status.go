@@ -0,0 +1,16 @@
+package payment
+
+type Status string
+
+const (
+	AUTHORIZED Status = "AUTHORIZED" // Средства заблокированы (холд)
+	CAPTURED   Status = "CAPTURED"   // Холд списан, транзакция проведена
+	VOIDED     Status = "VOIDED"     // Холд отменен
+	EXPIRED    Status = "EXPIRED"    // Холд истек без списания
+
+	PENDING_REVIEW Status = "PENDING_REVIEW" // Холд поставлен, платеж ждет решения антифрод-проверки
+	DECLINED       Status = "DECLINED"       // Платеж отклонен по итогам проверки, холд снят
+
+	PARTIALLY_REFUNDED Status = "PARTIALLY_REFUNDED" // Часть списанной суммы возвращена
+	REFUNDED           Status = "REFUNDED"           // Списанная сумма возвращена полностью
+)
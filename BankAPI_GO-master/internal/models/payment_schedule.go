@@ -6,10 +6,20 @@ import (
 )
 
 type PaymentSchedule struct {
-	ID        int64           `db:"id"         json:"id"`
-	CreditID  int64           `db:"credit_id"  json:"credit_id"`
-	DueDate   time.Time       `db:"due_date"   json:"due_date"`
-	Amount    decimal.Decimal `db:"amount" json:"amount"`
-	Paid      bool            `db:"paid"       json:"paid"`
-	CreatedAt time.Time       `db:"created_at" json:"created_at"`
+	ID                int64           `db:"id"                  json:"id"`
+	CreditID          int64           `db:"credit_id"           json:"credit_id"`
+	DueDate           time.Time       `db:"due_date"            json:"due_date"`
+	Amount            decimal.Decimal `db:"amount"              json:"amount"`
+	Paid              bool            `db:"paid"                json:"paid"`
+	OverdueNotifiedAt *time.Time      `db:"overdue_notified_at" json:"-"`
+	CreatedAt         time.Time       `db:"created_at"          json:"created_at"`
+}
+
+// OverdueInstallment просроченный платеж по графику вместе со счетом кредита
+type OverdueInstallment struct {
+	ScheduleID int64           `db:"id"`
+	CreditID   int64           `db:"credit_id"`
+	AccountID  int64           `db:"account_id"`
+	DueDate    time.Time       `db:"due_date"`
+	Amount     decimal.Decimal `db:"amount"`
 }
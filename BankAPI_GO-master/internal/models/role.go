@@ -0,0 +1,19 @@
+package models
+
+// Role роль пользователя
+type Role string
+
+const (
+	CUSTOMER Role = "CUSTOMER" // Клиент банка
+	SUPPORT  Role = "SUPPORT"  // Сотрудник поддержки
+	ADMIN    Role = "ADMIN"    // Администратор
+)
+
+// IsValid проверяет, что роль известна
+func (r Role) IsValid() bool {
+	switch r {
+	case CUSTOMER, SUPPORT, ADMIN:
+		return true
+	}
+	return false
+}
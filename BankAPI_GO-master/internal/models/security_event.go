@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// SecurityEventType тип события безопасности
+type SecurityEventType string
+
+const (
+	LOGIN_FAILED      SecurityEventType = "LOGIN_FAILED"      // Неверный email или пароль
+	TWO_FACTOR_FAILED SecurityEventType = "TWO_FACTOR_FAILED" // Неверный код второго шага входа
+	LOGIN_BLOCKED     SecurityEventType = "LOGIN_BLOCKED"     // Попытка входа во время блокировки
+	ACCOUNT_LOCKED    SecurityEventType = "ACCOUNT_LOCKED"    // Вход в аккаунт временно заблокирован
+	IP_LOCKED         SecurityEventType = "IP_LOCKED"         // Вход с IP-адреса временно заблокирован
+)
+
+// SecurityEvent событие безопасности, связанное со входом в систему
+type SecurityEvent struct {
+	ID        int64             `db:"id"         json:"id"`
+	UserID    *int64            `db:"user_id"    json:"user_id"`
+	Type      SecurityEventType `db:"event_type" json:"event_type"`
+	Email     string            `db:"email"      json:"email"`
+	IP        string            `db:"ip"         json:"ip"`
+	Details   string            `db:"details"    json:"details"`
+	CreatedAt time.Time         `db:"created_at" json:"created_at"`
+}
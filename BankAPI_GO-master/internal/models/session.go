@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// Session сессия пользователя, в рамках которой выдаются и ротируются refresh-токены
+type Session struct {
+	ID        string     `db:"id"         json:"id"`
+	UserID    int64      `db:"user_id"    json:"user_id"`
+	ExpiresAt time.Time  `db:"expires_at" json:"expires_at"`
+	RevokedAt *time.Time `db:"revoked_at" json:"revoked_at"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+}
+
+// RefreshToken одноразовый refresh-токен; в БД хранится только SHA-256 хеш
+type RefreshToken struct {
+	ID        int64      `db:"id"         json:"id"`
+	SessionID string     `db:"session_id" json:"session_id"`
+	TokenHash string     `db:"token_hash" json:"-"`
+	ExpiresAt time.Time  `db:"expires_at" json:"expires_at"`
+	UsedAt    *time.Time `db:"used_at"    json:"used_at"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// SigningKey ключ подписи JWT, идентифицируемый по kid
+type SigningKey struct {
+	KID        string    `db:"kid"         json:"kid"`
+	Algorithm  string    `db:"algorithm"   json:"algorithm"`
+	PrivateKey string    `db:"private_key" json:"-"` // PKCS#8 PEM (в БД хранится зашифрованным)
+	PublicKey  string    `db:"public_key"  json:"public_key"`
+	CreatedAt  time.Time `db:"created_at"  json:"created_at"`
+	ExpiresAt  time.Time `db:"expires_at"  json:"expires_at"`
+}
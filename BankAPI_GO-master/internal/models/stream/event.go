@@ -0,0 +1,25 @@
+package stream
+
+import (
+	"github.com/therealadik/bank-api/internal/models/account"
+	"github.com/therealadik/bank-api/internal/models/transaction"
+)
+
+// Channel канал PostgreSQL LISTEN/NOTIFY, в который репозитории сообщают об изменениях
+// счетов и транзакций
+const Channel = "account_events"
+
+type EventType string
+
+const (
+	ACCOUNT     EventType = "account"     // Изменились баланс или статус счета
+	TRANSACTION EventType = "transaction" // Создана транзакция или изменился ее статус
+)
+
+// Event изменение, которое отправляется в поток владельцу счета
+type Event struct {
+	Type        EventType
+	UserID      int64
+	Account     *account.Account         // Для ACCOUNT
+	Transaction *transaction.Transaction // Для TRANSACTION
+}
@@ -6,10 +6,11 @@ import (
 )
 
 type Transaction struct {
-	ID        int64           `db:"id"          json:"id"`
-	AccountID int64           `db:"account_id"  json:"account_id"`
-	Amount    decimal.Decimal `db:"amount" json:"amount"`
-	Type      Type            `db:"type"        json:"type"`
-	Status    Status          `db:"status"      json:"status"`
-	CreatedAt time.Time       `db:"created_at"  json:"created_at"`
+	ID         int64           `db:"id"          json:"id"`
+	AccountID  int64           `db:"account_id"  json:"account_id"`
+	Amount     decimal.Decimal `db:"amount" json:"amount"`
+	Type       Type            `db:"type"        json:"type"`
+	Status     Status          `db:"status"      json:"status"`
+	OriginalID *int64          `db:"original_transaction_id" json:"original_transaction_id"` // Исходная транзакция (для возвратов)
+	CreatedAt  time.Time       `db:"created_at"  json:"created_at"`
 }
@@ -3,7 +3,9 @@ package transaction
 type Type string
 
 const (
-	DEPOSIT    Type = "DEPOSIT"
-	WITHDRAWAL Type = "WITHDRAWAL"
-	TRANSFER   Type = "TRANSFER"
+	DEPOSIT    Type = "DEPOSIT"    // Зачисление, в том числе входящий перевод
+	WITHDRAWAL Type = "WITHDRAWAL" // Списание со счета
+	TRANSFER   Type = "TRANSFER"   // Исходящий перевод
+	REFUND     Type = "REFUND"     // Возврат по платежу картой
+	CHARGEBACK Type = "CHARGEBACK" // Возврат по удовлетворенному спору
 )
@@ -0,0 +1,58 @@
+package transfer
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+// LimitScope уровень, на котором действует лимит
+type LimitScope string
+
+const (
+	SCOPE_USER    LimitScope = "USER"    // Все счета пользователя
+	SCOPE_ACCOUNT LimitScope = "ACCOUNT" // Отдельный счет
+)
+
+// LimitKind вид лимита
+type LimitKind string
+
+const (
+	LIMIT_SINGLE       LimitKind = "SINGLE"       // Максимальная сумма одной операции
+	LIMIT_DAILY        LimitKind = "DAILY"        // Сумма за календарные сутки (UTC)
+	LIMIT_MONTHLY      LimitKind = "MONTHLY"      // Сумма за календарный месяц (UTC)
+	LIMIT_HOURLY_COUNT LimitKind = "HOURLY_COUNT" // Число операций за последний час
+)
+
+// Limits лимиты исходящих переводов и списаний. Пустое поле — лимит не установлен.
+type Limits struct {
+	SingleMax   decimal.NullDecimal `db:"single_max"   json:"single_max"`
+	DailyMax    decimal.NullDecimal `db:"daily_max"    json:"daily_max"`
+	MonthlyMax  decimal.NullDecimal `db:"monthly_max"  json:"monthly_max"`
+	HourlyCount *int                `db:"hourly_count" json:"hourly_count"`
+}
+
+// Merge возвращает лимиты, в которых поля override заменяют соответствующие поля l
+func (l Limits) Merge(override Limits) Limits {
+	if override.SingleMax.Valid {
+		l.SingleMax = override.SingleMax
+	}
+	if override.DailyMax.Valid {
+		l.DailyMax = override.DailyMax
+	}
+	if override.MonthlyMax.Valid {
+		l.MonthlyMax = override.MonthlyMax
+	}
+	if override.HourlyCount != nil {
+		l.HourlyCount = override.HourlyCount
+	}
+	return l
+}
+
+// LimitOverride индивидуальные лимиты пользователя или счета, установленные администратором
+type LimitOverride struct {
+	Scope     LimitScope `db:"scope"      json:"scope"`
+	OwnerID   int64      `db:"owner_id"   json:"owner_id"` // ID пользователя или счета
+	Limits               // Незаданные поля наследуются от лимитов по умолчанию
+	UpdatedBy *int64     `db:"updated_by" json:"updated_by"`
+	UpdatedAt time.Time  `db:"updated_at" json:"updated_at"`
+}
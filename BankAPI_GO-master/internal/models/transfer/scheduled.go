@@ -0,0 +1,40 @@
+package transfer
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+// Scheduled отложенный или регулярный перевод. Получатель задается ровно одним
+// из полей ToAccountID, ToAccountNumber, ToEmail и определяется заново при каждом исполнении.
+type Scheduled struct {
+	ID              int64           `db:"id"                json:"id"`
+	UserID          int64           `db:"user_id"           json:"user_id"`
+	FromAccountID   int64           `db:"from_account_id"   json:"from_account_id"`
+	ToAccountID     *int64          `db:"to_account_id"     json:"to_account_id"`
+	ToAccountNumber *string         `db:"to_account_number" json:"to_account_number"`
+	ToEmail         *string         `db:"to_email"          json:"to_email"`
+	Amount          decimal.Decimal `db:"amount"            json:"amount"`
+	Frequency       Frequency       `db:"frequency"         json:"frequency"`
+	CronExpr        *string         `db:"cron_expr"         json:"cron_expr"`
+	StartAt         time.Time       `db:"start_at"          json:"start_at"`
+	EndAt           *time.Time      `db:"end_at"            json:"end_at"`
+	ScheduledAt     time.Time       `db:"scheduled_at"      json:"scheduled_at"` // Плановое время текущего исполнения
+	NextRunAt       time.Time       `db:"next_run_at"       json:"next_run_at"`  // Время ближайшей попытки (с учетом повторов)
+	Attempts        int             `db:"attempts"          json:"attempts"`     // Неудачных попыток текущего исполнения
+	Status          Status          `db:"status"            json:"status"`
+	LastError       *string         `db:"last_error"        json:"last_error"`
+	CreatedAt       time.Time       `db:"created_at"        json:"created_at"`
+	UpdatedAt       time.Time       `db:"updated_at"        json:"updated_at"`
+}
+
+// Run результат попытки исполнения регулярного перевода
+type Run struct {
+	ID                  int64     `db:"id"                    json:"id"`
+	ScheduledTransferID int64     `db:"scheduled_transfer_id" json:"scheduled_transfer_id"`
+	ScheduledAt         time.Time `db:"scheduled_at"          json:"scheduled_at"`
+	Attempt             int       `db:"attempt"               json:"attempt"`
+	Status              RunStatus `db:"status"                json:"status"`
+	Error               *string   `db:"error"                 json:"error"`
+	CreatedAt           time.Time `db:"created_at"            json:"created_at"`
+}
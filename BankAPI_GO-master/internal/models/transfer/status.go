@@ -0,0 +1,40 @@
+package transfer
+
+// Frequency периодичность перевода
+type Frequency string
+
+const (
+	ONCE    Frequency = "ONCE"    // Однократный перевод в указанное время
+	DAILY   Frequency = "DAILY"   // Ежедневно
+	WEEKLY  Frequency = "WEEKLY"  // Еженедельно
+	MONTHLY Frequency = "MONTHLY" // Ежемесячно в день месяца даты начала
+	CRON    Frequency = "CRON"    // По cron-выражению (UTC)
+)
+
+// IsValid проверяет, что периодичность известна
+func (f Frequency) IsValid() bool {
+	switch f {
+	case ONCE, DAILY, WEEKLY, MONTHLY, CRON:
+		return true
+	}
+	return false
+}
+
+// Status статус регулярного перевода
+type Status string
+
+const (
+	ACTIVE    Status = "ACTIVE"    // Ожидает исполнения
+	COMPLETED Status = "COMPLETED" // Все исполнения выполнены
+	CANCELLED Status = "CANCELLED" // Отменен пользователем
+	FAILED    Status = "FAILED"    // Остановлен из-за ошибки, исполнение невозможно
+)
+
+// RunStatus результат попытки исполнения
+type RunStatus string
+
+const (
+	RUN_SUCCEEDED RunStatus = "SUCCEEDED" // Перевод выполнен
+	RUN_RETRYING  RunStatus = "RETRYING"  // Попытка не удалась, запланирован повтор
+	RUN_FAILED    RunStatus = "FAILED"    // Исполнение пропущено или перевод остановлен
+)
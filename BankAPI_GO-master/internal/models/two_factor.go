@@ -0,0 +1,9 @@
+package models
+
+// TwoFactor настройки TOTP пользователя
+type TwoFactor struct {
+	UserID   int64  `db:"id"             json:"user_id"`
+	Secret   string `db:"totp_secret"    json:"-"` // Base32-секрет, пустой если 2FA не настраивалась
+	Enabled  bool   `db:"totp_enabled"   json:"enabled"`
+	LastStep *int64 `db:"totp_last_step" json:"-"`
+}
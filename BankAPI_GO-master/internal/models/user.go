@@ -3,8 +3,18 @@ package models
 import "time"
 
 type User struct {
-	ID        int64     `db:"id" json:"id"`
-	Email     string    `db:"email" json:"email"`
-	Password  string    `db:"password_hash" json:"-"`
-	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	ID               int64      `db:"id" json:"id"`
+	Email            string     `db:"email" json:"email"`
+	Username         string     `db:"username" json:"username"`
+	Password         string     `db:"password_hash" json:"-"`
+	Role             Role       `db:"role" json:"role"`
+	TwoFactorEnabled bool       `db:"totp_enabled" json:"two_factor_enabled"`
+	EmailVerifiedAt  *time.Time `db:"email_verified_at" json:"email_verified_at"`
+	CreatedAt        time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt        time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// IsEmailVerified сообщает, подтвержден ли текущий email пользователя
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
 }
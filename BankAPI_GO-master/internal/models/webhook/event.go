@@ -0,0 +1,28 @@
+package webhook
+
+// Event тип события, на которое можно подписаться
+type Event string
+
+const (
+	TRANSACTION_COMPLETED Event = "transaction.completed" // Транзакция по счету проведена
+	CARD_PAYMENT          Event = "card.payment"          // Платеж картой создан или изменил статус
+	CREDIT_OVERDUE        Event = "credit.overdue"        // Платеж по графику кредита просрочен
+)
+
+// IsValid проверяет, что тип события известен
+func (e Event) IsValid() bool {
+	switch e {
+	case TRANSACTION_COMPLETED, CARD_PAYMENT, CREDIT_OVERDUE:
+		return true
+	}
+	return false
+}
+
+// DeliveryStatus статус доставки события
+type DeliveryStatus string
+
+const (
+	PENDING   DeliveryStatus = "PENDING"   // Ожидает первой или повторной попытки
+	SUCCEEDED DeliveryStatus = "SUCCEEDED" // Получатель ответил кодом 2xx
+	FAILED    DeliveryStatus = "FAILED"    // Попытки исчерпаны
+)
@@ -0,0 +1,42 @@
+package webhook
+
+import "time"
+
+// Webhook подписка пользователя на события. Secret заполняется только при создании
+// и при доставке: в остальных выборках секрет не расшифровывается.
+type Webhook struct {
+	ID        int64     `db:"id"         json:"id"`
+	UserID    int64     `db:"user_id"    json:"user_id"`
+	URL       string    `db:"url"        json:"url"`
+	Secret    string    `db:"secret"     json:"-"`
+	Events    []Event   `db:"events"     json:"events"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// Delivery доставка события на адрес вебхука. Payload — тело запроса, которое подписывается
+// и отправляется без изменений, в том числе при повторной доставке.
+type Delivery struct {
+	ID             int64          `db:"id"               json:"id"`
+	WebhookID      int64          `db:"webhook_id"       json:"webhook_id"`
+	EventID        string         `db:"event_id"         json:"event_id"`
+	EventType      Event          `db:"event_type"       json:"event_type"`
+	Payload        string         `db:"payload"          json:"payload"`
+	Status         DeliveryStatus `db:"status"           json:"status"`
+	Attempts       int            `db:"attempts"         json:"attempts"`
+	NextAttemptAt  time.Time      `db:"next_attempt_at"  json:"next_attempt_at"`
+	LastStatusCode *int           `db:"last_status_code" json:"last_status_code"`
+	LastError      *string        `db:"last_error"       json:"last_error"`
+	CreatedAt      time.Time      `db:"created_at"       json:"created_at"`
+	DeliveredAt    *time.Time     `db:"delivered_at"     json:"delivered_at"`
+}
+
+// Attempt попытка доставки: код ответа получателя или ошибка соединения
+type Attempt struct {
+	ID         int64     `db:"id"          json:"id"`
+	DeliveryID int64     `db:"delivery_id" json:"delivery_id"`
+	Attempt    int       `db:"attempt"     json:"attempt"`
+	StatusCode *int      `db:"status_code" json:"status_code"`
+	Error      *string   `db:"error"       json:"error"`
+	DurationMs int       `db:"duration_ms" json:"duration_ms"`
+	CreatedAt  time.Time `db:"created_at"  json:"created_at"`
+}
@@ -0,0 +1,285 @@
+package problem
+
+import "net/http"
+
+// Code стабильный машиночитаемый код ошибки API
+type Code string
+
+// Общие ошибки запроса и авторизации
+const (
+	INVALID_REQUEST_BODY            Code = "INVALID_REQUEST_BODY"
+	VALIDATION_FAILED               Code = "VALIDATION_FAILED"
+	REQUEST_BODY_TOO_LARGE          Code = "REQUEST_BODY_TOO_LARGE"
+	INVALID_QUERY_PARAMETER         Code = "INVALID_QUERY_PARAMETER"
+	INVALID_IDEMPOTENCY_KEY         Code = "INVALID_IDEMPOTENCY_KEY"
+	IDEMPOTENCY_KEY_REUSED          Code = "IDEMPOTENCY_KEY_REUSED"
+	IDEMPOTENCY_REQUEST_IN_PROGRESS Code = "IDEMPOTENCY_REQUEST_IN_PROGRESS"
+	UNAUTHORIZED                    Code = "UNAUTHORIZED"
+	INVALID_TOKEN_FORMAT            Code = "INVALID_TOKEN_FORMAT"
+	INVALID_TOKEN                   Code = "INVALID_TOKEN"
+	FORBIDDEN                       Code = "FORBIDDEN"
+	NOT_FOUND                       Code = "NOT_FOUND"
+	ROUTE_NOT_FOUND                 Code = "ROUTE_NOT_FOUND"
+	METHOD_NOT_ALLOWED              Code = "METHOD_NOT_ALLOWED"
+	INTERNAL_ERROR                  Code = "INTERNAL_ERROR"
+)
+
+// Неверные идентификаторы в пути запроса
+const (
+	INVALID_USER_ID               Code = "INVALID_USER_ID"
+	INVALID_ACCOUNT_ID            Code = "INVALID_ACCOUNT_ID"
+	INVALID_CARD_ID               Code = "INVALID_CARD_ID"
+	INVALID_PAYMENT_ID            Code = "INVALID_PAYMENT_ID"
+	INVALID_DISPUTE_ID            Code = "INVALID_DISPUTE_ID"
+	INVALID_SCHEDULED_TRANSFER_ID Code = "INVALID_SCHEDULED_TRANSFER_ID"
+	INVALID_FRAUD_REVIEW_ID       Code = "INVALID_FRAUD_REVIEW_ID"
+	INVALID_WEBHOOK_ID            Code = "INVALID_WEBHOOK_ID"
+	INVALID_WEBHOOK_DELIVERY_ID   Code = "INVALID_WEBHOOK_DELIVERY_ID"
+)
+
+// Вход, токены и двухфакторная аутентификация
+const (
+	INVALID_CREDENTIALS        Code = "INVALID_CREDENTIALS"
+	LOGIN_LOCKED               Code = "LOGIN_LOCKED"
+	INVALID_REFRESH_TOKEN      Code = "INVALID_REFRESH_TOKEN"
+	INVALID_CHALLENGE          Code = "INVALID_CHALLENGE"
+	INVALID_TOTP_CODE          Code = "INVALID_TOTP_CODE"
+	TOTP_REQUIRED              Code = "TOTP_REQUIRED"
+	STEP_UP_TOTP_INVALID       Code = "STEP_UP_TOTP_INVALID"
+	TWO_FACTOR_ALREADY_ENABLED Code = "TWO_FACTOR_ALREADY_ENABLED"
+	TWO_FACTOR_NOT_ENROLLED    Code = "TWO_FACTOR_NOT_ENROLLED"
+	TWO_FACTOR_NOT_ENABLED     Code = "TWO_FACTOR_NOT_ENABLED"
+)
+
+// Пользователи, пароли и подтверждение email
+const (
+	USER_NOT_FOUND             Code = "USER_NOT_FOUND"
+	INVALID_EMAIL              Code = "INVALID_EMAIL"
+	INVALID_USERNAME           Code = "INVALID_USERNAME"
+	WEAK_PASSWORD              Code = "WEAK_PASSWORD"
+	EMAIL_TAKEN                Code = "EMAIL_TAKEN"
+	USERNAME_TAKEN             Code = "USERNAME_TAKEN"
+	NO_FIELDS_TO_UPDATE        Code = "NO_FIELDS_TO_UPDATE"
+	WRONG_PASSWORD             Code = "WRONG_PASSWORD"
+	SAME_PASSWORD              Code = "SAME_PASSWORD"
+	INVALID_RESET_TOKEN        Code = "INVALID_RESET_TOKEN"
+	INVALID_VERIFICATION_TOKEN Code = "INVALID_VERIFICATION_TOKEN"
+	VERIFICATION_TOKEN_EXPIRED Code = "VERIFICATION_TOKEN_EXPIRED"
+	EMAIL_ALREADY_VERIFIED     Code = "EMAIL_ALREADY_VERIFIED"
+	VERIFICATION_TOO_FREQUENT  Code = "VERIFICATION_TOO_FREQUENT"
+	EMAIL_NOT_VERIFIED         Code = "EMAIL_NOT_VERIFIED"
+)
+
+// Счета и переводы
+const (
+	UNSUPPORTED_CURRENCY            Code = "UNSUPPORTED_CURRENCY"
+	ACCOUNT_NOT_FOUND               Code = "ACCOUNT_NOT_FOUND"
+	ACCOUNT_FORBIDDEN               Code = "ACCOUNT_FORBIDDEN"
+	ACCOUNT_FROZEN                  Code = "ACCOUNT_FROZEN"
+	ACCOUNT_CLOSED                  Code = "ACCOUNT_CLOSED"
+	ACCOUNT_ALREADY_CLOSED          Code = "ACCOUNT_ALREADY_CLOSED"
+	ACCOUNT_HAS_HOLDS               Code = "ACCOUNT_HAS_HOLDS"
+	ACCOUNT_RELOAD_FAILED           Code = "ACCOUNT_RELOAD_FAILED"
+	CLOSE_TARGET_REQUIRED           Code = "CLOSE_TARGET_REQUIRED"
+	CLOSE_TARGET_SAME_ACCOUNT       Code = "CLOSE_TARGET_SAME_ACCOUNT"
+	INSUFFICIENT_FUNDS              Code = "INSUFFICIENT_FUNDS"
+	SAME_ACCOUNT                    Code = "SAME_ACCOUNT"
+	INVALID_AMOUNT                  Code = "INVALID_AMOUNT"
+	RECIPIENT_REQUIRED              Code = "RECIPIENT_REQUIRED"
+	RECIPIENT_NOT_FOUND             Code = "RECIPIENT_NOT_FOUND"
+	INVALID_ACCOUNT_NUMBER          Code = "INVALID_ACCOUNT_NUMBER"
+	CURRENCY_MISMATCH               Code = "CURRENCY_MISMATCH"
+	INVALID_TRANSFER_LIMIT          Code = "INVALID_TRANSFER_LIMIT"
+	TRANSFER_SINGLE_LIMIT_EXCEEDED  Code = "TRANSFER_SINGLE_LIMIT_EXCEEDED"
+	TRANSFER_DAILY_LIMIT_EXCEEDED   Code = "TRANSFER_DAILY_LIMIT_EXCEEDED"
+	TRANSFER_MONTHLY_LIMIT_EXCEEDED Code = "TRANSFER_MONTHLY_LIMIT_EXCEEDED"
+	TRANSFER_RATE_LIMITED           Code = "TRANSFER_RATE_LIMITED"
+	TRANSFER_PREVIEW_RATE_LIMITED   Code = "TRANSFER_PREVIEW_RATE_LIMITED"
+	SCHEDULED_TRANSFER_NOT_FOUND    Code = "SCHEDULED_TRANSFER_NOT_FOUND"
+	SCHEDULED_TRANSFER_NOT_ACTIVE   Code = "SCHEDULED_TRANSFER_NOT_ACTIVE"
+	INVALID_FREQUENCY               Code = "INVALID_FREQUENCY"
+	INVALID_CRON_EXPR               Code = "INVALID_CRON_EXPR"
+	SCHEDULE_IN_PAST                Code = "SCHEDULE_IN_PAST"
+	INVALID_SCHEDULE_END            Code = "INVALID_SCHEDULE_END"
+	STREAM_LIMIT_EXCEEDED           Code = "STREAM_LIMIT_EXCEEDED"
+)
+
+// Карты, платежи и споры
+const (
+	CARD_NOT_FOUND                 Code = "CARD_NOT_FOUND"
+	CARD_FORBIDDEN                 Code = "CARD_FORBIDDEN"
+	CARD_BLOCKED                   Code = "CARD_BLOCKED"
+	CARD_NOT_LINKED                Code = "CARD_NOT_LINKED"
+	CARD_VERIFICATION_FAILED       Code = "CARD_VERIFICATION_FAILED"
+	PGP_KEY_REQUIRED               Code = "PGP_KEY_REQUIRED"
+	INVALID_CARD_LIMIT             Code = "INVALID_CARD_LIMIT"
+	INVALID_MERCHANT_CATEGORY      Code = "INVALID_MERCHANT_CATEGORY"
+	MERCHANT_CATEGORY_BLOCKED      Code = "MERCHANT_CATEGORY_BLOCKED"
+	PER_TRANSACTION_LIMIT_EXCEEDED Code = "PER_TRANSACTION_LIMIT_EXCEEDED"
+	DAILY_LIMIT_EXCEEDED           Code = "DAILY_LIMIT_EXCEEDED"
+	MONTHLY_LIMIT_EXCEEDED         Code = "MONTHLY_LIMIT_EXCEEDED"
+	PAYMENT_NOT_FOUND              Code = "PAYMENT_NOT_FOUND"
+	PAYMENT_NOT_AUTHORIZED         Code = "PAYMENT_NOT_AUTHORIZED"
+	PAYMENT_NOT_CAPTURED           Code = "PAYMENT_NOT_CAPTURED"
+	CAPTURE_EXCEEDS_HOLD           Code = "CAPTURE_EXCEEDS_HOLD"
+	REFUND_EXCEEDS_CAPTURED        Code = "REFUND_EXCEEDS_CAPTURED"
+	DISPUTE_NOT_FOUND              Code = "DISPUTE_NOT_FOUND"
+	DISPUTE_ALREADY_OPEN           Code = "DISPUTE_ALREADY_OPEN"
+	DISPUTE_REASON_REQUIRED        Code = "DISPUTE_REASON_REQUIRED"
+	INVALID_DISPUTE_STATUS         Code = "INVALID_DISPUTE_STATUS"
+	INVALID_DISPUTE_TRANSITION     Code = "INVALID_DISPUTE_TRANSITION"
+)
+
+// Администрирование и антифрод-проверка
+const (
+	ADMIN_REASON_REQUIRED            Code = "ADMIN_REASON_REQUIRED"
+	USER_LOOKUP_QUERY_REQUIRED       Code = "USER_LOOKUP_QUERY_REQUIRED"
+	INVALID_ROLE                     Code = "INVALID_ROLE"
+	CANNOT_CHANGE_OWN_ROLE           Code = "CANNOT_CHANGE_OWN_ROLE"
+	INVALID_ACCOUNT_STATUS           Code = "INVALID_ACCOUNT_STATUS"
+	FRAUD_REVIEW_NOT_FOUND           Code = "FRAUD_REVIEW_NOT_FOUND"
+	FRAUD_REVIEW_RESOLVED            Code = "FRAUD_REVIEW_RESOLVED"
+	INVALID_FRAUD_REVIEW_STATUS      Code = "INVALID_FRAUD_REVIEW_STATUS"
+	FRAUD_REVIEW_ACCOUNT_FROZEN      Code = "FRAUD_REVIEW_ACCOUNT_FROZEN"
+	FRAUD_REVIEW_ACCOUNT_UNAVAILABLE Code = "FRAUD_REVIEW_ACCOUNT_UNAVAILABLE"
+)
+
+// Вебхуки
+const (
+	WEBHOOK_NOT_FOUND          Code = "WEBHOOK_NOT_FOUND"
+	WEBHOOK_DELIVERY_NOT_FOUND Code = "WEBHOOK_DELIVERY_NOT_FOUND"
+	INVALID_WEBHOOK_URL        Code = "INVALID_WEBHOOK_URL"
+	INVALID_WEBHOOK_EVENT      Code = "INVALID_WEBHOOK_EVENT"
+	WEBHOOK_LIMIT_EXCEEDED     Code = "WEBHOOK_LIMIT_EXCEEDED"
+	WEBHOOK_DELIVERY_PENDING   Code = "WEBHOOK_DELIVERY_PENDING"
+)
+
+// statuses HTTP-статусы ошибок по кодам. Тексты ошибок хранятся в каталоге
+// сообщений i18n под теми же кодами.
+var statuses = map[Code]int{
+
+	INVALID_REQUEST_BODY:            http.StatusBadRequest,
+	VALIDATION_FAILED:               http.StatusBadRequest,
+	REQUEST_BODY_TOO_LARGE:          http.StatusRequestEntityTooLarge,
+	INVALID_QUERY_PARAMETER:         http.StatusBadRequest,
+	INVALID_IDEMPOTENCY_KEY:         http.StatusBadRequest,
+	IDEMPOTENCY_KEY_REUSED:          http.StatusUnprocessableEntity,
+	IDEMPOTENCY_REQUEST_IN_PROGRESS: http.StatusConflict,
+	UNAUTHORIZED:                    http.StatusUnauthorized,
+	INVALID_TOKEN_FORMAT:            http.StatusUnauthorized,
+	INVALID_TOKEN:                   http.StatusUnauthorized,
+	FORBIDDEN:                       http.StatusForbidden,
+	NOT_FOUND:                       http.StatusNotFound,
+	ROUTE_NOT_FOUND:                 http.StatusNotFound,
+	METHOD_NOT_ALLOWED:              http.StatusMethodNotAllowed,
+	INTERNAL_ERROR:                  http.StatusInternalServerError,
+
+	INVALID_USER_ID:               http.StatusBadRequest,
+	INVALID_ACCOUNT_ID:            http.StatusBadRequest,
+	INVALID_CARD_ID:               http.StatusBadRequest,
+	INVALID_PAYMENT_ID:            http.StatusBadRequest,
+	INVALID_DISPUTE_ID:            http.StatusBadRequest,
+	INVALID_SCHEDULED_TRANSFER_ID: http.StatusBadRequest,
+	INVALID_FRAUD_REVIEW_ID:       http.StatusBadRequest,
+	INVALID_WEBHOOK_ID:            http.StatusBadRequest,
+	INVALID_WEBHOOK_DELIVERY_ID:   http.StatusBadRequest,
+
+	INVALID_CREDENTIALS:        http.StatusUnauthorized,
+	LOGIN_LOCKED:               http.StatusTooManyRequests,
+	INVALID_REFRESH_TOKEN:      http.StatusUnauthorized,
+	INVALID_CHALLENGE:          http.StatusUnauthorized,
+	INVALID_TOTP_CODE:          http.StatusUnauthorized,
+	TOTP_REQUIRED:              http.StatusForbidden,
+	STEP_UP_TOTP_INVALID:       http.StatusForbidden,
+	TWO_FACTOR_ALREADY_ENABLED: http.StatusConflict,
+	TWO_FACTOR_NOT_ENROLLED:    http.StatusConflict,
+	TWO_FACTOR_NOT_ENABLED:     http.StatusConflict,
+
+	USER_NOT_FOUND:             http.StatusNotFound,
+	INVALID_EMAIL:              http.StatusBadRequest,
+	INVALID_USERNAME:           http.StatusBadRequest,
+	WEAK_PASSWORD:              http.StatusBadRequest,
+	EMAIL_TAKEN:                http.StatusConflict,
+	USERNAME_TAKEN:             http.StatusConflict,
+	NO_FIELDS_TO_UPDATE:        http.StatusBadRequest,
+	WRONG_PASSWORD:             http.StatusForbidden,
+	SAME_PASSWORD:              http.StatusBadRequest,
+	INVALID_RESET_TOKEN:        http.StatusBadRequest,
+	INVALID_VERIFICATION_TOKEN: http.StatusBadRequest,
+	VERIFICATION_TOKEN_EXPIRED: http.StatusGone,
+	EMAIL_ALREADY_VERIFIED:     http.StatusConflict,
+	VERIFICATION_TOO_FREQUENT:  http.StatusTooManyRequests,
+	EMAIL_NOT_VERIFIED:         http.StatusForbidden,
+
+	UNSUPPORTED_CURRENCY:            http.StatusBadRequest,
+	ACCOUNT_NOT_FOUND:               http.StatusNotFound,
+	ACCOUNT_FORBIDDEN:               http.StatusForbidden,
+	ACCOUNT_FROZEN:                  http.StatusForbidden,
+	ACCOUNT_CLOSED:                  http.StatusForbidden,
+	ACCOUNT_ALREADY_CLOSED:          http.StatusConflict,
+	ACCOUNT_HAS_HOLDS:               http.StatusConflict,
+	ACCOUNT_RELOAD_FAILED:           http.StatusInternalServerError,
+	CLOSE_TARGET_REQUIRED:           http.StatusBadRequest,
+	CLOSE_TARGET_SAME_ACCOUNT:       http.StatusBadRequest,
+	INSUFFICIENT_FUNDS:              http.StatusBadRequest,
+	SAME_ACCOUNT:                    http.StatusBadRequest,
+	INVALID_AMOUNT:                  http.StatusBadRequest,
+	RECIPIENT_REQUIRED:              http.StatusBadRequest,
+	RECIPIENT_NOT_FOUND:             http.StatusNotFound,
+	INVALID_ACCOUNT_NUMBER:          http.StatusBadRequest,
+	CURRENCY_MISMATCH:               http.StatusBadRequest,
+	INVALID_TRANSFER_LIMIT:          http.StatusBadRequest,
+	TRANSFER_SINGLE_LIMIT_EXCEEDED:  http.StatusBadRequest,
+	TRANSFER_DAILY_LIMIT_EXCEEDED:   http.StatusBadRequest,
+	TRANSFER_MONTHLY_LIMIT_EXCEEDED: http.StatusBadRequest,
+	TRANSFER_RATE_LIMITED:           http.StatusTooManyRequests,
+	TRANSFER_PREVIEW_RATE_LIMITED:   http.StatusTooManyRequests,
+	SCHEDULED_TRANSFER_NOT_FOUND:    http.StatusNotFound,
+	SCHEDULED_TRANSFER_NOT_ACTIVE:   http.StatusConflict,
+	INVALID_FREQUENCY:               http.StatusBadRequest,
+	INVALID_CRON_EXPR:               http.StatusBadRequest,
+	SCHEDULE_IN_PAST:                http.StatusBadRequest,
+	INVALID_SCHEDULE_END:            http.StatusBadRequest,
+	STREAM_LIMIT_EXCEEDED:           http.StatusTooManyRequests,
+
+	CARD_NOT_FOUND:                 http.StatusNotFound,
+	CARD_FORBIDDEN:                 http.StatusForbidden,
+	CARD_BLOCKED:                   http.StatusForbidden,
+	CARD_NOT_LINKED:                http.StatusBadRequest,
+	CARD_VERIFICATION_FAILED:       http.StatusBadRequest,
+	PGP_KEY_REQUIRED:               http.StatusBadRequest,
+	INVALID_CARD_LIMIT:             http.StatusBadRequest,
+	INVALID_MERCHANT_CATEGORY:      http.StatusBadRequest,
+	MERCHANT_CATEGORY_BLOCKED:      http.StatusForbidden,
+	PER_TRANSACTION_LIMIT_EXCEEDED: http.StatusBadRequest,
+	DAILY_LIMIT_EXCEEDED:           http.StatusBadRequest,
+	MONTHLY_LIMIT_EXCEEDED:         http.StatusBadRequest,
+	PAYMENT_NOT_FOUND:              http.StatusNotFound,
+	PAYMENT_NOT_AUTHORIZED:         http.StatusConflict,
+	PAYMENT_NOT_CAPTURED:           http.StatusConflict,
+	CAPTURE_EXCEEDS_HOLD:           http.StatusBadRequest,
+	REFUND_EXCEEDS_CAPTURED:        http.StatusBadRequest,
+	DISPUTE_NOT_FOUND:              http.StatusNotFound,
+	DISPUTE_ALREADY_OPEN:           http.StatusConflict,
+	DISPUTE_REASON_REQUIRED:        http.StatusBadRequest,
+	INVALID_DISPUTE_STATUS:         http.StatusBadRequest,
+	INVALID_DISPUTE_TRANSITION:     http.StatusConflict,
+
+	ADMIN_REASON_REQUIRED:            http.StatusBadRequest,
+	USER_LOOKUP_QUERY_REQUIRED:       http.StatusBadRequest,
+	INVALID_ROLE:                     http.StatusBadRequest,
+	CANNOT_CHANGE_OWN_ROLE:           http.StatusForbidden,
+	INVALID_ACCOUNT_STATUS:           http.StatusConflict,
+	FRAUD_REVIEW_NOT_FOUND:           http.StatusNotFound,
+	FRAUD_REVIEW_RESOLVED:            http.StatusConflict,
+	INVALID_FRAUD_REVIEW_STATUS:      http.StatusBadRequest,
+	FRAUD_REVIEW_ACCOUNT_FROZEN:      http.StatusConflict,
+	FRAUD_REVIEW_ACCOUNT_UNAVAILABLE: http.StatusConflict,
+
+	WEBHOOK_NOT_FOUND:          http.StatusNotFound,
+	WEBHOOK_DELIVERY_NOT_FOUND: http.StatusNotFound,
+	INVALID_WEBHOOK_URL:        http.StatusBadRequest,
+	INVALID_WEBHOOK_EVENT:      http.StatusBadRequest,
+	WEBHOOK_LIMIT_EXCEEDED:     http.StatusConflict,
+	WEBHOOK_DELIVERY_PENDING:   http.StatusConflict,
+}
@@ -0,0 +1,96 @@
+// Package problem формирует ответы с ошибками в формате RFC 7807 (application/problem+json).
+// Каждая ошибка API имеет стабильный машиночитаемый код; HTTP-статус определяется
+// кодом по каталогу, а текст для пользователя — кодом и языком запроса (пакет i18n).
+package problem
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+
+	"github.com/therealadik/bank-api/internal/i18n"
+	"github.com/therealadik/bank-api/internal/requestmeta"
+)
+
+// ContentType тип содержимого ответа с ошибкой
+const ContentType = "application/problem+json"
+
+// Problem описание ошибки по RFC 7807. Поле code — расширение с кодом ошибки,
+// по которому клиенты различают ошибки вместо сравнения текста.
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	Code      Code   `json:"code"`
+	RequestID string `json:"request_id,omitempty"`
+
+	// Extensions дополнительные поля ответа (например, параметры превышенного лимита)
+	Extensions map[string]any `json:"-"`
+}
+
+// New создает описание ошибки по коду из каталога с текстом на языке по умолчанию.
+// Неизвестный код считается внутренней ошибкой сервера.
+func New(code Code) *Problem {
+	status, ok := statuses[code]
+	if !ok {
+		code = INTERNAL_ERROR
+		status = statuses[code]
+	}
+	return &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: i18n.T(i18n.Default, i18n.Key(code)),
+		Code:   code,
+	}
+}
+
+// With добавляет в ответ дополнительное поле
+func (p *Problem) With(key string, value any) *Problem {
+	if p.Extensions == nil {
+		p.Extensions = make(map[string]any)
+	}
+	p.Extensions[key] = value
+	return p
+}
+
+// MarshalJSON выводит дополнительные поля на одном уровне со стандартными
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	type plain Problem
+	base, err := json.Marshal((*plain)(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return base, err
+	}
+
+	fields := make(map[string]any, len(p.Extensions))
+	maps.Copy(fields, p.Extensions)
+	if err := json.Unmarshal(base, &fields); err != nil {
+		return nil, err
+	}
+	return json.Marshal(fields)
+}
+
+// Write отправляет ответ с ошибкой, дополняя его путем и идентификатором запроса.
+// Текст ошибки переводится на язык запроса.
+func (p *Problem) Write(w http.ResponseWriter, r *http.Request) {
+	if r != nil {
+		lang := i18n.FromRequest(r)
+		p.Detail = i18n.T(lang, i18n.Key(p.Code))
+		p.Instance = r.URL.Path
+		p.RequestID = requestmeta.FromContext(r.Context()).RequestID
+		w.Header().Set("Content-Language", string(lang))
+	}
+
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+	// Заголовки уже отправлены, поэтому ошибку кодирования сообщить клиенту нельзя
+	_ = json.NewEncoder(w).Encode(p)
+}
+
+// Write отправляет ответ с ошибкой по коду из каталога
+func Write(w http.ResponseWriter, r *http.Request, code Code) {
+	New(code).Write(w, r)
+}
@@ -2,62 +2,80 @@ package repository
 
 import (
 	"context"
+	"errors"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/shopspring/decimal"
 	"github.com/therealadik/bank-api/internal/models/account"
+	"github.com/therealadik/bank-api/internal/models/stream"
 )
 
+const accountColumns = `id, number, user_id, balance, available_balance, currency, status, closed_at, created_at`
+
 type AccountRepository struct {
-	db *pgxpool.Pool
+	db DBTX
 }
 
 func NewAccountRepository(db *pgxpool.Pool) *AccountRepository {
 	return &AccountRepository{db: db}
 }
 
+// WithTx возвращает копию репозитория, работающую внутри транзакции
+func (r *AccountRepository) WithTx(tx pgx.Tx) *AccountRepository {
+	return &AccountRepository{db: tx}
+}
+
 // CreateAccount создает новый счет для пользователя
 func (r *AccountRepository) CreateAccount(ctx context.Context, userID int64, currency account.Currency) (*account.Account, error) {
-	query := `
-		INSERT INTO accounts (user_id, currency)
-		VALUES ($1, $2)
-		RETURNING id, user_id, balance, currency, created_at
-	`
-	var acc account.Account
-	err := r.db.QueryRow(ctx, query, userID, currency).Scan(
-		&acc.ID, &acc.UserID, &acc.Balance, &acc.Currency, &acc.CreatedAt,
-	)
+	// ID выделяется заранее: из него формируется номер счета
+	var id int64
+	err := r.db.QueryRow(ctx, `SELECT nextval(pg_get_serial_sequence('accounts', 'id'))`).Scan(&id)
 	if err != nil {
 		return nil, err
 	}
-	return &acc, nil
+
+	query := `
+		INSERT INTO accounts (id, number, user_id, currency)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + accountColumns
+	return r.updateAccount(ctx, query, id, account.GenerateNumber(id, currency), userID, currency)
 }
 
-// GetAccountByID получает счет по его ID
-func (r *AccountRepository) GetAccountByID(ctx context.Context, id int64) (*account.Account, error) {
+// GetAccountByNumber получает счет по его номеру
+func (r *AccountRepository) GetAccountByNumber(ctx context.Context, number string) (*account.Account, error) {
+	query := `SELECT ` + accountColumns + ` FROM accounts WHERE number = $1`
+	return scanAccount(r.db.QueryRow(ctx, query, number))
+}
+
+// GetPrimaryAccount получает основной счет пользователя в валюте — самый ранний активный.
+// Используется для зачисления переводов, адресованных пользователю, а не счету.
+func (r *AccountRepository) GetPrimaryAccount(ctx context.Context, userID int64, currency account.Currency) (*account.Account, error) {
 	query := `
-		SELECT id, user_id, balance, currency, created_at
+		SELECT ` + accountColumns + `
 		FROM accounts
-		WHERE id = $1
-	`
-	var acc account.Account
-	err := r.db.QueryRow(ctx, query, id).Scan(
-		&acc.ID, &acc.UserID, &acc.Balance, &acc.Currency, &acc.CreatedAt,
-	)
-	if err != nil {
-		return nil, err
-	}
-	return &acc, nil
+		WHERE user_id = $1 AND currency = $2 AND status = $3
+		ORDER BY id
+		LIMIT 1`
+	return scanAccount(r.db.QueryRow(ctx, query, userID, currency, account.ACTIVE))
+}
+
+// GetAccountByID получает счет по его ID
+func (r *AccountRepository) GetAccountByID(ctx context.Context, id int64) (*account.Account, error) {
+	query := `SELECT ` + accountColumns + ` FROM accounts WHERE id = $1`
+	return scanAccount(r.db.QueryRow(ctx, query, id))
+}
+
+// GetAccountForUpdate получает счет и блокирует его строку до конца транзакции,
+// чтобы проверки статуса и баланса не разошлись с последующим изменением
+func (r *AccountRepository) GetAccountForUpdate(ctx context.Context, id int64) (*account.Account, error) {
+	query := `SELECT ` + accountColumns + ` FROM accounts WHERE id = $1 FOR UPDATE`
+	return scanAccount(r.db.QueryRow(ctx, query, id))
 }
 
 // GetAccountsByUserID получает все счета пользователя
 func (r *AccountRepository) GetAccountsByUserID(ctx context.Context, userID int64) ([]*account.Account, error) {
-	query := `
-		SELECT id, user_id, balance, currency, created_at
-		FROM accounts
-		WHERE user_id = $1
-		ORDER BY id
-	`
+	query := `SELECT ` + accountColumns + ` FROM accounts WHERE user_id = $1 ORDER BY id`
 	rows, err := r.db.Query(ctx, query, userID)
 	if err != nil {
 		return nil, err
@@ -66,11 +84,11 @@ func (r *AccountRepository) GetAccountsByUserID(ctx context.Context, userID int6
 
 	var accounts []*account.Account
 	for rows.Next() {
-		var acc account.Account
-		if err := rows.Scan(&acc.ID, &acc.UserID, &acc.Balance, &acc.Currency, &acc.CreatedAt); err != nil {
+		acc, err := scanAccount(rows)
+		if err != nil {
 			return nil, err
 		}
-		accounts = append(accounts, &acc)
+		accounts = append(accounts, acc)
 	}
 
 	if err = rows.Err(); err != nil {
@@ -83,10 +101,11 @@ func (r *AccountRepository) GetAccountsByUserID(ctx context.Context, userID int6
 func (r *AccountRepository) UpdateBalance(ctx context.Context, id int64, amount decimal.Decimal) error {
 	query := `
 		UPDATE accounts
-		SET balance = balance + $1
+		SET balance           = balance + $1,
+		    available_balance = available_balance + $1
 		WHERE id = $2
-	`
-	_, err := r.db.Exec(ctx, query, amount, id)
+		RETURNING ` + accountColumns
+	_, err := r.updateAccount(ctx, query, amount, id)
 	return err
 }
 
@@ -98,29 +117,116 @@ func (r *AccountRepository) TransferBetweenAccounts(ctx context.Context, fromID,
 	}
 	defer tx.Rollback(ctx)
 
+	txRepo := r.WithTx(tx)
+
 	// Списание со счета отправителя
 	updateFromQuery := `
 		UPDATE accounts
-		SET balance = balance - $1
-		WHERE id = $2 AND balance >= $1
-		RETURNING balance
-	`
-	var newBalance decimal.Decimal
-	err = tx.QueryRow(ctx, updateFromQuery, amount, fromID).Scan(&newBalance)
-	if err != nil {
+		SET balance           = balance - $1,
+		    available_balance = available_balance - $1
+		WHERE id = $2 AND available_balance >= $1
+		RETURNING ` + accountColumns
+	if _, err = txRepo.updateAccount(ctx, updateFromQuery, amount, fromID); err != nil {
 		return err
 	}
 
 	// Пополнение счета получателя
 	updateToQuery := `
 		UPDATE accounts
-		SET balance = balance + $1
+		SET balance           = balance + $1,
+		    available_balance = available_balance + $1
 		WHERE id = $2
-	`
-	_, err = tx.Exec(ctx, updateToQuery, amount, toID)
-	if err != nil {
+		RETURNING ` + accountColumns
+	if _, err = txRepo.updateAccount(ctx, updateToQuery, amount, toID); err != nil {
 		return err
 	}
 
 	return tx.Commit(ctx)
 }
+
+// ReserveFunds ставит холд на сумму: уменьшает доступный баланс, если средств достаточно.
+// Возвращает false, если доступных средств не хватает.
+func (r *AccountRepository) ReserveFunds(ctx context.Context, id int64, amount decimal.Decimal) (bool, error) {
+	query := `
+		UPDATE accounts
+		SET available_balance = available_balance - $1
+		WHERE id = $2 AND available_balance >= $1
+		RETURNING ` + accountColumns
+	_, err := r.updateAccount(ctx, query, amount, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ReleaseFunds снимает холд, возвращая сумму в доступный баланс
+func (r *AccountRepository) ReleaseFunds(ctx context.Context, id int64, amount decimal.Decimal) error {
+	query := `
+		UPDATE accounts
+		SET available_balance = available_balance + $1
+		WHERE id = $2
+		RETURNING ` + accountColumns
+	_, err := r.updateAccount(ctx, query, amount, id)
+	return err
+}
+
+// CaptureFunds списывает сумму, ранее поставленную на холд.
+// Если списывается меньше удержанного, остаток холда возвращается в доступный баланс.
+func (r *AccountRepository) CaptureFunds(ctx context.Context, id int64, held, captured decimal.Decimal) error {
+	query := `
+		UPDATE accounts
+		SET balance           = balance - $1,
+		    available_balance = available_balance + $2 - $1
+		WHERE id = $3
+		RETURNING ` + accountColumns
+	_, err := r.updateAccount(ctx, query, captured, held, id)
+	return err
+}
+
+// UpdateStatus переводит счет из статуса from в статус to.
+// Возвращает pgx.ErrNoRows, если счет не найден или находится в другом статусе.
+func (r *AccountRepository) UpdateStatus(ctx context.Context, id int64, from, to account.Status) (*account.Account, error) {
+	query := `
+		UPDATE accounts
+		SET status = $1
+		WHERE id = $2 AND status = $3
+		RETURNING ` + accountColumns
+	return r.updateAccount(ctx, query, to, id, from)
+}
+
+// Close закрывает счет
+func (r *AccountRepository) Close(ctx context.Context, id int64) (*account.Account, error) {
+	query := `
+		UPDATE accounts
+		SET status = $1, closed_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+		RETURNING ` + accountColumns
+	return r.updateAccount(ctx, query, account.CLOSED, id)
+}
+
+// updateAccount выполняет запрос, изменяющий счет и возвращающий его строку, и сообщает
+// о новом состоянии счета в поток изменений
+func (r *AccountRepository) updateAccount(ctx context.Context, query string, args ...any) (*account.Account, error) {
+	acc, err := scanAccount(r.db.QueryRow(ctx, query, args...))
+	if err != nil {
+		return nil, err
+	}
+	if err := notifyStream(ctx, r.db, stream.ACCOUNT, acc.ID, acc); err != nil {
+		return nil, err
+	}
+	return acc, nil
+}
+
+// scanAccount сканирует строку счета
+func scanAccount(row pgx.Row) (*account.Account, error) {
+	var acc account.Account
+	err := row.Scan(&acc.ID, &acc.Number, &acc.UserID, &acc.Balance, &acc.AvailableBalance, &acc.Currency, &acc.Status,
+		&acc.ClosedAt, &acc.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &acc, nil
+}
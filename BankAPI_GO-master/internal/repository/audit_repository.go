@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/therealadik/bank-api/internal/models"
+)
+
+// auditChainLock ключ advisory-блокировки, сериализующей добавление записей в цепочку аудита
+const auditChainLock = 730002
+
+const auditColumns = `id, actor_id, action, target_type, target_id, request_id, ip, user_agent, details,
+		       created_at, prev_hash, hash`
+
+type AuditRepository struct {
+	db DBTX
+}
+
+func NewAuditRepository(db *pgxpool.Pool) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// WithTx возвращает копию репозитория, работающую внутри транзакции
+func (r *AuditRepository) WithTx(tx pgx.Tx) *AuditRepository {
+	return &AuditRepository{db: tx}
+}
+
+// LockChain берет advisory-блокировку цепочки аудита до конца транзакции
+func (r *AuditRepository) LockChain(ctx context.Context) error {
+	_, err := r.db.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, auditChainLock)
+	return err
+}
+
+// GetLastHash возвращает хеш последней записи, пустую строку если журнал пуст
+func (r *AuditRepository) GetLastHash(ctx context.Context) (string, error) {
+	var hash string
+	err := r.db.QueryRow(ctx, `SELECT hash FROM audit_log ORDER BY id DESC LIMIT 1`).Scan(&hash)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	return hash, err
+}
+
+// Create добавляет запись в журнал
+func (r *AuditRepository) Create(ctx context.Context, entry *models.AuditEntry) error {
+	query := `
+		INSERT INTO audit_log (actor_id, action, target_type, target_id, request_id, ip, user_agent, details,
+		                       created_at, prev_hash, hash)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id
+	`
+	return r.db.QueryRow(ctx, query, entry.ActorID, entry.Action, entry.TargetType, entry.TargetID,
+		entry.RequestID, entry.IP, entry.UserAgent, entry.Details, entry.CreatedAt, entry.PrevHash,
+		entry.Hash).Scan(&entry.ID)
+}
+
+// List возвращает записи, подходящие под фильтр, от новых к старым
+func (r *AuditRepository) List(ctx context.Context, filter models.AuditFilter) ([]*models.AuditEntry, error) {
+	var conditions []string
+	var args []any
+
+	add := func(condition string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.ActorID != nil {
+		add("actor_id = $%d", *filter.ActorID)
+	}
+	if filter.Action != "" {
+		add("action = $%d", filter.Action)
+	}
+	if filter.TargetType != "" {
+		add("target_type = $%d", filter.TargetType)
+	}
+	if filter.TargetID != nil {
+		add("target_id = $%d", *filter.TargetID)
+	}
+	if filter.RequestID != "" {
+		add("request_id = $%d", filter.RequestID)
+	}
+	if filter.From != nil {
+		add("created_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		add("created_at < $%d", *filter.To)
+	}
+	if filter.BeforeID != nil {
+		add("id < $%d", *filter.BeforeID)
+	}
+
+	query := `SELECT ` + auditColumns + ` FROM audit_log`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	args = append(args, filter.Limit)
+	query += fmt.Sprintf(` ORDER BY id DESC LIMIT $%d`, len(args))
+
+	return r.query(ctx, query, args...)
+}
+
+// ListAfter возвращает до limit записей с ID больше afterID в порядке цепочки
+func (r *AuditRepository) ListAfter(ctx context.Context, afterID int64, limit int) ([]*models.AuditEntry, error) {
+	query := `SELECT ` + auditColumns + ` FROM audit_log WHERE id > $1 ORDER BY id LIMIT $2`
+	return r.query(ctx, query, afterID, limit)
+}
+
+// query выполняет выборку записей журнала
+func (r *AuditRepository) query(ctx context.Context, query string, args ...any) ([]*models.AuditEntry, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*models.AuditEntry
+	for rows.Next() {
+		var e models.AuditEntry
+		if err := rows.Scan(&e.ID, &e.ActorID, &e.Action, &e.TargetType, &e.TargetID, &e.RequestID, &e.IP,
+			&e.UserAgent, &e.Details, &e.CreatedAt, &e.PrevHash, &e.Hash); err != nil {
+			return nil, err
+		}
+		entries = append(entries, &e)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
@@ -2,29 +2,37 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/therealadik/bank-api/internal/models"
 )
 
 type CardRepository struct {
-	db *pgxpool.Pool
+	db DBTX
 }
 
 func NewCardRepository(db *pgxpool.Pool) *CardRepository {
 	return &CardRepository{db: db}
 }
 
+// WithTx возвращает копию репозитория, работающую внутри транзакции
+func (r *CardRepository) WithTx(tx pgx.Tx) *CardRepository {
+	return &CardRepository{db: tx}
+}
+
 // CreateCard создает новую карту с зашифрованными данными
-func (r *CardRepository) CreateCard(ctx context.Context, userID int64, encryptedNumber, encryptedExpire []byte, cvvHash string) (*models.Card, error) {
+func (r *CardRepository) CreateCard(ctx context.Context, userID int64, accountID int64, encryptedNumber, encryptedExpire []byte, cvvHash string) (*models.Card, error) {
 	query := `
-		INSERT INTO cards (user_id, card_number, expire, cvv_hash)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id, user_id, created_at
+		INSERT INTO cards (user_id, account_id, card_number, expire, cvv_hash)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, user_id, account_id, status, created_at
 	`
 	var card models.Card
-	err := r.db.QueryRow(ctx, query, userID, encryptedNumber, encryptedExpire, cvvHash).Scan(
-		&card.ID, &card.UserID, &card.CreatedAt,
+	err := r.db.QueryRow(ctx, query, userID, accountID, encryptedNumber, encryptedExpire, cvvHash).Scan(
+		&card.ID, &card.UserID, &card.AccountID, &card.Status, &card.CreatedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -41,13 +49,13 @@ func (r *CardRepository) CreateCard(ctx context.Context, userID int64, encrypted
 // GetCardByID получает карту по ID
 func (r *CardRepository) GetCardByID(ctx context.Context, cardID int64) (*models.Card, error) {
 	query := `
-		SELECT id, user_id, card_number, expire, cvv_hash, created_at
+		SELECT id, user_id, account_id, card_number, expire, cvv_hash, status, created_at
 		FROM cards 
 		WHERE id = $1
 	`
 	var card models.Card
 	err := r.db.QueryRow(ctx, query, cardID).Scan(
-		&card.ID, &card.UserID, &card.CardNumber, &card.Expire, &card.CVVHash, &card.CreatedAt,
+		&card.ID, &card.UserID, &card.AccountID, &card.CardNumber, &card.Expire, &card.CVVHash, &card.Status, &card.CreatedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -59,7 +67,7 @@ func (r *CardRepository) GetCardByID(ctx context.Context, cardID int64) (*models
 // GetCardsByUserID получает все карты пользователя
 func (r *CardRepository) GetCardsByUserID(ctx context.Context, userID int64) ([]*models.Card, error) {
 	query := `
-		SELECT id, user_id, created_at
+		SELECT id, user_id, account_id, status, created_at
 		FROM cards 
 		WHERE user_id = $1
 		ORDER BY created_at DESC
@@ -73,7 +81,7 @@ func (r *CardRepository) GetCardsByUserID(ctx context.Context, userID int64) ([]
 	var cards []*models.Card
 	for rows.Next() {
 		var card models.Card
-		if err := rows.Scan(&card.ID, &card.UserID, &card.CreatedAt); err != nil {
+		if err := rows.Scan(&card.ID, &card.UserID, &card.AccountID, &card.Status, &card.CreatedAt); err != nil {
 			return nil, err
 		}
 		cards = append(cards, &card)
@@ -100,3 +108,130 @@ func (r *CardRepository) IsCardExistsForUser(ctx context.Context, cardID int64,
 
 	return true, nil
 }
+
+// LockCard блокирует строку карты до конца транзакции, чтобы платежи
+// по одной карте проверялись на лимиты последовательно
+func (r *CardRepository) LockCard(ctx context.Context, cardID int64) error {
+	query := `
+		SELECT id FROM cards
+		WHERE id = $1
+		FOR UPDATE
+	`
+	var id int64
+	return r.db.QueryRow(ctx, query, cardID).Scan(&id)
+}
+
+// RecordVerificationFailure записывает неудачную проверку CVV по карте и возвращает
+// число неудачных проверок подряд
+func (r *CardRepository) RecordVerificationFailure(ctx context.Context, cardID int64) (int, error) {
+	if _, err := r.db.Exec(ctx, `INSERT INTO card_verification_failures (card_id) VALUES ($1)`, cardID); err != nil {
+		return 0, err
+	}
+
+	query := `
+		UPDATE cards
+		SET failed_cvv_attempts = failed_cvv_attempts + 1
+		WHERE id = $1
+		RETURNING failed_cvv_attempts
+	`
+	var attempts int
+	err := r.db.QueryRow(ctx, query, cardID).Scan(&attempts)
+	return attempts, err
+}
+
+// ResetVerificationFailures обнуляет счетчик неудачных проверок CVV после успешной проверки
+func (r *CardRepository) ResetVerificationFailures(ctx context.Context, cardID int64) error {
+	_, err := r.db.Exec(ctx, `UPDATE cards SET failed_cvv_attempts = 0 WHERE id = $1 AND failed_cvv_attempts > 0`, cardID)
+	return err
+}
+
+// BlockActive блокирует действующую карту. Возвращает false, если карта уже заблокирована.
+func (r *CardRepository) BlockActive(ctx context.Context, cardID int64) (bool, error) {
+	tag, err := r.db.Exec(ctx, `UPDATE cards SET status = $1 WHERE id = $2 AND status = $3`,
+		models.CARD_BLOCKED, cardID, models.CARD_ACTIVE)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// CountVerificationFailuresSince возвращает число неудачных проверок CVV по карте с момента since
+func (r *CardRepository) CountVerificationFailuresSince(ctx context.Context, cardID int64, since time.Time) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM card_verification_failures
+		WHERE card_id = $1 AND created_at >= $2
+	`
+	var count int
+	err := r.db.QueryRow(ctx, query, cardID, since).Scan(&count)
+	return count, err
+}
+
+// GetCardLimit получает лимиты карты, nil если лимиты не заданы
+func (r *CardRepository) GetCardLimit(ctx context.Context, cardID int64) (*models.CardLimit, error) {
+	query := `
+		SELECT card_id, per_transaction, daily, monthly, blocked_categories, updated_at
+		FROM card_limits
+		WHERE card_id = $1
+	`
+	var limit models.CardLimit
+	err := r.db.QueryRow(ctx, query, cardID).Scan(
+		&limit.CardID, &limit.PerTransaction, &limit.Daily, &limit.Monthly,
+		&limit.BlockedCategories, &limit.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &limit, nil
+}
+
+// UpsertCardLimit создает или заменяет лимиты карты
+func (r *CardRepository) UpsertCardLimit(ctx context.Context, limit *models.CardLimit) (*models.CardLimit, error) {
+	query := `
+		INSERT INTO card_limits (card_id, per_transaction, daily, monthly, blocked_categories)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (card_id) DO UPDATE
+		SET per_transaction    = EXCLUDED.per_transaction,
+		    daily              = EXCLUDED.daily,
+		    monthly            = EXCLUDED.monthly,
+		    blocked_categories = EXCLUDED.blocked_categories,
+		    updated_at         = CURRENT_TIMESTAMP
+		RETURNING card_id, per_transaction, daily, monthly, blocked_categories, updated_at
+	`
+	var saved models.CardLimit
+	err := r.db.QueryRow(ctx, query, limit.CardID, limit.PerTransaction, limit.Daily, limit.Monthly,
+		limit.BlockedCategories).Scan(
+		&saved.CardID, &saved.PerTransaction, &saved.Daily, &saved.Monthly,
+		&saved.BlockedCategories, &saved.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &saved, nil
+}
+
+// SetStatus изменяет статус карты. Разблокировка обнуляет счетчик неудачных проверок CVV.
+// Возвращает pgx.ErrNoRows, если карта не найдена.
+func (r *CardRepository) SetStatus(ctx context.Context, cardID int64, status models.CardStatus) (*models.Card, error) {
+	query := `
+		UPDATE cards
+		SET status              = $1,
+		    failed_cvv_attempts = CASE WHEN $1 = 'ACTIVE' THEN 0 ELSE failed_cvv_attempts END
+		WHERE id = $2
+		RETURNING id, user_id, account_id, status, created_at
+	`
+	var card models.Card
+	err := r.db.QueryRow(ctx, query, status, cardID).Scan(
+		&card.ID, &card.UserID, &card.AccountID, &card.Status, &card.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &card, nil
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/therealadik/bank-api/internal/models/payment"
+)
+
+const disputeColumns = `id, payment_id, opened_by, amount, reason, status, resolution_comment,
+		resolved_by, refund_transaction_id, created_at, updated_at`
+
+type DisputeRepository struct {
+	db DBTX
+}
+
+func NewDisputeRepository(db *pgxpool.Pool) *DisputeRepository {
+	return &DisputeRepository{db: db}
+}
+
+// WithTx возвращает копию репозитория, работающую внутри транзакции
+func (r *DisputeRepository) WithTx(tx pgx.Tx) *DisputeRepository {
+	return &DisputeRepository{db: tx}
+}
+
+// CreateDispute создает спор по платежу
+func (r *DisputeRepository) CreateDispute(ctx context.Context, d *payment.Dispute) (*payment.Dispute, error) {
+	query := `
+		INSERT INTO payment_disputes (payment_id, opened_by, amount, reason, status)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + disputeColumns
+	return scanDispute(r.db.QueryRow(ctx, query, d.PaymentID, d.OpenedBy, d.Amount, d.Reason, d.Status))
+}
+
+// GetDisputeByIDForUpdate получает спор по ID и блокирует его строку до конца транзакции
+func (r *DisputeRepository) GetDisputeByIDForUpdate(ctx context.Context, id int64) (*payment.Dispute, error) {
+	query := `SELECT ` + disputeColumns + ` FROM payment_disputes WHERE id = $1 FOR UPDATE`
+	return scanDispute(r.db.QueryRow(ctx, query, id))
+}
+
+// UpdateDispute сохраняет результат рассмотрения спора
+func (r *DisputeRepository) UpdateDispute(ctx context.Context, d *payment.Dispute) error {
+	query := `
+		UPDATE payment_disputes
+		SET status                = $1,
+		    resolution_comment    = $2,
+		    resolved_by           = $3,
+		    refund_transaction_id = $4,
+		    updated_at            = CURRENT_TIMESTAMP
+		WHERE id = $5
+		RETURNING updated_at
+	`
+	return r.db.QueryRow(ctx, query, d.Status, d.ResolutionComment, d.ResolvedBy, d.RefundTransactionID, d.ID).
+		Scan(&d.UpdatedAt)
+}
+
+// GetDisputesByUserID получает споры, открытые пользователем
+func (r *DisputeRepository) GetDisputesByUserID(ctx context.Context, userID int64) ([]*payment.Dispute, error) {
+	query := `
+		SELECT ` + disputeColumns + `
+		FROM payment_disputes
+		WHERE opened_by = $1
+		ORDER BY created_at DESC
+	`
+	return r.queryDisputes(ctx, query, userID)
+}
+
+// GetDisputesByStatus получает споры в указанном статусе (все споры, если статус пустой)
+func (r *DisputeRepository) GetDisputesByStatus(ctx context.Context, status payment.DisputeStatus) ([]*payment.Dispute, error) {
+	query := `
+		SELECT ` + disputeColumns + `
+		FROM payment_disputes
+		WHERE $1 = '' OR status = $1
+		ORDER BY created_at
+	`
+	return r.queryDisputes(ctx, query, string(status))
+}
+
+// queryDisputes выполняет запрос и сканирует список споров
+func (r *DisputeRepository) queryDisputes(ctx context.Context, query string, args ...any) ([]*payment.Dispute, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var disputes []*payment.Dispute
+	for rows.Next() {
+		d, err := scanDispute(rows)
+		if err != nil {
+			return nil, err
+		}
+		disputes = append(disputes, d)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return disputes, nil
+}
+
+// scanDispute сканирует строку спора
+func scanDispute(row pgx.Row) (*payment.Dispute, error) {
+	var d payment.Dispute
+	err := row.Scan(
+		&d.ID, &d.PaymentID, &d.OpenedBy, &d.Amount, &d.Reason, &d.Status, &d.ResolutionComment,
+		&d.ResolvedBy, &d.RefundTransactionID, &d.CreatedAt, &d.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
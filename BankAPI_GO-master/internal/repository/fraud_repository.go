@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+	"github.com/therealadik/bank-api/internal/models/fraud"
+	"github.com/therealadik/bank-api/internal/models/transaction"
+)
+
+const fraudReviewColumns = `id, kind, user_id, account_id, to_account_id, transaction_id, payment_id, amount, score,
+	reasons, status, reviewed_by, comment, reviewed_at, created_at`
+
+type FraudRepository struct {
+	db DBTX
+}
+
+func NewFraudRepository(db *pgxpool.Pool) *FraudRepository {
+	return &FraudRepository{db: db}
+}
+
+// WithTx возвращает копию репозитория, работающую внутри транзакции
+func (r *FraudRepository) WithTx(tx pgx.Tx) *FraudRepository {
+	return &FraudRepository{db: tx}
+}
+
+// OutgoingStats возвращает число и среднюю сумму проведенных исходящих операций
+// (переводов и списаний, включая оплаты картой) по всем счетам пользователя с момента since
+func (r *FraudRepository) OutgoingStats(ctx context.Context, userID int64, since time.Time) (int, decimal.Decimal, error) {
+	query := `
+		SELECT COUNT(*), COALESCE(AVG(t.amount), 0)
+		FROM transactions t
+		JOIN accounts a ON a.id = t.account_id
+		WHERE a.user_id = $1 AND t.type IN ($2, $3) AND t.status = $4 AND t.created_at >= $5
+	`
+	var count int
+	var avg decimal.Decimal
+	err := r.db.QueryRow(ctx, query, userID, transaction.TRANSFER, transaction.WITHDRAWAL, transaction.COMPLETED,
+		since).Scan(&count, &avg)
+	return count, avg, err
+}
+
+// CountNewRecipientsSince возвращает число чужих счетов, на которые пользователь
+// впервые перевел деньги начиная с момента since
+func (r *FraudRepository) CountNewRecipientsSince(ctx context.Context, userID int64, since time.Time) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM (
+			SELECT t.counterparty_account_id
+			FROM transactions t
+			JOIN accounts a ON a.id = t.account_id
+			JOIN accounts c ON c.id = t.counterparty_account_id
+			WHERE a.user_id = $1 AND c.user_id <> $1 AND t.type = $2 AND t.status <> $3
+			GROUP BY t.counterparty_account_id
+			HAVING MIN(t.created_at) >= $4
+		) recipients
+	`
+	var count int
+	err := r.db.QueryRow(ctx, query, userID, transaction.TRANSFER, transaction.FAILED, since).Scan(&count)
+	return count, err
+}
+
+// HasTransferredTo проверяет, переводил ли пользователь деньги на счет toAccountID раньше
+func (r *FraudRepository) HasTransferredTo(ctx context.Context, userID, toAccountID int64) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM transactions t
+			JOIN accounts a ON a.id = t.account_id
+			WHERE a.user_id = $1 AND t.counterparty_account_id = $2 AND t.type = $3 AND t.status <> $4
+		)
+	`
+	var exists bool
+	err := r.db.QueryRow(ctx, query, userID, toAccountID, transaction.TRANSFER, transaction.FAILED).Scan(&exists)
+	return exists, err
+}
+
+// CreateReview сохраняет задержанную операцию
+func (r *FraudRepository) CreateReview(ctx context.Context, review *fraud.Review) (*fraud.Review, error) {
+	query := `
+		INSERT INTO fraud_reviews (kind, user_id, account_id, to_account_id, transaction_id, payment_id, amount,
+		                           score, reasons, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING ` + fraudReviewColumns
+	return scanFraudReview(r.db.QueryRow(ctx, query, review.Kind, review.UserID, review.AccountID, review.ToAccountID,
+		review.TransactionID, review.PaymentID, review.Amount, review.Score, reasonStrings(review.Reasons),
+		fraud.PENDING))
+}
+
+// GetReviewForUpdate получает задержанную операцию и блокирует ее до конца транзакции
+func (r *FraudRepository) GetReviewForUpdate(ctx context.Context, id int64) (*fraud.Review, error) {
+	query := `SELECT ` + fraudReviewColumns + ` FROM fraud_reviews WHERE id = $1 FOR UPDATE`
+	return scanFraudReview(r.db.QueryRow(ctx, query, id))
+}
+
+// GetReviews получает задержанные операции в указанном статусе (все — при пустом статусе), старые первыми
+func (r *FraudRepository) GetReviews(ctx context.Context, status fraud.Status, limit int) ([]*fraud.Review, error) {
+	query := `
+		SELECT ` + fraudReviewColumns + `
+		FROM fraud_reviews
+		WHERE $1 = '' OR status = $1
+		ORDER BY created_at, id
+		LIMIT $2
+	`
+	rows, err := r.db.Query(ctx, query, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reviews []*fraud.Review
+	for rows.Next() {
+		review, err := scanFraudReview(rows)
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, review)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return reviews, nil
+}
+
+// Resolve фиксирует решение по задержанной операции
+func (r *FraudRepository) Resolve(ctx context.Context, id int64, status fraud.Status, reviewedBy int64,
+	comment string) (*fraud.Review, error) {
+	query := `
+		UPDATE fraud_reviews
+		SET status = $1, reviewed_by = $2, comment = $3, reviewed_at = CURRENT_TIMESTAMP
+		WHERE id = $4
+		RETURNING ` + fraudReviewColumns
+	return scanFraudReview(r.db.QueryRow(ctx, query, status, reviewedBy, comment, id))
+}
+
+func scanFraudReview(row pgx.Row) (*fraud.Review, error) {
+	var review fraud.Review
+	var reasons []string
+	err := row.Scan(&review.ID, &review.Kind, &review.UserID, &review.AccountID, &review.ToAccountID,
+		&review.TransactionID, &review.PaymentID, &review.Amount, &review.Score, &reasons, &review.Status,
+		&review.ReviewedBy, &review.Comment, &review.ReviewedAt, &review.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	review.Reasons = make([]fraud.Reason, 0, len(reasons))
+	for _, reason := range reasons {
+		review.Reasons = append(review.Reasons, fraud.Reason(reason))
+	}
+	return &review, nil
+}
+
+// reasonStrings преобразует причины задержки в массив строк для записи в БД
+func reasonStrings(reasons []fraud.Reason) []string {
+	result := make([]string, 0, len(reasons))
+	for _, reason := range reasons {
+		result = append(result, string(reason))
+	}
+	return result
+}
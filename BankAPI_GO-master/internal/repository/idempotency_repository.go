@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/therealadik/bank-api/internal/models"
+)
+
+// ErrIdempotencyKeyNotFound возвращается, когда ключ идемпотентности не найден
+var ErrIdempotencyKeyNotFound = errors.New("ключ идемпотентности не найден")
+
+// IdempotencyRepository хранилище ключей идемпотентности и сохраненных ответов
+type IdempotencyRepository interface {
+	Reserve(ctx context.Context, userID int64, key, requestHash string, expiredBefore time.Time) (bool, error)
+	Get(ctx context.Context, userID int64, key string) (*models.IdempotencyKey, error)
+	Complete(ctx context.Context, userID int64, key string, statusCode int, contentType string, body []byte) error
+	Delete(ctx context.Context, userID int64, key string) error
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
+
+// IdempotencyRepositoryPgx реализация хранилища ключей идемпотентности в PostgreSQL
+type IdempotencyRepositoryPgx struct {
+	pool *pgxpool.Pool
+}
+
+// NewIdempotencyRepository создает новое хранилище ключей идемпотентности
+func NewIdempotencyRepository(pool *pgxpool.Pool) IdempotencyRepository {
+	return &IdempotencyRepositoryPgx{pool: pool}
+}
+
+// Reserve атомарно занимает ключ для выполнения запроса. Ключ, созданный раньше expiredBefore,
+// считается свободным и занимается заново. Возвращает false, если ключ уже занят.
+func (r *IdempotencyRepositoryPgx) Reserve(ctx context.Context, userID int64, key, requestHash string, expiredBefore time.Time) (bool, error) {
+	var reserved bool
+
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO idempotency_keys (user_id, key, request_hash)
+         VALUES ($1, $2, $3)
+         ON CONFLICT (user_id, key) DO UPDATE
+         SET request_hash  = EXCLUDED.request_hash,
+             status_code   = NULL,
+             content_type  = '',
+             response_body = NULL,
+             created_at    = CURRENT_TIMESTAMP
+         WHERE idempotency_keys.created_at < $4
+         RETURNING true`,
+		userID, key, requestHash, expiredBefore).Scan(&reserved)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	return reserved, err
+}
+
+// Get возвращает ключ идемпотентности пользователя
+func (r *IdempotencyRepositoryPgx) Get(ctx context.Context, userID int64, key string) (*models.IdempotencyKey, error) {
+	record := &models.IdempotencyKey{}
+
+	err := r.pool.QueryRow(ctx,
+		`SELECT user_id, key, request_hash, status_code, content_type, response_body, created_at
+         FROM idempotency_keys
+         WHERE user_id = $1 AND key = $2`,
+		userID, key).Scan(&record.UserID, &record.Key, &record.RequestHash, &record.StatusCode,
+		&record.ContentType, &record.ResponseBody, &record.CreatedAt)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrIdempotencyKeyNotFound
+		}
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// Complete сохраняет ответ на запрос, выполненный с ключом
+func (r *IdempotencyRepositoryPgx) Complete(ctx context.Context, userID int64, key string, statusCode int, contentType string, body []byte) error {
+	_, err := r.pool.Exec(ctx,
+		`UPDATE idempotency_keys
+         SET status_code = $3, content_type = $4, response_body = $5
+         WHERE user_id = $1 AND key = $2`,
+		userID, key, statusCode, contentType, body)
+	return err
+}
+
+// Delete освобождает ключ, чтобы запрос можно было повторить
+func (r *IdempotencyRepositoryPgx) Delete(ctx context.Context, userID int64, key string) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM idempotency_keys WHERE user_id = $1 AND key = $2`, userID, key)
+	return err
+}
+
+// DeleteExpired удаляет ключи, созданные раньше before, и возвращает их число
+func (r *IdempotencyRepositoryPgx) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM idempotency_keys WHERE created_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
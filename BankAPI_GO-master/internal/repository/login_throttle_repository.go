@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// LoginThrottleRepository хранилище счетчиков неудачных попыток входа.
+// Состояние должно быть общим для всех экземпляров API.
+type LoginThrottleRepository interface {
+	GetLockedUntil(ctx context.Context, keys []string) (*time.Time, error)
+	RegisterFailure(ctx context.Context, key string, window time.Duration) (int, error)
+	Lock(ctx context.Context, key string, until time.Time) error
+	Reset(ctx context.Context, key string) error
+}
+
+// LoginThrottleRepositoryPgx реализация хранилища попыток входа в PostgreSQL
+type LoginThrottleRepositoryPgx struct {
+	pool *pgxpool.Pool
+}
+
+// NewLoginThrottleRepository создает новое хранилище попыток входа
+func NewLoginThrottleRepository(pool *pgxpool.Pool) LoginThrottleRepository {
+	return &LoginThrottleRepositoryPgx{pool: pool}
+}
+
+// GetLockedUntil возвращает самый поздний срок блокировки среди ключей, nil если блокировки нет
+func (r *LoginThrottleRepositoryPgx) GetLockedUntil(ctx context.Context, keys []string) (*time.Time, error) {
+	var until *time.Time
+
+	err := r.pool.QueryRow(ctx,
+		`SELECT MAX(locked_until)
+         FROM login_throttles
+         WHERE key = ANY($1) AND locked_until > CURRENT_TIMESTAMP`,
+		keys).Scan(&until)
+
+	return until, err
+}
+
+// RegisterFailure атомарно увеличивает счетчик неудачных попыток и возвращает его значение.
+// Если последняя ошибка была раньше окна window, счетчик начинается заново.
+func (r *LoginThrottleRepositoryPgx) RegisterFailure(ctx context.Context, key string, window time.Duration) (int, error) {
+	var failures int
+
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO login_throttles (key, failures, last_failure_at)
+         VALUES ($1, 1, CURRENT_TIMESTAMP)
+         ON CONFLICT (key) DO UPDATE
+         SET failures        = CASE
+                                   WHEN login_throttles.last_failure_at < CURRENT_TIMESTAMP - $2::interval THEN 1
+                                   ELSE login_throttles.failures + 1
+                               END,
+             last_failure_at = CURRENT_TIMESTAMP
+         RETURNING failures`,
+		key, window).Scan(&failures)
+
+	return failures, err
+}
+
+// Lock блокирует попытки входа по ключу до указанного момента
+func (r *LoginThrottleRepositoryPgx) Lock(ctx context.Context, key string, until time.Time) error {
+	_, err := r.pool.Exec(ctx,
+		`UPDATE login_throttles
+         SET locked_until = GREATEST(COALESCE(locked_until, $2), $2)
+         WHERE key = $1`,
+		key, until)
+	return err
+}
+
+// Reset сбрасывает счетчик попыток после успешного входа
+func (r *LoginThrottleRepositoryPgx) Reset(ctx context.Context, key string) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM login_throttles WHERE key = $1`, key)
+	return err
+}
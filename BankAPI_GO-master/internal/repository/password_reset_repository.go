@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/therealadik/bank-api/internal/models"
+)
+
+// ErrResetTokenNotFound возвращается, когда токен сброса пароля не найден, использован или истек
+var ErrResetTokenNotFound = errors.New("токен сброса пароля не найден")
+
+// PasswordResetRepository интерфейс для работы с токенами сброса пароля
+type PasswordResetRepository interface {
+	Create(ctx context.Context, token *models.PasswordResetToken) error
+	UseToken(ctx context.Context, tokenHash string) (int64, error)
+	InvalidateUserTokens(ctx context.Context, userID int64) error
+}
+
+// PasswordResetRepositoryPgx реализация репозитория токенов сброса пароля с использованием pgx
+type PasswordResetRepositoryPgx struct {
+	pool *pgxpool.Pool
+}
+
+// NewPasswordResetRepository создает новый репозиторий токенов сброса пароля
+func NewPasswordResetRepository(pool *pgxpool.Pool) PasswordResetRepository {
+	return &PasswordResetRepositoryPgx{pool: pool}
+}
+
+// Create сохраняет хеш токена сброса пароля
+func (r *PasswordResetRepositoryPgx) Create(ctx context.Context, token *models.PasswordResetToken) error {
+	return r.pool.QueryRow(ctx,
+		`INSERT INTO password_reset_tokens (user_id, token_hash, expires_at)
+         VALUES ($1, $2, $3)
+         RETURNING id, created_at`,
+		token.UserID, token.TokenHash, token.ExpiresAt).Scan(&token.ID, &token.CreatedAt)
+}
+
+// UseToken атомарно погашает неиспользованный и непросроченный токен и возвращает ID пользователя
+func (r *PasswordResetRepositoryPgx) UseToken(ctx context.Context, tokenHash string) (int64, error) {
+	var userID int64
+
+	err := r.pool.QueryRow(ctx,
+		`UPDATE password_reset_tokens
+         SET used_at = CURRENT_TIMESTAMP
+         WHERE token_hash = $1 AND used_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+         RETURNING user_id`,
+		tokenHash).Scan(&userID)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrResetTokenNotFound
+		}
+		return 0, err
+	}
+
+	return userID, nil
+}
+
+// InvalidateUserTokens погашает все действующие токены сброса пароля пользователя
+func (r *PasswordResetRepositoryPgx) InvalidateUserTokens(ctx context.Context, userID int64) error {
+	_, err := r.pool.Exec(ctx,
+		`UPDATE password_reset_tokens
+         SET used_at = CURRENT_TIMESTAMP
+         WHERE user_id = $1 AND used_at IS NULL`,
+		userID)
+	return err
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+	"github.com/therealadik/bank-api/internal/models/payment"
+)
+
+const paymentColumns = `id, card_id, account_id, transaction_id, initiator_id, amount, refunded_amount,
+		merchant_category, status, expires_at, created_at, updated_at`
+
+type PaymentRepository struct {
+	db DBTX
+}
+
+func NewPaymentRepository(db *pgxpool.Pool) *PaymentRepository {
+	return &PaymentRepository{db: db}
+}
+
+// WithTx возвращает копию репозитория, работающую внутри транзакции
+func (r *PaymentRepository) WithTx(tx pgx.Tx) *PaymentRepository {
+	return &PaymentRepository{db: tx}
+}
+
+// CreatePayment записывает платеж по карте
+func (r *PaymentRepository) CreatePayment(ctx context.Context, p *payment.CardPayment) (*payment.CardPayment, error) {
+	query := `
+		INSERT INTO card_payments (card_id, account_id, transaction_id, initiator_id, amount,
+		                           merchant_category, status, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING ` + paymentColumns
+	return scanPayment(r.db.QueryRow(ctx, query, p.CardID, p.AccountID, p.TransactionID, p.InitiatorID,
+		p.Amount, p.MerchantCategory, p.Status, p.ExpiresAt))
+}
+
+// GetPaymentByID получает платеж по ID
+func (r *PaymentRepository) GetPaymentByID(ctx context.Context, id int64) (*payment.CardPayment, error) {
+	query := `SELECT ` + paymentColumns + ` FROM card_payments WHERE id = $1`
+	return scanPayment(r.db.QueryRow(ctx, query, id))
+}
+
+// GetPaymentByIDForUpdate получает платеж по ID и блокирует его строку до конца транзакции
+func (r *PaymentRepository) GetPaymentByIDForUpdate(ctx context.Context, id int64) (*payment.CardPayment, error) {
+	query := `SELECT ` + paymentColumns + ` FROM card_payments WHERE id = $1 FOR UPDATE`
+	return scanPayment(r.db.QueryRow(ctx, query, id))
+}
+
+// Authorize переводит платеж в статус AUTHORIZED с новым сроком холда
+// (после одобрения платежа, задержанного антифрод-проверкой) и возвращает обновленный платеж
+func (r *PaymentRepository) Authorize(ctx context.Context, id int64, expiresAt time.Time) (*payment.CardPayment, error) {
+	query := `
+		UPDATE card_payments
+		SET status = $1, expires_at = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+		RETURNING ` + paymentColumns
+	return scanPayment(r.db.QueryRow(ctx, query, payment.AUTHORIZED, expiresAt, id))
+}
+
+// UpdatePayment обновляет сумму и статус платежа и возвращает обновленный платеж
+func (r *PaymentRepository) UpdatePayment(ctx context.Context, id int64, amount decimal.Decimal,
+	status payment.Status) (*payment.CardPayment, error) {
+	query := `
+		UPDATE card_payments
+		SET amount = $1, status = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+		RETURNING ` + paymentColumns
+	return scanPayment(r.db.QueryRow(ctx, query, amount, status, id))
+}
+
+// AddRefund увеличивает возвращенную по платежу сумму, обновляет статус
+// и возвращает обновленный платеж
+func (r *PaymentRepository) AddRefund(ctx context.Context, id int64, amount decimal.Decimal,
+	status payment.Status) (*payment.CardPayment, error) {
+	query := `
+		UPDATE card_payments
+		SET refunded_amount = refunded_amount + $1, status = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+		RETURNING ` + paymentColumns
+	return scanPayment(r.db.QueryRow(ctx, query, amount, status, id))
+}
+
+// SumPaymentsSince возвращает сумму действующих холдов (включая ожидающие
+// антифрод-проверки) и списаний по карте за вычетом возвратов начиная с указанного момента
+func (r *PaymentRepository) SumPaymentsSince(ctx context.Context, cardID int64, since time.Time) (decimal.Decimal, error) {
+	query := `
+		SELECT COALESCE(SUM(amount - refunded_amount), 0)
+		FROM card_payments
+		WHERE card_id = $1 AND status IN ($2, $3, $4, $5) AND created_at >= $6
+	`
+	var total decimal.Decimal
+	err := r.db.QueryRow(ctx, query, cardID, payment.AUTHORIZED, payment.PENDING_REVIEW, payment.CAPTURED,
+		payment.PARTIALLY_REFUNDED, since).Scan(&total)
+	return total, err
+}
+
+// GetExpiredHoldIDs возвращает ID холдов, срок действия которых истек
+func (r *PaymentRepository) GetExpiredHoldIDs(ctx context.Context, now time.Time, limit int) ([]int64, error) {
+	query := `
+		SELECT id
+		FROM card_payments
+		WHERE status = $1 AND expires_at < $2
+		ORDER BY expires_at
+		LIMIT $3
+	`
+	rows, err := r.db.Query(ctx, query, payment.AUTHORIZED, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// scanPayment сканирует строку платежа
+func scanPayment(row pgx.Row) (*payment.CardPayment, error) {
+	var p payment.CardPayment
+	err := row.Scan(
+		&p.ID, &p.CardID, &p.AccountID, &p.TransactionID, &p.InitiatorID, &p.Amount, &p.RefundedAmount,
+		&p.MerchantCategory, &p.Status, &p.ExpiresAt, &p.CreatedAt, &p.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
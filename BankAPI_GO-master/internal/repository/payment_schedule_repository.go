@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/therealadik/bank-api/internal/models"
+)
+
+type PaymentScheduleRepository struct {
+	db DBTX
+}
+
+func NewPaymentScheduleRepository(db *pgxpool.Pool) *PaymentScheduleRepository {
+	return &PaymentScheduleRepository{db: db}
+}
+
+// WithTx возвращает копию репозитория, работающую внутри транзакции
+func (r *PaymentScheduleRepository) WithTx(tx pgx.Tx) *PaymentScheduleRepository {
+	return &PaymentScheduleRepository{db: tx}
+}
+
+// ClaimOverdue отмечает до limit неоплаченных платежей со сроком раньше today, о просрочке
+// которых еще не сообщалось, и возвращает их. Строки, заблокированные другими экземплярами
+// сервиса, пропускаются; отметка фиксируется вместе с транзакцией вызывающего.
+func (r *PaymentScheduleRepository) ClaimOverdue(ctx context.Context, today time.Time,
+	limit int) ([]*models.OverdueInstallment, error) {
+	query := `
+		WITH due AS (
+			SELECT id
+			FROM payment_schedules
+			WHERE NOT paid AND overdue_notified_at IS NULL AND due_date < $1
+			ORDER BY due_date, id
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE payment_schedules ps
+		SET overdue_notified_at = CURRENT_TIMESTAMP
+		FROM due, credits c
+		WHERE ps.id = due.id AND c.id = ps.credit_id
+		RETURNING ps.id, ps.credit_id, c.account_id, ps.due_date, ps.amount`
+
+	rows, err := r.db.Query(ctx, query, today, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var installments []*models.OverdueInstallment
+	for rows.Next() {
+		var inst models.OverdueInstallment
+		if err := rows.Scan(&inst.ScheduleID, &inst.CreditID, &inst.AccountID, &inst.DueDate,
+			&inst.Amount); err != nil {
+			return nil, err
+		}
+		installments = append(installments, &inst)
+	}
+	return installments, rows.Err()
+}
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/therealadik/bank-api/internal/models/transfer"
+)
+
+const scheduledTransferColumns = `id, user_id, from_account_id, to_account_id, to_account_number, to_email, amount,
+		frequency, cron_expr, start_at, end_at, scheduled_at, next_run_at, attempts, status, last_error,
+		created_at, updated_at`
+
+type ScheduledTransferRepository struct {
+	db DBTX
+}
+
+func NewScheduledTransferRepository(db *pgxpool.Pool) *ScheduledTransferRepository {
+	return &ScheduledTransferRepository{db: db}
+}
+
+// WithTx возвращает копию репозитория, работающую внутри транзакции
+func (r *ScheduledTransferRepository) WithTx(tx pgx.Tx) *ScheduledTransferRepository {
+	return &ScheduledTransferRepository{db: tx}
+}
+
+// Create сохраняет регулярный перевод
+func (r *ScheduledTransferRepository) Create(ctx context.Context, st *transfer.Scheduled) (*transfer.Scheduled, error) {
+	query := `
+		INSERT INTO scheduled_transfers (user_id, from_account_id, to_account_id, to_account_number, to_email,
+		                                 amount, frequency, cron_expr, start_at, end_at, scheduled_at, next_run_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $11)
+		RETURNING ` + scheduledTransferColumns
+	return scanScheduledTransfer(r.db.QueryRow(ctx, query, st.UserID, st.FromAccountID, st.ToAccountID,
+		st.ToAccountNumber, st.ToEmail, st.Amount, st.Frequency, st.CronExpr, st.StartAt, st.EndAt, st.ScheduledAt))
+}
+
+// GetByID получает регулярный перевод по ID
+func (r *ScheduledTransferRepository) GetByID(ctx context.Context, id int64) (*transfer.Scheduled, error) {
+	query := `SELECT ` + scheduledTransferColumns + ` FROM scheduled_transfers WHERE id = $1`
+	return scanScheduledTransfer(r.db.QueryRow(ctx, query, id))
+}
+
+// GetByUserID получает регулярные переводы пользователя
+func (r *ScheduledTransferRepository) GetByUserID(ctx context.Context, userID int64) ([]*transfer.Scheduled, error) {
+	query := `SELECT ` + scheduledTransferColumns + ` FROM scheduled_transfers WHERE user_id = $1 ORDER BY id`
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transfers []*transfer.Scheduled
+	for rows.Next() {
+		st, err := scanScheduledTransfer(rows)
+		if err != nil {
+			return nil, err
+		}
+		transfers = append(transfers, st)
+	}
+	return transfers, rows.Err()
+}
+
+// LockNextDue блокирует один активный перевод, время исполнения которого наступило.
+// Строки, заблокированные другими экземплярами сервиса, пропускаются.
+func (r *ScheduledTransferRepository) LockNextDue(ctx context.Context, now time.Time) (*transfer.Scheduled, error) {
+	query := `
+		SELECT ` + scheduledTransferColumns + `
+		FROM scheduled_transfers
+		WHERE status = $1 AND next_run_at <= $2
+		ORDER BY next_run_at
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED`
+	return scanScheduledTransfer(r.db.QueryRow(ctx, query, transfer.ACTIVE, now))
+}
+
+// UpdateSchedule сохраняет состояние перевода после попытки исполнения
+func (r *ScheduledTransferRepository) UpdateSchedule(ctx context.Context, st *transfer.Scheduled) error {
+	query := `
+		UPDATE scheduled_transfers
+		SET scheduled_at = $1, next_run_at = $2, attempts = $3, status = $4, last_error = $5,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $6
+	`
+	_, err := r.db.Exec(ctx, query, st.ScheduledAt, st.NextRunAt, st.Attempts, st.Status, st.LastError, st.ID)
+	return err
+}
+
+// Cancel отменяет активный перевод пользователя. Возвращает pgx.ErrNoRows,
+// если перевод не найден, принадлежит другому пользователю или уже не активен.
+func (r *ScheduledTransferRepository) Cancel(ctx context.Context, id, userID int64) (*transfer.Scheduled, error) {
+	query := `
+		UPDATE scheduled_transfers
+		SET status = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2 AND user_id = $3 AND status = $4
+		RETURNING ` + scheduledTransferColumns
+	return scanScheduledTransfer(r.db.QueryRow(ctx, query, transfer.CANCELLED, id, userID, transfer.ACTIVE))
+}
+
+// CreateRun записывает результат попытки исполнения
+func (r *ScheduledTransferRepository) CreateRun(ctx context.Context, run *transfer.Run) error {
+	query := `
+		INSERT INTO scheduled_transfer_runs (scheduled_transfer_id, scheduled_at, attempt, status, error)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	_, err := r.db.Exec(ctx, query, run.ScheduledTransferID, run.ScheduledAt, run.Attempt, run.Status, run.Error)
+	return err
+}
+
+// GetRuns получает историю попыток исполнения перевода, начиная с последней
+func (r *ScheduledTransferRepository) GetRuns(ctx context.Context, scheduledTransferID int64) ([]*transfer.Run, error) {
+	query := `
+		SELECT id, scheduled_transfer_id, scheduled_at, attempt, status, error, created_at
+		FROM scheduled_transfer_runs
+		WHERE scheduled_transfer_id = $1
+		ORDER BY id DESC
+	`
+	rows, err := r.db.Query(ctx, query, scheduledTransferID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []*transfer.Run
+	for rows.Next() {
+		var run transfer.Run
+		if err := rows.Scan(&run.ID, &run.ScheduledTransferID, &run.ScheduledAt, &run.Attempt, &run.Status,
+			&run.Error, &run.CreatedAt); err != nil {
+			return nil, err
+		}
+		runs = append(runs, &run)
+	}
+	return runs, rows.Err()
+}
+
+// scanScheduledTransfer сканирует строку регулярного перевода
+func scanScheduledTransfer(row pgx.Row) (*transfer.Scheduled, error) {
+	var st transfer.Scheduled
+	err := row.Scan(&st.ID, &st.UserID, &st.FromAccountID, &st.ToAccountID, &st.ToAccountNumber, &st.ToEmail,
+		&st.Amount, &st.Frequency, &st.CronExpr, &st.StartAt, &st.EndAt, &st.ScheduledAt, &st.NextRunAt,
+		&st.Attempts, &st.Status, &st.LastError, &st.CreatedAt, &st.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
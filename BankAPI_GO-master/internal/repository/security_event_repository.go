@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/therealadik/bank-api/internal/models"
+)
+
+// SecurityEventRepository интерфейс для журнала событий безопасности
+type SecurityEventRepository interface {
+	Create(ctx context.Context, event *models.SecurityEvent) error
+}
+
+// SecurityEventRepositoryPgx реализация журнала событий безопасности с использованием pgx
+type SecurityEventRepositoryPgx struct {
+	pool *pgxpool.Pool
+}
+
+// NewSecurityEventRepository создает новый журнал событий безопасности
+func NewSecurityEventRepository(pool *pgxpool.Pool) SecurityEventRepository {
+	return &SecurityEventRepositoryPgx{pool: pool}
+}
+
+// Create записывает событие безопасности
+func (r *SecurityEventRepositoryPgx) Create(ctx context.Context, event *models.SecurityEvent) error {
+	return r.pool.QueryRow(ctx,
+		`INSERT INTO security_events (user_id, event_type, email, ip, details)
+         VALUES ($1, $2, $3, $4, $5)
+         RETURNING id, created_at`,
+		event.UserID, event.Type, event.Email, event.IP, event.Details).Scan(&event.ID, &event.CreatedAt)
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/therealadik/bank-api/internal/models"
+)
+
+// signingKeyRotationLock ключ advisory-блокировки, сериализующей ротацию ключей между экземплярами
+const signingKeyRotationLock = 730001
+
+type SigningKeyRepository struct {
+	db DBTX
+}
+
+func NewSigningKeyRepository(db *pgxpool.Pool) *SigningKeyRepository {
+	return &SigningKeyRepository{db: db}
+}
+
+// WithTx возвращает копию репозитория, работающую внутри транзакции
+func (r *SigningKeyRepository) WithTx(tx pgx.Tx) *SigningKeyRepository {
+	return &SigningKeyRepository{db: tx}
+}
+
+// LockRotation берет advisory-блокировку ротации до конца транзакции
+func (r *SigningKeyRepository) LockRotation(ctx context.Context) error {
+	_, err := r.db.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, signingKeyRotationLock)
+	return err
+}
+
+// CreateKey сохраняет ключ, шифруя закрытую часть PGP-ключом
+func (r *SigningKeyRepository) CreateKey(ctx context.Context, key *models.SigningKey, pgpKey string) error {
+	query := `
+		INSERT INTO jwt_signing_keys (kid, algorithm, private_key, public_key, expires_at)
+		VALUES ($1, $2, pgp_sym_encrypt($3, $4), $5, $6)
+		RETURNING created_at
+	`
+	return r.db.QueryRow(ctx, query, key.KID, key.Algorithm, key.PrivateKey, pgpKey, key.PublicKey,
+		key.ExpiresAt).Scan(&key.CreatedAt)
+}
+
+// GetLatestKey получает самый новый действующий ключ, nil если ключей нет
+func (r *SigningKeyRepository) GetLatestKey(ctx context.Context) (*models.SigningKey, error) {
+	query := `
+		SELECT kid, algorithm, public_key, created_at, expires_at
+		FROM jwt_signing_keys
+		WHERE expires_at > CURRENT_TIMESTAMP
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+	var key models.SigningKey
+	err := r.db.QueryRow(ctx, query).Scan(&key.KID, &key.Algorithm, &key.PublicKey, &key.CreatedAt, &key.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+// GetActiveKeys получает все действующие ключи с расшифрованной закрытой частью,
+// от самого нового к самому старому
+func (r *SigningKeyRepository) GetActiveKeys(ctx context.Context, pgpKey string) ([]*models.SigningKey, error) {
+	query := `
+		SELECT kid, algorithm, pgp_sym_decrypt(private_key, $1), public_key, created_at, expires_at
+		FROM jwt_signing_keys
+		WHERE expires_at > CURRENT_TIMESTAMP
+		ORDER BY created_at DESC
+	`
+	rows, err := r.db.Query(ctx, query, pgpKey)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []*models.SigningKey
+	for rows.Next() {
+		var key models.SigningKey
+		if err := rows.Scan(&key.KID, &key.Algorithm, &key.PrivateKey, &key.PublicKey, &key.CreatedAt,
+			&key.ExpiresAt); err != nil {
+			return nil, err
+		}
+		keys = append(keys, &key)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// DeleteExpiredKeys удаляет ключи, срок действия которых истек
+func (r *SigningKeyRepository) DeleteExpiredKeys(ctx context.Context) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM jwt_signing_keys WHERE expires_at <= CURRENT_TIMESTAMP`)
+	return err
+}
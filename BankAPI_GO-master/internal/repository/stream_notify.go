@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/therealadik/bank-api/internal/models/stream"
+)
+
+// notifyStream сообщает в канал stream.Channel об изменении, относящемся к счету accountID.
+// Владелец счета определяется в запросе. Внутри транзакции уведомление доставляется слушателям
+// только после ее фиксации, при откате — не доставляется.
+func notifyStream(ctx context.Context, db DBTX, eventType stream.EventType, accountID int64, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("ошибка кодирования уведомления %s: %w", eventType, err)
+	}
+
+	query := `
+		SELECT pg_notify($1, json_build_object('type', $2::text, 'user_id', user_id, 'data', $3::json)::text)
+		FROM accounts
+		WHERE id = $4
+	`
+	if _, err := db.Exec(ctx, query, stream.Channel, string(eventType), string(payload), accountID); err != nil {
+		return fmt.Errorf("ошибка отправки уведомления %s: %w", eventType, err)
+	}
+	return nil
+}
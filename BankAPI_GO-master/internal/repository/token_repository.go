@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/therealadik/bank-api/internal/models"
+)
+
+// ErrRefreshTokenNotFound возвращается, когда refresh-токен не найден
+var ErrRefreshTokenNotFound = errors.New("refresh-токен не найден")
+
+// TokenRepository интерфейс для работы с сессиями и отзывом токенов
+type TokenRepository interface {
+	WithTx(tx pgx.Tx) TokenRepository
+	CreateSession(ctx context.Context, session *models.Session) error
+	CreateRefreshToken(ctx context.Context, token *models.RefreshToken) error
+	UseRefreshToken(ctx context.Context, tokenHash string) (*models.Session, error)
+	GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error)
+	RevokeSession(ctx context.Context, sessionID string) error
+	RevokeUserSessions(ctx context.Context, userID int64) error
+	RevokeToken(ctx context.Context, jti string, userID int64, expiresAt time.Time) error
+	ConsumeToken(ctx context.Context, jti string, userID int64, expiresAt time.Time) (bool, error)
+	IsRevoked(ctx context.Context, jti string, sessionID string) (bool, error)
+}
+
+// TokenRepositoryPgx реализация репозитория токенов с использованием pgx
+type TokenRepositoryPgx struct {
+	db DBTX
+}
+
+// NewTokenRepository создает новый репозиторий токенов
+func NewTokenRepository(pool *pgxpool.Pool) TokenRepository {
+	return &TokenRepositoryPgx{db: pool}
+}
+
+// WithTx возвращает копию репозитория, работающую внутри транзакции
+func (r *TokenRepositoryPgx) WithTx(tx pgx.Tx) TokenRepository {
+	return &TokenRepositoryPgx{db: tx}
+}
+
+// CreateSession создает новую сессию
+func (r *TokenRepositoryPgx) CreateSession(ctx context.Context, session *models.Session) error {
+	return r.db.QueryRow(ctx,
+		`INSERT INTO sessions (id, user_id, expires_at)
+         VALUES ($1, $2, $3)
+         RETURNING created_at`,
+		session.ID, session.UserID, session.ExpiresAt).Scan(&session.CreatedAt)
+}
+
+// CreateRefreshToken сохраняет хеш refresh-токена
+func (r *TokenRepositoryPgx) CreateRefreshToken(ctx context.Context, token *models.RefreshToken) error {
+	return r.db.QueryRow(ctx,
+		`INSERT INTO refresh_tokens (session_id, token_hash, expires_at)
+         VALUES ($1, $2, $3)
+         RETURNING id, created_at`,
+		token.SessionID, token.TokenHash, token.ExpiresAt).Scan(&token.ID, &token.CreatedAt)
+}
+
+// UseRefreshToken атомарно помечает refresh-токен использованным и возвращает его сессию.
+// Токен должен быть неиспользованным и непросроченным, а сессия - действующей.
+func (r *TokenRepositoryPgx) UseRefreshToken(ctx context.Context, tokenHash string) (*models.Session, error) {
+	session := &models.Session{}
+
+	err := r.db.QueryRow(ctx,
+		`UPDATE refresh_tokens rt
+         SET used_at = CURRENT_TIMESTAMP
+         FROM sessions s
+         WHERE rt.session_id = s.id
+           AND rt.token_hash = $1
+           AND rt.used_at IS NULL
+           AND rt.expires_at > CURRENT_TIMESTAMP
+           AND s.revoked_at IS NULL
+           AND s.expires_at > CURRENT_TIMESTAMP
+         RETURNING s.id, s.user_id, s.expires_at, s.created_at`,
+		tokenHash).Scan(&session.ID, &session.UserID, &session.ExpiresAt, &session.CreatedAt)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrRefreshTokenNotFound
+		}
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// GetRefreshTokenByHash находит refresh-токен по хешу независимо от его состояния
+func (r *TokenRepositoryPgx) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	token := &models.RefreshToken{}
+
+	err := r.db.QueryRow(ctx,
+		`SELECT id, session_id, token_hash, expires_at, used_at, created_at
+         FROM refresh_tokens
+         WHERE token_hash = $1`,
+		tokenHash).Scan(&token.ID, &token.SessionID, &token.TokenHash, &token.ExpiresAt, &token.UsedAt, &token.CreatedAt)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrRefreshTokenNotFound
+		}
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// RevokeSession отзывает сессию вместе со всеми ее токенами
+func (r *TokenRepositoryPgx) RevokeSession(ctx context.Context, sessionID string) error {
+	_, err := r.db.Exec(ctx,
+		`UPDATE sessions
+         SET revoked_at = CURRENT_TIMESTAMP
+         WHERE id = $1 AND revoked_at IS NULL`,
+		sessionID)
+	return err
+}
+
+// RevokeUserSessions отзывает все сессии пользователя
+func (r *TokenRepositoryPgx) RevokeUserSessions(ctx context.Context, userID int64) error {
+	_, err := r.db.Exec(ctx,
+		`UPDATE sessions
+         SET revoked_at = CURRENT_TIMESTAMP
+         WHERE user_id = $1 AND revoked_at IS NULL`,
+		userID)
+	return err
+}
+
+// RevokeToken отзывает access-токен по его jti до истечения срока действия
+func (r *TokenRepositoryPgx) RevokeToken(ctx context.Context, jti string, userID int64, expiresAt time.Time) error {
+	_, err := r.db.Exec(ctx,
+		`INSERT INTO revoked_tokens (jti, user_id, expires_at)
+         VALUES ($1, $2, $3)
+         ON CONFLICT (jti) DO NOTHING`,
+		jti, userID, expiresAt)
+	return err
+}
+
+// ConsumeToken отзывает одноразовый токен и сообщает, был ли он отозван именно этим вызовом.
+// Проверка и отзыв выполняются одним запросом, поэтому из нескольких параллельных
+// предъявлений одного токена успешным будет только одно.
+func (r *TokenRepositoryPgx) ConsumeToken(ctx context.Context, jti string, userID int64, expiresAt time.Time) (bool, error) {
+	err := r.db.QueryRow(ctx,
+		`INSERT INTO revoked_tokens (jti, user_id, expires_at)
+         VALUES ($1, $2, $3)
+         ON CONFLICT (jti) DO NOTHING
+         RETURNING jti`,
+		jti, userID, expiresAt).Scan(&jti)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// IsRevoked проверяет, отозван ли access-токен или его сессия
+func (r *TokenRepositoryPgx) IsRevoked(ctx context.Context, jti string, sessionID string) (bool, error) {
+	var revoked bool
+
+	err := r.db.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1)
+             OR EXISTS (SELECT 1 FROM sessions WHERE id = $2 AND revoked_at IS NOT NULL)`,
+		jti, sessionID).Scan(&revoked)
+
+	return revoked, err
+}
@@ -3,41 +3,86 @@ package repository
 import (
 	"context"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/shopspring/decimal"
+	"github.com/therealadik/bank-api/internal/models/stream"
 	"github.com/therealadik/bank-api/internal/models/transaction"
 )
 
 type TransactionRepository struct {
-	db *pgxpool.Pool
+	db DBTX
 }
 
 func NewTransactionRepository(db *pgxpool.Pool) *TransactionRepository {
 	return &TransactionRepository{db: db}
 }
 
+// WithTx возвращает копию репозитория, работающую внутри транзакции
+func (r *TransactionRepository) WithTx(tx pgx.Tx) *TransactionRepository {
+	return &TransactionRepository{db: tx}
+}
+
 // CreateTransaction создает новую запись о транзакции
 func (r *TransactionRepository) CreateTransaction(ctx context.Context, accountID int64, amount decimal.Decimal,
 	txType transaction.Type, status transaction.Status) (*transaction.Transaction, error) {
+	return r.CreateLinkedTransaction(ctx, accountID, amount, txType, status, nil)
+}
+
+// CreateLinkedTransaction создает запись о транзакции со ссылкой на исходную транзакцию
+func (r *TransactionRepository) CreateLinkedTransaction(ctx context.Context, accountID int64, amount decimal.Decimal,
+	txType transaction.Type, status transaction.Status, originalID *int64) (*transaction.Transaction, error) {
+	query := `
+		INSERT INTO transactions (account_id, amount, type, status, original_transaction_id)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, account_id, amount, type, status, original_transaction_id, created_at
+	`
+	return r.saveTransaction(ctx, query, accountID, amount, txType, status, originalID)
+}
+
+// CreateTransferTransaction создает запись о транзакции перевода со ссылкой на счет второй стороны
+func (r *TransactionRepository) CreateTransferTransaction(ctx context.Context, accountID int64, amount decimal.Decimal,
+	txType transaction.Type, status transaction.Status, counterpartyID int64) (*transaction.Transaction, error) {
 	query := `
-		INSERT INTO transactions (account_id, amount, type, status)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id, account_id, amount, type, status, created_at
+		INSERT INTO transactions (account_id, amount, type, status, counterparty_account_id)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, account_id, amount, type, status, original_transaction_id, created_at
 	`
+	return r.saveTransaction(ctx, query, accountID, amount, txType, status, counterpartyID)
+}
+
+// UpdateTransaction обновляет сумму и статус транзакции и возвращает обновленную транзакцию
+func (r *TransactionRepository) UpdateTransaction(ctx context.Context, id int64, amount decimal.Decimal,
+	status transaction.Status) (*transaction.Transaction, error) {
+	query := `
+		UPDATE transactions
+		SET amount = $1, status = $2
+		WHERE id = $3
+		RETURNING id, account_id, amount, type, status, original_transaction_id, created_at
+	`
+	return r.saveTransaction(ctx, query, amount, status, id)
+}
+
+// saveTransaction выполняет запрос, создающий или изменяющий транзакцию и возвращающий ее строку,
+// и сообщает о транзакции в поток изменений
+func (r *TransactionRepository) saveTransaction(ctx context.Context, query string, args ...any) (*transaction.Transaction, error) {
 	var tx transaction.Transaction
-	err := r.db.QueryRow(ctx, query, accountID, amount, txType, status).Scan(
-		&tx.ID, &tx.AccountID, &tx.Amount, &tx.Type, &tx.Status, &tx.CreatedAt,
+	err := r.db.QueryRow(ctx, query, args...).Scan(
+		&tx.ID, &tx.AccountID, &tx.Amount, &tx.Type, &tx.Status, &tx.OriginalID, &tx.CreatedAt,
 	)
 	if err != nil {
 		return nil, err
 	}
+	if err := notifyStream(ctx, r.db, stream.TRANSACTION, tx.AccountID, &tx); err != nil {
+		return nil, err
+	}
 	return &tx, nil
 }
 
 // GetTransactionsByAccountID получает все транзакции для указанного счета
 func (r *TransactionRepository) GetTransactionsByAccountID(ctx context.Context, accountID int64) ([]*transaction.Transaction, error) {
 	query := `
-		SELECT id, account_id, amount, type, status, created_at
+		SELECT id, account_id, amount, type, status, original_transaction_id, created_at
 		FROM transactions
 		WHERE account_id = $1
 		ORDER BY created_at DESC
@@ -51,7 +96,7 @@ func (r *TransactionRepository) GetTransactionsByAccountID(ctx context.Context,
 	var transactions []*transaction.Transaction
 	for rows.Next() {
 		var tx transaction.Transaction
-		if err := rows.Scan(&tx.ID, &tx.AccountID, &tx.Amount, &tx.Type, &tx.Status, &tx.CreatedAt); err != nil {
+		if err := rows.Scan(&tx.ID, &tx.AccountID, &tx.Amount, &tx.Type, &tx.Status, &tx.OriginalID, &tx.CreatedAt); err != nil {
 			return nil, err
 		}
 		transactions = append(transactions, &tx)
@@ -66,7 +111,7 @@ func (r *TransactionRepository) GetTransactionsByAccountID(ctx context.Context,
 // GetTransactionsByUserID получает все транзакции для всех счетов пользователя
 func (r *TransactionRepository) GetTransactionsByUserID(ctx context.Context, userID int64) ([]*transaction.Transaction, error) {
 	query := `
-		SELECT t.id, t.account_id, t.amount, t.type, t.status, t.created_at
+		SELECT t.id, t.account_id, t.amount, t.type, t.status, t.original_transaction_id, t.created_at
 		FROM transactions t
 		JOIN accounts a ON t.account_id = a.id
 		WHERE a.user_id = $1
@@ -81,7 +126,7 @@ func (r *TransactionRepository) GetTransactionsByUserID(ctx context.Context, use
 	var transactions []*transaction.Transaction
 	for rows.Next() {
 		var tx transaction.Transaction
-		if err := rows.Scan(&tx.ID, &tx.AccountID, &tx.Amount, &tx.Type, &tx.Status, &tx.CreatedAt); err != nil {
+		if err := rows.Scan(&tx.ID, &tx.AccountID, &tx.Amount, &tx.Type, &tx.Status, &tx.OriginalID, &tx.CreatedAt); err != nil {
 			return nil, err
 		}
 		transactions = append(transactions, &tx)
@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+	"github.com/therealadik/bank-api/internal/models/transaction"
+	"github.com/therealadik/bank-api/internal/models/transfer"
+)
+
+// outgoingFilter отбирает исходящие операции, на которые действуют лимиты переводов:
+// исходящие переводы и списания со счета, кроме платежей картой (у карт свои лимиты)
+const outgoingFilter = `
+		t.type IN ($2, $3) AND t.status <> $4 AND t.created_at >= $5
+		AND NOT EXISTS (SELECT 1 FROM card_payments cp WHERE cp.transaction_id = t.id)`
+
+type TransferLimitRepository struct {
+	db DBTX
+}
+
+func NewTransferLimitRepository(db *pgxpool.Pool) *TransferLimitRepository {
+	return &TransferLimitRepository{db: db}
+}
+
+// WithTx возвращает копию репозитория, работающую внутри транзакции
+func (r *TransferLimitRepository) WithTx(tx pgx.Tx) *TransferLimitRepository {
+	return &TransferLimitRepository{db: tx}
+}
+
+// LockUser блокирует строку пользователя до конца транзакции, чтобы параллельные
+// операции с разных счетов пользователя не превысили общий лимит
+func (r *TransferLimitRepository) LockUser(ctx context.Context, userID int64) error {
+	var id int64
+	return r.db.QueryRow(ctx, `SELECT id FROM users WHERE id = $1 FOR NO KEY UPDATE`, userID).Scan(&id)
+}
+
+// GetOverride получает индивидуальные лимиты пользователя или счета; nil, если они не заданы
+func (r *TransferLimitRepository) GetOverride(ctx context.Context, scope transfer.LimitScope, ownerID int64) (*transfer.LimitOverride, error) {
+	query := `
+		SELECT single_max, daily_max, monthly_max, hourly_count, updated_by, updated_at
+		FROM transfer_limits
+		WHERE ` + scopeColumn(scope) + ` = $1`
+
+	o := transfer.LimitOverride{Scope: scope, OwnerID: ownerID}
+	err := r.db.QueryRow(ctx, query, ownerID).Scan(&o.SingleMax, &o.DailyMax, &o.MonthlyMax, &o.HourlyCount,
+		&o.UpdatedBy, &o.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &o, nil
+}
+
+// SetOverride сохраняет индивидуальные лимиты пользователя или счета
+func (r *TransferLimitRepository) SetOverride(ctx context.Context, o *transfer.LimitOverride) (*transfer.LimitOverride, error) {
+	column := scopeColumn(o.Scope)
+	query := `
+		INSERT INTO transfer_limits (` + column + `, single_max, daily_max, monthly_max, hourly_count, updated_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (` + column + `) DO UPDATE
+		SET single_max   = EXCLUDED.single_max,
+		    daily_max    = EXCLUDED.daily_max,
+		    monthly_max  = EXCLUDED.monthly_max,
+		    hourly_count = EXCLUDED.hourly_count,
+		    updated_by   = EXCLUDED.updated_by,
+		    updated_at   = CURRENT_TIMESTAMP
+		RETURNING updated_at`
+
+	saved := *o
+	err := r.db.QueryRow(ctx, query, o.OwnerID, o.SingleMax, o.DailyMax, o.MonthlyMax, o.HourlyCount,
+		o.UpdatedBy).Scan(&saved.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &saved, nil
+}
+
+// SumOutgoingSince возвращает сумму исходящих операций пользователя или счета начиная с since
+func (r *TransferLimitRepository) SumOutgoingSince(ctx context.Context, scope transfer.LimitScope, ownerID int64,
+	since time.Time) (decimal.Decimal, error) {
+	query := `
+		SELECT COALESCE(SUM(t.amount), 0)
+		FROM transactions t
+		JOIN accounts a ON a.id = t.account_id
+		WHERE ` + scopeFilter(scope) + ` AND` + outgoingFilter
+
+	var sum decimal.Decimal
+	err := r.db.QueryRow(ctx, query, ownerID, transaction.TRANSFER, transaction.WITHDRAWAL, transaction.FAILED,
+		since).Scan(&sum)
+	return sum, err
+}
+
+// ListOutgoingTimesSince возвращает моменты исходящих операций начиная с since по возрастанию
+func (r *TransferLimitRepository) ListOutgoingTimesSince(ctx context.Context, scope transfer.LimitScope, ownerID int64,
+	since time.Time) ([]time.Time, error) {
+	query := `
+		SELECT t.created_at
+		FROM transactions t
+		JOIN accounts a ON a.id = t.account_id
+		WHERE ` + scopeFilter(scope) + ` AND` + outgoingFilter + `
+		ORDER BY t.created_at`
+
+	rows, err := r.db.Query(ctx, query, ownerID, transaction.TRANSFER, transaction.WITHDRAWAL, transaction.FAILED, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var times []time.Time
+	for rows.Next() {
+		var t time.Time
+		if err := rows.Scan(&t); err != nil {
+			return nil, err
+		}
+		times = append(times, t)
+	}
+	return times, rows.Err()
+}
+
+// RecordPreview записывает запрос предпросмотра получателя и удаляет записи пользователя старше before
+func (r *TransferLimitRepository) RecordPreview(ctx context.Context, userID int64, before time.Time) error {
+	if _, err := r.db.Exec(ctx, `DELETE FROM transfer_previews WHERE user_id = $1 AND created_at < $2`,
+		userID, before); err != nil {
+		return err
+	}
+	_, err := r.db.Exec(ctx, `INSERT INTO transfer_previews (user_id) VALUES ($1)`, userID)
+	return err
+}
+
+// CountPreviewsSince возвращает число запросов предпросмотра пользователя начиная с since
+// и момент самого раннего из них; nil, если запросов не было
+func (r *TransferLimitRepository) CountPreviewsSince(ctx context.Context, userID int64, since time.Time) (int, *time.Time, error) {
+	query := `
+		SELECT COUNT(*), MIN(created_at)
+		FROM transfer_previews
+		WHERE user_id = $1 AND created_at >= $2
+	`
+	var count int
+	var oldest *time.Time
+	err := r.db.QueryRow(ctx, query, userID, since).Scan(&count, &oldest)
+	return count, oldest, err
+}
+
+// scopeColumn возвращает колонку transfer_limits, соответствующую уровню лимита
+func scopeColumn(scope transfer.LimitScope) string {
+	if scope == transfer.SCOPE_ACCOUNT {
+		return "account_id"
+	}
+	return "user_id"
+}
+
+// scopeFilter возвращает условие отбора операций пользователя или счета
+func scopeFilter(scope transfer.LimitScope) string {
+	if scope == transfer.SCOPE_ACCOUNT {
+		return "t.account_id = $1"
+	}
+	return "a.user_id = $1"
+}
@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/therealadik/bank-api/internal/models"
+)
+
+// TwoFactorRepository интерфейс для работы с настройками TOTP и кодами восстановления
+type TwoFactorRepository interface {
+	GetTwoFactor(ctx context.Context, userID int64, pgpKey string) (*models.TwoFactor, error)
+	SetPendingSecret(ctx context.Context, userID int64, secret, pgpKey string) error
+	Enable(ctx context.Context, userID int64, codeHashes []string) error
+	Disable(ctx context.Context, userID int64) error
+	UseStep(ctx context.Context, userID int64, step int64) (bool, error)
+	ReplaceRecoveryCodes(ctx context.Context, userID int64, codeHashes []string) error
+	UseRecoveryCode(ctx context.Context, userID int64, codeHash string) (bool, error)
+}
+
+// TwoFactorRepositoryPgx реализация репозитория 2FA с использованием pgx
+type TwoFactorRepositoryPgx struct {
+	pool *pgxpool.Pool
+}
+
+// NewTwoFactorRepository создает новый репозиторий 2FA
+func NewTwoFactorRepository(pool *pgxpool.Pool) TwoFactorRepository {
+	return &TwoFactorRepositoryPgx{pool: pool}
+}
+
+// GetTwoFactor получает настройки TOTP пользователя с расшифрованным секретом
+func (r *TwoFactorRepositoryPgx) GetTwoFactor(ctx context.Context, userID int64, pgpKey string) (*models.TwoFactor, error) {
+	tf := &models.TwoFactor{}
+
+	err := r.pool.QueryRow(ctx,
+		`SELECT id, COALESCE(pgp_sym_decrypt(totp_secret, $2), ''), totp_enabled, totp_last_step
+         FROM users
+         WHERE id = $1`,
+		userID, pgpKey).Scan(&tf.UserID, &tf.Secret, &tf.Enabled, &tf.LastStep)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	return tf, nil
+}
+
+// SetPendingSecret сохраняет новый, еще не подтвержденный секрет TOTP.
+// Секрет не перезаписывается, если 2FA уже включена.
+func (r *TwoFactorRepositoryPgx) SetPendingSecret(ctx context.Context, userID int64, secret, pgpKey string) error {
+	tag, err := r.pool.Exec(ctx,
+		`UPDATE users
+         SET totp_secret = pgp_sym_encrypt($2, $3), totp_last_step = NULL
+         WHERE id = $1 AND NOT totp_enabled`,
+		userID, secret, pgpKey)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// Enable включает 2FA и сохраняет новые коды восстановления
+func (r *TwoFactorRepositoryPgx) Enable(ctx context.Context, userID int64, codeHashes []string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx,
+		`UPDATE users SET totp_enabled = TRUE WHERE id = $1`, userID); err != nil {
+		return err
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Disable отключает 2FA, удаляя секрет и коды восстановления
+func (r *TwoFactorRepositoryPgx) Disable(ctx context.Context, userID int64) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx,
+		`UPDATE users
+         SET totp_secret = NULL, totp_enabled = FALSE, totp_last_step = NULL
+         WHERE id = $1`, userID); err != nil {
+		return err
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, nil); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// UseStep атомарно запоминает принятый временной шаг TOTP.
+// Возвращает false, если код этого или более позднего шага уже использовался.
+func (r *TwoFactorRepositoryPgx) UseStep(ctx context.Context, userID int64, step int64) (bool, error) {
+	tag, err := r.pool.Exec(ctx,
+		`UPDATE users
+         SET totp_last_step = $2
+         WHERE id = $1 AND (totp_last_step IS NULL OR totp_last_step < $2)`,
+		userID, step)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// ReplaceRecoveryCodes заменяет все коды восстановления пользователя новыми
+func (r *TwoFactorRepositoryPgx) ReplaceRecoveryCodes(ctx context.Context, userID int64, codeHashes []string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// UseRecoveryCode атомарно погашает код восстановления. Возвращает false, если
+// код не найден или уже использован.
+func (r *TwoFactorRepositoryPgx) UseRecoveryCode(ctx context.Context, userID int64, codeHash string) (bool, error) {
+	tag, err := r.pool.Exec(ctx,
+		`UPDATE recovery_codes
+         SET used_at = CURRENT_TIMESTAMP
+         WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`,
+		userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// replaceRecoveryCodes удаляет старые коды восстановления и сохраняет новые внутри транзакции
+func replaceRecoveryCodes(ctx context.Context, tx pgx.Tx, userID int64, codeHashes []string) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	for _, hash := range codeHashes {
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO recovery_codes (user_id, code_hash) VALUES ($1, $2)`,
+			userID, hash); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -12,32 +13,52 @@ import (
 // ErrUserNotFound возвращается, когда пользователь не найден
 var ErrUserNotFound = errors.New("пользователь не найден")
 
+// Имена ограничений уникальности таблицы users
+const (
+	UsersEmailKey    = "users_email_key"
+	UsersUsernameKey = "users_username_key"
+)
+
+const userColumns = `id, email, username, password_hash, role, totp_enabled, email_verified_at, created_at, updated_at`
+
 // UserRepository интерфейс для работы с пользователями
 type UserRepository interface {
+	WithTx(tx pgx.Tx) UserRepository
 	Create(ctx context.Context, user *models.User) (int64, error)
 	GetByEmail(ctx context.Context, email string) (*models.User, error)
 	GetByID(ctx context.Context, id int64) (*models.User, error)
+	GetByUsername(ctx context.Context, username string) (*models.User, error)
+	UpdateRole(ctx context.Context, userID int64, role models.Role) error
+	UpdateProfile(ctx context.Context, user *models.User) error
+	UpdatePassword(ctx context.Context, userID int64, passwordHash string) error
+	MarkEmailVerified(ctx context.Context, userID int64, email string) (bool, error)
+	ClaimVerificationSend(ctx context.Context, userID int64, cooldown time.Duration) (bool, error)
 }
 
 // UserRepositoryPgx реализация репозитория пользователей с использованием pgx
 type UserRepositoryPgx struct {
-	pool *pgxpool.Pool
+	db DBTX
 }
 
 // NewUserRepository создает новый репозиторий пользователей
 func NewUserRepository(pool *pgxpool.Pool) UserRepository {
-	return &UserRepositoryPgx{pool: pool}
+	return &UserRepositoryPgx{db: pool}
+}
+
+// WithTx возвращает копию репозитория, работающую внутри транзакции
+func (r *UserRepositoryPgx) WithTx(tx pgx.Tx) UserRepository {
+	return &UserRepositoryPgx{db: tx}
 }
 
 // Create создает нового пользователя
 func (r *UserRepositoryPgx) Create(ctx context.Context, user *models.User) (int64, error) {
 	var id int64
 
-	err := r.pool.QueryRow(ctx,
-		`INSERT INTO users (email, password_hash) 
-         VALUES ($1, $2) 
+	err := r.db.QueryRow(ctx,
+		`INSERT INTO users (email, username, password_hash) 
+         VALUES ($1, $2, $3) 
          RETURNING id`,
-		user.Email, user.Password).Scan(&id)
+		user.Email, user.Username, user.Password).Scan(&id)
 
 	if err != nil {
 		return 0, err
@@ -46,37 +67,126 @@ func (r *UserRepositoryPgx) Create(ctx context.Context, user *models.User) (int6
 	return id, nil
 }
 
-// GetByEmail находит пользователя по email
+// GetByEmail находит пользователя по email без учета регистра
 func (r *UserRepositoryPgx) GetByEmail(ctx context.Context, email string) (*models.User, error) {
-	user := &models.User{}
+	return scanUser(r.db.QueryRow(ctx,
+		`SELECT `+userColumns+`
+         FROM users 
+         WHERE LOWER(email) = LOWER($1)`,
+		email))
+}
+
+// GetByID находит пользователя по ID
+func (r *UserRepositoryPgx) GetByID(ctx context.Context, id int64) (*models.User, error) {
+	return scanUser(r.db.QueryRow(ctx,
+		`SELECT `+userColumns+`
+         FROM users 
+         WHERE id = $1`,
+		id))
+}
 
-	err := r.pool.QueryRow(ctx,
-		`SELECT id, email, password_hash, created_at 
+// GetByUsername находит пользователя по username без учета регистра
+func (r *UserRepositoryPgx) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	return scanUser(r.db.QueryRow(ctx,
+		`SELECT `+userColumns+`
          FROM users 
-         WHERE email = $1`,
-		email).Scan(&user.ID, &user.Email, &user.Password, &user.CreatedAt)
+         WHERE LOWER(username) = LOWER($1)`,
+		username))
+}
 
+// UpdateRole изменяет роль пользователя
+func (r *UserRepositoryPgx) UpdateRole(ctx context.Context, userID int64, role models.Role) error {
+	tag, err := r.db.Exec(ctx,
+		`UPDATE users
+         SET role = $1, updated_at = CURRENT_TIMESTAMP
+         WHERE id = $2`,
+		role, userID)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, ErrUserNotFound
-		}
-		return nil, err
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrUserNotFound
 	}
+	return nil
+}
 
-	return user, nil
+// UpdateProfile сохраняет изменяемые пользователем поля профиля.
+// При смене email подтверждение сбрасывается.
+func (r *UserRepositoryPgx) UpdateProfile(ctx context.Context, user *models.User) error {
+	err := r.db.QueryRow(ctx,
+		`UPDATE users
+         SET email_verified_at          = CASE WHEN LOWER(email) = LOWER($1) THEN email_verified_at END,
+             email_verification_sent_at = CASE WHEN LOWER(email) = LOWER($1) THEN email_verification_sent_at END,
+             email                      = $1,
+             username                   = $2,
+             updated_at                 = CURRENT_TIMESTAMP
+         WHERE id = $3
+         RETURNING email_verified_at, updated_at`,
+		user.Email, user.Username, user.ID).Scan(&user.EmailVerifiedAt, &user.UpdatedAt)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrUserNotFound
+	}
+	return err
 }
 
-// GetByID находит пользователя по ID
-func (r *UserRepositoryPgx) GetByID(ctx context.Context, id int64) (*models.User, error) {
+// UpdatePassword сохраняет новый хеш пароля
+func (r *UserRepositoryPgx) UpdatePassword(ctx context.Context, userID int64, passwordHash string) error {
+	tag, err := r.db.Exec(ctx,
+		`UPDATE users
+         SET password_hash = $1, updated_at = CURRENT_TIMESTAMP
+         WHERE id = $2`,
+		passwordHash, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// MarkEmailVerified подтверждает email, если он не изменился с момента отправки ссылки.
+// Возвращает false, если email уже подтвержден или не совпадает.
+func (r *UserRepositoryPgx) MarkEmailVerified(ctx context.Context, userID int64, email string) (bool, error) {
+	tag, err := r.db.Exec(ctx,
+		`UPDATE users
+         SET email_verified_at = CURRENT_TIMESTAMP
+         WHERE id = $1 AND LOWER(email) = LOWER($2) AND email_verified_at IS NULL`,
+		userID, email)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// ClaimVerificationSend атомарно отмечает отправку письма подтверждения, если с прошлой
+// отправки прошло не меньше cooldown. Возвращает false, если отправлять рано.
+func (r *UserRepositoryPgx) ClaimVerificationSend(ctx context.Context, userID int64, cooldown time.Duration) (bool, error) {
+	tag, err := r.db.Exec(ctx,
+		`UPDATE users
+         SET email_verification_sent_at = CURRENT_TIMESTAMP
+         WHERE id = $1
+           AND (email_verification_sent_at IS NULL
+                OR email_verification_sent_at <= CURRENT_TIMESTAMP - $2::interval)`,
+		userID, cooldown)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// scanUser сканирует строку пользователя
+func scanUser(row pgx.Row) (*models.User, error) {
 	user := &models.User{}
 
-	err := r.pool.QueryRow(ctx,
-		`SELECT id, email, password_hash, created_at 
-         FROM users 
-         WHERE id = $1`,
-		id).Scan(&user.ID, &user.Email, &user.Password, &user.CreatedAt)
+	err := row.Scan(&user.ID, &user.Email, &user.Username, &user.Password, &user.Role, &user.TwoFactorEnabled,
+		&user.EmailVerifiedAt, &user.CreatedAt, &user.UpdatedAt)
 
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
 		return nil, err
 	}
 
@@ -0,0 +1,253 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/therealadik/bank-api/internal/models/webhook"
+)
+
+const webhookColumns = `id, user_id, url, events, created_at`
+
+const webhookDeliveryColumns = `id, webhook_id, event_id, event_type, payload, status, attempts, next_attempt_at,
+		last_status_code, last_error, created_at, delivered_at`
+
+type WebhookRepository struct {
+	db DBTX
+}
+
+func NewWebhookRepository(db *pgxpool.Pool) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+// WithTx возвращает копию репозитория, работающую внутри транзакции
+func (r *WebhookRepository) WithTx(tx pgx.Tx) *WebhookRepository {
+	return &WebhookRepository{db: tx}
+}
+
+// Create сохраняет вебхук; секрет шифруется ключом pgpKey
+func (r *WebhookRepository) Create(ctx context.Context, wh *webhook.Webhook, pgpKey string) (*webhook.Webhook, error) {
+	query := `
+		INSERT INTO webhooks (user_id, url, secret, events)
+		VALUES ($1, $2, pgp_sym_encrypt($3, $4), $5)
+		RETURNING ` + webhookColumns
+	return scanWebhook(r.db.QueryRow(ctx, query, wh.UserID, wh.URL, wh.Secret, pgpKey, wh.Events))
+}
+
+// GetByID получает вебхук по ID (без секрета)
+func (r *WebhookRepository) GetByID(ctx context.Context, id int64) (*webhook.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE id = $1`
+	return scanWebhook(r.db.QueryRow(ctx, query, id))
+}
+
+// GetByIDWithSecret получает вебхук по ID с расшифрованным секретом подписи
+func (r *WebhookRepository) GetByIDWithSecret(ctx context.Context, id int64, pgpKey string) (*webhook.Webhook, error) {
+	query := `
+		SELECT ` + webhookColumns + `, pgp_sym_decrypt(secret, $2)
+		FROM webhooks
+		WHERE id = $1`
+
+	var wh webhook.Webhook
+	err := r.db.QueryRow(ctx, query, id, pgpKey).Scan(&wh.ID, &wh.UserID, &wh.URL, &wh.Events, &wh.CreatedAt,
+		&wh.Secret)
+	if err != nil {
+		return nil, err
+	}
+	return &wh, nil
+}
+
+// GetByUserID получает вебхуки пользователя
+func (r *WebhookRepository) GetByUserID(ctx context.Context, userID int64) ([]*webhook.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE user_id = $1 ORDER BY id`
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var webhooks []*webhook.Webhook
+	for rows.Next() {
+		wh, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, wh)
+	}
+	return webhooks, rows.Err()
+}
+
+// CountByUserID возвращает число вебхуков пользователя
+func (r *WebhookRepository) CountByUserID(ctx context.Context, userID int64) (int, error) {
+	var count int
+	err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM webhooks WHERE user_id = $1`, userID).Scan(&count)
+	return count, err
+}
+
+// Delete удаляет вебхук пользователя вместе с историей доставок. Возвращает pgx.ErrNoRows,
+// если вебхук не найден или принадлежит другому пользователю.
+func (r *WebhookRepository) Delete(ctx context.Context, id, userID int64) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM webhooks WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// EnqueueForAccount ставит событие в очередь доставки для всех вебхуков владельца счета,
+// подписанных на этот тип события. Возвращает число созданных доставок.
+func (r *WebhookRepository) EnqueueForAccount(ctx context.Context, accountID int64, event webhook.Event,
+	eventID, payload string) (int64, error) {
+	query := `
+		INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload)
+		SELECT w.id, $2, $3, $4
+		FROM webhooks w
+		JOIN accounts a ON a.user_id = w.user_id
+		WHERE a.id = $1 AND $3 = ANY (w.events)
+	`
+	tag, err := r.db.Exec(ctx, query, accountID, eventID, event, payload)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// ClaimDue выбирает доставки, время попытки которых наступило, и переносит их следующую
+// попытку на leaseUntil, чтобы другие экземпляры сервиса не отправили их одновременно.
+// Если экземпляр остановится во время отправки, доставка будет повторена после leaseUntil.
+func (r *WebhookRepository) ClaimDue(ctx context.Context, now, leaseUntil time.Time,
+	limit int) ([]*webhook.Delivery, error) {
+	query := `
+		UPDATE webhook_deliveries
+		SET next_attempt_at = $3
+		WHERE id IN (
+			SELECT id
+			FROM webhook_deliveries
+			WHERE status = $1 AND next_attempt_at <= $2
+			ORDER BY next_attempt_at
+			LIMIT $4
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + webhookDeliveryColumns
+	return r.queryDeliveries(ctx, query, webhook.PENDING, now, leaseUntil, limit)
+}
+
+// GetDeliveryByID получает доставку по ID
+func (r *WebhookRepository) GetDeliveryByID(ctx context.Context, id int64) (*webhook.Delivery, error) {
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries WHERE id = $1`
+	return scanWebhookDelivery(r.db.QueryRow(ctx, query, id))
+}
+
+// GetDeliveries получает последние доставки вебхука, начиная с последней
+func (r *WebhookRepository) GetDeliveries(ctx context.Context, webhookID int64, limit int) ([]*webhook.Delivery, error) {
+	query := `
+		SELECT ` + webhookDeliveryColumns + `
+		FROM webhook_deliveries
+		WHERE webhook_id = $1
+		ORDER BY id DESC
+		LIMIT $2`
+	return r.queryDeliveries(ctx, query, webhookID, limit)
+}
+
+// UpdateDelivery сохраняет состояние доставки после попытки
+func (r *WebhookRepository) UpdateDelivery(ctx context.Context, d *webhook.Delivery) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $1, attempts = $2, next_attempt_at = $3, last_status_code = $4, last_error = $5,
+		    delivered_at = $6
+		WHERE id = $7
+	`
+	_, err := r.db.Exec(ctx, query, d.Status, d.Attempts, d.NextAttemptAt, d.LastStatusCode, d.LastError,
+		d.DeliveredAt, d.ID)
+	return err
+}
+
+// Redeliver создает новую доставку того же события с тем же телом запроса
+func (r *WebhookRepository) Redeliver(ctx context.Context, id int64) (*webhook.Delivery, error) {
+	query := `
+		INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload)
+		SELECT webhook_id, event_id, event_type, payload
+		FROM webhook_deliveries
+		WHERE id = $1
+		RETURNING ` + webhookDeliveryColumns
+	return scanWebhookDelivery(r.db.QueryRow(ctx, query, id))
+}
+
+// CreateAttempt записывает результат попытки доставки
+func (r *WebhookRepository) CreateAttempt(ctx context.Context, a *webhook.Attempt) error {
+	query := `
+		INSERT INTO webhook_delivery_attempts (delivery_id, attempt, status_code, error, duration_ms)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	_, err := r.db.Exec(ctx, query, a.DeliveryID, a.Attempt, a.StatusCode, a.Error, a.DurationMs)
+	return err
+}
+
+// GetAttempts получает попытки доставки, начиная с последней
+func (r *WebhookRepository) GetAttempts(ctx context.Context, deliveryID int64) ([]*webhook.Attempt, error) {
+	query := `
+		SELECT id, delivery_id, attempt, status_code, error, duration_ms, created_at
+		FROM webhook_delivery_attempts
+		WHERE delivery_id = $1
+		ORDER BY id DESC
+	`
+	rows, err := r.db.Query(ctx, query, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var attempts []*webhook.Attempt
+	for rows.Next() {
+		var a webhook.Attempt
+		if err := rows.Scan(&a.ID, &a.DeliveryID, &a.Attempt, &a.StatusCode, &a.Error, &a.DurationMs,
+			&a.CreatedAt); err != nil {
+			return nil, err
+		}
+		attempts = append(attempts, &a)
+	}
+	return attempts, rows.Err()
+}
+
+// queryDeliveries выполняет запрос, возвращающий строки доставок
+func (r *WebhookRepository) queryDeliveries(ctx context.Context, query string, args ...any) ([]*webhook.Delivery, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []*webhook.Delivery
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+// scanWebhook сканирует строку вебхука
+func scanWebhook(row pgx.Row) (*webhook.Webhook, error) {
+	var wh webhook.Webhook
+	if err := row.Scan(&wh.ID, &wh.UserID, &wh.URL, &wh.Events, &wh.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &wh, nil
+}
+
+// scanWebhookDelivery сканирует строку доставки
+func scanWebhookDelivery(row pgx.Row) (*webhook.Delivery, error) {
+	var d webhook.Delivery
+	err := row.Scan(&d.ID, &d.WebhookID, &d.EventID, &d.EventType, &d.Payload, &d.Status, &d.Attempts,
+		&d.NextAttemptAt, &d.LastStatusCode, &d.LastError, &d.CreatedAt, &d.DeliveredAt)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
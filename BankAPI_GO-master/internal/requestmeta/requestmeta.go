@@ -0,0 +1,39 @@
+// Package requestmeta хранит в контексте сведения о HTTP-запросе,
+// которые нужны сервисам (например, для журнала аудита)
+package requestmeta
+
+import (
+	"context"
+	"net"
+	"net/http"
+)
+
+type contextKey struct{}
+
+// Meta сведения о запросе
+type Meta struct {
+	RequestID string
+	IP        string
+	UserAgent string
+}
+
+// NewContext возвращает контекст со сведениями о запросе
+func NewContext(ctx context.Context, meta Meta) context.Context {
+	return context.WithValue(ctx, contextKey{}, meta)
+}
+
+// FromContext извлекает сведения о запросе; вне HTTP-запроса возвращает пустые значения
+func FromContext(ctx context.Context) Meta {
+	meta, _ := ctx.Value(contextKey{}).(Meta)
+	return meta
+}
+
+// ClientIP возвращает IP-адрес клиента из адреса соединения. Заголовки вроде
+// X-Forwarded-For не используются: клиент может подставить в них любой адрес.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,179 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/therealadik/bank-api/internal/handler"
+	"github.com/therealadik/bank-api/internal/middleware"
+	"github.com/therealadik/bank-api/internal/models"
+)
+
+// Handlers обработчики маршрутов API
+type Handlers struct {
+	Auth              *handler.AuthHandler
+	Account           *handler.AccountHandler
+	TransferLimit     *handler.TransferLimitHandler
+	ScheduledTransfer *handler.ScheduledTransferHandler
+	TwoFactor         *handler.TwoFactorHandler
+	User              *handler.UserHandler
+	Password          *handler.PasswordHandler
+	EmailVerification *handler.EmailVerificationHandler
+	Card              *handler.CardHandler
+	Dispute           *handler.DisputeHandler
+	JWKS              *handler.JWKSHandler
+	Admin             *handler.AdminHandler
+	Audit             *handler.AuditHandler
+	Fraud             *handler.FraudHandler
+	Docs              *handler.DocsHandler
+	Webhook           *handler.WebhookHandler
+	Stream            *handler.StreamHandler
+}
+
+// Middlewares middleware аутентификации и ограничений доступа
+type Middlewares struct {
+	JWT           *middleware.JWTMiddleware
+	Role          *middleware.RoleMiddleware
+	VerifiedEmail *middleware.VerifiedEmailMiddleware
+	Idempotency   *middleware.IdempotencyMiddleware
+}
+
+// New регистрирует маршруты API. Обработчики и middleware используются только при обработке
+// запросов, поэтому для проверки таблицы маршрутов их можно не заполнять.
+func New(h Handlers, m Middlewares) *mux.Router {
+	root := mux.NewRouter()
+	root.Use(middleware.RequestID)
+	root.Use(middleware.Language)
+	root.NotFoundHandler = http.HandlerFunc(handler.NotFound)
+	root.MethodNotAllowedHandler = http.HandlerFunc(handler.MethodNotAllowed)
+	root.HandleFunc("/.well-known/jwks.json", h.JWKS.GetJWKS).Methods(http.MethodGet)
+
+	r := root.PathPrefix("/api").Subrouter()
+
+	// Публичные маршруты (без аутентификации)
+	r.HandleFunc("/register", h.Auth.Register).Methods(http.MethodPost)
+	r.HandleFunc("/login", h.Auth.Login).Methods(http.MethodPost)
+	r.HandleFunc("/login/2fa", h.Auth.LoginTwoFactor).Methods(http.MethodPost)
+	r.HandleFunc("/token/refresh", h.Auth.Refresh).Methods(http.MethodPost)
+	r.HandleFunc("/password/forgot", h.Password.ForgotPassword).Methods(http.MethodPost)
+	r.HandleFunc("/password/reset", h.Password.ResetPassword).Methods(http.MethodPost)
+	r.HandleFunc("/email/verify", h.EmailVerification.Verify).Methods(http.MethodGet)
+
+	// Документация API
+	r.HandleFunc("/docs", h.Docs.GetUI).Methods(http.MethodGet)
+	r.HandleFunc("/docs/openapi.json", h.Docs.GetSpec).Methods(http.MethodGet)
+
+	// Поток изменений счетов принимает как заголовок Authorization, так и одноразовый билет
+	// в параметре ticket для браузерного EventSource
+	r.Handle("/accounts/stream", m.JWT.StreamMiddleware(http.HandlerFunc(h.Stream.Stream))).
+		Methods(http.MethodGet)
+
+	// Защищенные маршруты (с проверкой JWT)
+	apiRouter := r.PathPrefix("").Subrouter()
+	apiRouter.Use(m.JWT.Middleware)
+
+	apiRouter.HandleFunc("/logout", h.Auth.Logout).Methods(http.MethodPost)
+
+	// Профиль текущего пользователя
+	apiRouter.HandleFunc("/me", h.User.GetMe).Methods(http.MethodGet)
+	apiRouter.HandleFunc("/me", h.User.UpdateMe).Methods(http.MethodPatch)
+	apiRouter.HandleFunc("/me/password", h.Password.ChangePassword).Methods(http.MethodPost)
+	apiRouter.HandleFunc("/email/verification/resend", h.EmailVerification.Resend).Methods(http.MethodPost)
+
+	// Маршруты для двухфакторной аутентификации
+	apiRouter.HandleFunc("/2fa/enroll", h.TwoFactor.Enroll).Methods(http.MethodPost)
+	apiRouter.HandleFunc("/2fa/confirm", h.TwoFactor.Confirm).Methods(http.MethodPost)
+	apiRouter.HandleFunc("/2fa/disable", h.TwoFactor.Disable).Methods(http.MethodPost)
+	apiRouter.HandleFunc("/2fa/recovery-codes", h.TwoFactor.RegenerateRecoveryCodes).Methods(http.MethodPost)
+
+	// Маршруты для счетов
+	apiRouter.HandleFunc("/accounts", h.Account.CreateAccount).Methods(http.MethodPost)
+	apiRouter.HandleFunc("/accounts", h.Account.GetAccounts).Methods(http.MethodGet)
+	apiRouter.HandleFunc("/accounts/stream/ticket", h.Stream.IssueTicket).Methods(http.MethodPost)
+	apiRouter.HandleFunc("/accounts/{id}/transactions", h.Account.GetTransactions).Methods(http.MethodGet)
+	apiRouter.HandleFunc("/accounts/{id}/limits", h.TransferLimit.GetAccountLimits).Methods(http.MethodGet)
+
+	// Маршруты для регулярных переводов
+	apiRouter.HandleFunc("/scheduled-transfers", h.ScheduledTransfer.GetAll).Methods(http.MethodGet)
+	apiRouter.HandleFunc("/scheduled-transfers/{id}", h.ScheduledTransfer.Get).Methods(http.MethodGet)
+	apiRouter.HandleFunc("/scheduled-transfers/{id}", h.ScheduledTransfer.Cancel).Methods(http.MethodDelete)
+	apiRouter.HandleFunc("/scheduled-transfers/{id}/runs", h.ScheduledTransfer.GetRuns).Methods(http.MethodGet)
+
+	// Маршруты для карт
+	apiRouter.HandleFunc("/cards", h.Card.CreateCard).Methods(http.MethodPost)
+	apiRouter.HandleFunc("/cards", h.Card.GetCards).Methods(http.MethodGet)
+	apiRouter.HandleFunc("/cards/{id}", h.Card.GetCardDetails).Methods(http.MethodGet)
+	apiRouter.HandleFunc("/cards/{id}/limits", h.Card.GetCardLimit).Methods(http.MethodGet)
+	apiRouter.HandleFunc("/cards/{id}/limits", h.Card.UpdateCardLimit).Methods(http.MethodPut)
+	apiRouter.HandleFunc("/payments/{id}/void", h.Card.VoidPayment).Methods(http.MethodPost)
+
+	// Операции с движением денег доступны только после подтверждения email
+	// и принимают заголовок Idempotency-Key для безопасного повтора
+	moneyRouter := apiRouter.PathPrefix("").Subrouter()
+	moneyRouter.Use(m.VerifiedEmail.Middleware)
+	moneyRouter.Use(m.Idempotency.Middleware)
+	moneyRouter.HandleFunc("/accounts/{id}/balance", h.Account.UpdateBalance).Methods(http.MethodPatch)
+	moneyRouter.HandleFunc("/transfer", h.Account.Transfer).Methods(http.MethodPost)
+	moneyRouter.HandleFunc("/transfer/preview", h.Account.PreviewTransfer).Methods(http.MethodPost)
+	moneyRouter.HandleFunc("/accounts/{id}/close", h.Account.CloseAccount).Methods(http.MethodPost)
+	moneyRouter.HandleFunc("/scheduled-transfers", h.ScheduledTransfer.Create).Methods(http.MethodPost)
+	moneyRouter.HandleFunc("/payments", h.Card.ProcessPayment).Methods(http.MethodPost)
+
+	// Списание и возврат по платежу проводят сотрудники: владелец карты оспаривает платеж через спор
+	paymentOpsRouter := moneyRouter.PathPrefix("").Subrouter()
+	paymentOpsRouter.Use(m.Role.Require(models.SUPPORT, models.ADMIN))
+	paymentOpsRouter.HandleFunc("/payments/{id}/capture", h.Card.CapturePayment).Methods(http.MethodPost)
+	paymentOpsRouter.HandleFunc("/payments/{id}/refund", h.Card.RefundPayment).Methods(http.MethodPost)
+
+	// Маршруты для споров по платежам
+	apiRouter.HandleFunc("/payments/{id}/disputes", h.Dispute.OpenDispute).Methods(http.MethodPost)
+	apiRouter.HandleFunc("/disputes", h.Dispute.GetDisputes).Methods(http.MethodGet)
+
+	// Маршруты для вебхуков
+	apiRouter.HandleFunc("/webhooks", h.Webhook.Create).Methods(http.MethodPost)
+	apiRouter.HandleFunc("/webhooks", h.Webhook.GetAll).Methods(http.MethodGet)
+	apiRouter.HandleFunc("/webhooks/{id}", h.Webhook.Get).Methods(http.MethodGet)
+	apiRouter.HandleFunc("/webhooks/{id}", h.Webhook.Delete).Methods(http.MethodDelete)
+	apiRouter.HandleFunc("/webhooks/{id}/deliveries", h.Webhook.GetDeliveries).Methods(http.MethodGet)
+	apiRouter.HandleFunc("/webhooks/{id}/deliveries/{delivery_id}", h.Webhook.GetDelivery).
+		Methods(http.MethodGet)
+	apiRouter.HandleFunc("/webhooks/{id}/deliveries/{delivery_id}/redeliver", h.Webhook.Redeliver).
+		Methods(http.MethodPost)
+
+	// Маршруты для сотрудников поддержки
+	supportRouter := apiRouter.PathPrefix("/support").Subrouter()
+	supportRouter.Use(m.Role.Require(models.SUPPORT, models.ADMIN))
+	supportRouter.HandleFunc("/disputes", h.Dispute.GetDisputesForReview).Methods(http.MethodGet)
+	supportRouter.HandleFunc("/disputes/{id}/status", h.Dispute.UpdateDisputeStatus).Methods(http.MethodPost)
+
+	// Административное API: просмотр доступен поддержке, изменения - только администраторам.
+	// Все действия записываются в журнал аудита.
+	adminRouter := apiRouter.PathPrefix("/admin").Subrouter()
+	adminRouter.Use(m.Role.Require(models.SUPPORT, models.ADMIN))
+	adminRouter.HandleFunc("/users", h.Admin.FindUser).Methods(http.MethodGet)
+	adminRouter.HandleFunc("/users/{id}", h.Admin.GetUser).Methods(http.MethodGet)
+	adminRouter.HandleFunc("/users/{id}/transactions", h.Admin.GetUserTransactions).Methods(http.MethodGet)
+	adminRouter.HandleFunc("/accounts/{id}/transactions", h.Admin.GetAccountTransactions).Methods(http.MethodGet)
+	adminRouter.HandleFunc("/fraud-reviews", h.Fraud.GetReviews).Methods(http.MethodGet)
+	adminRouter.HandleFunc("/users/{id}/transfer-limits", h.TransferLimit.GetUserOverride).Methods(http.MethodGet)
+	adminRouter.HandleFunc("/accounts/{id}/transfer-limits", h.TransferLimit.GetAccountOverride).
+		Methods(http.MethodGet)
+
+	adminOnlyRouter := adminRouter.PathPrefix("").Subrouter()
+	adminOnlyRouter.Use(m.Role.Require(models.ADMIN))
+	adminOnlyRouter.HandleFunc("/users/{id}/role", h.Admin.ChangeRole).Methods(http.MethodPut)
+	adminOnlyRouter.HandleFunc("/accounts/{id}/freeze", h.Admin.FreezeAccount).Methods(http.MethodPost)
+	adminOnlyRouter.HandleFunc("/accounts/{id}/unfreeze", h.Admin.UnfreezeAccount).Methods(http.MethodPost)
+	adminOnlyRouter.HandleFunc("/users/{id}/transfer-limits", h.TransferLimit.SetUserOverride).
+		Methods(http.MethodPut)
+	adminOnlyRouter.HandleFunc("/accounts/{id}/transfer-limits", h.TransferLimit.SetAccountOverride).
+		Methods(http.MethodPut)
+	adminOnlyRouter.HandleFunc("/cards/{id}/block", h.Admin.BlockCard).Methods(http.MethodPost)
+	adminOnlyRouter.HandleFunc("/cards/{id}/unblock", h.Admin.UnblockCard).Methods(http.MethodPost)
+	adminOnlyRouter.HandleFunc("/fraud-reviews/{id}/approve", h.Fraud.Approve).Methods(http.MethodPost)
+	adminOnlyRouter.HandleFunc("/fraud-reviews/{id}/reject", h.Fraud.Reject).Methods(http.MethodPost)
+	adminOnlyRouter.HandleFunc("/audit", h.Audit.Query).Methods(http.MethodGet)
+	adminOnlyRouter.HandleFunc("/audit/verify", h.Audit.Verify).Methods(http.MethodGet)
+
+	return root
+}
@@ -0,0 +1,33 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/therealadik/bank-api/internal/docs"
+)
+
+// Каждый зарегистрированный маршрут должен быть описан в спецификации OpenAPI, и наоборот
+func TestRoutesMatchSpec(t *testing.T) {
+	if err := docs.Verify(New(Handlers{}, Middlewares{})); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Проверка должна замечать маршрут, которого нет в спецификации
+func TestVerifyDetectsUndocumentedRoute(t *testing.T) {
+	r := New(Handlers{}, Middlewares{})
+	r.HandleFunc("/api/undocumented", func(http.ResponseWriter, *http.Request) {}).Methods(http.MethodGet)
+
+	var mismatch *docs.Mismatch
+	if err := docs.Verify(r); !errors.As(err, &mismatch) {
+		t.Fatalf("ожидалась ошибка *docs.Mismatch, получено %v", err)
+	}
+	if len(mismatch.Undocumented) != 1 || mismatch.Undocumented[0] != (docs.Operation{Method: http.MethodGet, Path: "/api/undocumented"}) {
+		t.Fatalf("неверный список неописанных маршрутов: %v", mismatch.Undocumented)
+	}
+	if len(mismatch.Stale) != 0 {
+		t.Fatalf("лишние описанные операции: %v", mismatch.Stale)
+	}
+}
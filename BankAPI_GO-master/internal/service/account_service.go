@@ -3,8 +3,13 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/shopspring/decimal"
+	"github.com/therealadik/bank-api/internal/models"
 	"github.com/therealadik/bank-api/internal/models/account"
 	"github.com/therealadik/bank-api/internal/models/transaction"
 	"github.com/therealadik/bank-api/internal/repository"
@@ -14,35 +19,103 @@ var (
 	ErrInsufficientFunds = errors.New("недостаточно средств")
 	ErrSameAccount       = errors.New("нельзя переводить деньги на тот же счет")
 	ErrNegativeAmount    = errors.New("сумма не может быть отрицательной")
+	ErrAccountNotOwned   = newForbiddenError("счет не принадлежит пользователю")
+	ErrAccountFrozen     = errors.New("счет заморожен")
+	ErrAccountClosed     = errors.New("счет закрыт")
+	ErrAccountHasHolds   = errors.New("по счету есть незавершенные авторизации")
+	ErrCloseTargetNeeded = errors.New("для закрытия счета с остатком нужен счет для перевода остатка")
+	ErrCurrencyMismatch  = errors.New("валюты счетов не совпадают")
+
+	ErrRecipientRequired    = errors.New("необходимо указать ровно одного получателя: счет, номер счета или email")
+	ErrInvalidAccountNumber = errors.New("неверный номер счета")
+	ErrRecipientNotFound    = newNotFoundError("получатель не найден")
 )
 
+// TransferTarget получатель перевода. Заполняется ровно одно поле:
+// AccountID допускается только для собственных счетов, чужие счета
+// адресуются публичным номером или email владельца.
+type TransferTarget struct {
+	AccountID     int64
+	AccountNumber string
+	Email         string
+}
+
+// RecipientPreview данные получателя, показываемые перед подтверждением перевода
+type RecipientPreview struct {
+	Account *account.Account
+	Name    string // Замаскированное имя получателя
+}
+
 type AccountService struct {
+	userRepo        repository.UserRepository
 	accountRepo     *repository.AccountRepository
 	transactionRepo *repository.TransactionRepository
+	limits          *TransferLimitService
+	fraud           *FraudService
+	audit           *AuditService
+	webhooks        *WebhookService
+	db              *pgxpool.Pool
 }
 
-func NewAccountService(accountRepo *repository.AccountRepository, transactionRepo *repository.TransactionRepository) *AccountService {
+func NewAccountService(userRepo repository.UserRepository, accountRepo *repository.AccountRepository,
+	transactionRepo *repository.TransactionRepository, limits *TransferLimitService, fraud *FraudService,
+	audit *AuditService, webhooks *WebhookService, db *pgxpool.Pool) *AccountService {
 	return &AccountService{
+		userRepo:        userRepo,
 		accountRepo:     accountRepo,
 		transactionRepo: transactionRepo,
+		limits:          limits,
+		fraud:           fraud,
+		audit:           audit,
+		webhooks:        webhooks,
+		db:              db,
 	}
 }
 
 // CreateAccount создает новый счет для пользователя
 func (s *AccountService) CreateAccount(ctx context.Context, userID int64, currency account.Currency) (*account.Account, error) {
-	return s.accountRepo.CreateAccount(ctx, userID, currency)
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	acc, err := s.accountRepo.WithTx(tx).CreateAccount(ctx, userID, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.audit.RecordTx(ctx, tx, AuditEvent{
+		ActorID:    &userID,
+		Action:     models.AUDIT_ACCOUNT_CREATED,
+		TargetType: models.AuditTargetAccount,
+		TargetID:   &acc.ID,
+		Details:    "currency=" + string(currency),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return acc, nil
 }
 
 // GetAccountByID получает счет по ID с проверкой владения
 func (s *AccountService) GetAccountByID(ctx context.Context, id int64, userID int64) (*account.Account, error) {
 	acc, err := s.accountRepo.GetAccountByID(ctx, id)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrAccountNotFound
+		}
 		return nil, err
 	}
 
 	// Проверка, принадлежит ли счет пользователю
 	if acc.UserID != userID {
-		return nil, errors.New("счет не принадлежит пользователю")
+		return nil, ErrAccountNotOwned
 	}
 
 	return acc, nil
@@ -60,15 +133,35 @@ func (s *AccountService) UpdateBalance(ctx context.Context, id int64, userID int
 		return errors.New("сумма должна быть отлична от нуля")
 	}
 
-	// Получаем счет с проверкой владения
-	acc, err := s.GetAccountByID(ctx, id, userID)
+	// Начинаем транзакцию в БД
+	tx, err := s.db.Begin(ctx)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback(ctx)
+
+	accountRepo := s.accountRepo.WithTx(tx)
 
-	// Если это списание, проверяем достаточность средств
-	if amount.LessThan(decimal.Zero) && acc.Balance.Add(amount).LessThan(decimal.Zero) {
-		return ErrInsufficientFunds
+	// Блокируем счет, чтобы статус не изменился до конца операции
+	acc, err := accountRepo.GetAccountForUpdate(ctx, id)
+	if err != nil {
+		return err
+	}
+	if acc.UserID != userID {
+		return ErrAccountNotOwned
+	}
+	if err := checkAccountActive(acc); err != nil {
+		return err
+	}
+
+	// Если это списание, проверяем достаточность средств и лимиты
+	if amount.LessThan(decimal.Zero) {
+		if acc.AvailableBalance.Add(amount).LessThan(decimal.Zero) {
+			return ErrInsufficientFunds
+		}
+		if err := s.limits.CheckTx(ctx, tx, userID, id, amount.Abs()); err != nil {
+			return err
+		}
 	}
 
 	// Определяем тип транзакции
@@ -77,61 +170,359 @@ func (s *AccountService) UpdateBalance(ctx context.Context, id int64, userID int
 		txType = transaction.DEPOSIT // Пополнение
 	}
 
-	// Начинаем транзакцию в БД
-	err = s.accountRepo.UpdateBalance(ctx, id, amount)
+	err = accountRepo.UpdateBalance(ctx, id, amount)
 	if err != nil {
 		return err
 	}
 
 	// Записываем транзакцию
 	absAmount := amount.Abs()
-	_, err = s.transactionRepo.CreateTransaction(ctx, id, absAmount, txType, transaction.COMPLETED)
+	t, err := s.transactionRepo.WithTx(tx).CreateTransaction(ctx, id, absAmount, txType, transaction.COMPLETED)
+	if err != nil {
+		return err
+	}
+	if err := s.webhooks.TransactionCompletedTx(ctx, tx, t); err != nil {
+		return err
+	}
 
-	return err
+	err = s.audit.RecordTx(ctx, tx, AuditEvent{
+		ActorID:    &userID,
+		Action:     models.AUDIT_BALANCE_CHANGED,
+		TargetType: models.AuditTargetAccount,
+		TargetID:   &id,
+		Details:    fmt.Sprintf("type=%s amount=%s", txType, absAmount),
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
 }
 
-// Transfer переводит деньги между счетами
-func (s *AccountService) Transfer(ctx context.Context, fromID, toID int64, userID int64, amount decimal.Decimal) error {
-	// Проверки
-	if fromID == toID {
-		return ErrSameAccount
+// PreviewRecipient находит счет получателя перевода со счета fromID и возвращает
+// замаскированные данные получателя для подтверждения перевода пользователем.
+// Число запросов ограничено, чтобы по последовательным номерам счетов нельзя было
+// перебрать имена клиентов.
+func (s *AccountService) PreviewRecipient(ctx context.Context, fromID, userID int64, target TransferTarget) (*RecipientPreview, error) {
+	if err := s.limits.CheckPreview(ctx, userID); err != nil {
+		return nil, err
 	}
 
+	fromAcc, err := s.GetAccountByID(ctx, fromID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	toAcc, err := s.resolveTarget(ctx, fromAcc, target)
+	if err != nil {
+		return nil, err
+	}
+
+	owner, err := s.userRepo.GetByID(ctx, toAcc.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RecipientPreview{Account: toAcc, Name: maskName(owner.Username)}, nil
+}
+
+// Transfer переводит деньги со счета fromID получателю target. Возвращает исходящую
+// транзакцию; в статусе PENDING перевод задержан антифрод-проверкой до решения администратора.
+func (s *AccountService) Transfer(ctx context.Context, fromID int64, target TransferTarget, userID int64,
+	amount decimal.Decimal) (*transaction.Transaction, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	outgoing, err := s.TransferTx(ctx, tx, fromID, target, userID, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return outgoing, nil
+}
+
+// TransferTx выполняет перевод внутри переданной транзакции. Используется
+// исполнителем регулярных переводов, чтобы перевод и отметка об исполнении
+// фиксировались атомарно.
+func (s *AccountService) TransferTx(ctx context.Context, tx pgx.Tx, fromID int64, target TransferTarget, userID int64,
+	amount decimal.Decimal) (*transaction.Transaction, error) {
 	if amount.LessThanOrEqual(decimal.Zero) {
-		return ErrNegativeAmount
+		return nil, ErrNegativeAmount
 	}
 
-	// Проверяем, принадлежит ли счет-отправитель пользователю
 	fromAcc, err := s.GetAccountByID(ctx, fromID, userID)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	toAcc, err := s.resolveTarget(ctx, fromAcc, target)
+	if err != nil {
+		return nil, err
+	}
+	toID := toAcc.ID
+
+	// Перечитываем оба счета под блокировкой: статус и баланс могли измениться
+	fromAcc, toAcc, err = lockTransferAccounts(ctx, s.accountRepo.WithTx(tx), fromID, toID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Зачисления на замороженный или закрытый счет тоже невозможны
+	if err := checkAccountActive(fromAcc); err != nil {
+		return nil, err
+	}
+	if err := checkAccountActive(toAcc); err != nil {
+		return nil, err
 	}
 
 	// Проверяем достаточно ли средств
-	if fromAcc.Balance.LessThan(amount) {
-		return ErrInsufficientFunds
+	if fromAcc.AvailableBalance.LessThan(amount) {
+		return nil, ErrInsufficientFunds
 	}
 
-	// Проверяем существование счета получателя
-	_, err = s.accountRepo.GetAccountByID(ctx, toID)
+	if err := s.limits.CheckTx(ctx, tx, userID, fromID, amount); err != nil {
+		return nil, err
+	}
+
+	// Подозрительный перевод не проводится, а задерживается до решения администратора
+	assessment, err := s.fraud.AssessTransferTx(ctx, tx, userID, toAcc, amount)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if s.fraud.RequiresReview(assessment) {
+		return s.fraud.HoldTransferTx(ctx, tx, userID, fromID, toID, amount, assessment)
+	}
+
+	outgoing, err := s.moveFunds(ctx, tx, fromID, toID, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.audit.RecordTx(ctx, tx, AuditEvent{
+		ActorID:    &userID,
+		Action:     models.AUDIT_TRANSFER,
+		TargetType: models.AuditTargetAccount,
+		TargetID:   &fromID,
+		Details:    fmt.Sprintf("to_account_id=%d amount=%s", toID, amount),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return outgoing, nil
+}
+
+// resolveTarget находит счет получателя перевода со счета fromAcc
+func (s *AccountService) resolveTarget(ctx context.Context, fromAcc *account.Account, target TransferTarget) (*account.Account, error) {
+	given := 0
+	for _, set := range []bool{target.AccountID != 0, target.AccountNumber != "", target.Email != ""} {
+		if set {
+			given++
+		}
+	}
+	if given != 1 {
+		return nil, ErrRecipientRequired
+	}
+
+	var toAcc *account.Account
+	var err error
+	switch {
+	case target.AccountID != 0:
+		// По внутреннему ID можно переводить только между своими счетами
+		toAcc, err = s.accountRepo.GetAccountByID(ctx, target.AccountID)
+		if err == nil && toAcc.UserID != fromAcc.UserID {
+			return nil, ErrRecipientNotFound
+		}
+	case target.AccountNumber != "":
+		if !account.ValidateNumber(target.AccountNumber) {
+			return nil, ErrInvalidAccountNumber
+		}
+		toAcc, err = s.accountRepo.GetAccountByNumber(ctx, target.AccountNumber)
+	default:
+		var owner *models.User
+		owner, err = s.userRepo.GetByEmail(ctx, strings.TrimSpace(target.Email))
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrRecipientNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		toAcc, err = s.accountRepo.GetPrimaryAccount(ctx, owner.ID, fromAcc.Currency)
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrRecipientNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if toAcc.ID == fromAcc.ID {
+		return nil, ErrSameAccount
+	}
+	if toAcc.Currency != fromAcc.Currency {
+		return nil, ErrCurrencyMismatch
+	}
+	if err := checkAccountActive(toAcc); err != nil {
+		return nil, err
+	}
+
+	return toAcc, nil
+}
+
+// CloseAccount закрывает счет пользователя. Счет с остатком можно закрыть,
+// только указав активный счет того же владельца и в той же валюте, на который
+// будет переведен остаток. Счет с незавершенными авторизациями закрыть нельзя.
+func (s *AccountService) CloseAccount(ctx context.Context, id, userID int64, transferToID *int64) (*account.Account, error) {
+	if transferToID != nil && *transferToID == id {
+		return nil, ErrSameAccount
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var acc, target *account.Account
+	if transferToID != nil {
+		acc, target, err = lockTransferAccounts(ctx, s.accountRepo.WithTx(tx), id, *transferToID)
+	} else {
+		acc, err = s.accountRepo.WithTx(tx).GetAccountForUpdate(ctx, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if acc.UserID != userID {
+		return nil, ErrAccountNotOwned
+	}
+	if err := checkAccountActive(acc); err != nil {
+		return nil, err
 	}
 
+	// Захваченные средства еще могут быть списаны по карте
+	if !acc.Balance.Equal(acc.AvailableBalance) {
+		return nil, ErrAccountHasHolds
+	}
+
+	details := ""
+	if acc.Balance.GreaterThan(decimal.Zero) {
+		if target == nil {
+			return nil, ErrCloseTargetNeeded
+		}
+		if target.UserID != userID {
+			return nil, ErrAccountNotOwned
+		}
+		if err := checkAccountActive(target); err != nil {
+			return nil, err
+		}
+		if target.Currency != acc.Currency {
+			return nil, ErrCurrencyMismatch
+		}
+
+		if _, err := s.moveFunds(ctx, tx, acc.ID, target.ID, acc.Balance); err != nil {
+			return nil, err
+		}
+		details = fmt.Sprintf("to_account_id=%d amount=%s", target.ID, acc.Balance)
+	}
+
+	closed, err := s.accountRepo.WithTx(tx).Close(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.audit.RecordTx(ctx, tx, AuditEvent{
+		ActorID:    &userID,
+		Action:     models.AUDIT_ACCOUNT_CLOSED,
+		TargetType: models.AuditTargetAccount,
+		TargetID:   &id,
+		Details:    details,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return closed, nil
+}
+
+// lockTransferAccounts блокирует оба счета перевода в порядке возрастания ID,
+// чтобы встречные переводы не приводили к взаимной блокировке
+func lockTransferAccounts(ctx context.Context, accountRepo *repository.AccountRepository,
+	fromID, toID int64) (*account.Account, *account.Account, error) {
+	firstID, secondID := fromID, toID
+	if firstID > secondID {
+		firstID, secondID = secondID, firstID
+	}
+
+	first, err := accountRepo.GetAccountForUpdate(ctx, firstID)
+	if err != nil {
+		return nil, nil, err
+	}
+	second, err := accountRepo.GetAccountForUpdate(ctx, secondID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if first.ID == fromID {
+		return first, second, nil
+	}
+	return second, first, nil
+}
+
+// moveFunds переводит средства между счетами и записывает транзакции по обоим счетам.
+// Возвращает исходящую транзакцию.
+func (s *AccountService) moveFunds(ctx context.Context, tx pgx.Tx, fromID, toID int64,
+	amount decimal.Decimal) (*transaction.Transaction, error) {
+	transactionRepo := s.transactionRepo.WithTx(tx)
+
 	// Выполняем перевод между счетами
-	err = s.accountRepo.TransferBetweenAccounts(ctx, fromID, toID, amount)
+	err := s.accountRepo.WithTx(tx).TransferBetweenAccounts(ctx, fromID, toID, amount)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// Записываем транзакции
-	_, err = s.transactionRepo.CreateTransaction(ctx, fromID, amount, transaction.DEPOSIT, transaction.COMPLETED)
+	// Записываем транзакции: исходящий перевод у отправителя, зачисление у получателя
+	outgoing, err := transactionRepo.CreateTransferTransaction(ctx, fromID, amount, transaction.TRANSFER,
+		transaction.COMPLETED, toID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = s.transactionRepo.CreateTransaction(ctx, toID, amount, transaction.WITHDRAWAL, transaction.COMPLETED)
-	return err
+	incoming, err := transactionRepo.CreateTransferTransaction(ctx, toID, amount, transaction.DEPOSIT,
+		transaction.COMPLETED, fromID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range []*transaction.Transaction{outgoing, incoming} {
+		if err := s.webhooks.TransactionCompletedTx(ctx, tx, t); err != nil {
+			return nil, err
+		}
+	}
+
+	return outgoing, nil
+}
+
+// checkAccountActive проверяет, что по счету разрешено движение средств
+func checkAccountActive(acc *account.Account) error {
+	switch acc.Status {
+	case account.FROZEN:
+		return ErrAccountFrozen
+	case account.CLOSED:
+		return ErrAccountClosed
+	}
+	return nil
 }
 
 // GetTransactionsByAccountID получает историю транзакций для счета
@@ -149,3 +540,10 @@ func (s *AccountService) GetTransactionsByAccountID(ctx context.Context, account
 func (s *AccountService) GetTransactionsByUserID(ctx context.Context, userID int64) ([]*transaction.Transaction, error) {
 	return s.transactionRepo.GetTransactionsByUserID(ctx, userID)
 }
+
+// maskName маскирует имя получателя, оставляя только первый символ. Длина маски
+// постоянна, чтобы по ней нельзя было узнать длину имени.
+func maskName(name string) string {
+	runes := []rune(name)
+	return string(runes[:min(len(runes), 1)]) + "***"
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/therealadik/bank-api/internal/models/account"
+)
+
+// Получатель перевода задается ровно одним полем; номер счета проверяется
+// по контрольному ключу до обращения к базе
+func TestResolveTargetRejects(t *testing.T) {
+	valid := account.GenerateNumber(42, account.RUB)
+	broken := []byte(valid)
+	broken[19] = '0' + (broken[19]-'0'+1)%10
+
+	tests := []struct {
+		name   string
+		target TransferTarget
+		want   error
+	}{
+		{"получатель не указан", TransferTarget{}, ErrRecipientRequired},
+		{"счет и номер", TransferTarget{AccountID: 2, AccountNumber: valid}, ErrRecipientRequired},
+		{"номер и email", TransferTarget{AccountNumber: valid, Email: "user@example.com"}, ErrRecipientRequired},
+		{"все три поля", TransferTarget{AccountID: 2, AccountNumber: valid, Email: "user@example.com"}, ErrRecipientRequired},
+		{"короткий номер", TransferTarget{AccountNumber: valid[:19]}, ErrInvalidAccountNumber},
+		{"неверный контрольный ключ", TransferTarget{AccountNumber: string(broken)}, ErrInvalidAccountNumber},
+	}
+
+	s := &AccountService{}
+	from := &account.Account{ID: 1, UserID: testUserID, Currency: account.RUB}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := s.resolveTarget(context.Background(), from, tc.target); !errors.Is(err, tc.want) {
+				t.Fatalf("ошибка %v, ожидалась %v", err, tc.want)
+			}
+		})
+	}
+}
+
+// Имя получателя маскируется до первого символа маской постоянной длины
+func TestMaskName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"alexander", "a***"},
+		{"ab", "a***"},
+		{"a", "a***"},
+		{"", "***"},
+		{"Иванов", "И***"},
+	}
+
+	for _, tc := range tests {
+		if got := maskName(tc.name); got != tc.want {
+			t.Errorf("maskName(%q) = %q, ожидалось %q", tc.name, got, tc.want)
+		}
+	}
+}
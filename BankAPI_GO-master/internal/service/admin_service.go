@@ -0,0 +1,305 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/therealadik/bank-api/internal/models"
+	"github.com/therealadik/bank-api/internal/models/account"
+	"github.com/therealadik/bank-api/internal/models/transaction"
+	"github.com/therealadik/bank-api/internal/repository"
+)
+
+var (
+	ErrAccountNotFound       = newNotFoundError("счет не найден")
+	ErrInvalidAccountStatus  = errors.New("недопустимый переход статуса счета")
+	ErrInvalidRole           = errors.New("неизвестная роль")
+	ErrCannotChangeOwnRole   = errors.New("нельзя изменить собственную роль")
+	ErrAdminReasonRequired   = errors.New("необходимо указать причину действия")
+	ErrUserLookupQueryNeeded = errors.New("необходимо указать email или username")
+)
+
+// AdminUserDetails данные пользователя для администратора
+type AdminUserDetails struct {
+	User     *models.User
+	Accounts []*account.Account
+	Cards    []*models.Card
+}
+
+// AdminService операции администраторов и сотрудников поддержки.
+// Каждое действие, включая просмотр данных клиентов, записывается в журнал аудита;
+// смена роли и статуса счета или карты записывается в журнал в той же транзакции, что и само изменение.
+type AdminService struct {
+	userRepo        repository.UserRepository
+	tokenRepo       repository.TokenRepository
+	accountRepo     *repository.AccountRepository
+	cardRepo        *repository.CardRepository
+	transactionRepo *repository.TransactionRepository
+	audit           *AuditService
+	db              *pgxpool.Pool
+}
+
+func NewAdminService(userRepo repository.UserRepository, tokenRepo repository.TokenRepository,
+	accountRepo *repository.AccountRepository, cardRepo *repository.CardRepository,
+	transactionRepo *repository.TransactionRepository, audit *AuditService, db *pgxpool.Pool) *AdminService {
+	return &AdminService{
+		userRepo:        userRepo,
+		tokenRepo:       tokenRepo,
+		accountRepo:     accountRepo,
+		cardRepo:        cardRepo,
+		transactionRepo: transactionRepo,
+		audit:           audit,
+		db:              db,
+	}
+}
+
+// GetUser возвращает пользователя с его счетами и картами
+func (s *AdminService) GetUser(ctx context.Context, actorID, userID int64) (*AdminUserDetails, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.userDetails(ctx, actorID, user)
+}
+
+// FindUser находит пользователя по email или username (без учета регистра)
+func (s *AdminService) FindUser(ctx context.Context, actorID int64, email, username string) (*AdminUserDetails, error) {
+	email = strings.TrimSpace(email)
+	username = strings.TrimSpace(username)
+
+	var user *models.User
+	var err error
+	switch {
+	case email != "":
+		user, err = s.userRepo.GetByEmail(ctx, email)
+	case username != "":
+		user, err = s.userRepo.GetByUsername(ctx, username)
+	default:
+		return nil, ErrUserLookupQueryNeeded
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.userDetails(ctx, actorID, user)
+}
+
+// userDetails загружает счета и карты пользователя и фиксирует просмотр в журнале
+func (s *AdminService) userDetails(ctx context.Context, actorID int64, user *models.User) (*AdminUserDetails, error) {
+	accounts, err := s.accountRepo.GetAccountsByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения счетов: %w", err)
+	}
+
+	cards, err := s.cardRepo.GetCardsByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения карт: %w", err)
+	}
+
+	if err := s.record(ctx, nil, actorID, models.ADMIN_VIEW_USER, models.AuditTargetUser, user.ID, ""); err != nil {
+		return nil, err
+	}
+
+	return &AdminUserDetails{User: user, Accounts: accounts, Cards: cards}, nil
+}
+
+// ChangeRole изменяет роль пользователя и завершает все его сессии,
+// чтобы токены со старой ролью перестали действовать
+func (s *AdminService) ChangeRole(ctx context.Context, actorID, userID int64, role models.Role) (*models.User, error) {
+	if !role.IsValid() {
+		return nil, ErrInvalidRole
+	}
+	if actorID == userID {
+		return nil, ErrCannotChangeOwnRole
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	previous := user.Role
+
+	// Смена роли, запись в журнал и отзыв сессий выполняются в одной транзакции:
+	// роль не может измениться без записи в журнале или с действующими старыми токенами
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := s.userRepo.WithTx(tx).UpdateRole(ctx, userID, role); err != nil {
+		return nil, err
+	}
+	user.Role = role
+
+	details := fmt.Sprintf("%s -> %s", previous, role)
+	if err := s.record(ctx, tx, actorID, models.ADMIN_CHANGE_ROLE, models.AuditTargetUser, userID, details); err != nil {
+		return nil, err
+	}
+
+	if err := s.tokenRepo.WithTx(tx).RevokeUserSessions(ctx, userID); err != nil {
+		return nil, fmt.Errorf("ошибка отзыва сессий: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// FreezeAccount замораживает счет: операции по нему приостанавливаются
+func (s *AdminService) FreezeAccount(ctx context.Context, actorID, accountID int64, reason string) (*account.Account, error) {
+	return s.setAccountStatus(ctx, actorID, accountID, account.ACTIVE, account.FROZEN, models.ADMIN_FREEZE_ACCOUNT, reason)
+}
+
+// UnfreezeAccount снимает заморозку со счета
+func (s *AdminService) UnfreezeAccount(ctx context.Context, actorID, accountID int64, reason string) (*account.Account, error) {
+	return s.setAccountStatus(ctx, actorID, accountID, account.FROZEN, account.ACTIVE, models.ADMIN_UNFREEZE_ACCOUNT, reason)
+}
+
+// setAccountStatus переводит счет из статуса from в статус to и записывает действие
+// в журнал в одной транзакции. Закрытый счет не может быть ни заморожен, ни разморожен.
+func (s *AdminService) setAccountStatus(ctx context.Context, actorID, accountID int64, from, to account.Status,
+	action models.AuditAction, reason string) (*account.Account, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, ErrAdminReasonRequired
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	accountRepo := s.accountRepo.WithTx(tx)
+	acc, err := accountRepo.UpdateStatus(ctx, accountID, from, to)
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("ошибка изменения статуса счета: %w", err)
+		}
+		// Строка не обновлена: счет либо не существует, либо в другом статусе
+		if _, err := accountRepo.GetAccountByID(ctx, accountID); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, ErrAccountNotFound
+			}
+			return nil, err
+		}
+		return nil, ErrInvalidAccountStatus
+	}
+
+	if err := s.record(ctx, tx, actorID, action, models.AuditTargetAccount, accountID, reason); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return acc, nil
+}
+
+// BlockCard блокирует карту: авторизация платежей по ней отклоняется
+func (s *AdminService) BlockCard(ctx context.Context, actorID, cardID int64, reason string) (*models.Card, error) {
+	return s.setCardStatus(ctx, actorID, cardID, models.CARD_BLOCKED, models.ADMIN_BLOCK_CARD, reason)
+}
+
+// UnblockCard снимает блокировку с карты
+func (s *AdminService) UnblockCard(ctx context.Context, actorID, cardID int64, reason string) (*models.Card, error) {
+	return s.setCardStatus(ctx, actorID, cardID, models.CARD_ACTIVE, models.ADMIN_UNBLOCK_CARD, reason)
+}
+
+// setCardStatus изменяет статус карты и записывает действие в журнал в одной транзакции
+func (s *AdminService) setCardStatus(ctx context.Context, actorID, cardID int64, status models.CardStatus,
+	action models.AuditAction, reason string) (*models.Card, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, ErrAdminReasonRequired
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	card, err := s.cardRepo.WithTx(tx).SetStatus(ctx, cardID, status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrCardNotFound
+		}
+		return nil, fmt.Errorf("ошибка изменения статуса карты: %w", err)
+	}
+
+	if err := s.record(ctx, tx, actorID, action, models.AuditTargetCard, cardID, reason); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return card, nil
+}
+
+// GetAccountTransactions возвращает историю транзакций любого счета
+func (s *AdminService) GetAccountTransactions(ctx context.Context, actorID, accountID int64) ([]*transaction.Transaction, error) {
+	if _, err := s.accountRepo.GetAccountByID(ctx, accountID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrAccountNotFound
+		}
+		return nil, err
+	}
+
+	transactions, err := s.transactionRepo.GetTransactionsByAccountID(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.record(ctx, nil, actorID, models.ADMIN_VIEW_TRANSACTIONS, models.AuditTargetAccount, accountID, ""); err != nil {
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
+// GetUserTransactions возвращает транзакции по всем счетам пользователя
+func (s *AdminService) GetUserTransactions(ctx context.Context, actorID, userID int64) ([]*transaction.Transaction, error) {
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	transactions, err := s.transactionRepo.GetTransactionsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.record(ctx, nil, actorID, models.ADMIN_VIEW_TRANSACTIONS, models.AuditTargetUser, userID, ""); err != nil {
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
+// record записывает действие в журнал аудита (в транзакции tx, если она передана).
+// Если запись не удалась, действие не выполняется (или его результат не возвращается),
+// чтобы не было неучтенных действий.
+func (s *AdminService) record(ctx context.Context, tx pgx.Tx, actorID int64, action models.AuditAction,
+	targetType string, targetID int64, details string) error {
+	event := AuditEvent{
+		ActorID:    &actorID,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   &targetID,
+		Details:    details,
+	}
+	if tx != nil {
+		return s.audit.RecordTx(ctx, tx, event)
+	}
+	return s.audit.Record(ctx, event)
+}
@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/therealadik/bank-api/internal/models"
+	"github.com/therealadik/bank-api/internal/repository"
+	"github.com/therealadik/bank-api/internal/requestmeta"
+)
+
+const (
+	// auditDefaultLimit количество записей журнала, возвращаемых по умолчанию
+	auditDefaultLimit = 100
+	// auditMaxLimit максимальное количество записей журнала в одном ответе
+	auditMaxLimit = 1000
+	// auditVerifyBatchSize количество записей, проверяемых за одну выборку
+	auditVerifyBatchSize = 1000
+)
+
+// auditGenesisHash значение prev_hash первой записи цепочки
+var auditGenesisHash = strings.Repeat("0", sha256.Size*2)
+
+// AuditEvent событие для записи в журнал аудита. Идентификатор запроса, IP и User-Agent
+// берутся из контекста запроса.
+type AuditEvent struct {
+	ActorID    *int64
+	Action     models.AuditAction
+	TargetType string
+	TargetID   *int64
+	Details    string
+}
+
+// AuditVerification результат проверки цепочки журнала аудита
+type AuditVerification struct {
+	Valid      bool
+	Checked    int64  // Количество проверенных записей
+	BrokenAtID *int64 // Первая запись, не совпадающая с цепочкой
+}
+
+// AuditService ведет неизменяемый журнал аудита. Записи добавляются под advisory-блокировкой,
+// каждая содержит хеш предыдущей, поэтому изменение, удаление или вставка записи в середину
+// цепочки обнаруживаются при проверке.
+type AuditService struct {
+	auditRepo *repository.AuditRepository
+	db        *pgxpool.Pool
+}
+
+func NewAuditService(auditRepo *repository.AuditRepository, db *pgxpool.Pool) *AuditService {
+	return &AuditService{
+		auditRepo: auditRepo,
+		db:        db,
+	}
+}
+
+// Record записывает событие в отдельной транзакции
+func (s *AuditService) Record(ctx context.Context, event AuditEvent) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := s.RecordTx(ctx, tx, event); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// RecordTx записывает событие в транзакции вызывающего, чтобы запись появилась
+// в журнале только вместе с самим действием. Блокировка цепочки держится до конца транзакции.
+func (s *AuditService) RecordTx(ctx context.Context, tx pgx.Tx, event AuditEvent) error {
+	repo := s.auditRepo.WithTx(tx)
+
+	if err := repo.LockChain(ctx); err != nil {
+		return fmt.Errorf("ошибка блокировки журнала аудита: %w", err)
+	}
+
+	prevHash, err := repo.GetLastHash(ctx)
+	if err != nil {
+		return fmt.Errorf("ошибка чтения журнала аудита: %w", err)
+	}
+	if prevHash == "" {
+		prevHash = auditGenesisHash
+	}
+
+	meta := requestmeta.FromContext(ctx)
+	entry := &models.AuditEntry{
+		ActorID:    event.ActorID,
+		Action:     event.Action,
+		TargetType: event.TargetType,
+		TargetID:   event.TargetID,
+		RequestID:  meta.RequestID,
+		IP:         meta.IP,
+		UserAgent:  meta.UserAgent,
+		Details:    event.Details,
+		// В БД время хранится с точностью до микросекунд, хеш считается от того же значения
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+		PrevHash:  prevHash,
+	}
+	entry.Hash = auditHash(entry)
+
+	if err := repo.Create(ctx, entry); err != nil {
+		return fmt.Errorf("ошибка записи в журнал аудита: %w", err)
+	}
+	return nil
+}
+
+// Query возвращает записи журнала по фильтру; сам просмотр журнала тоже записывается
+func (s *AuditService) Query(ctx context.Context, actorID int64, filter models.AuditFilter) ([]*models.AuditEntry, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = auditDefaultLimit
+	}
+	filter.Limit = min(filter.Limit, auditMaxLimit)
+
+	entries, err := s.auditRepo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.Record(ctx, AuditEvent{
+		ActorID: &actorID,
+		Action:  models.AUDIT_LOG_VIEWED,
+		Details: describeAuditFilter(filter),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// Verify проходит цепочку от первой записи и проверяет связь и хеш каждой записи
+func (s *AuditService) Verify(ctx context.Context) (*AuditVerification, error) {
+	result := &AuditVerification{Valid: true}
+	prevHash := auditGenesisHash
+	var lastID int64
+
+	for {
+		entries, err := s.auditRepo.ListAfter(ctx, lastID, auditVerifyBatchSize)
+		if err != nil {
+			return nil, err
+		}
+
+		if prevHash = verifyChain(result, prevHash, entries); !result.Valid {
+			return result, nil
+		}
+		if len(entries) < auditVerifyBatchSize {
+			return result, nil
+		}
+		lastID = entries[len(entries)-1].ID
+	}
+}
+
+// verifyChain проверяет связь и хеш записей, следующих за записью с хешем prevHash,
+// и учитывает их в result. Возвращает хеш последней проверенной записи.
+func verifyChain(result *AuditVerification, prevHash string, entries []*models.AuditEntry) string {
+	for _, e := range entries {
+		if e.PrevHash != prevHash || auditHash(e) != e.Hash {
+			id := e.ID
+			result.Valid = false
+			result.BrokenAtID = &id
+			return prevHash
+		}
+		result.Checked++
+		prevHash = e.Hash
+	}
+	return prevHash
+}
+
+// auditHash вычисляет хеш записи: SHA-256 (hex) от конкатенации полей в формате
+// "<длина в байтах>:<значение>". Формат совпадает с используемым в миграции 000017.
+func auditHash(e *models.AuditEntry) string {
+	fields := []string{
+		e.PrevHash,
+		strconv.FormatInt(e.CreatedAt.UnixMicro(), 10),
+		formatOptionalID(e.ActorID),
+		string(e.Action),
+		e.TargetType,
+		formatOptionalID(e.TargetID),
+		e.RequestID,
+		e.IP,
+		e.UserAgent,
+		e.Details,
+	}
+
+	h := sha256.New()
+	for _, f := range fields {
+		h.Write([]byte(strconv.Itoa(len(f)) + ":" + f))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// formatOptionalID форматирует необязательный ID для хеша
+func formatOptionalID(id *int64) string {
+	if id == nil {
+		return ""
+	}
+	return strconv.FormatInt(*id, 10)
+}
+
+// describeAuditFilter описывает фильтр просмотра журнала для записи в сам журнал
+func describeAuditFilter(f models.AuditFilter) string {
+	var parts []string
+	if f.ActorID != nil {
+		parts = append(parts, "actor_id="+strconv.FormatInt(*f.ActorID, 10))
+	}
+	if f.Action != "" {
+		parts = append(parts, "action="+string(f.Action))
+	}
+	if f.TargetType != "" {
+		parts = append(parts, "target_type="+f.TargetType)
+	}
+	if f.TargetID != nil {
+		parts = append(parts, "target_id="+strconv.FormatInt(*f.TargetID, 10))
+	}
+	if f.RequestID != "" {
+		parts = append(parts, "request_id="+f.RequestID)
+	}
+	if f.From != nil {
+		parts = append(parts, "from="+f.From.UTC().Format(time.RFC3339))
+	}
+	if f.To != nil {
+		parts = append(parts, "to="+f.To.UTC().Format(time.RFC3339))
+	}
+	if f.BeforeID != nil {
+		parts = append(parts, "before_id="+strconv.FormatInt(*f.BeforeID, 10))
+	}
+	return strings.Join(parts, " ")
+}
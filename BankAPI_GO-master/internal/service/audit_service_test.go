@@ -0,0 +1,160 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/therealadik/bank-api/internal/models"
+)
+
+// auditTestTime время записей тестовой цепочки (2024-01-02T03:04:05.123456Z)
+var auditTestTime = time.UnixMicro(1704164645123456).UTC()
+
+// Хеш совпадает с рассчитанным по формату миграции 000017 независимо от кода сервиса
+func TestAuditHash(t *testing.T) {
+	actorID, targetID := int64(7), int64(42)
+
+	tests := []struct {
+		name  string
+		entry models.AuditEntry
+		want  string
+	}{
+		{"все поля", models.AuditEntry{
+			ActorID:    &actorID,
+			Action:     models.AUDIT_BALANCE_CHANGED,
+			TargetType: models.AuditTargetAccount,
+			TargetID:   &targetID,
+			RequestID:  "req-1",
+			IP:         "192.0.2.1",
+			UserAgent:  "curl/8.0",
+			Details:    "type=DEPOSIT amount=100.00",
+			CreatedAt:  auditTestTime,
+			PrevHash:   auditGenesisHash,
+		}, "7e33d2e562eab13a28922cff3e278bf67640621cf1d534cbd95cdabd390c4aca"},
+		{"без участника и объекта", models.AuditEntry{
+			Action:    models.AUDIT_LOGIN_FAILED,
+			Details:   "email=ivan@example.com",
+			CreatedAt: auditTestTime,
+			PrevHash:  auditGenesisHash,
+		}, "9fc91ea80988d8f71c65a7a63f21ee948e6218eed28e794eb1802904e6d67d9e"},
+	}
+
+	for _, tc := range tests {
+		if got := auditHash(&tc.entry); got != tc.want {
+			t.Errorf("%s: хеш %s, ожидался %s", tc.name, got, tc.want)
+		}
+	}
+}
+
+// Длина перед каждым полем не дает перенести часть значения в соседнее поле с тем же хешем
+func TestAuditHashFieldBoundaries(t *testing.T) {
+	zero := int64(0)
+	base := models.AuditEntry{UserAgent: "ab", Details: "c", CreatedAt: auditTestTime, PrevHash: auditGenesisHash}
+
+	tests := []struct {
+		name   string
+		modify func(e *models.AuditEntry)
+	}{
+		{"граница полей", func(e *models.AuditEntry) { e.UserAgent, e.Details = "a", "bc" }},
+		{"ID 0 вместо отсутствующего", func(e *models.AuditEntry) { e.ActorID = &zero }},
+		{"объект вместо участника", func(e *models.AuditEntry) { e.TargetID = &zero }},
+		{"другая микросекунда", func(e *models.AuditEntry) { e.CreatedAt = e.CreatedAt.Add(time.Microsecond) }},
+		{"другая предыдущая запись", func(e *models.AuditEntry) { e.PrevHash = auditHash(e) }},
+	}
+
+	want := auditHash(&base)
+	for _, tc := range tests {
+		e := base
+		tc.modify(&e)
+		if auditHash(&e) == want {
+			t.Errorf("%s: хеш не изменился", tc.name)
+		}
+	}
+}
+
+// newAuditChain строит цепочку из n связанных записей с ID от 1
+func newAuditChain(n int) []*models.AuditEntry {
+	entries := make([]*models.AuditEntry, n)
+	prevHash := auditGenesisHash
+	for i := range entries {
+		e := &models.AuditEntry{
+			ID:        int64(i + 1),
+			Action:    models.AUDIT_LOGIN_SUCCEEDED,
+			Details:   "вход",
+			CreatedAt: auditTestTime.Add(time.Duration(i) * time.Second),
+			PrevHash:  prevHash,
+		}
+		e.Hash = auditHash(e)
+		prevHash = e.Hash
+		entries[i] = e
+	}
+	return entries
+}
+
+// Проверка цепочки находит первую запись, после которой журнал изменен
+func TestVerifyChain(t *testing.T) {
+	tests := []struct {
+		name     string
+		tamper   func(entries []*models.AuditEntry) []*models.AuditEntry
+		brokenAt int64 // 0 — цепочка цела
+		checked  int64
+	}{
+		{"цепочка цела", func(e []*models.AuditEntry) []*models.AuditEntry { return e }, 0, 4},
+		{"пустой журнал", func(e []*models.AuditEntry) []*models.AuditEntry { return nil }, 0, 0},
+		{"изменено поле", func(e []*models.AuditEntry) []*models.AuditEntry {
+			e[1].Details = "выход"
+			return e
+		}, 2, 1},
+		{"изменено поле и пересчитан хеш", func(e []*models.AuditEntry) []*models.AuditEntry {
+			e[1].Details = "выход"
+			e[1].Hash = auditHash(e[1])
+			return e
+		}, 3, 2},
+		{"удалена запись", func(e []*models.AuditEntry) []*models.AuditEntry {
+			return append(e[:1], e[2:]...)
+		}, 3, 1},
+		{"удалена первая запись", func(e []*models.AuditEntry) []*models.AuditEntry { return e[1:] }, 2, 0},
+		{"вставлена запись", func(e []*models.AuditEntry) []*models.AuditEntry {
+			forged := &models.AuditEntry{ID: 10, Action: models.AUDIT_LOGOUT, CreatedAt: auditTestTime, PrevHash: e[1].Hash}
+			forged.Hash = auditHash(forged)
+			return append(e[:2], append([]*models.AuditEntry{forged}, e[2:]...)...)
+		}, 3, 3},
+		{"записи переставлены", func(e []*models.AuditEntry) []*models.AuditEntry {
+			e[1], e[2] = e[2], e[1]
+			return e
+		}, 3, 1},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			entries := tc.tamper(newAuditChain(4))
+			result := &AuditVerification{Valid: true}
+
+			verifyChain(result, auditGenesisHash, entries)
+
+			if tc.brokenAt == 0 {
+				if !result.Valid || result.BrokenAtID != nil {
+					t.Fatalf("цепочка признана нарушенной на записи %d", *result.BrokenAtID)
+				}
+			} else if result.Valid || result.BrokenAtID == nil || *result.BrokenAtID != tc.brokenAt {
+				t.Fatalf("результат %+v, ожидалось нарушение на записи %d", result, tc.brokenAt)
+			}
+			if result.Checked != tc.checked {
+				t.Fatalf("проверено %d записей, ожидалось %d", result.Checked, tc.checked)
+			}
+		})
+	}
+}
+
+// Проверка по частям дает тот же результат, что и проверка всей цепочки сразу
+func TestVerifyChainInBatches(t *testing.T) {
+	entries := newAuditChain(5)
+	result := &AuditVerification{Valid: true}
+
+	prevHash := verifyChain(result, auditGenesisHash, entries[:2])
+	prevHash = verifyChain(result, prevHash, entries[2:])
+
+	if !result.Valid || result.Checked != 5 || prevHash != entries[4].Hash {
+		t.Fatalf("результат %+v, последний хеш %s", result, prevHash)
+	}
+}
@@ -2,7 +2,12 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -15,33 +20,93 @@ import (
 
 // Различные ошибки, которые могут возникнуть в процессе аутентификации
 var (
-	ErrInvalidCredentials = errors.New("неверные учетные данные")
-	ErrUserExists         = errors.New("пользователь уже существует")
+	ErrInvalidCredentials  = errors.New("неверные учетные данные")
+	ErrInvalidRefreshToken = errors.New("неверный или просроченный refresh-токен")
+	ErrTokenRevoked        = errors.New("токен отозван")
+	ErrInvalidChallenge    = errors.New("неверный или просроченный токен второго шага входа")
+	ErrInvalidStreamTicket = errors.New("неверный, просроченный или использованный билет потока")
 )
 
+// Типы выпускаемых JWT (claim typ)
+const (
+	tokenTypeAccess    = "access"
+	tokenTypeChallenge = "2fa_challenge"
+	tokenTypeStream    = "stream_ticket"
+)
+
+// TokenClaims данные, извлеченные из access-токена
+type TokenClaims struct {
+	UserID    int64
+	Role      models.Role // Роль пользователя на момент выдачи токена
+	JTI       string      // Уникальный идентификатор токена
+	SessionID string      // Сессия, в рамках которой выдан токен
+	ExpiresAt time.Time   // Срок действия токена
+}
+
 // AuthService интерфейс для сервиса аутентификации
 type AuthService interface {
 	Register(ctx context.Context, req dto.RegisterRequest) (int64, error)
-	Login(ctx context.Context, req dto.LoginRequest) (string, error)
-	ParseToken(tokenString string) (int64, error)
+	Login(ctx context.Context, req dto.LoginRequest, clientIP string) (*dto.AuthResponse, error)
+	LoginTwoFactor(ctx context.Context, challengeToken, code string) (*dto.AuthResponse, error)
+	Refresh(ctx context.Context, refreshToken string) (*dto.AuthResponse, error)
+	Logout(ctx context.Context, claims *TokenClaims) error
+	ParseToken(tokenString string) (*TokenClaims, error)
+	ValidateToken(ctx context.Context, tokenString string) (*TokenClaims, error)
+	CheckSession(ctx context.Context, claims *TokenClaims) error
+	IssueStreamTicket(ctx context.Context, claims *TokenClaims) (*dto.StreamTicketResponse, error)
+	RedeemStreamTicket(ctx context.Context, ticket string) (*TokenClaims, error)
 }
 
 // authService реализация сервиса аутентификации
 type authService struct {
-	userRepo repository.UserRepository
-	jwtCfg   config.JWTConfig
+	userRepo  repository.UserRepository
+	tokenRepo repository.TokenRepository
+	twoFactor *TwoFactorService
+	guard     *LoginGuard
+	verifier  *EmailVerificationService
+	audit     *AuditService
+	keys      *KeyManager
+	jwtCfg    config.JWTConfig
+	tfCfg     config.TwoFactorConfig
 }
 
 // NewAuthService создает новый сервис аутентификации
-func NewAuthService(userRepo repository.UserRepository, jwtCfg config.JWTConfig) AuthService {
+func NewAuthService(userRepo repository.UserRepository, tokenRepo repository.TokenRepository,
+	twoFactor *TwoFactorService, guard *LoginGuard, verifier *EmailVerificationService, audit *AuditService,
+	keys *KeyManager, jwtCfg config.JWTConfig, tfCfg config.TwoFactorConfig) AuthService {
 	return &authService{
-		userRepo: userRepo,
-		jwtCfg:   jwtCfg,
+		userRepo:  userRepo,
+		tokenRepo: tokenRepo,
+		twoFactor: twoFactor,
+		guard:     guard,
+		verifier:  verifier,
+		audit:     audit,
+		keys:      keys,
+		jwtCfg:    jwtCfg,
+		tfCfg:     tfCfg,
 	}
 }
 
-// Register регистрирует нового пользователя
+// Register проверяет данные, регистрирует нового пользователя и отправляет ссылку подтверждения email
 func (s *authService) Register(ctx context.Context, req dto.RegisterRequest) (int64, error) {
+	email, err := normalizeEmail(req.Email)
+	if err != nil {
+		return 0, err
+	}
+
+	username, err := normalizeUsername(req.Username)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := validatePassword(req.Password); err != nil {
+		return 0, err
+	}
+
+	if err := checkEmailAvailable(ctx, s.userRepo, email); err != nil {
+		return 0, err
+	}
+
 	// Хеширование пароля с использованием bcrypt
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
@@ -49,93 +114,513 @@ func (s *authService) Register(ctx context.Context, req dto.RegisterRequest) (in
 	}
 
 	user := &models.User{
-		Email:    req.Email,
+		Email:    email,
+		Username: username,
 		Password: string(hashedPassword),
 	}
 
 	id, err := s.userRepo.Create(ctx, user)
+	if err != nil {
+		return 0, mapUserConflict(err)
+	}
+
+	err = s.audit.Record(ctx, AuditEvent{
+		ActorID:    &id,
+		Action:     models.AUDIT_USER_REGISTERED,
+		TargetType: models.AuditTargetUser,
+		TargetID:   &id,
+	})
 	if err != nil {
 		return 0, err
 	}
 
+	// До подтверждения email переводы и платежи недоступны
+	s.verifier.SendVerification(ctx, id)
+
 	return id, nil
 }
 
-// Login аутентифицирует пользователя и возвращает JWT-токен
-func (s *authService) Login(ctx context.Context, req dto.LoginRequest) (string, error) {
+// Login аутентифицирует пользователя, открывает новую сессию
+// и возвращает пару access- и refresh-токенов. Если у пользователя включена 2FA,
+// вместо токенов возвращается короткоживущий токен второго шага (LoginTwoFactor).
+// Неудачные попытки учитываются по email и IP; при превышении порогов вход
+// временно блокируется (LoginLockedError).
+func (s *authService) Login(ctx context.Context, req dto.LoginRequest, clientIP string) (*dto.AuthResponse, error) {
+	if err := s.guard.CheckLogin(ctx, req.Email, clientIP); err != nil {
+		return nil, err
+	}
+
 	user, err := s.userRepo.GetByEmail(ctx, req.Email)
 	if err != nil {
 		if errors.Is(err, repository.ErrUserNotFound) {
-			return "", ErrInvalidCredentials
+			if err := s.recordLoginFailure(ctx, models.AUDIT_LOGIN_FAILED, nil, "email="+req.Email); err != nil {
+				return nil, err
+			}
+			// Попытки по несуществующим email тоже учитываются, чтобы не раскрывать их наличие
+			if err := s.guard.LoginFailed(ctx, req.Email, clientIP, nil); err != nil {
+				return nil, err
+			}
+			return nil, ErrInvalidCredentials
 		}
-		return "", err
+		return nil, err
 	}
 
 	// Проверка пароля
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
-		return "", ErrInvalidCredentials
+		if err := s.recordLoginFailure(ctx, models.AUDIT_LOGIN_FAILED, &user.ID, "email="+req.Email); err != nil {
+			return nil, err
+		}
+		if err := s.guard.LoginFailed(ctx, req.Email, clientIP, user); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidCredentials
+	}
+
+	if err := s.guard.LoginSucceeded(ctx, req.Email); err != nil {
+		return nil, err
+	}
+
+	if user.TwoFactorEnabled {
+		return s.issueChallenge(user.ID)
+	}
+
+	return s.openSession(ctx, user.ID)
+}
+
+// LoginTwoFactor завершает вход с 2FA: проверяет токен второго шага и код TOTP
+// (или код восстановления) и открывает сессию. Токен второго шага одноразовый.
+func (s *authService) LoginTwoFactor(ctx context.Context, challengeToken, code string) (*dto.AuthResponse, error) {
+	claims, err := s.parseClaims(challengeToken, tokenTypeChallenge)
+	if err != nil {
+		return nil, ErrInvalidChallenge
 	}
 
-	// Генерация JWT-токена
-	token, err := s.generateToken(user.ID)
+	revoked, err := s.tokenRepo.IsRevoked(ctx, claims.JTI, "")
 	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("ошибка проверки отзыва токена: %w", err)
+	}
+	if revoked {
+		return nil, ErrInvalidChallenge
+	}
+
+	// Попытки учитываются защитой от перебора внутри Verify
+	if err := s.twoFactor.Verify(ctx, claims.UserID, code); err != nil {
+		switch {
+		case errors.Is(err, ErrTwoFactorNotEnabled):
+			return nil, ErrInvalidChallenge
+		case errors.Is(err, ErrInvalidTOTPCode):
+			if err := s.recordLoginFailure(ctx, models.AUDIT_TWO_FACTOR_FAILED, &claims.UserID, ""); err != nil {
+				return nil, err
+			}
+		}
+		return nil, err
+	}
+
+	// Погашаем токен второго шага. Проверка отзыва выше лишь отсекает заведомо
+	// использованные токены: из параллельных запросов с одним токеном сессию
+	// получит только тот, чей вызов погасил токен.
+	consumed, err := s.tokenRepo.ConsumeToken(ctx, claims.JTI, claims.UserID, claims.ExpiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка отзыва токена: %w", err)
+	}
+	if !consumed {
+		return nil, ErrInvalidChallenge
 	}
 
-	return token, nil
+	return s.openSession(ctx, claims.UserID)
 }
 
-// generateToken генерирует JWT-токен
-func (s *authService) generateToken(userID int64) (string, error) {
-	// Структура claims для JWT
+// openSession открывает сессию, в рамках которой будут ротироваться refresh-токены,
+// и выпускает первую пару токенов
+func (s *authService) openSession(ctx context.Context, userID int64) (*dto.AuthResponse, error) {
+	sessionID, err := randomToken(16)
+	if err != nil {
+		return nil, err
+	}
+
+	session := &models.Session{
+		ID:        sessionID,
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(s.jwtCfg.RefreshExpiresIn),
+	}
+	if err := s.tokenRepo.CreateSession(ctx, session); err != nil {
+		return nil, fmt.Errorf("ошибка создания сессии: %w", err)
+	}
+
+	err = s.audit.Record(ctx, AuditEvent{
+		ActorID:    &userID,
+		Action:     models.AUDIT_LOGIN_SUCCEEDED,
+		TargetType: models.AuditTargetUser,
+		TargetID:   &userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(ctx, session)
+}
+
+// issueChallenge выпускает токен второго шага входа. Он подписан тем же ключом,
+// что и access-токены, но отличается claim typ и не принимается защищенными маршрутами.
+func (s *authService) issueChallenge(userID int64) (*dto.AuthResponse, error) {
+	jti, err := randomToken(16)
+	if err != nil {
+		return nil, err
+	}
+
 	claims := jwt.MapClaims{
-		"sub": userID,                                    // subject (ID пользователя)
-		"exp": time.Now().Add(s.jwtCfg.ExpiresIn).Unix(), // expiration time
-		"iat": time.Now().Unix(),                         // issued at
+		"sub": userID,
+		"exp": time.Now().Add(s.tfCfg.ChallengeTTL).Unix(),
+		"iat": time.Now().Unix(),
+		"jti": jti,
+		"iss": s.jwtCfg.Issuer,
+		"typ": tokenTypeChallenge,
 	}
 
-	// Создание токена
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	challenge, err := s.keys.Sign(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.AuthResponse{
+		TwoFactorRequired: true,
+		ChallengeToken:    challenge,
+		ExpiresIn:         int64(s.tfCfg.ChallengeTTL.Seconds()),
+	}, nil
+}
+
+// Refresh обменивает refresh-токен на новую пару токенов (ротация).
+// Повторное предъявление уже использованного refresh-токена считается
+// признаком кражи: сессия отзывается целиком.
+func (s *authService) Refresh(ctx context.Context, refreshToken string) (*dto.AuthResponse, error) {
+	if refreshToken == "" {
+		return nil, ErrInvalidRefreshToken
+	}
 
-	// Подписание токена
-	tokenString, err := token.SignedString([]byte(s.jwtCfg.Secret))
+	tokenHash := hashToken(refreshToken)
+
+	session, err := s.tokenRepo.UseRefreshToken(ctx, tokenHash)
 	if err != nil {
-		return "", err
+		if !errors.Is(err, repository.ErrRefreshTokenNotFound) {
+			return nil, err
+		}
+
+		// Проверяем, не был ли токен уже использован
+		stored, lookupErr := s.tokenRepo.GetRefreshTokenByHash(ctx, tokenHash)
+		if lookupErr == nil && stored.UsedAt != nil {
+			if err := s.tokenRepo.RevokeSession(ctx, stored.SessionID); err != nil {
+				return nil, fmt.Errorf("ошибка отзыва сессии: %w", err)
+			}
+		}
+
+		return nil, ErrInvalidRefreshToken
 	}
 
-	return tokenString, nil
+	return s.issueTokens(ctx, session)
 }
 
-// ParseToken разбирает и проверяет JWT-токен, возвращает ID пользователя
-func (s *authService) ParseToken(tokenString string) (int64, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		// Проверка метода подписи
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("неожиданный метод подписи токена")
+// Logout отзывает текущий access-токен и сессию вместе с ее refresh-токенами
+func (s *authService) Logout(ctx context.Context, claims *TokenClaims) error {
+	if err := s.tokenRepo.RevokeToken(ctx, claims.JTI, claims.UserID, claims.ExpiresAt); err != nil {
+		return fmt.Errorf("ошибка отзыва токена: %w", err)
+	}
+
+	if claims.SessionID != "" {
+		if err := s.tokenRepo.RevokeSession(ctx, claims.SessionID); err != nil {
+			return fmt.Errorf("ошибка отзыва сессии: %w", err)
 		}
-		return []byte(s.jwtCfg.Secret), nil
+	}
+
+	return s.audit.Record(ctx, AuditEvent{
+		ActorID:    &claims.UserID,
+		Action:     models.AUDIT_LOGOUT,
+		TargetType: models.AuditTargetUser,
+		TargetID:   &claims.UserID,
 	})
+}
+
+// recordLoginFailure записывает неудачную попытку входа в журнал аудита.
+// Действие выполняет неаутентифицированный клиент, поэтому автор записи не указывается.
+func (s *authService) recordLoginFailure(ctx context.Context, action models.AuditAction, userID *int64,
+	details string) error {
+	event := AuditEvent{
+		Action:  action,
+		Details: details,
+	}
+	if userID != nil {
+		event.TargetType = models.AuditTargetUser
+		event.TargetID = userID
+	}
+	return s.audit.Record(ctx, event)
+}
 
+// issueTokens выпускает access-токен и новый refresh-токен для сессии
+func (s *authService) issueTokens(ctx context.Context, session *models.Session) (*dto.AuthResponse, error) {
+	// Роль перечитывается при каждой выдаче, поэтому ее изменение вступает в силу с новым access-токеном
+	user, err := s.userRepo.GetByID(ctx, session.UserID)
 	if err != nil {
-		return 0, err
+		return nil, fmt.Errorf("ошибка получения пользователя: %w", err)
+	}
+
+	accessToken, err := s.generateToken(session.UserID, user.Role, session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := randomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	// Refresh-токен не переживает сессию
+	expiresAt := time.Now().Add(s.jwtCfg.RefreshExpiresIn)
+	if expiresAt.After(session.ExpiresAt) {
+		expiresAt = session.ExpiresAt
+	}
+
+	err = s.tokenRepo.CreateRefreshToken(ctx, &models.RefreshToken{
+		SessionID: session.ID,
+		TokenHash: hashToken(refreshToken),
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка сохранения refresh-токена: %w", err)
+	}
+
+	return &dto.AuthResponse{
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(s.jwtCfg.ExpiresIn.Seconds()),
+	}, nil
+}
+
+// generateToken генерирует JWT-токен, подписанный асимметричным ключом
+func (s *authService) generateToken(userID int64, role models.Role, sessionID string) (string, error) {
+	jti, err := randomToken(16)
+	if err != nil {
+		return "", err
+	}
+
+	// Структура claims для JWT
+	claims := jwt.MapClaims{
+		"sub":  userID,                                    // subject (ID пользователя)
+		"exp":  time.Now().Add(s.jwtCfg.ExpiresIn).Unix(), // expiration time
+		"iat":  time.Now().Unix(),                         // issued at
+		"jti":  jti,                                       // ID токена для отзыва
+		"sid":  sessionID,                                 // ID сессии
+		"role": role,                                      // роль пользователя
+		"iss":  s.jwtCfg.Issuer,                           // issuer
+		"typ":  tokenTypeAccess,                           // тип токена
+	}
+
+	// Подписание токена текущим ключом (kid указывается в заголовке)
+	tokenString, err := s.keys.Sign(claims)
+	if err != nil {
+		return "", err
+	}
+
+	return tokenString, nil
+}
+
+// ParseToken разбирает и проверяет подпись и срок действия access-токена
+func (s *authService) ParseToken(tokenString string) (*TokenClaims, error) {
+	return s.parseClaims(tokenString, tokenTypeAccess)
+}
+
+// parseClaims разбирает JWT и проверяет, что он относится к ожидаемому типу
+func (s *authService) parseClaims(tokenString, tokenType string) (*TokenClaims, error) {
+	claims, err := s.verifyToken(tokenString, tokenType)
+	if err != nil {
+		return nil, err
+	}
+	return toTokenClaims(claims)
+}
+
+// verifyToken проверяет подпись, издателя, срок действия и тип JWT и возвращает его claims
+func (s *authService) verifyToken(tokenString, tokenType string) (jwt.MapClaims, error) {
+	// Ключ проверки выбирается по kid, допускаются только асимметричные алгоритмы
+	token, err := jwt.Parse(tokenString, s.keys.Keyfunc,
+		jwt.WithValidMethods(s.keys.ValidMethods()),
+		jwt.WithIssuer(s.jwtCfg.Issuer),
+		jwt.WithExpirationRequired(),
+	)
+
+	if err != nil {
+		return nil, err
 	}
 
 	// Проверка валидности токена
 	if !token.Valid {
-		return 0, errors.New("невалидный токен")
+		return nil, errors.New("невалидный токен")
 	}
 
 	// Извлечение claims
 	claims, ok := token.Claims.(jwt.MapClaims)
 	if !ok {
-		return 0, errors.New("невалидные claims")
+		return nil, errors.New("невалидные claims")
 	}
 
+	// Токен второго шага входа не должен приниматься как access-токен и наоборот
+	if typ, _ := claims["typ"].(string); typ != tokenType {
+		return nil, errors.New("неверный тип токена")
+	}
+
+	return claims, nil
+}
+
+// toTokenClaims извлекает из claims проверенного JWT данные, нужные сервисам
+func toTokenClaims(claims jwt.MapClaims) (*TokenClaims, error) {
 	// Извлечение ID пользователя
 	userID, ok := claims["sub"].(float64)
 	if !ok {
-		return 0, errors.New("невалидный ID пользователя")
+		return nil, errors.New("невалидный ID пользователя")
+	}
+
+	// Токены без jti нельзя отозвать, поэтому они не принимаются
+	jti, ok := claims["jti"].(string)
+	if !ok || jti == "" {
+		return nil, errors.New("отсутствует идентификатор токена")
+	}
+
+	sessionID, _ := claims["sid"].(string)
+
+	// Токены без роли получают наименьшие права
+	role := models.CUSTOMER
+	if r, ok := claims["role"].(string); ok && r != "" {
+		role = models.Role(r)
+	}
+
+	exp, err := claims.GetExpirationTime()
+	if err != nil || exp == nil {
+		return nil, errors.New("отсутствует срок действия токена")
 	}
 
-	return int64(userID), nil
+	return &TokenClaims{
+		UserID:    int64(userID),
+		Role:      role,
+		JTI:       jti,
+		SessionID: sessionID,
+		ExpiresAt: exp.Time,
+	}, nil
+}
+
+// ValidateToken проверяет JWT-токен и то, что ни он, ни его сессия не отозваны
+func (s *authService) ValidateToken(ctx context.Context, tokenString string) (*TokenClaims, error) {
+	claims, err := s.ParseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.CheckSession(ctx, claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// CheckSession проверяет, что access-токен и его сессия не отозваны (выход, смена пароля
+// или роли, повторное использование refresh-токена). Нужна для долгих соединений,
+// которые проверяют токен не только при подключении.
+func (s *authService) CheckSession(ctx context.Context, claims *TokenClaims) error {
+	revoked, err := s.tokenRepo.IsRevoked(ctx, claims.JTI, claims.SessionID)
+	if err != nil {
+		return fmt.Errorf("ошибка проверки отзыва токена: %w", err)
+	}
+	if revoked {
+		return ErrTokenRevoked
+	}
+	return nil
+}
+
+// IssueStreamTicket выпускает одноразовый билет для подключения к потоку изменений счетов.
+// Браузерный EventSource не передает заголовок Authorization, поэтому билет передается
+// в URL; он живет JWT_STREAM_TICKET_TTL и не дольше access-токена, из которого выпущен.
+// Поток, открытый по билету, привязан к этому access-токену: закрывается по его сроку
+// и при отзыве его сессии.
+func (s *authService) IssueStreamTicket(ctx context.Context, claims *TokenClaims) (*dto.StreamTicketResponse, error) {
+	jti, err := randomToken(16)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(s.jwtCfg.StreamTicketTTL)
+	if claims.ExpiresAt.Before(expiresAt) {
+		expiresAt = claims.ExpiresAt
+	}
+
+	ticket, err := s.keys.Sign(jwt.MapClaims{
+		"sub":  claims.UserID,
+		"exp":  expiresAt.Unix(),
+		"iat":  time.Now().Unix(),
+		"jti":  jti,
+		"sid":  claims.SessionID,
+		"role": claims.Role,
+		"iss":  s.jwtCfg.Issuer,
+		"typ":  tokenTypeStream,
+		"ajti": claims.JTI,              // access-токен, из которого выпущен билет
+		"aexp": claims.ExpiresAt.Unix(), // срок его действия
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.StreamTicketResponse{
+		Ticket:    ticket,
+		ExpiresIn: int64(time.Until(expiresAt).Seconds()),
+	}, nil
+}
+
+// RedeemStreamTicket погашает билет потока и возвращает данные access-токена,
+// из которого он выпущен. Повторно тот же билет не принимается.
+func (s *authService) RedeemStreamTicket(ctx context.Context, ticket string) (*TokenClaims, error) {
+	raw, err := s.verifyToken(ticket, tokenTypeStream)
+	if err != nil {
+		return nil, ErrInvalidStreamTicket
+	}
+	ticketClaims, err := toTokenClaims(raw)
+	if err != nil {
+		return nil, ErrInvalidStreamTicket
+	}
+
+	accessJTI, _ := raw["ajti"].(string)
+	accessExp, ok := raw["aexp"].(float64)
+	if accessJTI == "" || !ok {
+		return nil, ErrInvalidStreamTicket
+	}
+
+	consumed, err := s.tokenRepo.ConsumeToken(ctx, ticketClaims.JTI, ticketClaims.UserID, ticketClaims.ExpiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка погашения билета потока: %w", err)
+	}
+	if !consumed {
+		return nil, ErrInvalidStreamTicket
+	}
+
+	claims := &TokenClaims{
+		UserID:    ticketClaims.UserID,
+		Role:      ticketClaims.Role,
+		JTI:       accessJTI,
+		SessionID: ticketClaims.SessionID,
+		ExpiresAt: time.Unix(int64(accessExp), 0),
+	}
+	if err := s.CheckSession(ctx, claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// randomToken генерирует криптостойкую случайную строку из n байт
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken возвращает SHA-256 хеш токена для хранения в БД
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
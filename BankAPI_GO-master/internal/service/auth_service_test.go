@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/therealadik/bank-api/internal/config"
+	"github.com/therealadik/bank-api/internal/models"
+	"github.com/therealadik/bank-api/internal/repository"
+)
+
+// memoryRevocationRepository отозванные токены и сессии в памяти
+type memoryRevocationRepository struct {
+	repository.TokenRepository
+	tokens   map[string]bool
+	sessions map[string]bool
+}
+
+func (r *memoryRevocationRepository) ConsumeToken(_ context.Context, jti string, _ int64, _ time.Time) (bool, error) {
+	if r.tokens[jti] {
+		return false, nil
+	}
+	r.tokens[jti] = true
+	return true, nil
+}
+
+func (r *memoryRevocationRepository) IsRevoked(_ context.Context, jti string, sessionID string) (bool, error) {
+	return r.tokens[jti] || r.sessions[sessionID], nil
+}
+
+func newStreamTicketTest(t *testing.T) (*authService, *memoryRevocationRepository, *TokenClaims) {
+	t.Helper()
+
+	tokens := &memoryRevocationRepository{tokens: make(map[string]bool), sessions: make(map[string]bool)}
+	s := &authService{
+		tokenRepo: tokens,
+		keys:      newTestKeyManager(t, time.Now().Add(time.Hour)),
+		jwtCfg:    config.JWTConfig{Issuer: "bank-api", StreamTicketTTL: 30 * time.Second},
+	}
+	access := &TokenClaims{
+		UserID:    testUserID,
+		Role:      models.CUSTOMER,
+		JTI:       "access-jti",
+		SessionID: "session-1",
+		ExpiresAt: time.Now().Add(10 * time.Minute).Truncate(time.Second),
+	}
+	return s, tokens, access
+}
+
+// Билет потока принимается один раз и возвращает данные access-токена, из которого выпущен
+func TestStreamTicket(t *testing.T) {
+	ctx := context.Background()
+	s, _, access := newStreamTicketTest(t)
+
+	resp, err := s.IssueStreamTicket(ctx, access)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.ExpiresIn <= 0 || resp.ExpiresIn > 30 {
+		t.Fatalf("срок жизни билета %d секунд, ожидалось не больше 30", resp.ExpiresIn)
+	}
+
+	claims, err := s.RedeemStreamTicket(ctx, resp.Ticket)
+	if err != nil {
+		t.Fatalf("билет отклонен: %v", err)
+	}
+	if *claims != *access {
+		t.Fatalf("данные билета %+v, ожидались данные access-токена %+v", claims, access)
+	}
+
+	if _, err := s.RedeemStreamTicket(ctx, resp.Ticket); !errors.Is(err, ErrInvalidStreamTicket) {
+		t.Fatalf("повторный билет: %v, ожидалась ErrInvalidStreamTicket", err)
+	}
+}
+
+func TestStreamTicketRejected(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("access-токен вместо билета", func(t *testing.T) {
+		s, _, _ := newStreamTicketTest(t)
+		token, err := s.keys.Sign(jwt.MapClaims{
+			"sub": testUserID, "jti": "access-jti", "iss": "bank-api", "typ": tokenTypeAccess,
+			"exp": time.Now().Add(time.Minute).Unix(),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.RedeemStreamTicket(ctx, token); !errors.Is(err, ErrInvalidStreamTicket) {
+			t.Fatalf("access-токен принят как билет: %v", err)
+		}
+	})
+
+	t.Run("сессия отозвана", func(t *testing.T) {
+		s, tokens, access := newStreamTicketTest(t)
+		resp, err := s.IssueStreamTicket(ctx, access)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tokens.sessions[access.SessionID] = true
+		if _, err := s.RedeemStreamTicket(ctx, resp.Ticket); !errors.Is(err, ErrTokenRevoked) {
+			t.Fatalf("билет отозванной сессии: %v, ожидалась ErrTokenRevoked", err)
+		}
+	})
+
+	t.Run("срок не дольше access-токена", func(t *testing.T) {
+		s, _, access := newStreamTicketTest(t)
+		access.ExpiresAt = time.Now().Add(5 * time.Second)
+		resp, err := s.IssueStreamTicket(ctx, access)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.ExpiresIn > 5 {
+			t.Fatalf("билет живет %d секунд, дольше access-токена", resp.ExpiresIn)
+		}
+	})
+}
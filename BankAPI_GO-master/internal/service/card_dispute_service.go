@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/shopspring/decimal"
+	"github.com/therealadik/bank-api/internal/models/payment"
+	"github.com/therealadik/bank-api/internal/models/transaction"
+)
+
+// pgUniqueViolation код ошибки PostgreSQL при нарушении уникальности
+const pgUniqueViolation = "23505"
+
+// OpenDispute открывает спор держателя карты по проведенному платежу.
+// Если сумма не указана, оспаривается весь еще не возвращенный остаток.
+func (s *CardService) OpenDispute(ctx context.Context, userID int64, paymentID int64, amount decimal.NullDecimal,
+	reason string) (*payment.Dispute, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, ErrDisputeReasonRequired
+	}
+
+	p, err := s.paymentRepo.GetPaymentByID(ctx, paymentID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrPaymentNotFound
+		}
+		return nil, fmt.Errorf("ошибка получения платежа: %w", err)
+	}
+
+	// Оспорить платеж может только владелец карты
+	if _, err := s.getOwnedCard(ctx, p.CardID, userID); err != nil {
+		if errors.Is(err, ErrCardAccessDenied) || errors.Is(err, ErrCardNotFound) {
+			return nil, ErrPaymentNotFound
+		}
+		return nil, err
+	}
+
+	if p.Status != payment.CAPTURED && p.Status != payment.PARTIALLY_REFUNDED {
+		return nil, ErrPaymentNotCaptured
+	}
+
+	remaining := p.Amount.Sub(p.RefundedAmount)
+	disputed := remaining
+	if amount.Valid {
+		if amount.Decimal.LessThanOrEqual(decimal.Zero) {
+			return nil, ErrNegativeAmount
+		}
+		if amount.Decimal.GreaterThan(remaining) {
+			return nil, ErrRefundExceedsCaptured
+		}
+		disputed = amount.Decimal
+	}
+
+	d, err := s.disputeRepo.CreateDispute(ctx, &payment.Dispute{
+		PaymentID: paymentID,
+		OpenedBy:  userID,
+		Amount:    disputed,
+		Reason:    reason,
+		Status:    payment.DISPUTE_OPEN,
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+			return nil, ErrDisputeAlreadyOpen
+		}
+		return nil, fmt.Errorf("ошибка создания спора: %w", err)
+	}
+
+	return d, nil
+}
+
+// GetUserDisputes получает споры, открытые пользователем
+func (s *CardService) GetUserDisputes(ctx context.Context, userID int64) ([]*payment.Dispute, error) {
+	return s.disputeRepo.GetDisputesByUserID(ctx, userID)
+}
+
+// GetDisputesByStatus получает споры в указанном статусе для сотрудников поддержки
+func (s *CardService) GetDisputesByStatus(ctx context.Context, status payment.DisputeStatus) ([]*payment.Dispute, error) {
+	if status != "" && !status.IsValid() {
+		return nil, ErrInvalidDisputeStatus
+	}
+	return s.disputeRepo.GetDisputesByStatus(ctx, status)
+}
+
+// UpdateDisputeStatus переводит спор в новый статус по решению сотрудника поддержки.
+// При удовлетворении спора оспоренная сумма возвращается на счет карты (чарджбэк).
+func (s *CardService) UpdateDisputeStatus(ctx context.Context, staffID int64, disputeID int64,
+	status payment.DisputeStatus, comment string) (*payment.Dispute, error) {
+	if !status.IsValid() {
+		return nil, ErrInvalidDisputeStatus
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	disputeRepo := s.disputeRepo.WithTx(tx)
+
+	d, err := disputeRepo.GetDisputeByIDForUpdate(ctx, disputeID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrDisputeNotFound
+		}
+		return nil, fmt.Errorf("ошибка получения спора: %w", err)
+	}
+
+	if !d.Status.CanTransitionTo(status) {
+		return nil, ErrInvalidDisputeTransition
+	}
+
+	if status == payment.DISPUTE_ACCEPTED {
+		p, err := s.paymentRepo.WithTx(tx).GetPaymentByIDForUpdate(ctx, d.PaymentID)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка получения платежа: %w", err)
+		}
+
+		chargeback, err := s.refund(ctx, tx, p, d.Amount, transaction.CHARGEBACK)
+		if err != nil {
+			return nil, err
+		}
+		d.RefundTransactionID = &chargeback.ID
+	}
+
+	d.Status = status
+	d.ResolutionComment = strings.TrimSpace(comment)
+	d.ResolvedBy = &staffID
+
+	if err := disputeRepo.UpdateDispute(ctx, d); err != nil {
+		return nil, fmt.Errorf("ошибка обновления спора: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
@@ -0,0 +1,526 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/therealadik/bank-api/internal/models"
+	"github.com/therealadik/bank-api/internal/models/payment"
+	"github.com/therealadik/bank-api/internal/models/transaction"
+	"github.com/therealadik/bank-api/internal/repository"
+)
+
+// expiredHoldsBatchSize количество истекших холдов, обрабатываемых за один проход
+const expiredHoldsBatchSize = 100
+
+// AuthorizePayment проверяет данные карты и лимиты расходов и ставит холд на счет карты.
+// Платить картой может только ее владелец.
+// Платеж, задержанный антифрод-проверкой, получает статус PENDING_REVIEW.
+// Для холда создается транзакция в статусе PENDING, которая проводится при списании.
+// Проверка лимитов и холд выполняются в одной транзакции с блокировкой строки карты,
+// поэтому лимиты соблюдаются при работе нескольких экземпляров API.
+func (s *CardService) AuthorizePayment(ctx context.Context, initiatorID int64, cardID int64, cvv string, pgpKey string,
+	amount decimal.Decimal, merchantCategory string) (*payment.CardPayment, error) {
+	if amount.LessThanOrEqual(decimal.Zero) {
+		return nil, ErrNegativeAmount
+	}
+
+	if merchantCategory != "" && !isValidMCC(merchantCategory) {
+		return nil, ErrInvalidMerchantCategory
+	}
+
+	if err := s.VerifyCardPayment(ctx, initiatorID, cardID, cvv, pgpKey); err != nil {
+		if errors.Is(err, ErrCardVerificationFailed) || errors.Is(err, ErrCardBlocked) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %v", ErrCardVerificationFailed, err)
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	cardRepo := s.cardRepo.WithTx(tx)
+	paymentRepo := s.paymentRepo.WithTx(tx)
+
+	if err := cardRepo.LockCard(ctx, cardID); err != nil {
+		return nil, fmt.Errorf("ошибка блокировки карты: %w", err)
+	}
+
+	card, err := cardRepo.GetCardByID(ctx, cardID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения карты: %w", err)
+	}
+	if card.Status == models.CARD_BLOCKED {
+		return nil, ErrCardBlocked
+	}
+	if card.AccountID == nil {
+		return nil, ErrCardNotLinked
+	}
+
+	if err := s.checkPaymentAccount(ctx, tx, *card.AccountID); err != nil {
+		return nil, err
+	}
+
+	limit, err := cardRepo.GetCardLimit(ctx, cardID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения лимитов карты: %w", err)
+	}
+
+	if limit != nil {
+		if err := s.checkCardLimit(ctx, paymentRepo, limit, amount, merchantCategory); err != nil {
+			return nil, err
+		}
+	}
+
+	// Подозрительный платеж получает холд, но списать его можно только после одобрения администратором
+	assessment, err := s.fraud.AssessCardPaymentTx(ctx, tx, card.UserID, cardID, amount)
+	if err != nil {
+		return nil, err
+	}
+	held := s.fraud.RequiresReview(assessment)
+
+	// Ставим холд на сумму платежа
+	reserved, err := s.accountRepo.WithTx(tx).ReserveFunds(ctx, *card.AccountID, amount)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка блокировки средств: %w", err)
+	}
+	if !reserved {
+		return nil, ErrInsufficientFunds
+	}
+
+	pending, err := s.transactionRepo.WithTx(tx).CreateTransaction(ctx, *card.AccountID, amount,
+		transaction.WITHDRAWAL, transaction.PENDING)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка записи транзакции: %w", err)
+	}
+
+	newPayment := &payment.CardPayment{
+		CardID:           cardID,
+		AccountID:        card.AccountID,
+		TransactionID:    &pending.ID,
+		InitiatorID:      &initiatorID,
+		Amount:           amount,
+		MerchantCategory: merchantCategory,
+		Status:           payment.AUTHORIZED,
+	}
+	if held {
+		// Срок холда начинает идти после одобрения
+		newPayment.Status = payment.PENDING_REVIEW
+	} else {
+		expiresAt := time.Now().Add(s.paymentCfg.HoldTTL)
+		newPayment.ExpiresAt = &expiresAt
+	}
+
+	p, err := paymentRepo.CreatePayment(ctx, newPayment)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка записи платежа: %w", err)
+	}
+	if err := s.webhooks.CardPaymentTx(ctx, tx, p); err != nil {
+		return nil, err
+	}
+
+	if held {
+		if err := s.fraud.HoldCardPaymentTx(ctx, tx, card.UserID, p, assessment); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// CapturePayment списывает средства по авторизованному платежу.
+// Если сумма не указана, списывается вся сумма холда; остаток холда освобождается.
+// Выполняется сотрудником (actorID): владелец карты не может сам провести свой платеж.
+func (s *CardService) CapturePayment(ctx context.Context, actorID int64, paymentID int64,
+	amount decimal.NullDecimal) (*payment.CardPayment, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	paymentRepo := s.paymentRepo.WithTx(tx)
+
+	p, err := s.getAuthorizedPayment(ctx, paymentRepo, paymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	captured := p.Amount
+	if amount.Valid {
+		if amount.Decimal.LessThanOrEqual(decimal.Zero) {
+			return nil, ErrNegativeAmount
+		}
+		if amount.Decimal.GreaterThan(p.Amount) {
+			return nil, ErrCaptureExceedsHold
+		}
+		captured = amount.Decimal
+	}
+
+	if err := s.checkPaymentAccount(ctx, tx, *p.AccountID); err != nil {
+		return nil, err
+	}
+
+	if err := s.accountRepo.WithTx(tx).CaptureFunds(ctx, *p.AccountID, p.Amount, captured); err != nil {
+		return nil, fmt.Errorf("ошибка списания средств: %w", err)
+	}
+
+	if p.TransactionID != nil {
+		t, err := s.transactionRepo.WithTx(tx).UpdateTransaction(ctx, *p.TransactionID, captured, transaction.COMPLETED)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка проведения транзакции: %w", err)
+		}
+		if err := s.webhooks.TransactionCompletedTx(ctx, tx, t); err != nil {
+			return nil, err
+		}
+	}
+
+	p, err = paymentRepo.UpdatePayment(ctx, p.ID, captured, payment.CAPTURED)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка обновления платежа: %w", err)
+	}
+	if err := s.webhooks.CardPaymentTx(ctx, tx, p); err != nil {
+		return nil, err
+	}
+
+	err = s.audit.RecordTx(ctx, tx, AuditEvent{
+		ActorID:    &actorID,
+		Action:     models.ADMIN_CAPTURE_PAYMENT,
+		TargetType: models.AuditTargetPayment,
+		TargetID:   &p.ID,
+		Details:    fmt.Sprintf("amount=%s", captured),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// VoidPayment отменяет авторизованный платеж и снимает холд
+func (s *CardService) VoidPayment(ctx context.Context, initiatorID int64, paymentID int64) (*payment.CardPayment, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	paymentRepo := s.paymentRepo.WithTx(tx)
+
+	p, err := s.lockPayment(ctx, paymentRepo, paymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Отменить авторизацию может только тот, кто ее провел
+	if p.InitiatorID == nil || *p.InitiatorID != initiatorID {
+		return nil, ErrPaymentNotFound
+	}
+	if err := checkPaymentAuthorized(p); err != nil {
+		return nil, err
+	}
+
+	if err := s.releaseHold(ctx, tx, p, payment.VOIDED); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// RefundPayment возвращает полностью или частично списанную сумму платежа на счет карты.
+// Если сумма не указана, возвращается весь еще не возвращенный остаток.
+// Выполняется сотрудником (actorID): клиент оспаривает платеж через спор.
+func (s *CardService) RefundPayment(ctx context.Context, actorID int64, paymentID int64,
+	amount decimal.NullDecimal) (*payment.CardPayment, *transaction.Transaction, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	p, err := s.lockPayment(ctx, s.paymentRepo.WithTx(tx), paymentID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	refundAmount := p.Amount.Sub(p.RefundedAmount)
+	if amount.Valid {
+		refundAmount = amount.Decimal
+	}
+
+	refundTx, err := s.refund(ctx, tx, p, refundAmount, transaction.REFUND)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = s.audit.RecordTx(ctx, tx, AuditEvent{
+		ActorID:    &actorID,
+		Action:     models.ADMIN_REFUND_PAYMENT,
+		TargetType: models.AuditTargetPayment,
+		TargetID:   &p.ID,
+		Details:    fmt.Sprintf("amount=%s refund_transaction_id=%d", refundTx.Amount, refundTx.ID),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	return p, refundTx, nil
+}
+
+// refund зачисляет сумму возврата на счет платежа, создает транзакцию возврата,
+// связанную с исходной, и обновляет возвращенную сумму платежа.
+// Платеж должен быть заблокирован в текущей транзакции.
+func (s *CardService) refund(ctx context.Context, tx pgx.Tx, p *payment.CardPayment, amount decimal.Decimal,
+	txType transaction.Type) (*transaction.Transaction, error) {
+	if (p.Status != payment.CAPTURED && p.Status != payment.PARTIALLY_REFUNDED) || p.AccountID == nil {
+		return nil, ErrPaymentNotCaptured
+	}
+
+	if amount.LessThanOrEqual(decimal.Zero) {
+		return nil, ErrNegativeAmount
+	}
+
+	remaining := p.Amount.Sub(p.RefundedAmount)
+	if amount.GreaterThan(remaining) {
+		return nil, ErrRefundExceedsCaptured
+	}
+
+	if err := s.checkPaymentAccount(ctx, tx, *p.AccountID); err != nil {
+		return nil, err
+	}
+
+	if err := s.accountRepo.WithTx(tx).UpdateBalance(ctx, *p.AccountID, amount); err != nil {
+		return nil, fmt.Errorf("ошибка зачисления возврата: %w", err)
+	}
+
+	refundTx, err := s.transactionRepo.WithTx(tx).CreateLinkedTransaction(ctx, *p.AccountID, amount, txType,
+		transaction.COMPLETED, p.TransactionID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка записи транзакции возврата: %w", err)
+	}
+	if err := s.webhooks.TransactionCompletedTx(ctx, tx, refundTx); err != nil {
+		return nil, err
+	}
+
+	status := payment.PARTIALLY_REFUNDED
+	if amount.Equal(remaining) {
+		status = payment.REFUNDED
+	}
+
+	refunded, err := s.paymentRepo.WithTx(tx).AddRefund(ctx, p.ID, amount, status)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка обновления платежа: %w", err)
+	}
+	if err := s.webhooks.CardPaymentTx(ctx, tx, refunded); err != nil {
+		return nil, err
+	}
+
+	*p = *refunded
+	return refundTx, nil
+}
+
+// ExpireHolds снимает холды, срок действия которых истек.
+// Каждый холд обрабатывается в отдельной транзакции с блокировкой строки платежа,
+// поэтому задачу можно запускать одновременно на нескольких экземплярах API.
+func (s *CardService) ExpireHolds(ctx context.Context) (int, error) {
+	ids, err := s.paymentRepo.GetExpiredHoldIDs(ctx, time.Now(), expiredHoldsBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка получения истекших холдов: %w", err)
+	}
+
+	expired := 0
+	for _, id := range ids {
+		ok, err := s.expireHold(ctx, id)
+		if err != nil {
+			return expired, err
+		}
+		if ok {
+			expired++
+		}
+	}
+
+	return expired, nil
+}
+
+// RunHoldExpiry периодически снимает истекшие холды до отмены контекста
+func (s *CardService) RunHoldExpiry(ctx context.Context, logger *logrus.Logger) {
+	ticker := time.NewTicker(s.paymentCfg.ExpiryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			expired, err := s.ExpireHolds(ctx)
+			if err != nil {
+				logger.Errorf("Ошибка снятия истекших холдов: %v", err)
+			}
+			if expired > 0 {
+				logger.Infof("Снято истекших холдов: %d", expired)
+			}
+		}
+	}
+}
+
+// expireHold снимает один истекший холд; возвращает false, если платеж уже обработан
+func (s *CardService) expireHold(ctx context.Context, paymentID int64) (bool, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	p, err := s.paymentRepo.WithTx(tx).GetPaymentByIDForUpdate(ctx, paymentID)
+	if err != nil {
+		return false, fmt.Errorf("ошибка получения платежа: %w", err)
+	}
+
+	// Платеж мог быть списан или отменен другим экземпляром
+	if p.Status != payment.AUTHORIZED || p.ExpiresAt == nil || p.ExpiresAt.After(time.Now()) {
+		return false, nil
+	}
+
+	if err := s.releaseHold(ctx, tx, p, payment.EXPIRED); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit(ctx)
+}
+
+// releaseHold возвращает сумму холда в доступный баланс и закрывает платеж с указанным статусом.
+// p обновляется до нового состояния платежа.
+func (s *CardService) releaseHold(ctx context.Context, tx pgx.Tx, p *payment.CardPayment, status payment.Status) error {
+	if err := s.accountRepo.WithTx(tx).ReleaseFunds(ctx, *p.AccountID, p.Amount); err != nil {
+		return fmt.Errorf("ошибка снятия холда: %w", err)
+	}
+
+	if p.TransactionID != nil {
+		_, err := s.transactionRepo.WithTx(tx).UpdateTransaction(ctx, *p.TransactionID, p.Amount, transaction.FAILED)
+		if err != nil {
+			return fmt.Errorf("ошибка отмены транзакции: %w", err)
+		}
+	}
+
+	released, err := s.paymentRepo.WithTx(tx).UpdatePayment(ctx, p.ID, p.Amount, status)
+	if err != nil {
+		return fmt.Errorf("ошибка обновления платежа: %w", err)
+	}
+	if err := s.webhooks.CardPaymentTx(ctx, tx, released); err != nil {
+		return err
+	}
+
+	*p = *released
+	return nil
+}
+
+// checkPaymentAccount блокирует счет платежа в текущей транзакции и проверяет,
+// что по нему разрешено движение средств. Отмена холда и его истечение
+// по замороженному счету допускаются: они лишь освобождают средства.
+func (s *CardService) checkPaymentAccount(ctx context.Context, tx pgx.Tx, accountID int64) error {
+	acc, err := s.accountRepo.WithTx(tx).GetAccountForUpdate(ctx, accountID)
+	if err != nil {
+		return fmt.Errorf("ошибка получения счета карты: %w", err)
+	}
+	return checkAccountActive(acc)
+}
+
+// lockPayment получает платеж и блокирует его до конца транзакции
+func (s *CardService) lockPayment(ctx context.Context, paymentRepo *repository.PaymentRepository,
+	paymentID int64) (*payment.CardPayment, error) {
+	p, err := paymentRepo.GetPaymentByIDForUpdate(ctx, paymentID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrPaymentNotFound
+		}
+		return nil, fmt.Errorf("ошибка получения платежа: %w", err)
+	}
+	return p, nil
+}
+
+// getAuthorizedPayment блокирует платеж и проверяет, что он авторизован и холд еще не истек
+func (s *CardService) getAuthorizedPayment(ctx context.Context, paymentRepo *repository.PaymentRepository,
+	paymentID int64) (*payment.CardPayment, error) {
+	p, err := s.lockPayment(ctx, paymentRepo, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	return p, checkPaymentAuthorized(p)
+}
+
+// checkPaymentAuthorized проверяет, что платеж авторизован и холд еще не истек
+func checkPaymentAuthorized(p *payment.CardPayment) error {
+	if p.Status != payment.AUTHORIZED || p.AccountID == nil {
+		return ErrPaymentNotAuthorized
+	}
+
+	if p.ExpiresAt != nil && p.ExpiresAt.Before(time.Now()) {
+		return ErrPaymentNotAuthorized
+	}
+
+	return nil
+}
+
+// checkCardLimit проверяет платеж на соответствие лимитам карты
+// с учетом уже проведенных платежей и действующих холдов за текущие сутки и месяц (UTC)
+func (s *CardService) checkCardLimit(ctx context.Context, paymentRepo *repository.PaymentRepository,
+	limit *models.CardLimit, amount decimal.Decimal, merchantCategory string) error {
+	for _, mcc := range limit.BlockedCategories {
+		if mcc == merchantCategory {
+			return ErrMerchantCategoryBlocked
+		}
+	}
+
+	if limit.PerTransaction.Valid && amount.GreaterThan(limit.PerTransaction.Decimal) {
+		return ErrPerTransactionLimitExceeded
+	}
+
+	// Сутки и месяц считаются в UTC, как и у лимитов переводов, а не в часовом поясе сервера
+	startOfDay, startOfMonth := periodStarts(time.Now().UTC())
+
+	if limit.Daily.Valid {
+		spent, err := paymentRepo.SumPaymentsSince(ctx, limit.CardID, startOfDay)
+		if err != nil {
+			return fmt.Errorf("ошибка подсчета дневных расходов: %w", err)
+		}
+		if spent.Add(amount).GreaterThan(limit.Daily.Decimal) {
+			return ErrDailyLimitExceeded
+		}
+	}
+
+	if limit.Monthly.Valid {
+		spent, err := paymentRepo.SumPaymentsSince(ctx, limit.CardID, startOfMonth)
+		if err != nil {
+			return fmt.Errorf("ошибка подсчета месячных расходов: %w", err)
+		}
+		if spent.Add(amount).GreaterThan(limit.Monthly.Decimal) {
+			return ErrMonthlyLimitExceeded
+		}
+	}
+
+	return nil
+}
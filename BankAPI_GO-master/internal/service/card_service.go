@@ -12,23 +12,75 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+	"github.com/therealadik/bank-api/internal/config"
 	"github.com/therealadik/bank-api/internal/models"
+	"github.com/therealadik/bank-api/internal/models/account"
 	"github.com/therealadik/bank-api/internal/repository"
 	"golang.org/x/crypto/bcrypt"
 )
 
+var (
+	ErrCardNotFound                = newNotFoundError("карта не найдена")
+	ErrCardAccessDenied            = newForbiddenError("доступ запрещен: карта не принадлежит пользователю")
+	ErrCardVerificationFailed      = errors.New("ошибка проверки данных карты")
+	ErrInvalidLimit                = errors.New("лимит должен быть положительным")
+	ErrInvalidMerchantCategory     = errors.New("код категории торговца должен состоять из 4 цифр")
+	ErrMerchantCategoryBlocked     = errors.New("категория торговца запрещена для карты")
+	ErrPerTransactionLimitExceeded = errors.New("превышен лимит на одну операцию")
+	ErrDailyLimitExceeded          = errors.New("превышен дневной лимит по карте")
+	ErrMonthlyLimitExceeded        = errors.New("превышен месячный лимит по карте")
+	ErrCardNotLinked               = errors.New("карта не привязана к счету")
+	ErrCardBlocked                 = errors.New("карта заблокирована")
+	ErrPaymentNotFound             = newNotFoundError("платеж не найден")
+	ErrPaymentNotAuthorized        = errors.New("платеж не находится в статусе авторизации")
+	ErrCaptureExceedsHold          = errors.New("сумма списания превышает сумму холда")
+	ErrPaymentNotCaptured          = errors.New("платеж не проведен или уже полностью возвращен")
+	ErrRefundExceedsCaptured       = errors.New("сумма возврата превышает списанную сумму")
+	ErrDisputeNotFound             = newNotFoundError("спор не найден")
+	ErrDisputeAlreadyOpen          = errors.New("по платежу уже открыт спор")
+	ErrDisputeReasonRequired       = errors.New("необходимо указать причину спора")
+	ErrInvalidDisputeTransition    = errors.New("недопустимый переход статуса спора")
+	ErrInvalidDisputeStatus        = errors.New("неизвестный статус спора")
+)
+
 type CardService struct {
-	cardRepo      *repository.CardRepository
-	db            *pgxpool.Pool
-	encryptionKey []byte // Ключ для HMAC
+	cardRepo        *repository.CardRepository
+	accountRepo     *repository.AccountRepository
+	transactionRepo *repository.TransactionRepository
+	paymentRepo     *repository.PaymentRepository
+	disputeRepo     *repository.DisputeRepository
+	userRepo        repository.UserRepository
+	notifier        SecurityNotifier
+	fraud           *FraudService
+	audit           *AuditService
+	webhooks        *WebhookService
+	db              *pgxpool.Pool
+	encryptionKey   []byte // Ключ для HMAC
+	paymentCfg      config.PaymentConfig
 }
 
-func NewCardService(cardRepo *repository.CardRepository, db *pgxpool.Pool, encryptionKey string) *CardService {
+func NewCardService(cardRepo *repository.CardRepository, accountRepo *repository.AccountRepository,
+	transactionRepo *repository.TransactionRepository, paymentRepo *repository.PaymentRepository,
+	disputeRepo *repository.DisputeRepository, userRepo repository.UserRepository, notifier SecurityNotifier,
+	fraud *FraudService, audit *AuditService, webhooks *WebhookService, db *pgxpool.Pool,
+	encryptionKey string, paymentCfg config.PaymentConfig) *CardService {
 	return &CardService{
-		cardRepo:      cardRepo,
-		db:            db,
-		encryptionKey: []byte(encryptionKey),
+		cardRepo:        cardRepo,
+		accountRepo:     accountRepo,
+		transactionRepo: transactionRepo,
+		paymentRepo:     paymentRepo,
+		disputeRepo:     disputeRepo,
+		userRepo:        userRepo,
+		notifier:        notifier,
+		fraud:           fraud,
+		audit:           audit,
+		webhooks:        webhooks,
+		db:              db,
+		encryptionKey:   []byte(encryptionKey),
+		paymentCfg:      paymentCfg,
 	}
 }
 
@@ -133,8 +185,23 @@ func (s *CardService) validateCVV(cvv string, hash string) bool {
 	return err == nil
 }
 
-// CreateCard создает новую виртуальную карту
-func (s *CardService) CreateCard(ctx context.Context, userID int64, pgpKey string) (*models.Card, map[string]string, error) {
+// CreateCard создает новую виртуальную карту, привязанную к счету пользователя
+func (s *CardService) CreateCard(ctx context.Context, userID int64, accountID int64, pgpKey string) (*models.Card, map[string]string, error) {
+	// Проверяем, что счет принадлежит пользователю
+	acc, err := s.accountRepo.GetAccountByID(ctx, accountID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, ErrAccountNotOwned
+		}
+		return nil, nil, fmt.Errorf("ошибка получения счета: %w", err)
+	}
+	if acc.UserID != userID {
+		return nil, nil, ErrAccountNotOwned
+	}
+	if acc.Status == account.CLOSED {
+		return nil, nil, ErrAccountClosed
+	}
+
 	// Генерируем данные карты
 	cardNumber, err := s.generateCardNumber()
 	if err != nil {
@@ -164,12 +231,33 @@ func (s *CardService) CreateCard(ctx context.Context, userID int64, pgpKey strin
 		return nil, nil, fmt.Errorf("ошибка хеширования CVV: %w", err)
 	}
 
-	// Создаем запись в БД
-	card, err := s.cardRepo.CreateCard(ctx, userID, encryptedNumber, encryptedExpire, cvvHash)
+	// Создаем запись в БД вместе с записью в журнале аудита
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	card, err := s.cardRepo.WithTx(tx).CreateCard(ctx, userID, accountID, encryptedNumber, encryptedExpire, cvvHash)
 	if err != nil {
 		return nil, nil, fmt.Errorf("ошибка создания карты в БД: %w", err)
 	}
 
+	err = s.audit.RecordTx(ctx, tx, AuditEvent{
+		ActorID:    &userID,
+		Action:     models.AUDIT_CARD_ISSUED,
+		TargetType: models.AuditTargetCard,
+		TargetID:   &card.ID,
+		Details:    fmt.Sprintf("account_id=%d", accountID),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, err
+	}
+
 	// Генерируем цифровую подпись для проверки целостности
 	message := fmt.Sprintf("%d:%s:%s:%s", card.ID, cardNumber, expireDate, cvv)
 	signature := s.generateHMAC(message)
@@ -215,6 +303,16 @@ func (s *CardService) GetCardDetails(ctx context.Context, cardID int64, userID i
 		return nil, fmt.Errorf("ошибка расшифровки срока действия: %w", err)
 	}
 
+	err = s.audit.Record(ctx, AuditEvent{
+		ActorID:    &userID,
+		Action:     models.AUDIT_CARD_DETAILS_VIEWED,
+		TargetType: models.AuditTargetCard,
+		TargetID:   &cardID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	// Маскируем номер карты для безопасности (отображаем только последние 4 цифры)
 	maskedNumber := "**** **** **** " + cardNumber[len(cardNumber)-4:]
 
@@ -232,40 +330,65 @@ func (s *CardService) GetUserCards(ctx context.Context, userID int64) ([]*models
 	return s.cardRepo.GetCardsByUserID(ctx, userID)
 }
 
-// VerifyCardPayment проверяет данные карты для платежа
-func (s *CardService) VerifyCardPayment(ctx context.Context, cardID int64, cvv string, pgpKey string) (bool, error) {
+// dummyCVVHash сравнивается с CVV, когда карта не найдена, чтобы время ответа
+// не выдавало существование карты
+const dummyCVVHash = "$2a$10$O.Wn6/dtFbCTiD5fLm6OGuX2doG.3cSW9dzRCMYg//F7PNN55TfVa"
+
+// VerifyCardPayment проверяет данные карты для платежа. Платить картой может только ее владелец.
+// Для несуществующей и чужой карты, неверного CVV и просроченной карты возвращается одна и та же
+// ErrCardVerificationFailed, чтобы по ответу нельзя было перебирать номера карт.
+// Неверные CVV владельца считаются подряд; при достижении порога карта блокируется,
+// а владелец получает уведомление.
+func (s *CardService) VerifyCardPayment(ctx context.Context, userID int64, cardID int64, cvv string, pgpKey string) error {
 	// Получаем карту
 	card, err := s.cardRepo.GetCardByID(ctx, cardID)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return false, errors.New("карта не найдена")
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.validateCVV(cvv, dummyCVVHash)
+			return ErrCardVerificationFailed
 		}
-		return false, fmt.Errorf("ошибка получения карты: %w", err)
+		return fmt.Errorf("ошибка получения карты: %w", err)
 	}
 
 	// Проверяем CVV
 	isValidCVV := s.validateCVV(cvv, card.CVVHash)
+
+	// Попытки по чужой карте не учитываются, иначе любой мог бы заблокировать чужую карту
+	if card.UserID != userID {
+		return ErrCardVerificationFailed
+	}
+	if card.Status == models.CARD_BLOCKED {
+		return ErrCardBlocked
+	}
+
 	if !isValidCVV {
-		return false, errors.New("неверный CVV код")
+		if err := s.recordCVVFailure(ctx, card); err != nil {
+			return err
+		}
+		return ErrCardVerificationFailed
+	}
+
+	if err := s.cardRepo.ResetVerificationFailures(ctx, cardID); err != nil {
+		return fmt.Errorf("ошибка сброса счетчика проверок CVV: %w", err)
 	}
 
 	// Проверяем срок действия
 	expire, err := s.decryptWithPGP(ctx, card.Expire, pgpKey)
 	if err != nil {
-		return false, fmt.Errorf("ошибка расшифровки срока действия: %w", err)
+		return fmt.Errorf("ошибка расшифровки срока действия: %w", err)
 	}
 
 	// Расшифровываем номер карты (нужен для формирования HMAC)
 	cardNumber, err := s.decryptWithPGP(ctx, card.CardNumber, pgpKey)
 	if err != nil {
-		return false, fmt.Errorf("ошибка расшифровки номера карты: %w", err)
+		return fmt.Errorf("ошибка расшифровки номера карты: %w", err)
 	}
 
 	// Парсим дату из формата MM/YY
 	var month, year int
 	_, err = fmt.Sscanf(expire, "%d/%d", &month, &year)
 	if err != nil {
-		return false, fmt.Errorf("ошибка парсинга срока действия: %w", err)
+		return fmt.Errorf("ошибка парсинга срока действия: %w", err)
 	}
 
 	// Добавляем 2000 к году (для формата YY)
@@ -278,7 +401,7 @@ func (s *CardService) VerifyCardPayment(ctx context.Context, cardID int64, cvv s
 	expiryDate = expiryDate.AddDate(0, 1, -1)
 
 	if now.After(expiryDate) {
-		return false, errors.New("карта просрочена")
+		return ErrCardVerificationFailed
 	}
 
 	// Генерируем цифровую подпись для проверки целостности
@@ -289,11 +412,138 @@ func (s *CardService) VerifyCardPayment(ctx context.Context, cardID int64, cvv s
 	// с сохраненной при создании карты, но в данном случае просто
 	// проверяем, что подпись корректно сформирована
 	if len(hmacSignature) == 0 {
-		return false, errors.New("ошибка генерации HMAC-подписи")
+		return errors.New("ошибка генерации HMAC-подписи")
 	}
 
 	// Проверка успешна
-	return true, nil
+	return nil
+}
+
+// recordCVVFailure учитывает неверный CVV и блокирует карту при достижении порога.
+// Неудачные попытки также учитываются антифрод-проверкой следующих платежей по карте.
+func (s *CardService) recordCVVFailure(ctx context.Context, card *models.Card) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	cardRepo := s.cardRepo.WithTx(tx)
+
+	attempts, err := cardRepo.RecordVerificationFailure(ctx, card.ID)
+	if err != nil {
+		return fmt.Errorf("ошибка записи неудачной проверки CVV: %w", err)
+	}
+
+	blocked := false
+	if attempts >= s.paymentCfg.CVVMaxAttempts {
+		blocked, err = cardRepo.BlockActive(ctx, card.ID)
+		if err != nil {
+			return fmt.Errorf("ошибка блокировки карты: %w", err)
+		}
+	}
+
+	if blocked {
+		err = s.audit.RecordTx(ctx, tx, AuditEvent{
+			Action:     models.AUDIT_CARD_BLOCKED_CVV,
+			TargetType: models.AuditTargetCard,
+			TargetID:   &card.ID,
+			Details:    fmt.Sprintf("failed_cvv_attempts=%d", attempts),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	if !blocked {
+		return nil
+	}
+
+	user, err := s.userRepo.GetByID(ctx, card.UserID)
+	if err != nil {
+		return fmt.Errorf("карта заблокирована, но не удалось получить владельца для уведомления: %w", err)
+	}
+	if err := s.notifier.NotifyCardBlocked(ctx, user, card.ID); err != nil {
+		return fmt.Errorf("карта заблокирована, но не удалось уведомить владельца: %w", err)
+	}
+	return nil
+}
+
+// getOwnedCard получает карту с проверкой владения
+func (s *CardService) getOwnedCard(ctx context.Context, cardID int64, userID int64) (*models.Card, error) {
+	card, err := s.cardRepo.GetCardByID(ctx, cardID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrCardNotFound
+		}
+		return nil, fmt.Errorf("ошибка получения карты: %w", err)
+	}
+
+	if card.UserID != userID {
+		return nil, ErrCardAccessDenied
+	}
+
+	return card, nil
+}
+
+// GetCardLimit получает лимиты карты (только для владельца).
+// Если лимиты не заданы, возвращаются пустые лимиты.
+func (s *CardService) GetCardLimit(ctx context.Context, cardID int64, userID int64) (*models.CardLimit, error) {
+	if _, err := s.getOwnedCard(ctx, cardID, userID); err != nil {
+		return nil, err
+	}
+
+	limit, err := s.cardRepo.GetCardLimit(ctx, cardID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения лимитов карты: %w", err)
+	}
+
+	if limit == nil {
+		limit = &models.CardLimit{CardID: cardID, BlockedCategories: []string{}}
+	}
+
+	return limit, nil
+}
+
+// SetCardLimit устанавливает лимиты карты (только для владельца)
+func (s *CardService) SetCardLimit(ctx context.Context, userID int64, limit *models.CardLimit) (*models.CardLimit, error) {
+	if _, err := s.getOwnedCard(ctx, limit.CardID, userID); err != nil {
+		return nil, err
+	}
+
+	for _, l := range []decimal.NullDecimal{limit.PerTransaction, limit.Daily, limit.Monthly} {
+		if l.Valid && l.Decimal.LessThanOrEqual(decimal.Zero) {
+			return nil, ErrInvalidLimit
+		}
+	}
+
+	if limit.BlockedCategories == nil {
+		limit.BlockedCategories = []string{}
+	}
+	for _, mcc := range limit.BlockedCategories {
+		if !isValidMCC(mcc) {
+			return nil, ErrInvalidMerchantCategory
+		}
+	}
+
+	return s.cardRepo.UpsertCardLimit(ctx, limit)
+}
+
+// isValidMCC проверяет формат кода категории торговца (4 цифры)
+func isValidMCC(mcc string) bool {
+	if len(mcc) != 4 {
+		return false
+	}
+	for _, c := range mcc {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
 }
 
 // generateHMAC создает HMAC-SHA256 для данных
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+	"github.com/therealadik/bank-api/internal/config"
+	"github.com/therealadik/bank-api/internal/repository"
+)
+
+// CreditOverdueService находит просроченные платежи по графикам кредитов и сообщает
+// о них владельцам счетов событием credit.overdue. Платеж просрочен, если он не оплачен,
+// а дата платежа (UTC) прошла. О каждом платеже сообщается один раз: отметка о просрочке
+// и событие фиксируются в одной транзакции.
+type CreditOverdueService struct {
+	scheduleRepo *repository.PaymentScheduleRepository
+	webhooks     *WebhookService
+	db           *pgxpool.Pool
+	cfg          config.CreditOverdueConfig
+}
+
+// NewCreditOverdueService создает сервис поиска просроченных платежей
+func NewCreditOverdueService(scheduleRepo *repository.PaymentScheduleRepository, webhooks *WebhookService,
+	db *pgxpool.Pool, cfg config.CreditOverdueConfig) *CreditOverdueService {
+	return &CreditOverdueService{
+		scheduleRepo: scheduleRepo,
+		webhooks:     webhooks,
+		db:           db,
+		cfg:          cfg,
+	}
+}
+
+// NotifyOverdue ставит в очередь события о всех новых просрочках. Возвращает число платежей.
+func (s *CreditOverdueService) NotifyOverdue(ctx context.Context) (int, error) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	total := 0
+	for {
+		n, err := s.notifyBatch(ctx, today)
+		total += n
+		if err != nil {
+			return total, err
+		}
+		if n < s.cfg.BatchSize {
+			return total, nil
+		}
+	}
+}
+
+// notifyBatch отмечает до BatchSize просроченных платежей и ставит события о них в очередь
+func (s *CreditOverdueService) notifyBatch(ctx context.Context, today time.Time) (int, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	installments, err := s.scheduleRepo.WithTx(tx).ClaimOverdue(ctx, today, s.cfg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, inst := range installments {
+		if err := s.webhooks.CreditOverdueTx(ctx, tx, inst); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return len(installments), nil
+}
+
+// RunOverdueCheck периодически ищет просроченные платежи до отмены контекста
+func (s *CreditOverdueService) RunOverdueCheck(ctx context.Context, logger *logrus.Logger) {
+	ticker := time.NewTicker(s.cfg.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			notified, err := s.NotifyOverdue(ctx)
+			if err != nil {
+				logger.Errorf("Ошибка обработки просроченных платежей по кредитам: %v", err)
+			}
+			if notified > 0 {
+				logger.Infof("Обработано просроченных платежей по кредитам: %d", notified)
+			}
+		}
+	}
+}
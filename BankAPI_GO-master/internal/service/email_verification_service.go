@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/therealadik/bank-api/internal/config"
+	"github.com/therealadik/bank-api/internal/email"
+	"github.com/therealadik/bank-api/internal/i18n"
+	"github.com/therealadik/bank-api/internal/models"
+	"github.com/therealadik/bank-api/internal/repository"
+)
+
+var (
+	ErrInvalidVerificationToken = errors.New("неверная ссылка подтверждения email")
+	ErrVerificationTokenExpired = errors.New("срок действия ссылки подтверждения email истек")
+	ErrEmailAlreadyVerified     = errors.New("email уже подтвержден")
+	ErrVerificationTooFrequent  = errors.New("письмо подтверждения уже отправлено, повторите позже")
+	ErrEmailNotVerified         = errors.New("email не подтвержден")
+)
+
+// EmailVerificationService сервис подтверждения email.
+// Ссылка содержит подписанный HMAC токен с ID пользователя, email и сроком действия,
+// поэтому токены не хранятся в БД, а смена email делает старые ссылки недействительными.
+type EmailVerificationService struct {
+	userRepo repository.UserRepository
+	sender   email.Sender
+	cfg      config.EmailVerificationConfig
+	hmacKey  []byte
+	logger   *logrus.Logger
+}
+
+// NewEmailVerificationService создает сервис подтверждения email
+func NewEmailVerificationService(userRepo repository.UserRepository, sender email.Sender,
+	cfg config.EmailVerificationConfig, hmacKey string, logger *logrus.Logger) *EmailVerificationService {
+	return &EmailVerificationService{
+		userRepo: userRepo,
+		sender:   sender,
+		cfg:      cfg,
+		hmacKey:  []byte(hmacKey),
+		logger:   logger,
+	}
+}
+
+// SendVerification отправляет письмо со ссылкой подтверждения, если email еще не подтвержден.
+// Ошибка отправки только логируется: пользователь может запросить письмо повторно.
+func (s *EmailVerificationService) SendVerification(ctx context.Context, userID int64) {
+	if err := s.Resend(ctx, userID); err != nil && !errors.Is(err, ErrVerificationTooFrequent) {
+		s.logger.Errorf("Ошибка отправки письма подтверждения email пользователю %d: %v", userID, err)
+	}
+}
+
+// Resend повторно отправляет письмо подтверждения не чаще EMAIL_VERIFICATION_RESEND_INTERVAL
+func (s *EmailVerificationService) Resend(ctx context.Context, userID int64) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.IsEmailVerified() {
+		return ErrEmailAlreadyVerified
+	}
+
+	claimed, err := s.userRepo.ClaimVerificationSend(ctx, userID, s.cfg.ResendInterval)
+	if err != nil {
+		return fmt.Errorf("ошибка отметки отправки письма: %w", err)
+	}
+	if !claimed {
+		return ErrVerificationTooFrequent
+	}
+
+	token := s.signToken(user, time.Now().Add(s.cfg.TokenTTL))
+
+	lang := i18n.FromContext(ctx)
+	return s.sender.Send(ctx, email.Message{
+		To:      user.Email,
+		Subject: i18n.T(lang, i18n.EMAIL_VERIFICATION_SUBJECT),
+		Body:    i18n.T(lang, i18n.EMAIL_VERIFICATION_BODY, user.Username, s.cfg.URL+token, s.cfg.TokenTTL),
+	})
+}
+
+// Verify подтверждает email по токену из ссылки
+func (s *EmailVerificationService) Verify(ctx context.Context, token string) error {
+	userID, emailAddr, err := s.parseToken(token)
+	if err != nil {
+		return err
+	}
+
+	verified, err := s.userRepo.MarkEmailVerified(ctx, userID, emailAddr)
+	if err != nil {
+		return fmt.Errorf("ошибка подтверждения email: %w", err)
+	}
+	if verified {
+		return nil
+	}
+
+	// Токен корректен, но email уже подтвержден или был изменен после отправки ссылки
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.IsEmailVerified() && strings.EqualFold(user.Email, emailAddr) {
+		return ErrEmailAlreadyVerified
+	}
+	return ErrInvalidVerificationToken
+}
+
+// signToken формирует токен вида base64url(userID:expires:email).base64url(HMAC-SHA256)
+func (s *EmailVerificationService) signToken(user *models.User, expiresAt time.Time) string {
+	payload := strconv.FormatInt(user.ID, 10) + ":" + strconv.FormatInt(expiresAt.Unix(), 10) + ":" +
+		strings.ToLower(user.Email)
+
+	encoded := base64.RawURLEncoding.EncodeToString([]byte(payload))
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(s.sign(encoded))
+}
+
+// parseToken проверяет подпись и срок действия токена и возвращает ID пользователя и email
+func (s *EmailVerificationService) parseToken(token string) (int64, string, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return 0, "", ErrInvalidVerificationToken
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(sig, s.sign(encoded)) {
+		return 0, "", ErrInvalidVerificationToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return 0, "", ErrInvalidVerificationToken
+	}
+
+	parts := strings.SplitN(string(payload), ":", 3)
+	if len(parts) != 3 {
+		return 0, "", ErrInvalidVerificationToken
+	}
+
+	userID, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, "", ErrInvalidVerificationToken
+	}
+	expires, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, "", ErrInvalidVerificationToken
+	}
+	if time.Now().Unix() > expires {
+		return 0, "", ErrVerificationTokenExpired
+	}
+
+	return userID, parts[2], nil
+}
+
+// sign вычисляет HMAC-SHA256 данных токена; префикс отделяет эти подписи от других HMAC сервиса
+func (s *EmailVerificationService) sign(data string) []byte {
+	mac := hmac.New(sha256.New, s.hmacKey)
+	mac.Write([]byte("email-verification:" + data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,35 @@
+package service
+
+import "errors"
+
+// Категории ошибок «объект не найден» и «нет доступа». Конкретные ошибки сервисов
+// относятся к ним через errors.Is, поэтому вызывающий код может отличить отсутствие
+// объекта от чужого объекта, не перечисляя все конкретные ошибки.
+var (
+	ErrNotFound  = errors.New("объект не найден")
+	ErrForbidden = errors.New("доступ запрещен")
+)
+
+// categorizedError ошибка сервиса, относящаяся к одной из категорий
+type categorizedError struct {
+	msg      string
+	category error
+}
+
+func (e *categorizedError) Error() string {
+	return e.msg
+}
+
+func (e *categorizedError) Is(target error) bool {
+	return target == e.category
+}
+
+// newNotFoundError создает ошибку категории ErrNotFound
+func newNotFoundError(msg string) error {
+	return &categorizedError{msg: msg, category: ErrNotFound}
+}
+
+// newForbiddenError создает ошибку категории ErrForbidden
+func newForbiddenError(msg string) error {
+	return &categorizedError{msg: msg, category: ErrForbidden}
+}
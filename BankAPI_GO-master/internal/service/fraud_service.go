@@ -0,0 +1,440 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+	"github.com/therealadik/bank-api/internal/config"
+	"github.com/therealadik/bank-api/internal/models"
+	"github.com/therealadik/bank-api/internal/models/account"
+	"github.com/therealadik/bank-api/internal/models/fraud"
+	"github.com/therealadik/bank-api/internal/models/payment"
+	"github.com/therealadik/bank-api/internal/models/transaction"
+	"github.com/therealadik/bank-api/internal/repository"
+)
+
+var (
+	ErrFraudReviewNotFound      = newNotFoundError("задержанная операция не найдена")
+	ErrFraudReviewResolved      = errors.New("решение по операции уже принято")
+	ErrInvalidFraudReviewStatus = errors.New("неизвестный статус проверки")
+)
+
+// Баллы, начисляемые сработавшими правилами. Операция задерживается,
+// когда сумма баллов достигает FRAUD_REVIEW_THRESHOLD.
+const (
+	weightUnusualAmount      = 40
+	weightManyNewRecipients  = 30
+	weightFirstLargeTransfer = 60
+	weightCVVFailures        = 60
+)
+
+// fraudReviewsLimit максимальное число операций в ответе списка
+const fraudReviewsLimit = 100
+
+// FraudService оценивает переводы и платежи по картам правилами антифрода,
+// задерживает подозрительные операции и проводит или отклоняет их по решению администратора
+type FraudService struct {
+	fraudRepo       *repository.FraudRepository
+	accountRepo     *repository.AccountRepository
+	transactionRepo *repository.TransactionRepository
+	paymentRepo     *repository.PaymentRepository
+	cardRepo        *repository.CardRepository
+	audit           *AuditService
+	webhooks        *WebhookService
+	db              *pgxpool.Pool
+	cfg             config.FraudConfig
+	paymentCfg      config.PaymentConfig
+}
+
+func NewFraudService(fraudRepo *repository.FraudRepository, accountRepo *repository.AccountRepository,
+	transactionRepo *repository.TransactionRepository, paymentRepo *repository.PaymentRepository,
+	cardRepo *repository.CardRepository, audit *AuditService, webhooks *WebhookService, db *pgxpool.Pool,
+	cfg config.FraudConfig, paymentCfg config.PaymentConfig) *FraudService {
+	return &FraudService{
+		fraudRepo:       fraudRepo,
+		accountRepo:     accountRepo,
+		transactionRepo: transactionRepo,
+		paymentRepo:     paymentRepo,
+		cardRepo:        cardRepo,
+		audit:           audit,
+		webhooks:        webhooks,
+		db:              db,
+		cfg:             cfg,
+		paymentCfg:      paymentCfg,
+	}
+}
+
+// AssessTransferTx оценивает перевод пользователя userID на счет toAcc
+func (s *FraudService) AssessTransferTx(ctx context.Context, tx pgx.Tx, userID int64, toAcc *account.Account,
+	amount decimal.Decimal) (*fraud.Assessment, error) {
+	fraudRepo := s.fraudRepo.WithTx(tx)
+
+	var a fraud.Assessment
+	if err := s.assessAmount(ctx, fraudRepo, &a, userID, amount); err != nil {
+		return nil, err
+	}
+
+	// Переводы между своими счетами правила о получателях не проверяют
+	if toAcc.UserID == userID {
+		return &a, nil
+	}
+
+	known, err := fraudRepo.HasTransferredTo(ctx, userID, toAcc.ID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка проверки получателя: %w", err)
+	}
+	if known {
+		return &a, nil
+	}
+
+	newRecipients, err := fraudRepo.CountNewRecipientsSince(ctx, userID, time.Now().Add(-s.cfg.NewRecipientsWindow))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка подсчета новых получателей: %w", err)
+	}
+	// Текущий получатель тоже новый
+	if newRecipients+1 >= s.cfg.NewRecipientsMax {
+		a.Add(fraud.REASON_MANY_NEW_RECIPIENTS, weightManyNewRecipients)
+	}
+
+	if amount.GreaterThanOrEqual(s.cfg.LargeTransferAmount) {
+		a.Add(fraud.REASON_FIRST_LARGE_TRANSFER, weightFirstLargeTransfer)
+	}
+
+	return &a, nil
+}
+
+// AssessCardPaymentTx оценивает платеж по карте cardID владельца ownerID
+func (s *FraudService) AssessCardPaymentTx(ctx context.Context, tx pgx.Tx, ownerID, cardID int64,
+	amount decimal.Decimal) (*fraud.Assessment, error) {
+	var a fraud.Assessment
+	if err := s.assessAmount(ctx, s.fraudRepo.WithTx(tx), &a, ownerID, amount); err != nil {
+		return nil, err
+	}
+
+	failures, err := s.cardRepo.WithTx(tx).CountVerificationFailuresSince(ctx, cardID,
+		time.Now().Add(-s.cfg.CVVFailuresWindow))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка подсчета неудачных проверок CVV: %w", err)
+	}
+	if failures >= s.cfg.CVVFailuresMax {
+		a.Add(fraud.REASON_CVV_FAILURES, weightCVVFailures)
+	}
+
+	return &a, nil
+}
+
+// RequiresReview проверяет, набрала ли операция достаточно баллов для задержки
+func (s *FraudService) RequiresReview(a *fraud.Assessment) bool {
+	return a.Score >= s.cfg.ReviewThreshold
+}
+
+// HoldTransferTx задерживает перевод до решения администратора: сумма ставится
+// на холд, исходящая транзакция создается в статусе PENDING
+func (s *FraudService) HoldTransferTx(ctx context.Context, tx pgx.Tx, userID, fromID, toID int64,
+	amount decimal.Decimal, a *fraud.Assessment) (*transaction.Transaction, error) {
+	reserved, err := s.accountRepo.WithTx(tx).ReserveFunds(ctx, fromID, amount)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка блокировки средств: %w", err)
+	}
+	if !reserved {
+		return nil, ErrInsufficientFunds
+	}
+
+	pending, err := s.transactionRepo.WithTx(tx).CreateTransferTransaction(ctx, fromID, amount,
+		transaction.TRANSFER, transaction.PENDING, toID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка записи транзакции: %w", err)
+	}
+
+	err = s.createReview(ctx, tx, &fraud.Review{
+		Kind:          fraud.KIND_TRANSFER,
+		UserID:        userID,
+		AccountID:     fromID,
+		ToAccountID:   &toID,
+		TransactionID: &pending.ID,
+		Amount:        amount,
+		Score:         a.Score,
+		Reasons:       a.Reasons,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pending, nil
+}
+
+// HoldCardPaymentTx задерживает авторизованный платеж по карте до решения администратора.
+// Холд уже поставлен; платеж нельзя списать, пока он не одобрен.
+func (s *FraudService) HoldCardPaymentTx(ctx context.Context, tx pgx.Tx, ownerID int64, p *payment.CardPayment,
+	a *fraud.Assessment) error {
+	return s.createReview(ctx, tx, &fraud.Review{
+		Kind:          fraud.KIND_CARD_PAYMENT,
+		UserID:        ownerID,
+		AccountID:     *p.AccountID,
+		TransactionID: p.TransactionID,
+		PaymentID:     &p.ID,
+		Amount:        p.Amount,
+		Score:         a.Score,
+		Reasons:       a.Reasons,
+	})
+}
+
+// GetReviews возвращает задержанные операции в указанном статусе (все — при пустом статусе)
+func (s *FraudService) GetReviews(ctx context.Context, status fraud.Status) ([]*fraud.Review, error) {
+	if status != "" && !status.IsValid() {
+		return nil, ErrInvalidFraudReviewStatus
+	}
+	return s.fraudRepo.GetReviews(ctx, status, fraudReviewsLimit)
+}
+
+// Approve проводит задержанную операцию
+func (s *FraudService) Approve(ctx context.Context, actorID, reviewID int64, comment string) (*fraud.Review, error) {
+	return s.resolve(ctx, actorID, reviewID, fraud.APPROVED, comment)
+}
+
+// Reject отклоняет задержанную операцию и снимает холд
+func (s *FraudService) Reject(ctx context.Context, actorID, reviewID int64, comment string) (*fraud.Review, error) {
+	return s.resolve(ctx, actorID, reviewID, fraud.REJECTED, comment)
+}
+
+// resolve выполняет решение по задержанной операции и записывает его в журнал в одной транзакции
+func (s *FraudService) resolve(ctx context.Context, actorID, reviewID int64, status fraud.Status,
+	comment string) (*fraud.Review, error) {
+	comment = strings.TrimSpace(comment)
+	if comment == "" {
+		return nil, ErrAdminReasonRequired
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	fraudRepo := s.fraudRepo.WithTx(tx)
+	review, err := fraudRepo.GetReviewForUpdate(ctx, reviewID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrFraudReviewNotFound
+		}
+		return nil, fmt.Errorf("ошибка получения задержанной операции: %w", err)
+	}
+	if review.Status != fraud.PENDING {
+		return nil, ErrFraudReviewResolved
+	}
+
+	switch {
+	case review.Kind == fraud.KIND_TRANSFER && status == fraud.APPROVED:
+		err = s.completeTransfer(ctx, tx, review)
+	case review.Kind == fraud.KIND_TRANSFER:
+		err = s.cancelTransfer(ctx, tx, review)
+	case status == fraud.APPROVED:
+		err = s.authorizeCardPayment(ctx, tx, review)
+	default:
+		err = s.declineCardPayment(ctx, tx, review)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	resolved, err := fraudRepo.Resolve(ctx, reviewID, status, actorID, comment)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка сохранения решения: %w", err)
+	}
+
+	action := models.ADMIN_APPROVE_OPERATION
+	if status == fraud.REJECTED {
+		action = models.ADMIN_REJECT_OPERATION
+	}
+	err = s.audit.RecordTx(ctx, tx, AuditEvent{
+		ActorID:    &actorID,
+		Action:     action,
+		TargetType: models.AuditTargetFraudReview,
+		TargetID:   &reviewID,
+		Details:    comment,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return resolved, nil
+}
+
+// completeTransfer проводит задержанный перевод: списывает холд и зачисляет сумму получателю
+func (s *FraudService) completeTransfer(ctx context.Context, tx pgx.Tx, review *fraud.Review) error {
+	if review.ToAccountID == nil || review.TransactionID == nil {
+		return ErrAccountNotFound
+	}
+
+	accountRepo := s.accountRepo.WithTx(tx)
+	fromAcc, toAcc, err := lockTransferAccounts(ctx, accountRepo, review.AccountID, *review.ToAccountID)
+	if err != nil {
+		return err
+	}
+	if err := checkAccountActive(fromAcc); err != nil {
+		return err
+	}
+	if err := checkAccountActive(toAcc); err != nil {
+		return err
+	}
+
+	if err := accountRepo.CaptureFunds(ctx, fromAcc.ID, review.Amount, review.Amount); err != nil {
+		return fmt.Errorf("ошибка списания средств: %w", err)
+	}
+	if err := accountRepo.UpdateBalance(ctx, toAcc.ID, review.Amount); err != nil {
+		return fmt.Errorf("ошибка зачисления средств: %w", err)
+	}
+
+	transactionRepo := s.transactionRepo.WithTx(tx)
+	outgoing, err := transactionRepo.UpdateTransaction(ctx, *review.TransactionID, review.Amount, transaction.COMPLETED)
+	if err != nil {
+		return fmt.Errorf("ошибка проведения транзакции: %w", err)
+	}
+	incoming, err := transactionRepo.CreateTransferTransaction(ctx, toAcc.ID, review.Amount, transaction.DEPOSIT,
+		transaction.COMPLETED, fromAcc.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, t := range []*transaction.Transaction{outgoing, incoming} {
+		if err := s.webhooks.TransactionCompletedTx(ctx, tx, t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cancelTransfer отклоняет задержанный перевод и возвращает сумму холда в доступный баланс
+func (s *FraudService) cancelTransfer(ctx context.Context, tx pgx.Tx, review *fraud.Review) error {
+	accountRepo := s.accountRepo.WithTx(tx)
+	if _, err := accountRepo.GetAccountForUpdate(ctx, review.AccountID); err != nil {
+		return fmt.Errorf("ошибка получения счета: %w", err)
+	}
+	if err := accountRepo.ReleaseFunds(ctx, review.AccountID, review.Amount); err != nil {
+		return fmt.Errorf("ошибка снятия холда: %w", err)
+	}
+
+	if review.TransactionID == nil {
+		return nil
+	}
+	_, err := s.transactionRepo.WithTx(tx).UpdateTransaction(ctx, *review.TransactionID, review.Amount,
+		transaction.FAILED)
+	if err != nil {
+		return fmt.Errorf("ошибка отмены транзакции: %w", err)
+	}
+	return nil
+}
+
+// authorizeCardPayment одобряет задержанный платеж: он становится обычной авторизацией с новым сроком холда
+func (s *FraudService) authorizeCardPayment(ctx context.Context, tx pgx.Tx, review *fraud.Review) error {
+	p, err := s.lockReviewedPayment(ctx, tx, review)
+	if err != nil {
+		return err
+	}
+
+	acc, err := s.accountRepo.WithTx(tx).GetAccountForUpdate(ctx, *p.AccountID)
+	if err != nil {
+		return fmt.Errorf("ошибка получения счета карты: %w", err)
+	}
+	if err := checkAccountActive(acc); err != nil {
+		return err
+	}
+
+	authorized, err := s.paymentRepo.WithTx(tx).Authorize(ctx, p.ID, time.Now().Add(s.paymentCfg.HoldTTL))
+	if err != nil {
+		return fmt.Errorf("ошибка обновления платежа: %w", err)
+	}
+	return s.webhooks.CardPaymentTx(ctx, tx, authorized)
+}
+
+// declineCardPayment отклоняет задержанный платеж и снимает холд
+func (s *FraudService) declineCardPayment(ctx context.Context, tx pgx.Tx, review *fraud.Review) error {
+	p, err := s.lockReviewedPayment(ctx, tx, review)
+	if err != nil {
+		return err
+	}
+
+	if err := s.accountRepo.WithTx(tx).ReleaseFunds(ctx, *p.AccountID, p.Amount); err != nil {
+		return fmt.Errorf("ошибка снятия холда: %w", err)
+	}
+
+	if p.TransactionID != nil {
+		_, err := s.transactionRepo.WithTx(tx).UpdateTransaction(ctx, *p.TransactionID, p.Amount, transaction.FAILED)
+		if err != nil {
+			return fmt.Errorf("ошибка отмены транзакции: %w", err)
+		}
+	}
+
+	declined, err := s.paymentRepo.WithTx(tx).UpdatePayment(ctx, p.ID, p.Amount, payment.DECLINED)
+	if err != nil {
+		return fmt.Errorf("ошибка обновления платежа: %w", err)
+	}
+	return s.webhooks.CardPaymentTx(ctx, tx, declined)
+}
+
+// lockReviewedPayment блокирует платеж задержанной операции и проверяет, что он ждет решения
+func (s *FraudService) lockReviewedPayment(ctx context.Context, tx pgx.Tx, review *fraud.Review) (*payment.CardPayment, error) {
+	if review.PaymentID == nil {
+		return nil, ErrPaymentNotFound
+	}
+
+	p, err := s.paymentRepo.WithTx(tx).GetPaymentByIDForUpdate(ctx, *review.PaymentID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrPaymentNotFound
+		}
+		return nil, fmt.Errorf("ошибка получения платежа: %w", err)
+	}
+	if p.Status != payment.PENDING_REVIEW || p.AccountID == nil {
+		return nil, ErrFraudReviewResolved
+	}
+	return p, nil
+}
+
+// assessAmount проверяет правило необычной суммы: сумма во много раз больше
+// средней суммы исходящих операций пользователя за период истории
+func (s *FraudService) assessAmount(ctx context.Context, fraudRepo *repository.FraudRepository, a *fraud.Assessment,
+	userID int64, amount decimal.Decimal) error {
+	count, avg, err := fraudRepo.OutgoingStats(ctx, userID, time.Now().Add(-s.cfg.HistoryWindow))
+	if err != nil {
+		return fmt.Errorf("ошибка получения истории операций: %w", err)
+	}
+
+	// Без достаточной истории обычную сумму оценить нельзя
+	if count >= s.cfg.HistoryMinOperations && amount.GreaterThan(avg.Mul(s.cfg.UnusualAmountFactor)) {
+		a.Add(fraud.REASON_UNUSUAL_AMOUNT, weightUnusualAmount)
+	}
+	return nil
+}
+
+// createReview сохраняет задержанную операцию и записывает задержку в журнал аудита
+func (s *FraudService) createReview(ctx context.Context, tx pgx.Tx, review *fraud.Review) error {
+	saved, err := s.fraudRepo.WithTx(tx).CreateReview(ctx, review)
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения задержанной операции: %w", err)
+	}
+
+	reasons := make([]string, 0, len(saved.Reasons))
+	for _, reason := range saved.Reasons {
+		reasons = append(reasons, string(reason))
+	}
+
+	return s.audit.RecordTx(ctx, tx, AuditEvent{
+		ActorID:    &saved.UserID,
+		Action:     models.AUDIT_OPERATION_HELD,
+		TargetType: models.AuditTargetFraudReview,
+		TargetID:   &saved.ID,
+		Details: fmt.Sprintf("kind=%s account_id=%d amount=%s score=%d reasons=%s", saved.Kind, saved.AccountID,
+			saved.Amount, saved.Score, strings.Join(reasons, ",")),
+	})
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/therealadik/bank-api/internal/config"
+	"github.com/therealadik/bank-api/internal/models"
+	"github.com/therealadik/bank-api/internal/repository"
+)
+
+// maxIdempotencyKeyLength максимальная длина ключа идемпотентности
+const maxIdempotencyKeyLength = 255
+
+var (
+	ErrInvalidIdempotencyKey        = errors.New("неверный ключ идемпотентности")
+	ErrIdempotencyKeyReused         = errors.New("ключ идемпотентности использован с другим запросом")
+	ErrIdempotencyRequestInProgress = errors.New("запрос с этим ключом идемпотентности еще выполняется")
+)
+
+// IdempotencyService обеспечивает однократное выполнение запросов с ключом идемпотентности.
+// Ответ сохраняется и возвращается повторно на запросы с тем же ключом в течение TTL.
+// После ответа 4xx ключ освобождается, и запрос можно повторить (например, с кодом TOTP):
+// такие ошибки возникают до изменения данных. Ответ 5xx сохраняется, как и успешный:
+// сбой мог произойти уже после фиксации операции, и повтор не должен выполнить ее дважды.
+type IdempotencyService struct {
+	repo repository.IdempotencyRepository
+	cfg  config.IdempotencyConfig
+}
+
+// NewIdempotencyService создает сервис ключей идемпотентности
+func NewIdempotencyService(repo repository.IdempotencyRepository, cfg config.IdempotencyConfig) *IdempotencyService {
+	return &IdempotencyService{
+		repo: repo,
+		cfg:  cfg,
+	}
+}
+
+// Begin занимает ключ перед выполнением запроса. Если запрос с этим ключом уже выполнен,
+// возвращает сохраненный ответ; nil означает, что запрос нужно выполнить и затем вызвать Complete.
+// requestHash — хеш запроса: тот же ключ с другим запросом отклоняется.
+func (s *IdempotencyService) Begin(ctx context.Context, userID int64, key, requestHash string) (*models.IdempotencyKey, error) {
+	if !validIdempotencyKey(key) {
+		return nil, ErrInvalidIdempotencyKey
+	}
+
+	reserved, err := s.repo.Reserve(ctx, userID, key, requestHash, time.Now().Add(-s.cfg.TTL))
+	if err != nil {
+		return nil, err
+	}
+	if reserved {
+		return nil, nil
+	}
+
+	record, err := s.repo.Get(ctx, userID, key)
+	if err != nil {
+		// Ключ освобожден между вставкой и чтением: первый запрос только что завершился ошибкой
+		if errors.Is(err, repository.ErrIdempotencyKeyNotFound) {
+			return nil, ErrIdempotencyRequestInProgress
+		}
+		return nil, err
+	}
+
+	if record.RequestHash != requestHash {
+		return nil, ErrIdempotencyKeyReused
+	}
+	if record.StatusCode == nil {
+		return nil, ErrIdempotencyRequestInProgress
+	}
+	return record, nil
+}
+
+// Complete сохраняет ответ для повторных запросов или освобождает ключ после ошибки клиента (4xx)
+func (s *IdempotencyService) Complete(ctx context.Context, userID int64, key string, statusCode int, contentType string, body []byte) error {
+	if statusCode >= 400 && statusCode < 500 {
+		return s.repo.Delete(ctx, userID, key)
+	}
+	return s.repo.Complete(ctx, userID, key, statusCode, contentType, body)
+}
+
+// RunCleanup периодически удаляет ключи старше TTL до отмены контекста
+func (s *IdempotencyService) RunCleanup(ctx context.Context, logger *logrus.Logger) {
+	ticker := time.NewTicker(s.cfg.CleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := s.repo.DeleteExpired(ctx, time.Now().Add(-s.cfg.TTL))
+			if err != nil {
+				logger.Errorf("Ошибка удаления устаревших ключей идемпотентности: %v", err)
+			}
+			if deleted > 0 {
+				logger.Infof("Удалено устаревших ключей идемпотентности: %d", deleted)
+			}
+		}
+	}
+}
+
+// validIdempotencyKey проверяет, что ключ непустой, не длиннее 255 символов
+// и состоит из печатных символов ASCII
+func validIdempotencyKey(key string) bool {
+	if key == "" || len(key) > maxIdempotencyKeyLength {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x21 || key[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/therealadik/bank-api/internal/config"
+	"github.com/therealadik/bank-api/internal/models"
+	"github.com/therealadik/bank-api/internal/repository"
+)
+
+// memoryIdempotencyRepository ключи идемпотентности в памяти одного пользователя
+type memoryIdempotencyRepository struct {
+	repository.IdempotencyRepository
+	keys map[string]*models.IdempotencyKey
+}
+
+func (r *memoryIdempotencyRepository) Reserve(_ context.Context, _ int64, key, requestHash string, _ time.Time) (bool, error) {
+	if _, ok := r.keys[key]; ok {
+		return false, nil
+	}
+	r.keys[key] = &models.IdempotencyKey{Key: key, RequestHash: requestHash}
+	return true, nil
+}
+
+func (r *memoryIdempotencyRepository) Get(_ context.Context, _ int64, key string) (*models.IdempotencyKey, error) {
+	record, ok := r.keys[key]
+	if !ok {
+		return nil, repository.ErrIdempotencyKeyNotFound
+	}
+	return record, nil
+}
+
+func (r *memoryIdempotencyRepository) Complete(_ context.Context, _ int64, key string, statusCode int, contentType string, body []byte) error {
+	record := r.keys[key]
+	record.StatusCode = &statusCode
+	record.ContentType = contentType
+	record.ResponseBody = body
+	return nil
+}
+
+func (r *memoryIdempotencyRepository) Delete(_ context.Context, _ int64, key string) error {
+	delete(r.keys, key)
+	return nil
+}
+
+// Ключ освобождается только после ошибки клиента: ответ 5xx мог прийти после фиксации
+// операции, и повтор с тем же ключом должен получить сохраненный ответ
+func TestIdempotencyComplete(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		replayed bool
+	}{
+		{"успех", http.StatusOK, true},
+		{"перевод на проверке", http.StatusAccepted, true},
+		{"неверный запрос", http.StatusBadRequest, false},
+		{"нужен код TOTP", http.StatusForbidden, false},
+		{"лимит переводов", http.StatusTooManyRequests, false},
+		{"сбой после фиксации", http.StatusInternalServerError, true},
+		{"сервис недоступен", http.StatusServiceUnavailable, true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := NewIdempotencyService(&memoryIdempotencyRepository{keys: make(map[string]*models.IdempotencyKey)},
+				config.IdempotencyConfig{TTL: time.Hour})
+
+			if saved, err := s.Begin(ctx, testUserID, "key-1", "hash"); err != nil || saved != nil {
+				t.Fatalf("первый запрос: %v, %v", saved, err)
+			}
+			if err := s.Complete(ctx, testUserID, "key-1", tc.status, "application/json", []byte(`{}`)); err != nil {
+				t.Fatal(err)
+			}
+
+			saved, err := s.Begin(ctx, testUserID, "key-1", "hash")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !tc.replayed {
+				if saved != nil {
+					t.Fatalf("ответ %d сохранен, ожидалось освобождение ключа", tc.status)
+				}
+				return
+			}
+			if saved == nil || saved.StatusCode == nil || *saved.StatusCode != tc.status {
+				t.Fatalf("повтор: %+v, ожидался сохраненный ответ %d", saved, tc.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,396 @@
+package service
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+	"github.com/therealadik/bank-api/internal/config"
+	"github.com/therealadik/bank-api/internal/dto"
+	"github.com/therealadik/bank-api/internal/models"
+	"github.com/therealadik/bank-api/internal/repository"
+)
+
+// rsaKeyBits размер генерируемых RSA-ключей
+const rsaKeyBits = 2048
+
+// keyReloadTimeout таймаут перезагрузки ключей при встрече неизвестного kid
+const keyReloadTimeout = 5 * time.Second
+
+// keyReloadMinInterval минимальный интервал между перезагрузками ключей из-за неизвестного kid.
+// Заголовок токена проверяется до подписи, поэтому без ограничения случайные kid
+// в запросах превращались бы в запросы к БД на каждый вызов.
+const keyReloadMinInterval = 10 * time.Second
+
+var (
+	ErrUnsupportedSigningAlgorithm = errors.New("неподдерживаемый алгоритм подписи JWT")
+	ErrUnknownSigningKey           = errors.New("неизвестный ключ подписи токена")
+	ErrNoSigningKey                = errors.New("нет действующего ключа подписи")
+)
+
+// signingKey разобранный ключ подписи
+type signingKey struct {
+	kid       string
+	method    jwt.SigningMethod
+	private   crypto.Signer
+	public    crypto.PublicKey
+	expiresAt time.Time
+}
+
+// KeyManager управляет ключами подписи JWT: подписывает токены текущим ключом,
+// проверяет их по kid среди всех действующих ключей и периодически ротирует ключи.
+// Ключи хранятся в БД, поэтому все экземпляры API используют общий набор.
+type KeyManager struct {
+	keyRepo *repository.SigningKeyRepository
+	db      *pgxpool.Pool
+	jwtCfg  config.JWTConfig
+	pgpKey  string // Ключ шифрования закрытых ключей в БД
+
+	mu       sync.RWMutex
+	keys     map[string]*signingKey
+	current  *signingKey
+	loadedAt time.Time // Время последней загрузки ключей из БД
+
+	reloadMu sync.Mutex // Сериализует перезагрузки из-за неизвестного kid
+}
+
+// NewKeyManager создает менеджер ключей подписи
+func NewKeyManager(keyRepo *repository.SigningKeyRepository, db *pgxpool.Pool, jwtCfg config.JWTConfig,
+	pgpKey string) (*KeyManager, error) {
+	if signingMethod(jwtCfg.SigningAlgorithm) == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedSigningAlgorithm, jwtCfg.SigningAlgorithm)
+	}
+
+	return &KeyManager{
+		keyRepo: keyRepo,
+		db:      db,
+		jwtCfg:  jwtCfg,
+		pgpKey:  pgpKey,
+		keys:    make(map[string]*signingKey),
+	}, nil
+}
+
+// Init создает первый ключ при необходимости и загружает действующие ключи
+func (m *KeyManager) Init(ctx context.Context) error {
+	if _, err := m.RotateIfDue(ctx); err != nil {
+		return err
+	}
+	return m.Reload(ctx)
+}
+
+// Sign подписывает claims текущим ключом и указывает его kid в заголовке
+func (m *KeyManager) Sign(claims jwt.Claims) (string, error) {
+	m.mu.RLock()
+	key := m.current
+	m.mu.RUnlock()
+
+	if key == nil {
+		return "", ErrNoSigningKey
+	}
+
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.kid
+
+	return token.SignedString(key.private)
+}
+
+// Keyfunc возвращает открытый ключ для проверки токена по kid из заголовка.
+// Если kid неизвестен, ключи перечитываются из БД (токен мог быть подписан
+// ключом, созданным другим экземпляром), но не чаще раза в keyReloadMinInterval.
+func (m *KeyManager) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, ErrUnknownSigningKey
+	}
+
+	key := m.lookup(kid)
+	if key == nil {
+		var err error
+		if key, err = m.reloadForKID(kid); err != nil {
+			return nil, err
+		}
+	}
+
+	if key == nil || key.expiresAt.Before(time.Now()) {
+		return nil, ErrUnknownSigningKey
+	}
+
+	// Алгоритм токена должен совпадать с алгоритмом ключа
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, errors.New("алгоритм токена не соответствует ключу")
+	}
+
+	return key.public, nil
+}
+
+// ValidMethods возвращает алгоритмы, которыми могут быть подписаны токены
+func (m *KeyManager) ValidMethods() []string {
+	return []string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}
+}
+
+// JWKS возвращает открытые части всех действующих ключей
+func (m *KeyManager) JWKS() dto.JWKSResponse {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	resp := dto.JWKSResponse{Keys: make([]dto.JWK, 0, len(m.keys))}
+	now := time.Now()
+
+	for _, key := range m.keys {
+		if key.expiresAt.Before(now) {
+			continue
+		}
+
+		jwk := dto.JWK{Kid: key.kid, Use: "sig", Alg: key.method.Alg()}
+		switch pub := key.public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		default:
+			continue
+		}
+		resp.Keys = append(resp.Keys, jwk)
+	}
+
+	return resp
+}
+
+// Reload перечитывает действующие ключи из БД
+func (m *KeyManager) Reload(ctx context.Context) error {
+	stored, err := m.keyRepo.GetActiveKeys(ctx, m.pgpKey)
+	if err != nil {
+		return fmt.Errorf("ошибка загрузки ключей подписи: %w", err)
+	}
+
+	keys := make(map[string]*signingKey, len(stored))
+	var current *signingKey
+
+	// Ключи отсортированы от новых к старым: первый подходящий - текущий
+	for _, s := range stored {
+		key, err := parseSigningKey(s)
+		if err != nil {
+			return fmt.Errorf("ошибка разбора ключа %s: %w", s.KID, err)
+		}
+		keys[key.kid] = key
+		if current == nil && key.method.Alg() == m.jwtCfg.SigningAlgorithm {
+			current = key
+		}
+	}
+
+	m.mu.Lock()
+	m.keys = keys
+	m.current = current
+	m.loadedAt = time.Now()
+	m.mu.Unlock()
+
+	return nil
+}
+
+// reloadForKID перечитывает ключи, встретив неизвестный kid, и ищет его снова.
+// Параллельные вызовы ждут одну перезагрузку, а повторная возможна не раньше
+// чем через keyReloadMinInterval после предыдущей загрузки (включая плановую).
+func (m *KeyManager) reloadForKID(kid string) (*signingKey, error) {
+	m.reloadMu.Lock()
+	defer m.reloadMu.Unlock()
+
+	// Ключ мог загрузить параллельный вызов, пока этот ждал блокировку
+	if key := m.lookup(kid); key != nil {
+		return key, nil
+	}
+
+	m.mu.RLock()
+	recent := time.Since(m.loadedAt) < keyReloadMinInterval
+	m.mu.RUnlock()
+	if recent {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), keyReloadTimeout)
+	defer cancel()
+
+	if err := m.Reload(ctx); err != nil {
+		// Неудачная попытка тоже откладывает следующую, чтобы не нагружать недоступную БД
+		m.mu.Lock()
+		m.loadedAt = time.Now()
+		m.mu.Unlock()
+		return nil, err
+	}
+	return m.lookup(kid), nil
+}
+
+// RotateIfDue создает новый ключ, если текущий старше периода ротации или
+// алгоритм подписи изменился. Ротация сериализуется advisory-блокировкой,
+// поэтому несколько экземпляров не создадут лишних ключей.
+func (m *KeyManager) RotateIfDue(ctx context.Context) (bool, error) {
+	tx, err := m.db.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	keyRepo := m.keyRepo.WithTx(tx)
+
+	if err := keyRepo.LockRotation(ctx); err != nil {
+		return false, fmt.Errorf("ошибка блокировки ротации ключей: %w", err)
+	}
+
+	latest, err := keyRepo.GetLatestKey(ctx)
+	if err != nil {
+		return false, fmt.Errorf("ошибка получения текущего ключа: %w", err)
+	}
+
+	if latest != nil && latest.Algorithm == m.jwtCfg.SigningAlgorithm &&
+		time.Since(latest.CreatedAt) < m.jwtCfg.RotationInterval {
+		return false, nil
+	}
+
+	key, err := generateSigningKey(m.jwtCfg.SigningAlgorithm)
+	if err != nil {
+		return false, err
+	}
+
+	// Ключ подписывает токены в течение периода ротации и остается
+	// действующим для проверки, пока не истекут выданные им токены
+	key.ExpiresAt = time.Now().Add(m.jwtCfg.RotationInterval + m.jwtCfg.ExpiresIn)
+
+	if err := keyRepo.CreateKey(ctx, key, m.pgpKey); err != nil {
+		return false, fmt.Errorf("ошибка сохранения ключа подписи: %w", err)
+	}
+
+	if err := keyRepo.DeleteExpiredKeys(ctx); err != nil {
+		return false, fmt.Errorf("ошибка удаления истекших ключей: %w", err)
+	}
+
+	return true, tx.Commit(ctx)
+}
+
+// RunRotation периодически ротирует ключи и подхватывает ключи,
+// созданные другими экземплярами, до отмены контекста
+func (m *KeyManager) RunRotation(ctx context.Context, logger *logrus.Logger) {
+	ticker := time.NewTicker(m.jwtCfg.KeyCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			rotated, err := m.RotateIfDue(ctx)
+			if err != nil {
+				logger.Errorf("Ошибка ротации ключей подписи: %v", err)
+			}
+			if rotated {
+				logger.Info("Создан новый ключ подписи JWT")
+			}
+			if err := m.Reload(ctx); err != nil {
+				logger.Errorf("Ошибка обновления ключей подписи: %v", err)
+			}
+		}
+	}
+}
+
+// lookup находит загруженный ключ по kid
+func (m *KeyManager) lookup(kid string) *signingKey {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.keys[kid]
+}
+
+// signingMethod возвращает метод подписи jwt для алгоритма, nil если алгоритм не поддерживается
+func signingMethod(alg string) jwt.SigningMethod {
+	switch alg {
+	case jwt.SigningMethodRS256.Alg():
+		return jwt.SigningMethodRS256
+	case jwt.SigningMethodEdDSA.Alg():
+		return jwt.SigningMethodEdDSA
+	}
+	return nil
+}
+
+// generateSigningKey генерирует новую пару ключей в PEM
+func generateSigningKey(alg string) (*models.SigningKey, error) {
+	var private crypto.Signer
+	var err error
+
+	switch signingMethod(alg) {
+	case jwt.SigningMethodRS256:
+		private, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	case jwt.SigningMethodEdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedSigningAlgorithm, alg)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка генерации ключа подписи: %w", err)
+	}
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, err
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(private.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	kid, err := randomToken(16)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.SigningKey{
+		KID:        kid,
+		Algorithm:  alg,
+		PrivateKey: string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER})),
+		PublicKey:  string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})),
+	}, nil
+}
+
+// parseSigningKey разбирает сохраненный ключ
+func parseSigningKey(s *models.SigningKey) (*signingKey, error) {
+	method := signingMethod(s.Algorithm)
+	if method == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedSigningAlgorithm, s.Algorithm)
+	}
+
+	block, _ := pem.Decode([]byte(s.PrivateKey))
+	if block == nil {
+		return nil, errors.New("неверный формат закрытого ключа")
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	private, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("закрытый ключ не поддерживает подпись")
+	}
+
+	return &signingKey{
+		kid:       s.KID,
+		method:    method,
+		private:   private,
+		public:    private.Public(),
+		expiresAt: s.ExpiresAt,
+	}, nil
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/therealadik/bank-api/internal/config"
+)
+
+// newTestKeyManager создает менеджер с одним загруженным ключом EdDSA.
+// Репозиторий ключей не задан: попытка обратиться к БД завершит тест паникой.
+func newTestKeyManager(t *testing.T, expiresAt time.Time) *KeyManager {
+	t.Helper()
+
+	stored, err := generateSigningKey(jwt.SigningMethodEdDSA.Alg())
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored.ExpiresAt = expiresAt
+	key, err := parseSigningKey(stored)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := NewKeyManager(nil, nil, config.JWTConfig{SigningAlgorithm: jwt.SigningMethodEdDSA.Alg()}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.keys[key.kid] = key
+	m.current = key
+	m.loadedAt = time.Now()
+	return m
+}
+
+func parseTestToken(m *KeyManager, token string) error {
+	_, err := jwt.Parse(token, m.Keyfunc, jwt.WithValidMethods(m.ValidMethods()))
+	return err
+}
+
+func TestKeyManagerVerifiesKnownKey(t *testing.T) {
+	m := newTestKeyManager(t, time.Now().Add(time.Hour))
+
+	token, err := m.Sign(jwt.MapClaims{"sub": "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := parseTestToken(m, token); err != nil {
+		t.Fatalf("токен текущего ключа отклонен: %v", err)
+	}
+}
+
+// Неизвестный kid вскоре после загрузки ключей отклоняется без обращения к БД
+func TestKeyManagerUnknownKIDThrottled(t *testing.T) {
+	m := newTestKeyManager(t, time.Now().Add(time.Hour))
+
+	token, err := m.Sign(jwt.MapClaims{"sub": "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, kid := range []string{"unknown-1", "unknown-2", "unknown-3"} {
+		parsed.Header["kid"] = kid
+		if _, err := m.Keyfunc(parsed); !errors.Is(err, ErrUnknownSigningKey) {
+			t.Fatalf("kid %s: %v, ожидалась ErrUnknownSigningKey", kid, err)
+		}
+	}
+}
+
+func TestKeyManagerRejectsExpiredKey(t *testing.T) {
+	m := newTestKeyManager(t, time.Now().Add(-time.Minute))
+
+	token, err := m.Sign(jwt.MapClaims{"sub": "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := parseTestToken(m, token); !errors.Is(err, ErrUnknownSigningKey) {
+		t.Fatalf("токен истекшего ключа: %v, ожидалась ErrUnknownSigningKey", err)
+	}
+}
@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/therealadik/bank-api/internal/config"
+	"github.com/therealadik/bank-api/internal/email"
+	"github.com/therealadik/bank-api/internal/i18n"
+	"github.com/therealadik/bank-api/internal/models"
+	"github.com/therealadik/bank-api/internal/repository"
+)
+
+// ErrLoginLocked возвращается, когда попытки входа временно заблокированы
+var ErrLoginLocked = errors.New("слишком много неудачных попыток входа")
+
+// LoginLockedError ошибка блокировки входа с временем до следующей попытки
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginLockedError) Error() string {
+	return fmt.Sprintf("%s, повторите через %s", ErrLoginLocked, e.RetryAfter.Round(time.Second))
+}
+
+func (e *LoginLockedError) Unwrap() error {
+	return ErrLoginLocked
+}
+
+// SecurityNotifier уведомляет пользователя о событиях безопасности
+type SecurityNotifier interface {
+	NotifyLockout(ctx context.Context, user *models.User, until time.Time) error
+	NotifyCardBlocked(ctx context.Context, user *models.User, cardID int64) error
+}
+
+// EmailSecurityNotifier отправляет уведомления о безопасности на email пользователя
+type EmailSecurityNotifier struct {
+	sender email.Sender
+}
+
+// NewEmailSecurityNotifier создает уведомитель, отправляющий письма
+func NewEmailSecurityNotifier(sender email.Sender) *EmailSecurityNotifier {
+	return &EmailSecurityNotifier{sender: sender}
+}
+
+// NotifyLockout сообщает о временной блокировке входа в аккаунт
+func (n *EmailSecurityNotifier) NotifyLockout(ctx context.Context, user *models.User, until time.Time) error {
+	lang := i18n.FromContext(ctx)
+	return n.sender.Send(ctx, email.Message{
+		To:      user.Email,
+		Subject: i18n.T(lang, i18n.EMAIL_LOGIN_LOCKED_SUBJECT),
+		Body:    i18n.T(lang, i18n.EMAIL_LOGIN_LOCKED_BODY, until.UTC().Format("2006-01-02 15:04")),
+	})
+}
+
+// NotifyCardBlocked сообщает о блокировке карты после нескольких неверных CVV
+func (n *EmailSecurityNotifier) NotifyCardBlocked(ctx context.Context, user *models.User, cardID int64) error {
+	lang := i18n.FromContext(ctx)
+	return n.sender.Send(ctx, email.Message{
+		To:      user.Email,
+		Subject: i18n.T(lang, i18n.EMAIL_CARD_BLOCKED_SUBJECT),
+		Body:    i18n.T(lang, i18n.EMAIL_CARD_BLOCKED_BODY, cardID),
+	})
+}
+
+// LoginGuard защищает вход от перебора: считает неудачные попытки по email
+// (или пользователю для второго шага) и по IP, увеличивает задержку между
+// попытками экспоненциально и временно блокирует вход после порога.
+// Счетчики хранятся в БД и общие для всех экземпляров API.
+type LoginGuard struct {
+	throttleRepo repository.LoginThrottleRepository
+	eventRepo    repository.SecurityEventRepository
+	userRepo     repository.UserRepository
+	notifier     SecurityNotifier
+	cfg          config.LoginGuardConfig
+	logger       *logrus.Logger
+}
+
+// NewLoginGuard создает защиту входа от перебора
+func NewLoginGuard(throttleRepo repository.LoginThrottleRepository, eventRepo repository.SecurityEventRepository,
+	userRepo repository.UserRepository, notifier SecurityNotifier, cfg config.LoginGuardConfig,
+	logger *logrus.Logger) *LoginGuard {
+	return &LoginGuard{
+		throttleRepo: throttleRepo,
+		eventRepo:    eventRepo,
+		userRepo:     userRepo,
+		notifier:     notifier,
+		cfg:          cfg,
+		logger:       logger,
+	}
+}
+
+// CheckLogin проверяет, разрешена ли сейчас попытка входа по email с IP
+func (g *LoginGuard) CheckLogin(ctx context.Context, email, ip string) error {
+	return g.check(ctx, models.SecurityEvent{Email: email, IP: ip}, emailKey(email), ipKey(ip))
+}
+
+// LoginFailed учитывает неудачную попытку входа по паролю (user = nil, если email не найден)
+func (g *LoginGuard) LoginFailed(ctx context.Context, email, ip string, user *models.User) error {
+	event := models.SecurityEvent{Type: models.LOGIN_FAILED, Email: email, IP: ip}
+	if user != nil {
+		event.UserID = &user.ID
+	}
+	return g.failure(ctx, event, emailKey(email))
+}
+
+// LoginSucceeded сбрасывает счетчик попыток аккаунта после успешного входа.
+// Счетчик IP не сбрасывается: с одного адреса может перебираться много аккаунтов.
+func (g *LoginGuard) LoginSucceeded(ctx context.Context, email string) error {
+	return g.throttleRepo.Reset(ctx, emailKey(email))
+}
+
+// CheckTwoFactor проверяет, разрешена ли сейчас проверка кода 2FA пользователя:
+// на втором шаге входа, при настройке 2FA или подтверждении операции.
+// Без IP (вне HTTP-запроса) проверяется только счетчик пользователя.
+func (g *LoginGuard) CheckTwoFactor(ctx context.Context, userID int64, ip string) error {
+	event := models.SecurityEvent{UserID: &userID, IP: ip}
+	if ip == "" {
+		return g.check(ctx, event, twoFactorKey(userID))
+	}
+	return g.check(ctx, event, twoFactorKey(userID), ipKey(ip))
+}
+
+// TwoFactorFailed учитывает неверный код 2FA
+func (g *LoginGuard) TwoFactorFailed(ctx context.Context, userID int64, ip string) error {
+	event := models.SecurityEvent{Type: models.TWO_FACTOR_FAILED, UserID: &userID, IP: ip}
+	return g.failure(ctx, event, twoFactorKey(userID))
+}
+
+// TwoFactorSucceeded сбрасывает счетчик неверных кодов 2FA пользователя
+func (g *LoginGuard) TwoFactorSucceeded(ctx context.Context, userID int64) error {
+	return g.throttleRepo.Reset(ctx, twoFactorKey(userID))
+}
+
+// check возвращает LoginLockedError, если хотя бы один из ключей заблокирован
+func (g *LoginGuard) check(ctx context.Context, event models.SecurityEvent, keys ...string) error {
+	until, err := g.throttleRepo.GetLockedUntil(ctx, keys)
+	if err != nil {
+		return fmt.Errorf("ошибка проверки блокировки входа: %w", err)
+	}
+	if until == nil {
+		return nil
+	}
+
+	event.Type = models.LOGIN_BLOCKED
+	event.Details = "заблокировано до " + until.Format(time.RFC3339)
+	g.record(ctx, event)
+
+	return &LoginLockedError{RetryAfter: time.Until(*until)}
+}
+
+// failure учитывает неудачную попытку по ключу аккаунта и по IP
+func (g *LoginGuard) failure(ctx context.Context, event models.SecurityEvent, accountKey string) error {
+	g.record(ctx, event)
+
+	until, locked, err := g.registerFailure(ctx, accountKey, g.cfg.LockoutThreshold)
+	if err != nil {
+		return err
+	}
+	if locked {
+		g.lockedOut(ctx, event, until)
+	}
+
+	if event.IP == "" {
+		return nil
+	}
+
+	until, locked, err = g.registerFailure(ctx, ipKey(event.IP), g.cfg.IPLockoutThreshold)
+	if err != nil {
+		return err
+	}
+	if locked {
+		ipEvent := models.SecurityEvent{Type: models.IP_LOCKED, IP: event.IP,
+			Details: "заблокировано до " + until.Format(time.RFC3339)}
+		g.record(ctx, ipEvent)
+	}
+
+	return nil
+}
+
+// registerFailure увеличивает счетчик ключа и назначает задержку или блокировку.
+// Возвращает true, если попытка привела к временной блокировке.
+func (g *LoginGuard) registerFailure(ctx context.Context, key string, threshold int) (time.Time, bool, error) {
+	failures, err := g.throttleRepo.RegisterFailure(ctx, key, g.cfg.FailureWindow)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("ошибка учета неудачной попытки входа: %w", err)
+	}
+
+	var until time.Time
+	locked := failures >= threshold
+
+	switch {
+	case failures >= threshold:
+		until = time.Now().Add(g.cfg.LockoutDuration)
+	case failures > g.cfg.FreeAttempts:
+		until = time.Now().Add(g.backoff(failures - g.cfg.FreeAttempts))
+	default:
+		return time.Time{}, false, nil
+	}
+
+	if err := g.throttleRepo.Lock(ctx, key, until); err != nil {
+		return time.Time{}, false, fmt.Errorf("ошибка блокировки входа: %w", err)
+	}
+
+	return until, locked, nil
+}
+
+// backoff вычисляет задержку для n-й попытки сверх бесплатных: BaseDelay * 2^(n-1), не больше MaxDelay
+func (g *LoginGuard) backoff(n int) time.Duration {
+	delay := g.cfg.BaseDelay
+	for i := 1; i < n; i++ {
+		delay *= 2
+		if delay >= g.cfg.MaxDelay {
+			return g.cfg.MaxDelay
+		}
+	}
+	return delay
+}
+
+// lockedOut фиксирует блокировку аккаунта и уведомляет его владельца
+func (g *LoginGuard) lockedOut(ctx context.Context, event models.SecurityEvent, until time.Time) {
+	event.Type = models.ACCOUNT_LOCKED
+	event.Details = "заблокировано до " + until.Format(time.RFC3339)
+	g.record(ctx, event)
+
+	if event.UserID == nil {
+		return
+	}
+
+	user, err := g.userRepo.GetByID(ctx, *event.UserID)
+	if err != nil {
+		g.logger.Errorf("Ошибка получения пользователя %d для уведомления о блокировке: %v", *event.UserID, err)
+		return
+	}
+
+	if err := g.notifier.NotifyLockout(ctx, user, until); err != nil {
+		g.logger.Errorf("Ошибка уведомления пользователя %d о блокировке: %v", user.ID, err)
+	}
+}
+
+// record пишет событие безопасности в журнал и в лог. Ошибка записи
+// в журнал не должна мешать входу, поэтому только логируется.
+func (g *LoginGuard) record(ctx context.Context, event models.SecurityEvent) {
+	fields := logrus.Fields{"event": event.Type, "ip": event.IP}
+	if event.Email != "" {
+		fields["email"] = event.Email
+	}
+	if event.UserID != nil {
+		fields["user_id"] = *event.UserID
+	}
+	if event.Details != "" {
+		fields["details"] = event.Details
+	}
+	g.logger.WithFields(fields).Warn("Событие безопасности")
+
+	if err := g.eventRepo.Create(ctx, &event); err != nil {
+		g.logger.Errorf("Ошибка записи события безопасности: %v", err)
+	}
+}
+
+func emailKey(email string) string {
+	return "email:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
+
+func twoFactorKey(userID int64) string {
+	return "2fa:" + strconv.FormatInt(userID, 10)
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/therealadik/bank-api/internal/config"
+	"github.com/therealadik/bank-api/internal/email"
+	"github.com/therealadik/bank-api/internal/i18n"
+	"github.com/therealadik/bank-api/internal/models"
+	"github.com/therealadik/bank-api/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrWrongPassword     = errors.New("неверный текущий пароль")
+	ErrInvalidResetToken = errors.New("неверный или просроченный токен сброса пароля")
+	ErrSamePassword      = errors.New("новый пароль совпадает с текущим")
+)
+
+// PasswordService сервис смены и восстановления пароля
+type PasswordService struct {
+	userRepo  repository.UserRepository
+	tokenRepo repository.TokenRepository
+	resetRepo repository.PasswordResetRepository
+	sender    email.Sender
+	cfg       config.PasswordResetConfig
+	logger    *logrus.Logger
+}
+
+// NewPasswordService создает сервис смены и восстановления пароля
+func NewPasswordService(userRepo repository.UserRepository, tokenRepo repository.TokenRepository,
+	resetRepo repository.PasswordResetRepository, sender email.Sender, cfg config.PasswordResetConfig,
+	logger *logrus.Logger) *PasswordService {
+	return &PasswordService{
+		userRepo:  userRepo,
+		tokenRepo: tokenRepo,
+		resetRepo: resetRepo,
+		sender:    sender,
+		cfg:       cfg,
+		logger:    logger,
+	}
+}
+
+// ChangePassword меняет пароль после проверки текущего и отзывает все сессии пользователя
+func (s *PasswordService) ChangePassword(ctx context.Context, userID int64, currentPassword, newPassword string) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(currentPassword)); err != nil {
+		return ErrWrongPassword
+	}
+
+	if currentPassword == newPassword {
+		return ErrSamePassword
+	}
+
+	return s.setPassword(ctx, user, newPassword)
+}
+
+// RequestReset отправляет на email ссылку с одноразовым токеном сброса пароля.
+// Для неизвестного email ничего не делает и не возвращает ошибку, чтобы не
+// раскрывать, зарегистрирован ли адрес.
+func (s *PasswordService) RequestReset(ctx context.Context, emailAddr string) error {
+	user, err := s.userRepo.GetByEmail(ctx, emailAddr)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	// Действует только последний запрошенный токен
+	if err := s.resetRepo.InvalidateUserTokens(ctx, user.ID); err != nil {
+		return fmt.Errorf("ошибка отзыва токенов сброса пароля: %w", err)
+	}
+
+	token, err := randomToken(32)
+	if err != nil {
+		return err
+	}
+
+	err = s.resetRepo.Create(ctx, &models.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(s.cfg.TokenTTL),
+	})
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения токена сброса пароля: %w", err)
+	}
+
+	lang := i18n.FromContext(ctx)
+	err = s.sender.Send(ctx, email.Message{
+		To:      user.Email,
+		Subject: i18n.T(lang, i18n.EMAIL_PASSWORD_RESET_SUBJECT),
+		Body:    i18n.T(lang, i18n.EMAIL_PASSWORD_RESET_BODY, s.cfg.URL+token, s.cfg.TokenTTL),
+	})
+	if err != nil {
+		// Ответ клиенту не должен отличаться для существующих адресов, поэтому ошибку только логируем
+		s.logger.Errorf("Ошибка отправки письма сброса пароля пользователю %d: %v", user.ID, err)
+	}
+
+	return nil
+}
+
+// ResetPassword устанавливает новый пароль по токену сброса и отзывает все сессии пользователя
+func (s *PasswordService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	// Пароль проверяется до погашения токена, чтобы слабый пароль не сжигал ссылку
+	if err := validatePassword(newPassword); err != nil {
+		return err
+	}
+
+	userID, err := s.resetRepo.UseToken(ctx, hashToken(token))
+	if err != nil {
+		if errors.Is(err, repository.ErrResetTokenNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	return s.setPassword(ctx, user, newPassword)
+}
+
+// setPassword проверяет и сохраняет новый пароль, завершает все сессии
+// и уведомляет пользователя о смене пароля
+func (s *PasswordService) setPassword(ctx context.Context, user *models.User, newPassword string) error {
+	if err := validatePassword(newPassword); err != nil {
+		return err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	if err := s.userRepo.UpdatePassword(ctx, user.ID, string(hash)); err != nil {
+		return fmt.Errorf("ошибка сохранения пароля: %w", err)
+	}
+
+	// Со старым паролем могли войти другие: завершаем все сессии и гасим ссылки сброса
+	if err := s.tokenRepo.RevokeUserSessions(ctx, user.ID); err != nil {
+		return fmt.Errorf("ошибка отзыва сессий: %w", err)
+	}
+	if err := s.resetRepo.InvalidateUserTokens(ctx, user.ID); err != nil {
+		return fmt.Errorf("ошибка отзыва токенов сброса пароля: %w", err)
+	}
+
+	lang := i18n.FromContext(ctx)
+	err = s.sender.Send(ctx, email.Message{
+		To:      user.Email,
+		Subject: i18n.T(lang, i18n.EMAIL_PASSWORD_CHANGED_SUBJECT),
+		Body:    i18n.T(lang, i18n.EMAIL_PASSWORD_CHANGED_BODY),
+	})
+	if err != nil {
+		s.logger.Errorf("Ошибка отправки уведомления о смене пароля пользователю %d: %v", user.ID, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,411 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/therealadik/bank-api/internal/config"
+	"github.com/therealadik/bank-api/internal/email"
+	"github.com/therealadik/bank-api/internal/i18n"
+	"github.com/therealadik/bank-api/internal/models"
+	"github.com/therealadik/bank-api/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	testUserID       = int64(1)
+	testUserEmail    = "user@example.com"
+	testUserPassword = "secret123"
+	testResetURL     = "https://bank.example.com/reset-password?token="
+	testResetTTL     = time.Hour
+)
+
+// stubSender запоминает отправленные письма вместо отправки
+type stubSender struct {
+	mu       sync.Mutex
+	messages []email.Message
+	err      error
+}
+
+func (s *stubSender) Send(_ context.Context, msg email.Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.messages = append(s.messages, msg)
+	return s.err
+}
+
+// sent возвращает отправленные письма и очищает список
+func (s *stubSender) sent() []email.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	messages := s.messages
+	s.messages = nil
+	return messages
+}
+
+// memoryUserRepository пользователи в памяти: только методы, нужные сервису паролей
+type memoryUserRepository struct {
+	repository.UserRepository
+	users map[int64]*models.User
+}
+
+func (r *memoryUserRepository) GetByID(_ context.Context, id int64) (*models.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, repository.ErrUserNotFound
+	}
+	u := *user
+	return &u, nil
+}
+
+func (r *memoryUserRepository) GetByEmail(_ context.Context, emailAddr string) (*models.User, error) {
+	for _, user := range r.users {
+		if strings.EqualFold(user.Email, emailAddr) {
+			u := *user
+			return &u, nil
+		}
+	}
+	return nil, repository.ErrUserNotFound
+}
+
+func (r *memoryUserRepository) UpdatePassword(_ context.Context, userID int64, passwordHash string) error {
+	user, ok := r.users[userID]
+	if !ok {
+		return repository.ErrUserNotFound
+	}
+	user.Password = passwordHash
+	return nil
+}
+
+// memoryTokenRepository запоминает пользователей, чьи сессии были отозваны
+type memoryTokenRepository struct {
+	repository.TokenRepository
+	revoked []int64
+}
+
+func (r *memoryTokenRepository) RevokeUserSessions(_ context.Context, userID int64) error {
+	r.revoked = append(r.revoked, userID)
+	return nil
+}
+
+// memoryPasswordResetRepository токены сброса пароля в памяти с той же логикой погашения, что и в БД
+type memoryPasswordResetRepository struct {
+	tokens map[string]*models.PasswordResetToken
+}
+
+func (r *memoryPasswordResetRepository) Create(_ context.Context, token *models.PasswordResetToken) error {
+	token.ID = int64(len(r.tokens) + 1)
+	token.CreatedAt = time.Now()
+	r.tokens[token.TokenHash] = token
+	return nil
+}
+
+func (r *memoryPasswordResetRepository) UseToken(_ context.Context, tokenHash string) (int64, error) {
+	token, ok := r.tokens[tokenHash]
+	if !ok || token.UsedAt != nil || !token.ExpiresAt.After(time.Now()) {
+		return 0, repository.ErrResetTokenNotFound
+	}
+	now := time.Now()
+	token.UsedAt = &now
+	return token.UserID, nil
+}
+
+func (r *memoryPasswordResetRepository) InvalidateUserTokens(_ context.Context, userID int64) error {
+	now := time.Now()
+	for _, token := range r.tokens {
+		if token.UserID == userID && token.UsedAt == nil {
+			token.UsedAt = &now
+		}
+	}
+	return nil
+}
+
+// passwordTest сервис паролей с хранилищами в памяти и заглушкой отправки писем
+type passwordTest struct {
+	service *PasswordService
+	users   *memoryUserRepository
+	tokens  *memoryTokenRepository
+	resets  *memoryPasswordResetRepository
+	sender  *stubSender
+}
+
+func newPasswordTest(t *testing.T) *passwordTest {
+	t.Helper()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(testUserPassword), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	pt := &passwordTest{
+		users: &memoryUserRepository{users: map[int64]*models.User{
+			testUserID: {ID: testUserID, Email: testUserEmail, Password: string(hash)},
+		}},
+		tokens: &memoryTokenRepository{},
+		resets: &memoryPasswordResetRepository{tokens: make(map[string]*models.PasswordResetToken)},
+		sender: &stubSender{},
+	}
+	cfg := config.PasswordResetConfig{TokenTTL: testResetTTL, URL: testResetURL}
+	pt.service = NewPasswordService(pt.users, pt.tokens, pt.resets, pt.sender, cfg, logger)
+	return pt
+}
+
+// requestReset запрашивает сброс пароля и возвращает токен из ссылки в письме
+func (pt *passwordTest) requestReset(t *testing.T, ctx context.Context) string {
+	t.Helper()
+
+	if err := pt.service.RequestReset(ctx, testUserEmail); err != nil {
+		t.Fatalf("RequestReset: %v", err)
+	}
+	messages := pt.sender.sent()
+	if len(messages) != 1 {
+		t.Fatalf("отправлено писем: %d, ожидалось 1", len(messages))
+	}
+	msg := messages[0]
+	if msg.To != testUserEmail {
+		t.Fatalf("письмо отправлено на %q, ожидалось %q", msg.To, testUserEmail)
+	}
+
+	_, link, ok := strings.Cut(msg.Body, testResetURL)
+	if !ok {
+		t.Fatalf("в письме нет ссылки сброса: %q", msg.Body)
+	}
+	token, _, _ := strings.Cut(link, "\n")
+	if token == "" {
+		t.Fatalf("в ссылке нет токена: %q", msg.Body)
+	}
+	return token
+}
+
+// assertPassword проверяет, что у пользователя сохранен хеш пароля password
+func (pt *passwordTest) assertPassword(t *testing.T, password string) {
+	t.Helper()
+
+	hash := pt.users.users[testUserID].Password
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err != nil {
+		t.Fatalf("сохранен не тот пароль: %v", err)
+	}
+}
+
+// assertChangedNotice проверяет, что пользователю ушло одно уведомление о смене пароля
+func (pt *passwordTest) assertChangedNotice(t *testing.T, lang i18n.Lang) {
+	t.Helper()
+
+	messages := pt.sender.sent()
+	if len(messages) != 1 {
+		t.Fatalf("отправлено писем: %d, ожидалось уведомление о смене пароля", len(messages))
+	}
+	msg := messages[0]
+	if msg.To != testUserEmail || msg.Subject != i18n.T(lang, i18n.EMAIL_PASSWORD_CHANGED_SUBJECT) ||
+		msg.Body != i18n.T(lang, i18n.EMAIL_PASSWORD_CHANGED_BODY) {
+		t.Fatalf("неверное уведомление о смене пароля: %+v", msg)
+	}
+}
+
+// Письмо со ссылкой сброса, смена пароля по токену, отзыв сессий и уведомление
+func TestPasswordReset(t *testing.T) {
+	pt := newPasswordTest(t)
+	ctx := i18n.NewContext(context.Background(), i18n.EN)
+
+	before := time.Now()
+	if err := pt.service.RequestReset(ctx, "USER@example.com"); err != nil {
+		t.Fatalf("RequestReset: %v", err)
+	}
+	messages := pt.sender.sent()
+	if len(messages) != 1 {
+		t.Fatalf("отправлено писем: %d, ожидалось 1", len(messages))
+	}
+	msg := messages[0]
+	if msg.Subject != i18n.T(i18n.EN, i18n.EMAIL_PASSWORD_RESET_SUBJECT) {
+		t.Fatalf("тема письма %q", msg.Subject)
+	}
+	_, link, _ := strings.Cut(msg.Body, testResetURL)
+	token, _, _ := strings.Cut(link, "\n")
+	if want := i18n.T(i18n.EN, i18n.EMAIL_PASSWORD_RESET_BODY, testResetURL+token, testResetTTL); msg.Body != want {
+		t.Fatalf("текст письма %q, ожидался %q", msg.Body, want)
+	}
+
+	// В хранилище попадает только хеш токена
+	if len(pt.resets.tokens) != 1 {
+		t.Fatalf("сохранено токенов: %d, ожидался 1", len(pt.resets.tokens))
+	}
+	stored, ok := pt.resets.tokens[hashToken(token)]
+	if !ok {
+		t.Fatal("токен из письма не найден по хешу")
+	}
+	if _, ok := pt.resets.tokens[token]; ok {
+		t.Fatal("токен сохранен в открытом виде")
+	}
+	if stored.UserID != testUserID || stored.ExpiresAt.Before(before.Add(testResetTTL)) ||
+		stored.ExpiresAt.After(time.Now().Add(testResetTTL)) {
+		t.Fatalf("неверный токен сброса: %+v", stored)
+	}
+
+	if err := pt.service.ResetPassword(ctx, token, "newsecret456"); err != nil {
+		t.Fatalf("ResetPassword: %v", err)
+	}
+	pt.assertPassword(t, "newsecret456")
+	if len(pt.tokens.revoked) != 1 || pt.tokens.revoked[0] != testUserID {
+		t.Fatalf("отозваны сессии пользователей %v, ожидался %d", pt.tokens.revoked, testUserID)
+	}
+	pt.assertChangedNotice(t, i18n.EN)
+
+	// Токен одноразовый
+	if err := pt.service.ResetPassword(ctx, token, "another789"); !errors.Is(err, ErrInvalidResetToken) {
+		t.Fatalf("повторное использование токена: %v, ожидалась ErrInvalidResetToken", err)
+	}
+	pt.assertPassword(t, "newsecret456")
+	if messages := pt.sender.sent(); len(messages) != 0 {
+		t.Fatalf("отправлены лишние письма: %+v", messages)
+	}
+}
+
+// Для неизвестного email письмо не отправляется, а ответ не отличается от успешного
+func TestPasswordResetUnknownEmail(t *testing.T) {
+	pt := newPasswordTest(t)
+
+	if err := pt.service.RequestReset(context.Background(), "nobody@example.com"); err != nil {
+		t.Fatalf("RequestReset: %v", err)
+	}
+	if messages := pt.sender.sent(); len(messages) != 0 {
+		t.Fatalf("отправлены письма: %+v", messages)
+	}
+	if len(pt.resets.tokens) != 0 {
+		t.Fatal("создан токен сброса для неизвестного email")
+	}
+}
+
+// Действует только последняя отправленная ссылка
+func TestPasswordResetLatestTokenOnly(t *testing.T) {
+	pt := newPasswordTest(t)
+	ctx := context.Background()
+
+	first := pt.requestReset(t, ctx)
+	second := pt.requestReset(t, ctx)
+	if first == second {
+		t.Fatal("повторный запрос вернул тот же токен")
+	}
+
+	if err := pt.service.ResetPassword(ctx, first, "newsecret456"); !errors.Is(err, ErrInvalidResetToken) {
+		t.Fatalf("старый токен: %v, ожидалась ErrInvalidResetToken", err)
+	}
+	if err := pt.service.ResetPassword(ctx, second, "newsecret456"); err != nil {
+		t.Fatalf("ResetPassword: %v", err)
+	}
+	pt.assertPassword(t, "newsecret456")
+}
+
+// Слабый пароль отклоняется до погашения токена: ссылка остается действительной
+func TestPasswordResetWeakPassword(t *testing.T) {
+	pt := newPasswordTest(t)
+	ctx := context.Background()
+	token := pt.requestReset(t, ctx)
+
+	if err := pt.service.ResetPassword(ctx, token, "short"); !errors.Is(err, ErrWeakPassword) {
+		t.Fatalf("слабый пароль: %v, ожидалась ErrWeakPassword", err)
+	}
+	pt.assertPassword(t, testUserPassword)
+	if len(pt.tokens.revoked) != 0 {
+		t.Fatal("сессии отозваны без смены пароля")
+	}
+
+	if err := pt.service.ResetPassword(ctx, token, "newsecret456"); err != nil {
+		t.Fatalf("ResetPassword после слабого пароля: %v", err)
+	}
+	pt.assertPassword(t, "newsecret456")
+}
+
+// Просроченный и неизвестный токены отклоняются
+func TestPasswordResetInvalidToken(t *testing.T) {
+	pt := newPasswordTest(t)
+	ctx := context.Background()
+	token := pt.requestReset(t, ctx)
+	pt.resets.tokens[hashToken(token)].ExpiresAt = time.Now().Add(-time.Minute)
+
+	for name, token := range map[string]string{"просроченный": token, "неизвестный": "unknown"} {
+		t.Run(name, func(t *testing.T) {
+			if err := pt.service.ResetPassword(ctx, token, "newsecret456"); !errors.Is(err, ErrInvalidResetToken) {
+				t.Fatalf("ResetPassword: %v, ожидалась ErrInvalidResetToken", err)
+			}
+		})
+	}
+	pt.assertPassword(t, testUserPassword)
+	if messages := pt.sender.sent(); len(messages) != 0 {
+		t.Fatalf("отправлены письма: %+v", messages)
+	}
+}
+
+// Ошибка отправки письма не раскрывается клиенту, токен остается действительным
+func TestPasswordResetSendError(t *testing.T) {
+	pt := newPasswordTest(t)
+	pt.sender.err = errors.New("smtp недоступен")
+
+	if err := pt.service.RequestReset(context.Background(), testUserEmail); err != nil {
+		t.Fatalf("RequestReset: %v", err)
+	}
+	if len(pt.resets.tokens) != 1 {
+		t.Fatalf("сохранено токенов: %d, ожидался 1", len(pt.resets.tokens))
+	}
+}
+
+// Смена пароля отзывает сессии, гасит ссылки сброса и уведомляет пользователя
+func TestChangePassword(t *testing.T) {
+	pt := newPasswordTest(t)
+	ctx := context.Background()
+	token := pt.requestReset(t, ctx)
+
+	if err := pt.service.ChangePassword(ctx, testUserID, testUserPassword, "newsecret456"); err != nil {
+		t.Fatalf("ChangePassword: %v", err)
+	}
+	pt.assertPassword(t, "newsecret456")
+	if len(pt.tokens.revoked) != 1 || pt.tokens.revoked[0] != testUserID {
+		t.Fatalf("отозваны сессии пользователей %v, ожидался %d", pt.tokens.revoked, testUserID)
+	}
+	pt.assertChangedNotice(t, i18n.Default)
+
+	if err := pt.service.ResetPassword(ctx, token, "another789"); !errors.Is(err, ErrInvalidResetToken) {
+		t.Fatalf("ссылка сброса после смены пароля: %v, ожидалась ErrInvalidResetToken", err)
+	}
+}
+
+// Отклоненная смена пароля ничего не меняет и не отправляет писем
+func TestChangePasswordRejected(t *testing.T) {
+	tests := []struct {
+		name             string
+		current, newPass string
+		want             error
+	}{
+		{"неверный текущий пароль", "wrong123", "newsecret456", ErrWrongPassword},
+		{"тот же пароль", testUserPassword, testUserPassword, ErrSamePassword},
+		{"слабый пароль", testUserPassword, "password", ErrWeakPassword},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pt := newPasswordTest(t)
+
+			err := pt.service.ChangePassword(context.Background(), testUserID, tt.current, tt.newPass)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("ChangePassword: %v, ожидалась %v", err, tt.want)
+			}
+			pt.assertPassword(t, testUserPassword)
+			if len(pt.tokens.revoked) != 0 {
+				t.Fatal("сессии отозваны без смены пароля")
+			}
+			if messages := pt.sender.sent(); len(messages) != 0 {
+				t.Fatalf("отправлены письма: %+v", messages)
+			}
+		})
+	}
+}
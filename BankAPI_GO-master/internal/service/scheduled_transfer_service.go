@@ -0,0 +1,489 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/therealadik/bank-api/internal/config"
+	"github.com/therealadik/bank-api/internal/cron"
+	"github.com/therealadik/bank-api/internal/email"
+	"github.com/therealadik/bank-api/internal/i18n"
+	"github.com/therealadik/bank-api/internal/models"
+	"github.com/therealadik/bank-api/internal/models/transfer"
+	"github.com/therealadik/bank-api/internal/repository"
+)
+
+var (
+	ErrScheduledTransferNotFound  = newNotFoundError("регулярный перевод не найден")
+	ErrScheduledTransferNotActive = errors.New("регулярный перевод уже завершен или отменен")
+	ErrInvalidFrequency           = errors.New("неизвестная периодичность перевода")
+	ErrInvalidCronExpr            = errors.New("неверное cron-выражение")
+	ErrScheduleInPast             = errors.New("время начала перевода в прошлом")
+	ErrInvalidScheduleEnd         = errors.New("время окончания должно быть позже первого исполнения")
+)
+
+// scheduleClockSkew допустимое отставание времени начала от текущего момента
+const scheduleClockSkew = time.Minute
+
+// NewScheduledTransfer параметры создаваемого регулярного перевода
+type NewScheduledTransfer struct {
+	FromAccountID int64
+	Target        TransferTarget
+	Amount        decimal.Decimal
+	Frequency     transfer.Frequency
+	CronExpr      string
+	StartAt       time.Time // Нулевое значение — как можно скорее
+	EndAt         *time.Time
+}
+
+// ScheduledTransferService управляет отложенными и регулярными переводами и исполняет их
+type ScheduledTransferService struct {
+	scheduledRepo  *repository.ScheduledTransferRepository
+	userRepo       repository.UserRepository
+	accountService *AccountService
+	audit          *AuditService
+	mailer         email.Sender
+	db             *pgxpool.Pool
+	cfg            config.ScheduledTransferConfig
+}
+
+func NewScheduledTransferService(scheduledRepo *repository.ScheduledTransferRepository, userRepo repository.UserRepository,
+	accountService *AccountService, audit *AuditService, mailer email.Sender, db *pgxpool.Pool,
+	cfg config.ScheduledTransferConfig) *ScheduledTransferService {
+	return &ScheduledTransferService{
+		scheduledRepo:  scheduledRepo,
+		userRepo:       userRepo,
+		accountService: accountService,
+		audit:          audit,
+		mailer:         mailer,
+		db:             db,
+		cfg:            cfg,
+	}
+}
+
+// Create создает отложенный или регулярный перевод. Счет списания и получатель
+// проверяются сразу, но при каждом исполнении получатель определяется заново.
+func (s *ScheduledTransferService) Create(ctx context.Context, userID int64, req NewScheduledTransfer) (*transfer.Scheduled, error) {
+	if req.Amount.LessThanOrEqual(decimal.Zero) {
+		return nil, ErrNegativeAmount
+	}
+	if !req.Frequency.IsValid() {
+		return nil, ErrInvalidFrequency
+	}
+
+	st := &transfer.Scheduled{
+		UserID:        userID,
+		FromAccountID: req.FromAccountID,
+		Amount:        req.Amount,
+		Frequency:     req.Frequency,
+		EndAt:         req.EndAt,
+	}
+
+	req.CronExpr = strings.TrimSpace(req.CronExpr)
+	if (req.Frequency == transfer.CRON) != (req.CronExpr != "") {
+		return nil, ErrInvalidCronExpr
+	}
+	if req.CronExpr != "" {
+		if _, err := cron.Parse(req.CronExpr); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidCronExpr, err)
+		}
+		st.CronExpr = &req.CronExpr
+	}
+
+	now := time.Now().UTC()
+	st.StartAt = req.StartAt.UTC()
+	if req.StartAt.IsZero() {
+		st.StartAt = now
+	}
+	if st.StartAt.Before(now.Add(-scheduleClockSkew)) {
+		return nil, ErrScheduleInPast
+	}
+
+	// Первое исполнение: время начала или ближайшее срабатывание cron-выражения
+	st.ScheduledAt = st.StartAt
+	if st.Frequency == transfer.CRON {
+		st.ScheduledAt = s.nextSlot(st, st.StartAt.Add(-time.Nanosecond))
+		if st.ScheduledAt.IsZero() {
+			return nil, fmt.Errorf("%w: нет ближайших срабатываний", ErrInvalidCronExpr)
+		}
+	}
+	if st.EndAt != nil && !st.EndAt.After(st.ScheduledAt) {
+		return nil, ErrInvalidScheduleEnd
+	}
+
+	if req.Target.AccountID != 0 {
+		st.ToAccountID = &req.Target.AccountID
+	}
+	if req.Target.AccountNumber != "" {
+		st.ToAccountNumber = &req.Target.AccountNumber
+	}
+	if req.Target.Email != "" {
+		targetEmail := strings.TrimSpace(req.Target.Email)
+		st.ToEmail = &targetEmail
+	}
+
+	// Проверяем счет списания и получателя так же, как перед обычным переводом
+	if _, err := s.accountService.PreviewRecipient(ctx, req.FromAccountID, userID, req.Target); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	created, err := s.scheduledRepo.WithTx(tx).Create(ctx, st)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.audit.RecordTx(ctx, tx, AuditEvent{
+		ActorID:    &userID,
+		Action:     models.AUDIT_SCHEDULED_TRANSFER_CREATED,
+		TargetType: models.AuditTargetScheduledTransfer,
+		TargetID:   &created.ID,
+		Details: fmt.Sprintf("from_account_id=%d amount=%s frequency=%s", created.FromAccountID, created.Amount,
+			created.Frequency),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+// GetByUserID получает регулярные переводы пользователя
+func (s *ScheduledTransferService) GetByUserID(ctx context.Context, userID int64) ([]*transfer.Scheduled, error) {
+	return s.scheduledRepo.GetByUserID(ctx, userID)
+}
+
+// GetByID получает регулярный перевод пользователя
+func (s *ScheduledTransferService) GetByID(ctx context.Context, userID, id int64) (*transfer.Scheduled, error) {
+	st, err := s.scheduledRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrScheduledTransferNotFound
+		}
+		return nil, err
+	}
+	if st.UserID != userID {
+		return nil, ErrScheduledTransferNotFound
+	}
+	return st, nil
+}
+
+// GetRuns получает историю исполнений регулярного перевода пользователя
+func (s *ScheduledTransferService) GetRuns(ctx context.Context, userID, id int64) ([]*transfer.Run, error) {
+	if _, err := s.GetByID(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	return s.scheduledRepo.GetRuns(ctx, id)
+}
+
+// Cancel отменяет активный регулярный перевод пользователя
+func (s *ScheduledTransferService) Cancel(ctx context.Context, userID, id int64) (*transfer.Scheduled, error) {
+	if _, err := s.GetByID(ctx, userID, id); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	st, err := s.scheduledRepo.WithTx(tx).Cancel(ctx, id, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrScheduledTransferNotActive
+		}
+		return nil, err
+	}
+
+	err = s.audit.RecordTx(ctx, tx, AuditEvent{
+		ActorID:    &userID,
+		Action:     models.AUDIT_SCHEDULED_TRANSFER_CANCELLED,
+		TargetType: models.AuditTargetScheduledTransfer,
+		TargetID:   &id,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return st, nil
+}
+
+// ExecuteDue исполняет переводы, время которых наступило (не больше BatchSize за вызов).
+// Возвращает число обработанных переводов.
+func (s *ScheduledTransferService) ExecuteDue(ctx context.Context, logger *logrus.Logger) (int, error) {
+	processed := 0
+	for processed < s.cfg.BatchSize {
+		st, run, err := s.executeNext(ctx)
+		if err != nil {
+			return processed, err
+		}
+		if st == nil {
+			break
+		}
+		processed++
+
+		if run.Status != transfer.RUN_SUCCEEDED {
+			logger.Warnf("Регулярный перевод %d не исполнен (попытка %d): %s", st.ID, run.Attempt, *run.Error)
+			// Сообщаем о первой неудаче и об окончательной; промежуточные повторы не дублируем
+			if run.Status == transfer.RUN_FAILED || run.Attempt == 1 {
+				if err := s.notifyFailure(ctx, st, run); err != nil {
+					logger.Errorf("Ошибка отправки уведомления о регулярном переводе %d: %v", st.ID, err)
+				}
+			}
+		}
+	}
+	return processed, nil
+}
+
+// RunExecution периодически исполняет наступившие переводы до отмены контекста
+func (s *ScheduledTransferService) RunExecution(ctx context.Context, logger *logrus.Logger) {
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			executed, err := s.ExecuteDue(ctx, logger)
+			if err != nil {
+				logger.Errorf("Ошибка исполнения регулярных переводов: %v", err)
+			}
+			if executed > 0 {
+				logger.Infof("Обработано регулярных переводов: %d", executed)
+			}
+		}
+	}
+}
+
+// executeNext исполняет один наступивший перевод. Перевод, запись о попытке и новое
+// расписание фиксируются в одной транзакции, поэтому перевод не выполняется дважды.
+// Возвращает nil, если исполнять нечего.
+func (s *ScheduledTransferService) executeNext(ctx context.Context) (*transfer.Scheduled, *transfer.Run, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	scheduledRepo := s.scheduledRepo.WithTx(tx)
+
+	now := time.Now().UTC()
+	st, err := scheduledRepo.LockNextDue(ctx, now)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+
+	// Перевод выполняется в точке сохранения: при ошибке откатывается только он,
+	// а результат попытки все равно записывается
+	transferErr := s.transferSavepoint(ctx, tx, st)
+
+	run := &transfer.Run{
+		ScheduledTransferID: st.ID,
+		ScheduledAt:         st.ScheduledAt,
+		Attempt:             st.Attempts + 1,
+		Status:              transfer.RUN_SUCCEEDED,
+	}
+
+	switch {
+	case transferErr == nil:
+		st.LastError = nil
+		s.advance(st, now, transfer.COMPLETED)
+	case isPermanentTransferError(transferErr):
+		// Перевод не сможет выполниться и в будущем: останавливаем его
+		run.Status = transfer.RUN_FAILED
+		st.Status = transfer.FAILED
+		st.Attempts = 0
+	case errors.Is(transferErr, ErrAccountFrozen):
+		// Заморозка снимается только администратором: пропускаем это исполнение
+		run.Status = transfer.RUN_FAILED
+		s.advance(st, now, transfer.FAILED)
+	default:
+		// Нехватка средств или временная ошибка: повторяем, пока не наступит следующее исполнение
+		retryAt := now.Add(s.cfg.RetryInterval)
+		next := s.nextSlot(st, st.ScheduledAt)
+		if st.Attempts < s.cfg.MaxRetries && (next.IsZero() || retryAt.Before(next)) {
+			run.Status = transfer.RUN_RETRYING
+			st.Attempts++
+			st.NextRunAt = retryAt
+		} else {
+			run.Status = transfer.RUN_FAILED
+			s.advance(st, now, transfer.FAILED)
+		}
+	}
+
+	if transferErr != nil {
+		message := transferErr.Error()
+		run.Error = &message
+		st.LastError = &message
+	}
+
+	if err := scheduledRepo.CreateRun(ctx, run); err != nil {
+		return nil, nil, err
+	}
+	if err := scheduledRepo.UpdateSchedule(ctx, st); err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	return st, run, nil
+}
+
+// transferSavepoint выполняет перевод в точке сохранения внутри транзакции tx
+func (s *ScheduledTransferService) transferSavepoint(ctx context.Context, tx pgx.Tx, st *transfer.Scheduled) error {
+	savepoint, err := tx.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer savepoint.Rollback(ctx)
+
+	target := TransferTarget{}
+	switch {
+	case st.ToAccountID != nil:
+		target.AccountID = *st.ToAccountID
+	case st.ToAccountNumber != nil:
+		target.AccountNumber = *st.ToAccountNumber
+	case st.ToEmail != nil:
+		target.Email = *st.ToEmail
+	}
+
+	if _, err := s.accountService.TransferTx(ctx, savepoint, st.FromAccountID, target, st.UserID, st.Amount); err != nil {
+		return err
+	}
+
+	return savepoint.Commit(ctx)
+}
+
+// advance переносит перевод на следующее исполнение после now. Если исполнений
+// больше нет, перевод получает статус final (для однократного перевода) или COMPLETED.
+func (s *ScheduledTransferService) advance(st *transfer.Scheduled, now time.Time, final transfer.Status) {
+	st.Attempts = 0
+
+	after := st.ScheduledAt
+	if now.After(after) {
+		after = now // Пропущенные за время простоя исполнения не наверстываются
+	}
+
+	next := s.nextSlot(st, after)
+	if next.IsZero() || (st.EndAt != nil && next.After(*st.EndAt)) {
+		st.Status = transfer.COMPLETED
+		if st.Frequency == transfer.ONCE {
+			st.Status = final
+		}
+		return
+	}
+
+	st.ScheduledAt = next
+	st.NextRunAt = next
+}
+
+// nextSlot возвращает плановое время исполнения строго после after
+// или нулевое время, если исполнений больше нет
+func (s *ScheduledTransferService) nextSlot(st *transfer.Scheduled, after time.Time) time.Time {
+	switch st.Frequency {
+	case transfer.DAILY, transfer.WEEKLY:
+		days := 1
+		if st.Frequency == transfer.WEEKLY {
+			days = 7
+		}
+		next := st.ScheduledAt
+		for !next.After(after) {
+			next = next.AddDate(0, 0, days)
+		}
+		return next
+	case transfer.MONTHLY:
+		// Считаем от даты начала, чтобы 31-е число не смещалось после коротких месяцев
+		n := (st.ScheduledAt.Year()-st.StartAt.Year())*12 + int(st.ScheduledAt.Month()-st.StartAt.Month())
+		next := st.ScheduledAt
+		for !next.After(after) {
+			n++
+			next = addMonths(st.StartAt, n)
+		}
+		return next
+	case transfer.CRON:
+		if st.CronExpr == nil {
+			return time.Time{}
+		}
+		schedule, err := cron.Parse(*st.CronExpr)
+		if err != nil {
+			return time.Time{}
+		}
+		return schedule.Next(after.UTC())
+	default:
+		return time.Time{}
+	}
+}
+
+// notifyFailure сообщает владельцу по email о неудачном исполнении перевода
+func (s *ScheduledTransferService) notifyFailure(ctx context.Context, st *transfer.Scheduled, run *transfer.Run) error {
+	user, err := s.userRepo.GetByID(ctx, st.UserID)
+	if err != nil {
+		return err
+	}
+
+	// Перевод исполняется в фоне, поэтому письмо отправляется на языке по умолчанию
+	lang := i18n.FromContext(ctx)
+	outcome := i18n.T(lang, i18n.EMAIL_SCHEDULED_WILL_RETRY, st.NextRunAt.Format(time.RFC3339))
+	switch {
+	case st.Status == transfer.FAILED:
+		outcome = i18n.T(lang, i18n.EMAIL_SCHEDULED_STOPPED)
+	case run.Status == transfer.RUN_FAILED && st.Status == transfer.ACTIVE:
+		outcome = i18n.T(lang, i18n.EMAIL_SCHEDULED_SKIPPED, st.NextRunAt.Format(time.RFC3339))
+	case run.Status == transfer.RUN_FAILED:
+		outcome = i18n.T(lang, i18n.EMAIL_SCHEDULED_SKIPPED_NO_NEXT)
+	}
+
+	return s.mailer.Send(ctx, email.Message{
+		To:      user.Email,
+		Subject: i18n.T(lang, i18n.EMAIL_SCHEDULED_FAILED_SUBJECT),
+		Body: i18n.T(lang, i18n.EMAIL_SCHEDULED_FAILED_BODY,
+			st.ID, st.Amount, run.ScheduledAt.Format(time.RFC3339), *run.Error, outcome),
+	})
+}
+
+// isPermanentTransferError сообщает, что перевод не выполнится и при повторе
+func isPermanentTransferError(err error) bool {
+	return errors.Is(err, ErrAccountClosed) ||
+		errors.Is(err, ErrAccountNotOwned) ||
+		errors.Is(err, ErrAccountNotFound) ||
+		errors.Is(err, ErrRecipientNotFound) ||
+		errors.Is(err, ErrRecipientRequired) ||
+		errors.Is(err, ErrInvalidAccountNumber) ||
+		errors.Is(err, ErrCurrencyMismatch) ||
+		errors.Is(err, ErrSameAccount) ||
+		errors.Is(err, pgx.ErrNoRows)
+}
+
+// addMonths прибавляет n месяцев к t; если в целевом месяце нет такого дня, берется последний
+func addMonths(t time.Time, n int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(n), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	lastDay := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(t.Day(), lastDay)-1)
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+	"github.com/therealadik/bank-api/internal/config"
+	"github.com/therealadik/bank-api/internal/models/account"
+	"github.com/therealadik/bank-api/internal/models/stream"
+	"github.com/therealadik/bank-api/internal/models/transaction"
+)
+
+var ErrStreamLimitExceeded = errors.New("превышено число потоков изменений пользователя")
+
+// StreamService раздает изменения счетов и транзакций открытым потокам их владельцев.
+// Репозитории сообщают об изменениях через PostgreSQL NOTIFY в той же транзакции,
+// поэтому каждый экземпляр сервиса получает изменения, сделанные любым экземпляром,
+// и только после их фиксации.
+type StreamService struct {
+	db  *pgxpool.Pool
+	cfg config.StreamConfig
+
+	mu          sync.Mutex
+	subscribers map[int64]map[*StreamSubscription]struct{}
+	stopped     bool
+}
+
+// StreamSubscription подписка на изменения счетов пользователя. Канал Events закрывается,
+// если клиент не успевает получать события или соединение с PostgreSQL было потеряно
+// и часть изменений могла быть пропущена: клиент должен переподключиться и получить
+// актуальное состояние заново.
+type StreamSubscription struct {
+	userID int64
+	events chan stream.Event
+}
+
+// Events канал событий подписки
+func (s *StreamSubscription) Events() <-chan stream.Event {
+	return s.events
+}
+
+func NewStreamService(db *pgxpool.Pool, cfg config.StreamConfig) *StreamService {
+	return &StreamService{
+		db:          db,
+		cfg:         cfg,
+		subscribers: make(map[int64]map[*StreamSubscription]struct{}),
+	}
+}
+
+// Subscribe подписывает на изменения счетов пользователя. Подписываться нужно до чтения
+// текущего состояния, чтобы не пропустить изменения между чтением и подпиской.
+func (s *StreamService) Subscribe(userID int64) (*StreamSubscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub := &StreamSubscription{
+		userID: userID,
+		events: make(chan stream.Event, s.cfg.BufferSize),
+	}
+	// Сервис остановлен: поток завершится сразу после отправки текущего состояния
+	if s.stopped {
+		close(sub.events)
+		return sub, nil
+	}
+
+	subs := s.subscribers[userID]
+	if len(subs) >= s.cfg.MaxConnectionsPerUser {
+		return nil, ErrStreamLimitExceeded
+	}
+	if subs == nil {
+		subs = make(map[*StreamSubscription]struct{})
+		s.subscribers[userID] = subs
+	}
+	subs[sub] = struct{}{}
+	return sub, nil
+}
+
+// Unsubscribe отменяет подписку
+func (s *StreamService) Unsubscribe(sub *StreamSubscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.remove(sub)
+}
+
+// Run слушает канал изменений до отмены контекста; при потере соединения переподключается.
+// После остановки все подписки закрываются, чтобы открытые потоки не задерживали
+// завершение сервера.
+func (s *StreamService) Run(ctx context.Context, logger *logrus.Logger) {
+	defer s.stop()
+
+	for {
+		err := s.listen(ctx, logger)
+		if ctx.Err() != nil {
+			return
+		}
+		logger.Errorf("Ошибка получения изменений счетов, переподключение через %v: %v", s.cfg.ReconnectDelay, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(s.cfg.ReconnectDelay):
+		}
+	}
+}
+
+// listen открывает отдельное соединение (не из пула: оно занято ожиданием уведомлений
+// все время работы) и раздает уведомления подписчикам до ошибки
+func (s *StreamService) listen(ctx context.Context, logger *logrus.Logger) error {
+	conn, err := pgx.ConnectConfig(ctx, s.db.Config().ConnConfig.Copy())
+	if err != nil {
+		return fmt.Errorf("ошибка подключения: %w", err)
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{stream.Channel}.Sanitize()); err != nil {
+		return fmt.Errorf("ошибка подписки на канал %s: %w", stream.Channel, err)
+	}
+	// Пока соединения не было, подписчики могли пропустить изменения
+	s.resetSubscribers()
+	logger.Infof("Получение изменений счетов из канала %s запущено", stream.Channel)
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		event, err := decodeStreamEvent(n.Payload)
+		if err != nil {
+			logger.Errorf("Ошибка разбора уведомления об изменении счета: %v", err)
+			continue
+		}
+		s.dispatch(event)
+	}
+}
+
+// dispatch передает событие подписчикам владельца счета. Подписка клиента, который
+// не успевает получать события, закрывается.
+func (s *StreamService) dispatch(event stream.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for sub := range s.subscribers[event.UserID] {
+		select {
+		case sub.events <- event:
+		default:
+			s.remove(sub)
+		}
+	}
+}
+
+// resetSubscribers закрывает все подписки
+func (s *StreamService) resetSubscribers() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.removeAll()
+}
+
+// stop закрывает все подписки и запрещает новые
+func (s *StreamService) stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stopped = true
+	s.removeAll()
+}
+
+// removeAll удаляет все подписки. Вызывается под s.mu.
+func (s *StreamService) removeAll() {
+	for _, subs := range s.subscribers {
+		for sub := range subs {
+			s.remove(sub)
+		}
+	}
+}
+
+// remove удаляет подписку и закрывает ее канал. Вызывается под s.mu.
+func (s *StreamService) remove(sub *StreamSubscription) {
+	subs := s.subscribers[sub.userID]
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(s.subscribers, sub.userID)
+	}
+	close(sub.events)
+}
+
+// decodeStreamEvent разбирает уведомление, отправленное репозиторием
+func decodeStreamEvent(payload string) (stream.Event, error) {
+	var n struct {
+		Type   stream.EventType `json:"type"`
+		UserID int64            `json:"user_id"`
+		Data   json.RawMessage  `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(payload), &n); err != nil {
+		return stream.Event{}, err
+	}
+
+	event := stream.Event{Type: n.Type, UserID: n.UserID}
+	switch n.Type {
+	case stream.ACCOUNT:
+		event.Account = &account.Account{}
+		if err := json.Unmarshal(n.Data, event.Account); err != nil {
+			return stream.Event{}, err
+		}
+	case stream.TRANSACTION:
+		event.Transaction = &transaction.Transaction{}
+		if err := json.Unmarshal(n.Data, event.Transaction); err != nil {
+			return stream.Event{}, err
+		}
+	default:
+		return stream.Event{}, fmt.Errorf("неизвестный тип события %q", n.Type)
+	}
+	return event, nil
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret ключ из тестовых векторов RFC 4226 и RFC 6238 ("12345678901234567890") в base32
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// Коды совпадают с тестовыми векторами RFC 4226 (приложение D) и RFC 6238
+// (приложение B, SHA1, последние шесть цифр)
+func TestTOTPCode(t *testing.T) {
+	key := []byte("12345678901234567890")
+
+	tests := []struct {
+		name string
+		step int64
+		want string
+	}{
+		{"HOTP 0", 0, "755224"},
+		{"HOTP 1", 1, "287082"},
+		{"HOTP 2", 2, "359152"},
+		{"HOTP 5", 5, "254676"},
+		{"HOTP 9", 9, "520489"},
+		{"TOTP 59", 59 / totpPeriod, "287082"},
+		{"TOTP 1111111109", 1111111109 / totpPeriod, "081804"},
+		{"TOTP 1111111111", 1111111111 / totpPeriod, "050471"},
+		{"TOTP 1234567890", 1234567890 / totpPeriod, "005924"},
+		{"TOTP 2000000000", 2000000000 / totpPeriod, "279037"},
+		{"TOTP 20000000000", 20000000000 / totpPeriod, "353130"},
+	}
+
+	for _, tc := range tests {
+		if got := totpCode(key, tc.step); got != tc.want {
+			t.Errorf("%s: код %s, ожидался %s", tc.name, got, tc.want)
+		}
+	}
+}
+
+// Код принимается в текущем шаге и в соседних с учетом расхождения часов
+func TestValidateTOTP(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := now.Unix() / totpPeriod
+
+	tests := []struct {
+		name     string
+		secret   string
+		code     string
+		wantStep int64
+		wantOK   bool
+	}{
+		{"текущий шаг", rfcSecret, "050471", current, true},
+		{"код с пробелами", rfcSecret, " 050471 ", current, true},
+		{"секрет в нижнем регистре", "gezdgnbvgy3tqojqgezdgnbvgy3tqojq", "050471", current, true},
+		{"предыдущий шаг", rfcSecret, "081804", current - 1, true},
+		{"следующий шаг", rfcSecret, totpCode([]byte("12345678901234567890"), current+1), current + 1, true},
+		{"два шага назад", rfcSecret, totpCode([]byte("12345678901234567890"), current-2), 0, false},
+		{"неверный код", rfcSecret, "000000", 0, false},
+		{"короткий код", rfcSecret, "05047", 0, false},
+		{"длинный код", rfcSecret, "0504710", 0, false},
+		{"неверный секрет", "не base32", "050471", 0, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			step, ok := validateTOTP(tc.secret, tc.code, now)
+			if ok != tc.wantOK || step != tc.wantStep {
+				t.Fatalf("validateTOTP = (%d, %v), ожидалось (%d, %v)", step, ok, tc.wantStep, tc.wantOK)
+			}
+		})
+	}
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	uri := totpProvisioningURI("Bank API", "user@example.com", rfcSecret)
+
+	u, err := url.Parse(uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Scheme != "otpauth" || u.Host != "totp" || u.Path != "/Bank API:user@example.com" {
+		t.Fatalf("неверный URI %s", uri)
+	}
+
+	query := u.Query()
+	for param, want := range map[string]string{
+		"secret":    rfcSecret,
+		"issuer":    "Bank API",
+		"algorithm": "SHA1",
+		"digits":    "6",
+		"period":    "30",
+	} {
+		if got := query.Get(param); got != want {
+			t.Errorf("параметр %s = %q, ожидалось %q", param, got, want)
+		}
+	}
+
+	// Пробел кодируется как %20: приложения-аутентификаторы не декодируют "+"
+	if strings.Contains(u.RawQuery, "+") || !strings.Contains(u.RawQuery, "issuer=Bank%20API") {
+		t.Fatalf("пробел закодирован как +: %s", u.RawQuery)
+	}
+}
+
+func TestNormalizeRecoveryCode(t *testing.T) {
+	tests := []struct {
+		code string
+		want string
+	}{
+		{"aaaaa-bbbbb", "aaaaabbbbb"},
+		{"AAAAA-BBBBB", "aaaaabbbbb"},
+		{"aaaaa bbbbb", "aaaaabbbbb"},
+		{"aaaaabbbbb", "aaaaabbbbb"},
+	}
+
+	for _, tc := range tests {
+		if got := normalizeRecoveryCode(tc.code); got != tc.want {
+			t.Errorf("normalizeRecoveryCode(%q) = %q, ожидалось %q", tc.code, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,384 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+	"github.com/therealadik/bank-api/internal/config"
+	"github.com/therealadik/bank-api/internal/models"
+	"github.com/therealadik/bank-api/internal/models/transfer"
+	"github.com/therealadik/bank-api/internal/repository"
+)
+
+var (
+	ErrTransferLimitExceeded = errors.New("превышен лимит переводов")
+	ErrInvalidTransferLimit  = errors.New("лимит не может быть отрицательным")
+	ErrPreviewRateLimited    = errors.New("слишком много запросов предпросмотра получателя")
+)
+
+// hourlyWindow окно подсчета числа операций
+const hourlyWindow = time.Hour
+
+// TransferLimitError описывает превышенный лимит и момент, когда операция снова станет возможна
+type TransferLimitError struct {
+	Scope    transfer.LimitScope
+	Limit    transfer.LimitKind
+	Max      decimal.Decimal // Значение лимита (для HOURLY_COUNT — число операций)
+	Used     decimal.Decimal // Уже использовано до текущей операции
+	ResetsAt *time.Time      // Нет для лимита на одну операцию
+}
+
+func (e *TransferLimitError) Error() string {
+	return fmt.Sprintf("%s: %s/%s, лимит %s, использовано %s", ErrTransferLimitExceeded, e.Scope, e.Limit, e.Max, e.Used)
+}
+
+// Is позволяет проверять ошибку через errors.Is(err, ErrTransferLimitExceeded)
+func (e *TransferLimitError) Is(target error) bool {
+	return target == ErrTransferLimitExceeded
+}
+
+// PreviewRateLimitError ошибка превышения частоты предпросмотра получателя с временем до следующей попытки
+type PreviewRateLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *PreviewRateLimitError) Error() string {
+	return fmt.Sprintf("%s, повторите через %s", ErrPreviewRateLimited, e.RetryAfter.Round(time.Second))
+}
+
+func (e *PreviewRateLimitError) Unwrap() error {
+	return ErrPreviewRateLimited
+}
+
+// LimitUsage действующие лимиты и их использование для счета или пользователя
+type LimitUsage struct {
+	Scope       transfer.LimitScope
+	Limits      transfer.Limits
+	DailyUsed   decimal.Decimal
+	MonthlyUsed decimal.Decimal
+	HourlyCount int
+}
+
+// TransferLimitService проверяет лимиты исходящих переводов и списаний
+type TransferLimitService struct {
+	limitRepo   *repository.TransferLimitRepository
+	accountRepo *repository.AccountRepository
+	userRepo    repository.UserRepository
+	audit       *AuditService
+	db          *pgxpool.Pool
+	cfg         config.TransferLimitsConfig
+}
+
+func NewTransferLimitService(limitRepo *repository.TransferLimitRepository, accountRepo *repository.AccountRepository,
+	userRepo repository.UserRepository, audit *AuditService, db *pgxpool.Pool,
+	cfg config.TransferLimitsConfig) *TransferLimitService {
+	return &TransferLimitService{
+		limitRepo:   limitRepo,
+		accountRepo: accountRepo,
+		userRepo:    userRepo,
+		audit:       audit,
+		db:          db,
+		cfg:         cfg,
+	}
+}
+
+// CheckTx проверяет, что исходящая операция на сумму amount со счета accountID
+// не превышает лимиты счета и пользователя. Использование считается по таблице
+// transactions в транзакции tx; строка пользователя блокируется до ее конца.
+func (s *TransferLimitService) CheckTx(ctx context.Context, tx pgx.Tx, userID, accountID int64, amount decimal.Decimal) error {
+	limitRepo := s.limitRepo.WithTx(tx)
+
+	if err := limitRepo.LockUser(ctx, userID); err != nil {
+		return fmt.Errorf("ошибка блокировки пользователя: %w", err)
+	}
+
+	now := time.Now().UTC()
+
+	accountLimits, err := s.effectiveLimits(ctx, limitRepo, transfer.SCOPE_ACCOUNT, accountID)
+	if err != nil {
+		return err
+	}
+	if err := s.check(ctx, limitRepo, transfer.SCOPE_ACCOUNT, accountID, accountLimits, amount, now); err != nil {
+		return err
+	}
+
+	userLimits, err := s.effectiveLimits(ctx, limitRepo, transfer.SCOPE_USER, userID)
+	if err != nil {
+		return err
+	}
+	return s.check(ctx, limitRepo, transfer.SCOPE_USER, userID, userLimits, amount, now)
+}
+
+// CheckPreview проверяет, что пользователь не превысил число запросов предпросмотра получателя
+// в скользящем окне, и учитывает текущий запрос. Строка пользователя блокируется, чтобы
+// параллельные запросы не превысили ограничение.
+func (s *TransferLimitService) CheckPreview(ctx context.Context, userID int64) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	limitRepo := s.limitRepo.WithTx(tx)
+	if err := limitRepo.LockUser(ctx, userID); err != nil {
+		return fmt.Errorf("ошибка блокировки пользователя: %w", err)
+	}
+
+	since := time.Now().Add(-s.cfg.PreviewWindow)
+	count, oldest, err := limitRepo.CountPreviewsSince(ctx, userID, since)
+	if err != nil {
+		return fmt.Errorf("ошибка подсчета предпросмотров: %w", err)
+	}
+	if count >= s.cfg.PreviewMax && oldest != nil {
+		return &PreviewRateLimitError{RetryAfter: time.Until(oldest.Add(s.cfg.PreviewWindow))}
+	}
+
+	if err := limitRepo.RecordPreview(ctx, userID, since); err != nil {
+		return fmt.Errorf("ошибка учета предпросмотра: %w", err)
+	}
+	return tx.Commit(ctx)
+}
+
+// GetAccountUsage возвращает действующие лимиты и их использование для счета пользователя и для самого пользователя
+func (s *TransferLimitService) GetAccountUsage(ctx context.Context, userID, accountID int64) ([]*LimitUsage, error) {
+	acc, err := s.accountRepo.GetAccountByID(ctx, accountID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrAccountNotFound
+		}
+		return nil, err
+	}
+	if acc.UserID != userID {
+		return nil, ErrAccountNotOwned
+	}
+
+	now := time.Now().UTC()
+	var usage []*LimitUsage
+	for _, scope := range []struct {
+		scope   transfer.LimitScope
+		ownerID int64
+	}{{transfer.SCOPE_ACCOUNT, accountID}, {transfer.SCOPE_USER, userID}} {
+		u, err := s.usage(ctx, scope.scope, scope.ownerID, now)
+		if err != nil {
+			return nil, err
+		}
+		usage = append(usage, u)
+	}
+	return usage, nil
+}
+
+// GetOverride возвращает индивидуальные и действующие лимиты пользователя или счета
+func (s *TransferLimitService) GetOverride(ctx context.Context, scope transfer.LimitScope,
+	ownerID int64) (*transfer.LimitOverride, transfer.Limits, error) {
+	if err := s.ensureOwnerExists(ctx, scope, ownerID); err != nil {
+		return nil, transfer.Limits{}, err
+	}
+
+	override, err := s.limitRepo.GetOverride(ctx, scope, ownerID)
+	if err != nil {
+		return nil, transfer.Limits{}, err
+	}
+
+	effective, err := s.effectiveLimits(ctx, s.limitRepo, scope, ownerID)
+	if err != nil {
+		return nil, transfer.Limits{}, err
+	}
+	return override, effective, nil
+}
+
+// SetOverride устанавливает индивидуальные лимиты пользователя или счета и записывает действие в журнал
+func (s *TransferLimitService) SetOverride(ctx context.Context, actorID int64, scope transfer.LimitScope, ownerID int64,
+	limits transfer.Limits) (*transfer.LimitOverride, error) {
+	for _, value := range []decimal.NullDecimal{limits.SingleMax, limits.DailyMax, limits.MonthlyMax} {
+		if value.Valid && value.Decimal.IsNegative() {
+			return nil, ErrInvalidTransferLimit
+		}
+	}
+	if limits.HourlyCount != nil && *limits.HourlyCount < 0 {
+		return nil, ErrInvalidTransferLimit
+	}
+
+	if err := s.ensureOwnerExists(ctx, scope, ownerID); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	saved, err := s.limitRepo.WithTx(tx).SetOverride(ctx, &transfer.LimitOverride{
+		Scope:     scope,
+		OwnerID:   ownerID,
+		Limits:    limits,
+		UpdatedBy: &actorID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	targetType := models.AuditTargetUser
+	if scope == transfer.SCOPE_ACCOUNT {
+		targetType = models.AuditTargetAccount
+	}
+	err = s.audit.RecordTx(ctx, tx, AuditEvent{
+		ActorID:    &actorID,
+		Action:     models.ADMIN_SET_TRANSFER_LIMITS,
+		TargetType: targetType,
+		TargetID:   &ownerID,
+		Details:    describeLimits(limits),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return saved, nil
+}
+
+// effectiveLimits возвращает действующие лимиты: для пользователя — лимиты по умолчанию
+// с индивидуальными поправками, для счета — только индивидуальные
+func (s *TransferLimitService) effectiveLimits(ctx context.Context, limitRepo *repository.TransferLimitRepository,
+	scope transfer.LimitScope, ownerID int64) (transfer.Limits, error) {
+	var limits transfer.Limits
+	if scope == transfer.SCOPE_USER {
+		limits = s.cfg.Defaults
+	}
+
+	override, err := limitRepo.GetOverride(ctx, scope, ownerID)
+	if err != nil {
+		return transfer.Limits{}, err
+	}
+	if override != nil {
+		limits = limits.Merge(override.Limits)
+	}
+	return limits, nil
+}
+
+// check проверяет лимиты одного уровня
+func (s *TransferLimitService) check(ctx context.Context, limitRepo *repository.TransferLimitRepository,
+	scope transfer.LimitScope, ownerID int64, limits transfer.Limits, amount decimal.Decimal, now time.Time) error {
+	if limits.SingleMax.Valid && amount.GreaterThan(limits.SingleMax.Decimal) {
+		return &TransferLimitError{Scope: scope, Limit: transfer.LIMIT_SINGLE, Max: limits.SingleMax.Decimal,
+			Used: decimal.Zero}
+	}
+
+	dayStart, monthStart := periodStarts(now)
+
+	if limits.DailyMax.Valid {
+		used, err := limitRepo.SumOutgoingSince(ctx, scope, ownerID, dayStart)
+		if err != nil {
+			return err
+		}
+		if used.Add(amount).GreaterThan(limits.DailyMax.Decimal) {
+			resetsAt := dayStart.AddDate(0, 0, 1)
+			return &TransferLimitError{Scope: scope, Limit: transfer.LIMIT_DAILY, Max: limits.DailyMax.Decimal,
+				Used: used, ResetsAt: &resetsAt}
+		}
+	}
+
+	if limits.MonthlyMax.Valid {
+		used, err := limitRepo.SumOutgoingSince(ctx, scope, ownerID, monthStart)
+		if err != nil {
+			return err
+		}
+		if used.Add(amount).GreaterThan(limits.MonthlyMax.Decimal) {
+			resetsAt := monthStart.AddDate(0, 1, 0)
+			return &TransferLimitError{Scope: scope, Limit: transfer.LIMIT_MONTHLY, Max: limits.MonthlyMax.Decimal,
+				Used: used, ResetsAt: &resetsAt}
+		}
+	}
+
+	if limits.HourlyCount != nil {
+		times, err := limitRepo.ListOutgoingTimesSince(ctx, scope, ownerID, now.Add(-hourlyWindow))
+		if err != nil {
+			return err
+		}
+		if maxCount := *limits.HourlyCount; len(times) >= maxCount {
+			// Операция станет возможна, когда из окна выйдет столько операций, чтобы их
+			// осталось меньше лимита. При нулевом лимите операции запрещены без срока.
+			var resetsAt *time.Time
+			if maxCount > 0 {
+				reset := times[len(times)-maxCount].Add(hourlyWindow)
+				resetsAt = &reset
+			}
+			return &TransferLimitError{Scope: scope, Limit: transfer.LIMIT_HOURLY_COUNT,
+				Max: decimal.NewFromInt(int64(maxCount)), Used: decimal.NewFromInt(int64(len(times))), ResetsAt: resetsAt}
+		}
+	}
+
+	return nil
+}
+
+// usage считает использование лимитов пользователя или счета
+func (s *TransferLimitService) usage(ctx context.Context, scope transfer.LimitScope, ownerID int64,
+	now time.Time) (*LimitUsage, error) {
+	limits, err := s.effectiveLimits(ctx, s.limitRepo, scope, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	dayStart, monthStart := periodStarts(now)
+	u := &LimitUsage{Scope: scope, Limits: limits}
+
+	if u.DailyUsed, err = s.limitRepo.SumOutgoingSince(ctx, scope, ownerID, dayStart); err != nil {
+		return nil, err
+	}
+	if u.MonthlyUsed, err = s.limitRepo.SumOutgoingSince(ctx, scope, ownerID, monthStart); err != nil {
+		return nil, err
+	}
+	times, err := s.limitRepo.ListOutgoingTimesSince(ctx, scope, ownerID, now.Add(-hourlyWindow))
+	if err != nil {
+		return nil, err
+	}
+	u.HourlyCount = len(times)
+
+	return u, nil
+}
+
+// ensureOwnerExists проверяет существование пользователя или счета
+func (s *TransferLimitService) ensureOwnerExists(ctx context.Context, scope transfer.LimitScope, ownerID int64) error {
+	if scope == transfer.SCOPE_ACCOUNT {
+		if _, err := s.accountRepo.GetAccountByID(ctx, ownerID); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrAccountNotFound
+			}
+			return err
+		}
+		return nil
+	}
+	_, err := s.userRepo.GetByID(ctx, ownerID)
+	return err
+}
+
+// periodStarts возвращает начало текущих суток и месяца (UTC)
+func periodStarts(now time.Time) (time.Time, time.Time) {
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return dayStart, monthStart
+}
+
+// describeLimits формирует описание лимитов для журнала аудита
+func describeLimits(l transfer.Limits) string {
+	describe := func(v decimal.NullDecimal) string {
+		if !v.Valid {
+			return "-"
+		}
+		return v.Decimal.String()
+	}
+	hourly := "-"
+	if l.HourlyCount != nil {
+		hourly = fmt.Sprint(*l.HourlyCount)
+	}
+	return fmt.Sprintf("single=%s daily=%s monthly=%s hourly_count=%s",
+		describe(l.SingleMax), describe(l.DailyMax), describe(l.MonthlyMax), hourly)
+}
@@ -0,0 +1,272 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/therealadik/bank-api/internal/config"
+	"github.com/therealadik/bank-api/internal/models"
+	"github.com/therealadik/bank-api/internal/repository"
+	"github.com/therealadik/bank-api/internal/requestmeta"
+)
+
+// recoveryCodeCount количество кодов восстановления, выдаваемых при включении 2FA
+const recoveryCodeCount = 10
+
+var (
+	ErrTwoFactorAlreadyEnabled = errors.New("двухфакторная аутентификация уже включена")
+	ErrTwoFactorNotEnrolled    = errors.New("двухфакторная аутентификация не настроена")
+	ErrTwoFactorNotEnabled     = errors.New("двухфакторная аутентификация не включена")
+	ErrInvalidTOTPCode         = errors.New("неверный код подтверждения")
+	ErrTOTPRequired            = errors.New("операция требует подтверждения кодом TOTP")
+)
+
+// TwoFactorService сервис двухфакторной аутентификации по TOTP.
+// Все проверки кодов проходят через защиту от перебора LoginGuard
+// с общим для пользователя счетчиком неверных кодов.
+type TwoFactorService struct {
+	twoFactorRepo repository.TwoFactorRepository
+	userRepo      repository.UserRepository
+	guard         *LoginGuard
+	cfg           config.TwoFactorConfig
+	pgpKey        string // Ключ шифрования секретов TOTP в БД
+}
+
+// NewTwoFactorService создает сервис двухфакторной аутентификации
+func NewTwoFactorService(twoFactorRepo repository.TwoFactorRepository, userRepo repository.UserRepository,
+	guard *LoginGuard, cfg config.TwoFactorConfig, pgpKey string) *TwoFactorService {
+	return &TwoFactorService{
+		twoFactorRepo: twoFactorRepo,
+		userRepo:      userRepo,
+		guard:         guard,
+		cfg:           cfg,
+		pgpKey:        pgpKey,
+	}
+}
+
+// Enroll генерирует новый секрет TOTP и возвращает его вместе с otpauth URI для QR-кода.
+// 2FA включается только после подтверждения кодом из приложения (Confirm).
+func (s *TwoFactorService) Enroll(ctx context.Context, userID int64) (secret, uri string, err error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return "", "", err
+	}
+	if user.TwoFactorEnabled {
+		return "", "", ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err = generateTOTPSecret()
+	if err != nil {
+		return "", "", fmt.Errorf("ошибка генерации секрета TOTP: %w", err)
+	}
+
+	if err := s.twoFactorRepo.SetPendingSecret(ctx, userID, secret, s.pgpKey); err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			// Между проверкой и записью 2FA успели включить
+			return "", "", ErrTwoFactorAlreadyEnabled
+		}
+		return "", "", fmt.Errorf("ошибка сохранения секрета TOTP: %w", err)
+	}
+
+	return secret, totpProvisioningURI(s.cfg.Issuer, user.Email, secret), nil
+}
+
+// Confirm включает 2FA после проверки первого кода и возвращает коды восстановления.
+// Коды показываются пользователю один раз, в БД хранятся только их хеши.
+func (s *TwoFactorService) Confirm(ctx context.Context, userID int64, code string) ([]string, error) {
+	tf, err := s.twoFactorRepo.GetTwoFactor(ctx, userID, s.pgpKey)
+	if err != nil {
+		return nil, err
+	}
+	if tf.Enabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	if tf.Secret == "" {
+		return nil, ErrTwoFactorNotEnrolled
+	}
+
+	err = s.checkCode(ctx, userID, func() error {
+		return s.verifyTOTP(ctx, userID, tf.Secret, code)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.twoFactorRepo.Enable(ctx, userID, hashes); err != nil {
+		return nil, fmt.Errorf("ошибка включения 2FA: %w", err)
+	}
+
+	return codes, nil
+}
+
+// Disable отключает 2FA после проверки кода TOTP или кода восстановления
+func (s *TwoFactorService) Disable(ctx context.Context, userID int64, code string) error {
+	if err := s.Verify(ctx, userID, code); err != nil {
+		return err
+	}
+
+	if err := s.twoFactorRepo.Disable(ctx, userID); err != nil {
+		return fmt.Errorf("ошибка отключения 2FA: %w", err)
+	}
+	return nil
+}
+
+// RegenerateRecoveryCodes выпускает новый набор кодов восстановления взамен старого
+func (s *TwoFactorService) RegenerateRecoveryCodes(ctx context.Context, userID int64, code string) ([]string, error) {
+	tf, err := s.getEnabled(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.checkCode(ctx, userID, func() error {
+		return s.verifyTOTP(ctx, userID, tf.Secret, code)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.twoFactorRepo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, fmt.Errorf("ошибка сохранения кодов восстановления: %w", err)
+	}
+
+	return codes, nil
+}
+
+// Verify проверяет второй фактор: код TOTP или одноразовый код восстановления
+func (s *TwoFactorService) Verify(ctx context.Context, userID int64, code string) error {
+	tf, err := s.getEnabled(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	return s.checkCode(ctx, userID, func() error {
+		code := strings.TrimSpace(code)
+		if len(code) == totpDigits {
+			return s.verifyTOTP(ctx, userID, tf.Secret, code)
+		}
+
+		used, err := s.twoFactorRepo.UseRecoveryCode(ctx, userID, hashToken(normalizeRecoveryCode(code)))
+		if err != nil {
+			return fmt.Errorf("ошибка проверки кода восстановления: %w", err)
+		}
+		if !used {
+			return ErrInvalidTOTPCode
+		}
+		return nil
+	})
+}
+
+// RequireStepUp проверяет свежий код TOTP для операции на сумму выше порога
+// TOTP_STEP_UP_AMOUNT. Требование действует только для пользователей с включенной 2FA;
+// коды восстановления для подтверждения операций не принимаются.
+func (s *TwoFactorService) RequireStepUp(ctx context.Context, userID int64, amount decimal.Decimal, code string) error {
+	if !s.cfg.StepUpAmount.Valid || amount.LessThanOrEqual(s.cfg.StepUpAmount.Decimal) {
+		return nil
+	}
+
+	tf, err := s.twoFactorRepo.GetTwoFactor(ctx, userID, s.pgpKey)
+	if err != nil {
+		return err
+	}
+	if !tf.Enabled {
+		return nil
+	}
+
+	if strings.TrimSpace(code) == "" {
+		return ErrTOTPRequired
+	}
+
+	return s.checkCode(ctx, userID, func() error {
+		return s.verifyTOTP(ctx, userID, tf.Secret, code)
+	})
+}
+
+// getEnabled получает настройки TOTP пользователя, у которого включена 2FA
+func (s *TwoFactorService) getEnabled(ctx context.Context, userID int64) (*models.TwoFactor, error) {
+	tf, err := s.twoFactorRepo.GetTwoFactor(ctx, userID, s.pgpKey)
+	if err != nil {
+		return nil, err
+	}
+	if !tf.Enabled {
+		return nil, ErrTwoFactorNotEnabled
+	}
+	return tf, nil
+}
+
+// checkCode выполняет проверку кода под защитой от перебора. Пока счетчик
+// неверных кодов пользователя (или IP) заблокирован, код не проверяется вовсе
+// и возвращается LoginLockedError — даже верный код не снимает блокировку.
+// Неверный код увеличивает счетчик, верный сбрасывает его.
+func (s *TwoFactorService) checkCode(ctx context.Context, userID int64, verify func() error) error {
+	ip := requestmeta.FromContext(ctx).IP
+	if err := s.guard.CheckTwoFactor(ctx, userID, ip); err != nil {
+		return err
+	}
+
+	if err := verify(); err != nil {
+		if errors.Is(err, ErrInvalidTOTPCode) {
+			if err := s.guard.TwoFactorFailed(ctx, userID, ip); err != nil {
+				return err
+			}
+		}
+		return err
+	}
+
+	return s.guard.TwoFactorSucceeded(ctx, userID)
+}
+
+// verifyTOTP проверяет код и запоминает его временной шаг,
+// чтобы один и тот же код нельзя было использовать повторно
+func (s *TwoFactorService) verifyTOTP(ctx context.Context, userID int64, secret, code string) error {
+	step, ok := validateTOTP(secret, code, time.Now())
+	if !ok {
+		return ErrInvalidTOTPCode
+	}
+
+	fresh, err := s.twoFactorRepo.UseStep(ctx, userID, step)
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения шага TOTP: %w", err)
+	}
+	if !fresh {
+		return ErrInvalidTOTPCode
+	}
+
+	return nil
+}
+
+// generateRecoveryCodes генерирует коды восстановления вида xxxxx-xxxxx и их хеши
+func generateRecoveryCodes() (codes, hashes []string, err error) {
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+
+		raw := strings.ToLower(totpEncoding.EncodeToString(b))[:10]
+		codes = append(codes, raw[:5]+"-"+raw[5:])
+		hashes = append(hashes, hashToken(raw))
+	}
+	return codes, hashes, nil
+}
+
+// normalizeRecoveryCode приводит введенный код восстановления к виду, в котором хешируется
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
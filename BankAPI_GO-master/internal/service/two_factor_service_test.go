@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/therealadik/bank-api/internal/config"
+	"github.com/therealadik/bank-api/internal/models"
+	"github.com/therealadik/bank-api/internal/repository"
+)
+
+// testLockoutThreshold число неверных кодов до блокировки; задержек до порога нет
+const testLockoutThreshold = 5
+
+// memoryTwoFactorRepository настройки 2FA в памяти: только методы, нужные проверке кодов
+type memoryTwoFactorRepository struct {
+	repository.TwoFactorRepository
+	tf            models.TwoFactor
+	recoveryCodes map[string]bool
+}
+
+func (r *memoryTwoFactorRepository) GetTwoFactor(_ context.Context, _ int64, _ string) (*models.TwoFactor, error) {
+	tf := r.tf
+	return &tf, nil
+}
+
+func (r *memoryTwoFactorRepository) UseStep(_ context.Context, _ int64, step int64) (bool, error) {
+	if r.tf.LastStep != nil && *r.tf.LastStep >= step {
+		return false, nil
+	}
+	r.tf.LastStep = &step
+	return true, nil
+}
+
+func (r *memoryTwoFactorRepository) UseRecoveryCode(_ context.Context, _ int64, codeHash string) (bool, error) {
+	if !r.recoveryCodes[codeHash] {
+		return false, nil
+	}
+	delete(r.recoveryCodes, codeHash)
+	return true, nil
+}
+
+func (r *memoryTwoFactorRepository) ReplaceRecoveryCodes(_ context.Context, _ int64, codeHashes []string) error {
+	r.recoveryCodes = make(map[string]bool)
+	for _, hash := range codeHashes {
+		r.recoveryCodes[hash] = true
+	}
+	return nil
+}
+
+// memoryLoginThrottleRepository счетчики попыток в памяти
+type memoryLoginThrottleRepository struct {
+	failures map[string]int
+	locked   map[string]time.Time
+}
+
+func (r *memoryLoginThrottleRepository) GetLockedUntil(_ context.Context, keys []string) (*time.Time, error) {
+	var latest *time.Time
+	for _, key := range keys {
+		until, ok := r.locked[key]
+		if ok && until.After(time.Now()) && (latest == nil || until.After(*latest)) {
+			latest = &until
+		}
+	}
+	return latest, nil
+}
+
+func (r *memoryLoginThrottleRepository) RegisterFailure(_ context.Context, key string, _ time.Duration) (int, error) {
+	r.failures[key]++
+	return r.failures[key], nil
+}
+
+func (r *memoryLoginThrottleRepository) Lock(_ context.Context, key string, until time.Time) error {
+	r.locked[key] = until
+	return nil
+}
+
+func (r *memoryLoginThrottleRepository) Reset(_ context.Context, key string) error {
+	delete(r.failures, key)
+	delete(r.locked, key)
+	return nil
+}
+
+// discardSecurityEventRepository журнал событий безопасности, который ничего не хранит
+type discardSecurityEventRepository struct{}
+
+func (discardSecurityEventRepository) Create(context.Context, *models.SecurityEvent) error {
+	return nil
+}
+
+// twoFactorTest сервис 2FA с включенной 2FA у тестового пользователя
+type twoFactorTest struct {
+	service  *TwoFactorService
+	tf       *memoryTwoFactorRepository
+	throttle *memoryLoginThrottleRepository
+	sender   *stubSender
+	secret   string
+}
+
+func newTwoFactorTest(t *testing.T) *twoFactorTest {
+	t.Helper()
+
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	tt := &twoFactorTest{
+		tf: &memoryTwoFactorRepository{
+			tf:            models.TwoFactor{UserID: testUserID, Secret: secret, Enabled: true},
+			recoveryCodes: map[string]bool{hashToken("aaaaabbbbb"): true},
+		},
+		throttle: &memoryLoginThrottleRepository{failures: make(map[string]int), locked: make(map[string]time.Time)},
+		sender:   &stubSender{},
+		secret:   secret,
+	}
+	users := &memoryUserRepository{users: map[int64]*models.User{
+		testUserID: {ID: testUserID, Email: testUserEmail, TwoFactorEnabled: true},
+	}}
+	guardCfg := config.LoginGuardConfig{
+		FreeAttempts:       testLockoutThreshold,
+		LockoutThreshold:   testLockoutThreshold,
+		IPLockoutThreshold: 100,
+		LockoutDuration:    15 * time.Minute,
+		FailureWindow:      time.Hour,
+	}
+	guard := NewLoginGuard(tt.throttle, discardSecurityEventRepository{}, users, NewEmailSecurityNotifier(tt.sender),
+		guardCfg, logger)
+	tfCfg := config.TwoFactorConfig{StepUpAmount: decimal.NewNullDecimal(decimal.NewFromInt(1000))}
+	tt.service = NewTwoFactorService(tt.tf, users, guard, tfCfg, "")
+	return tt
+}
+
+// validCode возвращает код TOTP для текущего временного шага
+func (tt *twoFactorTest) validCode(t *testing.T) string {
+	t.Helper()
+
+	key, err := totpEncoding.DecodeString(tt.secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return totpCode(key, time.Now().Unix()/totpPeriod)
+}
+
+// wrongCode возвращает код из шести цифр, не совпадающий с действующими
+func (tt *twoFactorTest) wrongCode(t *testing.T) string {
+	t.Helper()
+
+	for _, code := range []string{"000000", "111111", "222222", "333333"} {
+		if _, ok := validateTOTP(tt.secret, code, time.Now()); !ok {
+			return code
+		}
+	}
+	t.Fatal("не удалось подобрать неверный код")
+	return ""
+}
+
+// Все проверки кодов 2FA используют общий счетчик: после порога неверных кодов
+// даже верный код отклоняется с LoginLockedError
+func TestTwoFactorCodeLockout(t *testing.T) {
+	tests := []struct {
+		name  string
+		check func(ctx context.Context, s *TwoFactorService, code string) error
+	}{
+		{"verify", func(ctx context.Context, s *TwoFactorService, code string) error {
+			return s.Verify(ctx, testUserID, code)
+		}},
+		{"disable", func(ctx context.Context, s *TwoFactorService, code string) error {
+			return s.Disable(ctx, testUserID, code)
+		}},
+		{"recovery codes", func(ctx context.Context, s *TwoFactorService, code string) error {
+			_, err := s.RegenerateRecoveryCodes(ctx, testUserID, code)
+			return err
+		}},
+		{"step-up", func(ctx context.Context, s *TwoFactorService, code string) error {
+			return s.RequireStepUp(ctx, testUserID, decimal.NewFromInt(5000), code)
+		}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			tt := newTwoFactorTest(t)
+
+			for i := 0; i < testLockoutThreshold; i++ {
+				if err := tc.check(ctx, tt.service, tt.wrongCode(t)); !errors.Is(err, ErrInvalidTOTPCode) {
+					t.Fatalf("попытка %d: %v, ожидалась ErrInvalidTOTPCode", i+1, err)
+				}
+			}
+
+			err := tc.check(ctx, tt.service, tt.validCode(t))
+			var locked *LoginLockedError
+			if !errors.As(err, &locked) || locked.RetryAfter <= 0 {
+				t.Fatalf("верный код после блокировки: %v, ожидалась LoginLockedError", err)
+			}
+			if tt.tf.tf.LastStep != nil {
+				t.Fatal("код проверен несмотря на блокировку")
+			}
+			if len(tt.sender.sent()) != 1 {
+				t.Fatal("владелец не получил уведомление о блокировке")
+			}
+		})
+	}
+}
+
+// Верный код до порога сбрасывает счетчик неверных кодов
+func TestTwoFactorCodeResetsFailures(t *testing.T) {
+	ctx := context.Background()
+	tt := newTwoFactorTest(t)
+
+	for i := 0; i < testLockoutThreshold-1; i++ {
+		if err := tt.service.Verify(ctx, testUserID, tt.wrongCode(t)); !errors.Is(err, ErrInvalidTOTPCode) {
+			t.Fatalf("попытка %d: %v, ожидалась ErrInvalidTOTPCode", i+1, err)
+		}
+	}
+	if err := tt.service.Verify(ctx, testUserID, "aaaaa-bbbbb"); err != nil {
+		t.Fatalf("код восстановления отклонен: %v", err)
+	}
+	if len(tt.throttle.failures) != 0 {
+		t.Fatalf("счетчик не сброшен: %v", tt.throttle.failures)
+	}
+
+	// Использованный код восстановления считается неверным
+	if err := tt.service.Verify(ctx, testUserID, "aaaaa-bbbbb"); !errors.Is(err, ErrInvalidTOTPCode) {
+		t.Fatalf("повторный код восстановления: %v, ожидалась ErrInvalidTOTPCode", err)
+	}
+}
+
+// Запрос без кода для операции выше порога не расходует попытки
+func TestTwoFactorStepUpWithoutCode(t *testing.T) {
+	ctx := context.Background()
+	tt := newTwoFactorTest(t)
+
+	if err := tt.service.RequireStepUp(ctx, testUserID, decimal.NewFromInt(5000), ""); !errors.Is(err, ErrTOTPRequired) {
+		t.Fatalf("операция без кода: %v, ожидалась ErrTOTPRequired", err)
+	}
+	if err := tt.service.RequireStepUp(ctx, testUserID, decimal.NewFromInt(500), ""); err != nil {
+		t.Fatalf("операция ниже порога: %v", err)
+	}
+	if len(tt.throttle.failures) != 0 {
+		t.Fatalf("попытки учтены без проверки кода: %v", tt.throttle.failures)
+	}
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/mail"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/therealadik/bank-api/internal/models"
+	"github.com/therealadik/bank-api/internal/repository"
+)
+
+// Ограничения на данные пользователя
+const (
+	maxEmailLength    = 320
+	minPasswordLength = 8
+	maxPasswordLength = 72 // bcrypt учитывает только первые 72 байта
+)
+
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]{3,32}$`)
+
+var (
+	ErrInvalidEmail    = errors.New("неверный формат email")
+	ErrInvalidUsername = errors.New("имя пользователя должно содержать от 3 до 32 латинских букв, цифр или символов _ . -")
+	ErrWeakPassword    = errors.New("пароль должен содержать от 8 до 72 символов, включая буквы и цифры")
+	ErrEmailTaken      = errors.New("email уже занят")
+	ErrUsernameTaken   = errors.New("имя пользователя уже занято")
+)
+
+// UserService сервис профиля пользователя
+type UserService struct {
+	userRepo repository.UserRepository
+	verifier *EmailVerificationService
+}
+
+// NewUserService создает сервис профиля пользователя
+func NewUserService(userRepo repository.UserRepository, verifier *EmailVerificationService) *UserService {
+	return &UserService{
+		userRepo: userRepo,
+		verifier: verifier,
+	}
+}
+
+// GetProfile получает профиль текущего пользователя
+func (s *UserService) GetProfile(ctx context.Context, userID int64) (*models.User, error) {
+	return s.userRepo.GetByID(ctx, userID)
+}
+
+// UpdateProfile изменяет имя пользователя и/или email; nil означает "не менять".
+// Новый email требует повторного подтверждения.
+func (s *UserService) UpdateProfile(ctx context.Context, userID int64, username, email *string) (*models.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if username != nil {
+		if user.Username, err = normalizeUsername(*username); err != nil {
+			return nil, err
+		}
+	}
+
+	if email != nil {
+		normalized, err := normalizeEmail(*email)
+		if err != nil {
+			return nil, err
+		}
+
+		if !strings.EqualFold(normalized, user.Email) {
+			if err := checkEmailAvailable(ctx, s.userRepo, normalized); err != nil {
+				return nil, err
+			}
+		}
+		user.Email = normalized
+	}
+
+	if err := s.userRepo.UpdateProfile(ctx, user); err != nil {
+		return nil, mapUserConflict(err)
+	}
+
+	if !user.IsEmailVerified() {
+		s.verifier.SendVerification(ctx, user.ID)
+	}
+
+	return user, nil
+}
+
+// normalizeEmail проверяет формат email и приводит его к нижнему регистру
+func normalizeEmail(email string) (string, error) {
+	email = strings.TrimSpace(email)
+	if email == "" || len(email) > maxEmailLength {
+		return "", ErrInvalidEmail
+	}
+
+	// Допускается только голый адрес, без имени и угловых скобок
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email || !strings.Contains(email[strings.LastIndex(email, "@"):], ".") {
+		return "", ErrInvalidEmail
+	}
+
+	return strings.ToLower(email), nil
+}
+
+// normalizeUsername проверяет имя пользователя
+func normalizeUsername(username string) (string, error) {
+	username = strings.TrimSpace(username)
+	if !usernamePattern.MatchString(username) {
+		return "", ErrInvalidUsername
+	}
+	return username, nil
+}
+
+// validatePassword проверяет сложность пароля
+func validatePassword(password string) error {
+	if len([]rune(password)) < minPasswordLength || len(password) > maxPasswordLength {
+		return ErrWeakPassword
+	}
+
+	var hasLetter, hasDigit bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		}
+	}
+
+	if !hasLetter || !hasDigit {
+		return ErrWeakPassword
+	}
+	return nil
+}
+
+// checkEmailAvailable проверяет, что email не занят с точностью до регистра
+// (уникальный индекс БД учитывает регистр у ранее созданных пользователей)
+func checkEmailAvailable(ctx context.Context, userRepo repository.UserRepository, email string) error {
+	_, err := userRepo.GetByEmail(ctx, email)
+	switch {
+	case err == nil:
+		return ErrEmailTaken
+	case errors.Is(err, repository.ErrUserNotFound):
+		return nil
+	default:
+		return err
+	}
+}
+
+// mapUserConflict преобразует нарушение уникальности email или имени в ошибку сервиса
+func mapUserConflict(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != pgUniqueViolation {
+		return err
+	}
+
+	switch pgErr.ConstraintName {
+	case repository.UsersEmailKey:
+		return ErrEmailTaken
+	case repository.UsersUsernameKey:
+		return ErrUsernameTaken
+	}
+	return err
+}
@@ -0,0 +1,662 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/therealadik/bank-api/internal/config"
+	"github.com/therealadik/bank-api/internal/models"
+	"github.com/therealadik/bank-api/internal/models/payment"
+	"github.com/therealadik/bank-api/internal/models/transaction"
+	"github.com/therealadik/bank-api/internal/models/webhook"
+	"github.com/therealadik/bank-api/internal/repository"
+)
+
+var (
+	ErrWebhookNotFound         = newNotFoundError("вебхук не найден")
+	ErrWebhookDeliveryNotFound = newNotFoundError("доставка вебхука не найдена")
+	ErrInvalidWebhookURL       = errors.New("неверный адрес вебхука")
+	ErrInvalidWebhookEvent     = errors.New("неизвестный тип события")
+	ErrWebhookLimitExceeded    = errors.New("превышено число вебхуков пользователя")
+	ErrWebhookDeliveryPending  = errors.New("доставка вебхука еще выполняется")
+)
+
+// Заголовки запроса с событием
+const (
+	WebhookEventIDHeader   = "X-Webhook-ID"
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+const (
+	webhookSignaturePrefix = "sha256="
+	webhookSecretPrefix    = "whsec_"
+	webhookUserAgent       = "BankAPI-Webhooks/1.0"
+	webhookMaxURLLength    = 2048
+	// webhookResponseReadSize сколько байт ответа получателя читается перед закрытием соединения
+	webhookResponseReadSize = 4 << 10
+)
+
+const (
+	// webhooksPerUserLimit максимальное число вебхуков пользователя
+	webhooksPerUserLimit = 10
+	// webhookDeliveriesLimit количество доставок в ответе списка
+	webhookDeliveriesLimit = 100
+)
+
+// WebhookService управляет вебхуками пользователей и доставляет события на их адреса.
+// События ставятся в очередь в транзакции операции, которая их вызвала, поэтому о
+// неудавшейся операции событие не отправляется, а о зафиксированной — не теряется.
+// Тело запроса подписывается HMAC-SHA256 с секретом вебхука; неудачные доставки
+// повторяются с экспоненциально растущей задержкой.
+type WebhookService struct {
+	webhookRepo *repository.WebhookRepository
+	audit       *AuditService
+	db          *pgxpool.Pool
+	client      *http.Client
+	pgpKey      string
+	cfg         config.WebhookConfig
+}
+
+func NewWebhookService(webhookRepo *repository.WebhookRepository, audit *AuditService, db *pgxpool.Pool,
+	pgpKey string, cfg config.WebhookConfig) *WebhookService {
+	return &WebhookService{
+		webhookRepo: webhookRepo,
+		audit:       audit,
+		db:          db,
+		client:      newWebhookClient(cfg),
+		pgpKey:      pgpKey,
+		cfg:         cfg,
+	}
+}
+
+// newWebhookClient создает HTTP-клиент доставки. Перенаправления не выполняются, а соединения
+// с локальными и частными адресами запрещаются при подключении (после разрешения имени),
+// если это не разрешено настройкой AllowPrivateNetworks.
+func newWebhookClient(cfg config.WebhookConfig) *http.Client {
+	dialer := &net.Dialer{Timeout: cfg.Timeout}
+	if !cfg.AllowPrivateNetworks {
+		dialer.Control = func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+				return fmt.Errorf("адрес %s недоступен для вебхуков", host)
+			}
+			return nil
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   cfg.Timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// sharedAddressSpace адреса операторского NAT (100.64.0.0/10, RFC 6598): не маршрутизируются
+// в интернете и могут вести во внутреннюю сеть провайдера или облака
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// isPublicIP сообщает, что адрес не относится к локальным, частным и служебным сетям
+func isPublicIP(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsUnspecified() && !ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() && !ip.IsInterfaceLocalMulticast() && !ip.IsMulticast() &&
+		!sharedAddressSpace.Contains(ip)
+}
+
+// Create регистрирует вебхук пользователя. Секрет подписи возвращается в поле Secret
+// только здесь: повторно получить его нельзя.
+func (s *WebhookService) Create(ctx context.Context, userID int64, rawURL string,
+	events []webhook.Event) (*webhook.Webhook, error) {
+	if err := s.validateURL(rawURL); err != nil {
+		return nil, err
+	}
+
+	events, err := normalizeWebhookEvents(events)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := s.webhookRepo.CountByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= webhooksPerUserLimit {
+		return nil, ErrWebhookLimitExceeded
+	}
+
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	created, err := s.webhookRepo.WithTx(tx).Create(ctx, &webhook.Webhook{
+		UserID: userID,
+		URL:    rawURL,
+		Secret: secret,
+		Events: events,
+	}, s.pgpKey)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.audit.RecordTx(ctx, tx, AuditEvent{
+		ActorID:    &userID,
+		Action:     models.AUDIT_WEBHOOK_CREATED,
+		TargetType: models.AuditTargetWebhook,
+		TargetID:   &created.ID,
+		Details:    fmt.Sprintf("url=%s events=%s", created.URL, joinWebhookEvents(created.Events)),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	created.Secret = secret
+	return created, nil
+}
+
+// GetByUserID получает вебхуки пользователя
+func (s *WebhookService) GetByUserID(ctx context.Context, userID int64) ([]*webhook.Webhook, error) {
+	return s.webhookRepo.GetByUserID(ctx, userID)
+}
+
+// GetByID получает вебхук пользователя
+func (s *WebhookService) GetByID(ctx context.Context, userID, id int64) (*webhook.Webhook, error) {
+	wh, err := s.webhookRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, err
+	}
+	if wh.UserID != userID {
+		return nil, ErrWebhookNotFound
+	}
+	return wh, nil
+}
+
+// Delete удаляет вебхук пользователя; недоставленные события отменяются
+func (s *WebhookService) Delete(ctx context.Context, userID, id int64) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := s.webhookRepo.WithTx(tx).Delete(ctx, id, userID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrWebhookNotFound
+		}
+		return err
+	}
+
+	err = s.audit.RecordTx(ctx, tx, AuditEvent{
+		ActorID:    &userID,
+		Action:     models.AUDIT_WEBHOOK_DELETED,
+		TargetType: models.AuditTargetWebhook,
+		TargetID:   &id,
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetDeliveries получает последние доставки вебхука пользователя
+func (s *WebhookService) GetDeliveries(ctx context.Context, userID, webhookID int64) ([]*webhook.Delivery, error) {
+	if _, err := s.GetByID(ctx, userID, webhookID); err != nil {
+		return nil, err
+	}
+	return s.webhookRepo.GetDeliveries(ctx, webhookID, webhookDeliveriesLimit)
+}
+
+// GetDelivery получает доставку вебхука пользователя и журнал ее попыток
+func (s *WebhookService) GetDelivery(ctx context.Context, userID, webhookID,
+	deliveryID int64) (*webhook.Delivery, []*webhook.Attempt, error) {
+	d, err := s.getDelivery(ctx, userID, webhookID, deliveryID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	attempts, err := s.webhookRepo.GetAttempts(ctx, d.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return d, attempts, nil
+}
+
+// Redeliver ставит событие доставки в очередь повторно: создается новая доставка
+// с тем же идентификатором события и тем же телом запроса
+func (s *WebhookService) Redeliver(ctx context.Context, userID, webhookID, deliveryID int64) (*webhook.Delivery, error) {
+	d, err := s.getDelivery(ctx, userID, webhookID, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if d.Status == webhook.PENDING {
+		return nil, ErrWebhookDeliveryPending
+	}
+
+	return s.webhookRepo.Redeliver(ctx, d.ID)
+}
+
+// getDelivery получает доставку и проверяет, что она относится к вебхуку пользователя
+func (s *WebhookService) getDelivery(ctx context.Context, userID, webhookID, deliveryID int64) (*webhook.Delivery, error) {
+	if _, err := s.GetByID(ctx, userID, webhookID); err != nil {
+		return nil, err
+	}
+
+	d, err := s.webhookRepo.GetDeliveryByID(ctx, deliveryID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrWebhookDeliveryNotFound
+		}
+		return nil, err
+	}
+	if d.WebhookID != webhookID {
+		return nil, ErrWebhookDeliveryNotFound
+	}
+	return d, nil
+}
+
+// webhookEnvelope тело запроса с событием
+type webhookEnvelope struct {
+	ID        string        `json:"id"`
+	Type      webhook.Event `json:"type"`
+	CreatedAt string        `json:"created_at"`
+	Data      any           `json:"data"`
+}
+
+// webhookTransaction данные события transaction.completed
+type webhookTransaction struct {
+	ID         int64              `json:"id"`
+	AccountID  int64              `json:"account_id"`
+	Amount     decimal.Decimal    `json:"amount"`
+	Type       transaction.Type   `json:"type"`
+	Status     transaction.Status `json:"status"`
+	OriginalID *int64             `json:"original_transaction_id,omitempty"`
+	CreatedAt  string             `json:"created_at"`
+}
+
+// webhookCardPayment данные события card.payment. ID платежа передается строкой, как в API.
+type webhookCardPayment struct {
+	PaymentID        string          `json:"payment_id"`
+	CardID           int64           `json:"card_id"`
+	AccountID        int64           `json:"account_id"`
+	TransactionID    *int64          `json:"transaction_id,omitempty"`
+	Amount           decimal.Decimal `json:"amount"`
+	RefundedAmount   decimal.Decimal `json:"refunded_amount"`
+	MerchantCategory string          `json:"merchant_category,omitempty"`
+	Status           payment.Status  `json:"status"`
+	ExpiresAt        *string         `json:"expires_at,omitempty"`
+	CreatedAt        string          `json:"created_at"`
+	UpdatedAt        string          `json:"updated_at"`
+}
+
+// webhookCreditOverdue данные события credit.overdue
+type webhookCreditOverdue struct {
+	CreditID   int64           `json:"credit_id"`
+	AccountID  int64           `json:"account_id"`
+	ScheduleID int64           `json:"schedule_id"`
+	DueDate    string          `json:"due_date"`
+	Amount     decimal.Decimal `json:"amount"`
+}
+
+// TransactionCompletedTx ставит в очередь событие transaction.completed для владельца счета транзакции
+func (s *WebhookService) TransactionCompletedTx(ctx context.Context, tx pgx.Tx, t *transaction.Transaction) error {
+	return s.PublishTx(ctx, tx, t.AccountID, webhook.TRANSACTION_COMPLETED, webhookTransaction{
+		ID:         t.ID,
+		AccountID:  t.AccountID,
+		Amount:     t.Amount,
+		Type:       t.Type,
+		Status:     t.Status,
+		OriginalID: t.OriginalID,
+		CreatedAt:  t.CreatedAt.Format("2006-01-02T15:04:05Z"),
+	})
+}
+
+// CardPaymentTx ставит в очередь событие card.payment с текущим состоянием платежа
+// для владельца счета карты
+func (s *WebhookService) CardPaymentTx(ctx context.Context, tx pgx.Tx, p *payment.CardPayment) error {
+	if p.AccountID == nil {
+		return nil
+	}
+
+	data := webhookCardPayment{
+		PaymentID:        strconv.FormatInt(p.ID, 10),
+		CardID:           p.CardID,
+		AccountID:        *p.AccountID,
+		TransactionID:    p.TransactionID,
+		Amount:           p.Amount,
+		RefundedAmount:   p.RefundedAmount,
+		MerchantCategory: p.MerchantCategory,
+		Status:           p.Status,
+		CreatedAt:        p.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		UpdatedAt:        p.UpdatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+	if p.ExpiresAt != nil {
+		expiresAt := p.ExpiresAt.Format("2006-01-02T15:04:05Z")
+		data.ExpiresAt = &expiresAt
+	}
+	return s.PublishTx(ctx, tx, *p.AccountID, webhook.CARD_PAYMENT, data)
+}
+
+// CreditOverdueTx ставит в очередь событие credit.overdue о просроченном платеже
+// для владельца счета кредита
+func (s *WebhookService) CreditOverdueTx(ctx context.Context, tx pgx.Tx, inst *models.OverdueInstallment) error {
+	return s.PublishTx(ctx, tx, inst.AccountID, webhook.CREDIT_OVERDUE, webhookCreditOverdue{
+		CreditID:   inst.CreditID,
+		AccountID:  inst.AccountID,
+		ScheduleID: inst.ScheduleID,
+		DueDate:    inst.DueDate.Format("2006-01-02"),
+		Amount:     inst.Amount,
+	})
+}
+
+// PublishTx ставит событие в очередь доставки всех вебхуков владельца счета, подписанных на него.
+// Выполняется в транзакции вызывающего: событие будет доставлено, только если она зафиксирована.
+func (s *WebhookService) PublishTx(ctx context.Context, tx pgx.Tx, accountID int64, event webhook.Event,
+	data any) error {
+	eventID, err := generateWebhookEventID()
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(webhookEnvelope{
+		ID:        eventID,
+		Type:      event,
+		CreatedAt: time.Now().UTC().Format("2006-01-02T15:04:05Z"),
+		Data:      data,
+	})
+	if err != nil {
+		return fmt.Errorf("ошибка кодирования события %s: %w", event, err)
+	}
+
+	if _, err := s.webhookRepo.WithTx(tx).EnqueueForAccount(ctx, accountID, event, eventID, string(payload)); err != nil {
+		return fmt.Errorf("ошибка постановки события %s в очередь: %w", event, err)
+	}
+	return nil
+}
+
+// DeliverDue выполняет доставки, время которых наступило (не больше BatchSize за вызов), параллельно.
+// Возвращает число выполненных попыток.
+func (s *WebhookService) DeliverDue(ctx context.Context, logger *logrus.Logger) (int, error) {
+	now := time.Now()
+	// Все доставки выборки отправляются одновременно, каждая не дольше таймаута
+	leaseUntil := now.Add(2*s.cfg.Timeout + time.Minute)
+
+	deliveries, err := s.webhookRepo.ClaimDue(ctx, now, leaseUntil, s.cfg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var wg sync.WaitGroup
+	for _, d := range deliveries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.deliver(ctx, d); err != nil {
+				logger.Errorf("Ошибка доставки вебхука (доставка %d): %v", d.ID, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return len(deliveries), nil
+}
+
+// RunDelivery периодически доставляет события до отмены контекста
+func (s *WebhookService) RunDelivery(ctx context.Context, logger *logrus.Logger) {
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			delivered, err := s.DeliverDue(ctx, logger)
+			if err != nil {
+				logger.Errorf("Ошибка доставки вебхуков: %v", err)
+			}
+			if delivered > 0 {
+				logger.Infof("Выполнено попыток доставки вебхуков: %d", delivered)
+			}
+		}
+	}
+}
+
+// deliver выполняет одну попытку доставки и сохраняет ее результат вместе с новым состоянием доставки
+func (s *WebhookService) deliver(ctx context.Context, d *webhook.Delivery) error {
+	wh, err := s.webhookRepo.GetByIDWithSecret(ctx, d.WebhookID, s.pgpKey)
+	if err != nil {
+		// Вебхук удален вместе с доставкой
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("ошибка получения вебхука: %w", err)
+	}
+
+	attempt := s.attempt(ctx, wh, d)
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	webhookRepo := s.webhookRepo.WithTx(tx)
+	if err := webhookRepo.CreateAttempt(ctx, attempt); err != nil {
+		return fmt.Errorf("ошибка записи попытки доставки: %w", err)
+	}
+	if err := webhookRepo.UpdateDelivery(ctx, d); err != nil {
+		return fmt.Errorf("ошибка обновления доставки: %w", err)
+	}
+	return tx.Commit(ctx)
+}
+
+// attempt отправляет событие и переводит доставку в следующее состояние: успешна,
+// неудачна после MaxAttempts попыток или ждет повтора с задержкой retryDelay.
+// Возвращает результат попытки для журнала.
+func (s *WebhookService) attempt(ctx context.Context, wh *webhook.Webhook, d *webhook.Delivery) *webhook.Attempt {
+	started := time.Now()
+	statusCode, sendErr := s.send(ctx, wh, d)
+	finished := time.Now()
+
+	attempt := &webhook.Attempt{
+		DeliveryID: d.ID,
+		Attempt:    d.Attempts + 1,
+		DurationMs: int(finished.Sub(started).Milliseconds()),
+	}
+	if statusCode != 0 {
+		attempt.StatusCode = &statusCode
+	}
+	if sendErr != nil {
+		msg := sendErr.Error()
+		attempt.Error = &msg
+	}
+
+	d.Attempts = attempt.Attempt
+	d.LastStatusCode = attempt.StatusCode
+	d.LastError = attempt.Error
+	switch {
+	case sendErr == nil:
+		d.Status = webhook.SUCCEEDED
+		d.DeliveredAt = &finished
+	case d.Attempts >= s.cfg.MaxAttempts:
+		d.Status = webhook.FAILED
+	default:
+		d.NextAttemptAt = finished.Add(s.retryDelay(d.Attempts))
+	}
+	return attempt
+}
+
+// send отправляет событие на адрес вебхука. Доставка успешна, если получатель ответил кодом 2xx;
+// код ответа возвращается и при неудаче (0 — ответа не было).
+func (s *WebhookService) send(ctx context.Context, wh *webhook.Webhook, d *webhook.Delivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, wh.URL, strings.NewReader(d.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("ошибка создания запроса: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", webhookUserAgent)
+	req.Header.Set(WebhookEventIDHeader, d.EventID)
+	req.Header.Set(WebhookEventHeader, string(d.EventType))
+	req.Header.Set(WebhookDeliveryHeader, strconv.FormatInt(d.ID, 10))
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, webhookSignaturePrefix+SignWebhookPayload(wh.Secret, timestamp, d.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, webhookResponseReadSize))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("получатель ответил кодом %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// retryDelay задержка перед повтором после attempt неудачных попыток:
+// RetryBaseDelay, затем удваивается, но не больше RetryMaxDelay
+func (s *WebhookService) retryDelay(attempt int) time.Duration {
+	delay := s.cfg.RetryBaseDelay
+	for i := 1; i < attempt && delay < s.cfg.RetryMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, s.cfg.RetryMaxDelay)
+}
+
+// SignWebhookPayload вычисляет подпись события: HMAC-SHA256 от строки "<timestamp>.<тело запроса>"
+// с секретом вебхука в шестнадцатеричном виде. Получатель проверяет подпись тем же способом
+// и отклоняет события со старой меткой времени, чтобы их нельзя было воспроизвести повторно.
+func SignWebhookPayload(secret, timestamp, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "." + payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// validateURL проверяет адрес вебхука: абсолютный https-адрес без учетных данных.
+// Адрес, заданный IP, должен быть публичным; имена проверяются при подключении.
+func (s *WebhookService) validateURL(rawURL string) error {
+	if len(rawURL) > webhookMaxURLLength {
+		return ErrInvalidWebhookURL
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" || u.User != nil || u.Fragment != "" {
+		return ErrInvalidWebhookURL
+	}
+
+	switch u.Scheme {
+	case "https":
+	case "http":
+		if !s.cfg.AllowPrivateNetworks {
+			return ErrInvalidWebhookURL
+		}
+	default:
+		return ErrInvalidWebhookURL
+	}
+
+	if s.cfg.AllowPrivateNetworks {
+		return nil
+	}
+	host := u.Hostname()
+	if strings.EqualFold(host, "localhost") || strings.HasSuffix(strings.ToLower(host), ".localhost") {
+		return ErrInvalidWebhookURL
+	}
+	if ip := net.ParseIP(host); ip != nil && !isPublicIP(ip) {
+		return ErrInvalidWebhookURL
+	}
+	return nil
+}
+
+// normalizeWebhookEvents проверяет типы событий и удаляет повторы
+func normalizeWebhookEvents(events []webhook.Event) ([]webhook.Event, error) {
+	if len(events) == 0 {
+		return nil, ErrInvalidWebhookEvent
+	}
+
+	seen := make(map[webhook.Event]bool, len(events))
+	unique := make([]webhook.Event, 0, len(events))
+	for _, e := range events {
+		if !e.IsValid() {
+			return nil, ErrInvalidWebhookEvent
+		}
+		if !seen[e] {
+			seen[e] = true
+			unique = append(unique, e)
+		}
+	}
+	return unique, nil
+}
+
+// joinWebhookEvents перечисляет типы событий через запятую
+func joinWebhookEvents(events []webhook.Event) string {
+	names := make([]string, len(events))
+	for i, e := range events {
+		names[i] = string(e)
+	}
+	return strings.Join(names, ",")
+}
+
+// generateWebhookSecret генерирует секрет подписи вебхука
+func generateWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("ошибка генерации секрета вебхука: %w", err)
+	}
+	return webhookSecretPrefix + hex.EncodeToString(b), nil
+}
+
+// generateWebhookEventID генерирует идентификатор события
+func generateWebhookEventID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("ошибка генерации идентификатора события: %w", err)
+	}
+	return "evt_" + hex.EncodeToString(b), nil
+}
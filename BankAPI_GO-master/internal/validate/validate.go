@@ -0,0 +1,276 @@
+// Package validate проверяет DTO запросов по тегам binding и возвращает
+// все ошибки полей сразу.
+//
+// Поддерживаемые правила (через запятую, проверяются по порядку, для поля
+// сообщается первое нарушенное):
+//
+//	required  — значение задано: непустая строка, ненулевое число, не nil
+//	omitempty — остальные правила проверяются, только если значение задано
+//	email     — адрес электронной почты
+//	min=N     — для строк и списков минимальная длина, для чисел и сумм минимальное значение
+//	max=N     — для строк и списков максимальная длина, для чисел и сумм максимальное значение
+//	len=N     — точная длина строки
+//	oneof=A B — строка равна одному из значений
+//	digits    — строка состоит только из цифр
+//	amount    — положительная сумма не более чем с двумя знаками после запятой (NUMERIC(12,2))
+//	money     — сумма любого знака не более чем с двумя знаками после запятой
+//
+// Вложенные (встроенные) структуры проверяются так же, их поля считаются полями запроса.
+package validate
+
+import (
+	"fmt"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/shopspring/decimal"
+)
+
+// Tag имя тега с правилами проверки
+const Tag = "binding"
+
+// amountScale допустимое число знаков после запятой в суммах
+const amountScale = 2
+
+// maxAmount максимальная сумма, помещающаяся в NUMERIC(12,2)
+var maxAmount = decimal.RequireFromString("9999999999.99")
+
+// Rule нарушенное правило проверки
+type Rule string
+
+const (
+	REQUIRED      Rule = "required"
+	EMAIL         Rule = "email"
+	MIN_LENGTH    Rule = "min_length"
+	MAX_LENGTH    Rule = "max_length"
+	MIN           Rule = "min"
+	MAX           Rule = "max"
+	LENGTH        Rule = "length"
+	ONEOF         Rule = "oneof"
+	DIGITS        Rule = "digits"
+	AMOUNT        Rule = "amount"
+	MONEY         Rule = "money"
+	UNKNOWN_FIELD Rule = "unknown_field"
+	INVALID_TYPE  Rule = "invalid_type"
+)
+
+// FieldError ошибка проверки поля запроса. Field — имя поля в JSON,
+// Param — параметр правила (например, длина для min_length).
+type FieldError struct {
+	Field string
+	Rule  Rule
+	Param string
+}
+
+func (e FieldError) Error() string {
+	if e.Param == "" {
+		return fmt.Sprintf("поле %s: %s", e.Field, e.Rule)
+	}
+	return fmt.Sprintf("поле %s: %s=%s", e.Field, e.Rule, e.Param)
+}
+
+// Errors ошибки проверки всех полей запроса
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Error()
+	}
+	return "ошибка проверки запроса: " + strings.Join(msgs, "; ")
+}
+
+var (
+	decimalType     = reflect.TypeOf(decimal.Decimal{})
+	nullDecimalType = reflect.TypeOf(decimal.NullDecimal{})
+	digitsPattern   = regexp.MustCompile(`^[0-9]+$`)
+)
+
+// Struct проверяет структуру (или указатель на нее) по тегам binding.
+// Возвращает nil, если все поля корректны.
+func Struct(v any) error {
+	var errs Errors
+	checkStruct(reflect.Indirect(reflect.ValueOf(v)), &errs)
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// checkStruct проверяет поля структуры, включая поля встроенных структур
+func checkStruct(v reflect.Value, errs *Errors) {
+	if v.Kind() != reflect.Struct {
+		return
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			checkStruct(v.Field(i), errs)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		rules := field.Tag.Get(Tag)
+		if rules == "" {
+			continue
+		}
+		if fe, ok := checkField(v.Field(i), rules); !ok {
+			fe.Field = FieldName(field)
+			*errs = append(*errs, fe)
+		}
+	}
+}
+
+// FieldName возвращает имя поля в JSON
+func FieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
+
+// checkField проверяет значение по правилам и возвращает первое нарушенное
+func checkField(v reflect.Value, rules string) (FieldError, bool) {
+	zero := isZero(v)
+	v = unwrap(v)
+
+	for _, rule := range strings.Split(rules, ",") {
+		name, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch name {
+		case "omitempty":
+			if zero {
+				return FieldError{}, true
+			}
+		case "required":
+			if zero {
+				return FieldError{Rule: REQUIRED}, false
+			}
+		case "email":
+			if !isEmail(v.String()) {
+				return FieldError{Rule: EMAIL}, false
+			}
+		case "min", "max":
+			if fe, ok := checkBound(v, name, param); !ok {
+				return fe, false
+			}
+		case "len":
+			n, _ := strconv.Atoi(param)
+			if utf8.RuneCountInString(v.String()) != n {
+				return FieldError{Rule: LENGTH, Param: param}, false
+			}
+		case "oneof":
+			if !slices.Contains(strings.Fields(param), v.String()) {
+				return FieldError{Rule: ONEOF, Param: strings.Join(strings.Fields(param), ", ")}, false
+			}
+		case "digits":
+			if !digitsPattern.MatchString(v.String()) {
+				return FieldError{Rule: DIGITS}, false
+			}
+		case "amount":
+			d := v.Interface().(decimal.Decimal)
+			if !d.IsPositive() || !hasScale(d) || d.GreaterThan(maxAmount) {
+				return FieldError{Rule: AMOUNT}, false
+			}
+		case "money":
+			d := v.Interface().(decimal.Decimal)
+			if !hasScale(d) || d.Abs().GreaterThan(maxAmount) {
+				return FieldError{Rule: MONEY}, false
+			}
+		default:
+			panic(fmt.Sprintf("validate: неизвестное правило %q", name))
+		}
+	}
+	return FieldError{}, true
+}
+
+// checkBound проверяет правила min и max: для строк и списков — длину, для чисел и сумм — значение
+func checkBound(v reflect.Value, name, param string) (FieldError, bool) {
+	var cmp int
+	lengthRule := false
+	switch {
+	case v.Type() == decimalType:
+		cmp = v.Interface().(decimal.Decimal).Cmp(decimal.RequireFromString(param))
+	case v.Kind() == reflect.String:
+		n, _ := strconv.Atoi(param)
+		cmp = compareInt(int64(utf8.RuneCountInString(v.String())), int64(n))
+		lengthRule = true
+	case v.Kind() == reflect.Slice:
+		n, _ := strconv.Atoi(param)
+		cmp = compareInt(int64(v.Len()), int64(n))
+		lengthRule = true
+	case v.CanInt():
+		n, _ := strconv.ParseInt(param, 10, 64)
+		cmp = compareInt(v.Int(), n)
+	default:
+		panic(fmt.Sprintf("validate: правило %s неприменимо к типу %s", name, v.Type()))
+	}
+
+	switch {
+	case name == "min" && cmp < 0 && lengthRule:
+		return FieldError{Rule: MIN_LENGTH, Param: param}, false
+	case name == "min" && cmp < 0:
+		return FieldError{Rule: MIN, Param: param}, false
+	case name == "max" && cmp > 0 && lengthRule:
+		return FieldError{Rule: MAX_LENGTH, Param: param}, false
+	case name == "max" && cmp > 0:
+		return FieldError{Rule: MAX, Param: param}, false
+	}
+	return FieldError{}, true
+}
+
+// isZero сообщает, что значение не задано
+func isZero(v reflect.Value) bool {
+	switch {
+	case v.Kind() == reflect.Pointer:
+		return v.IsNil()
+	case v.Type() == nullDecimalType:
+		return !v.Interface().(decimal.NullDecimal).Valid
+	case v.Type() == decimalType:
+		return v.Interface().(decimal.Decimal).IsZero()
+	case v.Kind() == reflect.Slice:
+		return v.Len() == 0
+	default:
+		return v.IsZero()
+	}
+}
+
+// unwrap возвращает значение под указателем или NullDecimal
+func unwrap(v reflect.Value) reflect.Value {
+	if v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Type() == nullDecimalType {
+		return reflect.ValueOf(v.Interface().(decimal.NullDecimal).Decimal)
+	}
+	return v
+}
+
+// hasScale сообщает, что у суммы не больше двух знаков после запятой
+func hasScale(d decimal.Decimal) bool {
+	return d.Equal(d.Truncate(amountScale))
+}
+
+// isEmail проверяет, что строка — один адрес без имени и угловых скобок
+func isEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Address == s
+}
+
+func compareInt(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
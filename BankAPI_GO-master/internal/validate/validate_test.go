@@ -0,0 +1,107 @@
+package validate
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+type recipient struct {
+	ToAccountID int64  `json:"to_account_id,omitempty" binding:"omitempty,min=1"`
+	ToEmail     string `json:"to_email,omitempty" binding:"omitempty,email"`
+}
+
+type request struct {
+	Name     string              `json:"name" binding:"required,min=3,max=5"`
+	Code     string              `json:"code,omitempty" binding:"omitempty,len=4,digits"`
+	Kind     string              `json:"kind,omitempty" binding:"omitempty,oneof=DAILY  WEEKLY"`
+	Count    int                 `json:"count,omitempty" binding:"omitempty,min=1,max=10"`
+	Tags     []string            `json:"tags,omitempty" binding:"omitempty,max=2"`
+	Amount   decimal.Decimal     `json:"amount" binding:"amount"`
+	Delta    decimal.Decimal     `json:"delta,omitempty" binding:"omitempty,money"`
+	Limit    decimal.NullDecimal `json:"limit" binding:"omitempty,min=0"`
+	Note     *string             `json:"note" binding:"omitempty,max=3"`
+	internal string              `binding:"required"`
+	recipient
+}
+
+// validRequest возвращает запрос, проходящий все правила
+func validRequest() request {
+	return request{Name: "ivan", Amount: decimal.RequireFromString("100.50")}
+}
+
+func TestStruct(t *testing.T) {
+	note := "long"
+
+	tests := []struct {
+		name   string
+		modify func(r *request)
+		want   Errors
+	}{
+		{"корректный запрос", func(r *request) {}, nil},
+		{"необязательные поля заданы", func(r *request) {
+			r.Code, r.Kind, r.Count, r.Tags = "0042", "WEEKLY", 10, []string{"a", "b"}
+			r.Delta = decimal.RequireFromString("-9999999999.99")
+			r.Limit = decimal.NewNullDecimal(decimal.Zero)
+			r.ToAccountID, r.ToEmail = 7, "user@example.com"
+		}, nil},
+		{"required", func(r *request) { r.Name = "" }, Errors{{Field: "name", Rule: REQUIRED}}},
+		{"min_length", func(r *request) { r.Name = "ив" }, Errors{{Field: "name", Rule: MIN_LENGTH, Param: "3"}}},
+		{"max_length в символах", func(r *request) { r.Name = "иванов" }, Errors{{Field: "name", Rule: MAX_LENGTH, Param: "5"}}},
+		{"max_length списка", func(r *request) { r.Tags = []string{"a", "b", "c"} }, Errors{{Field: "tags", Rule: MAX_LENGTH, Param: "2"}}},
+		{"len", func(r *request) { r.Code = "123" }, Errors{{Field: "code", Rule: LENGTH, Param: "4"}}},
+		{"digits", func(r *request) { r.Code = "12a4" }, Errors{{Field: "code", Rule: DIGITS}}},
+		{"oneof", func(r *request) { r.Kind = "daily" }, Errors{{Field: "kind", Rule: ONEOF, Param: "DAILY, WEEKLY"}}},
+		{"max числа", func(r *request) { r.Count = 11 }, Errors{{Field: "count", Rule: MAX, Param: "10"}}},
+		{"min суммы", func(r *request) { r.Limit = decimal.NewNullDecimal(decimal.NewFromInt(-1)) }, Errors{{Field: "limit", Rule: MIN, Param: "0"}}},
+		{"max по указателю", func(r *request) { r.Note = &note }, Errors{{Field: "note", Rule: MAX_LENGTH, Param: "3"}}},
+		{"amount ноль", func(r *request) { r.Amount = decimal.Zero }, Errors{{Field: "amount", Rule: AMOUNT}}},
+		{"amount отрицательная", func(r *request) { r.Amount = decimal.NewFromInt(-5) }, Errors{{Field: "amount", Rule: AMOUNT}}},
+		{"amount три знака", func(r *request) { r.Amount = decimal.RequireFromString("1.005") }, Errors{{Field: "amount", Rule: AMOUNT}}},
+		{"amount больше NUMERIC(12,2)", func(r *request) { r.Amount = decimal.RequireFromString("10000000000") }, Errors{{Field: "amount", Rule: AMOUNT}}},
+		{"money три знака", func(r *request) { r.Delta = decimal.RequireFromString("-0.001") }, Errors{{Field: "delta", Rule: MONEY}}},
+		{"money больше NUMERIC(12,2)", func(r *request) { r.Delta = decimal.RequireFromString("-10000000000") }, Errors{{Field: "delta", Rule: MONEY}}},
+		{"email встроенной структуры", func(r *request) { r.ToEmail = "Иван <user@example.com>" }, Errors{{Field: "to_email", Rule: EMAIL}}},
+		{"min встроенной структуры", func(r *request) { r.ToAccountID = -1 }, Errors{{Field: "to_account_id", Rule: MIN, Param: "1"}}},
+		{"все ошибки сразу", func(r *request) { r.Name, r.Code, r.ToEmail = "", "x", "user" }, Errors{
+			{Field: "name", Rule: REQUIRED},
+			{Field: "code", Rule: LENGTH, Param: "4"},
+			{Field: "to_email", Rule: EMAIL},
+		}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := validRequest()
+			tc.modify(&r)
+
+			err := Struct(&r)
+			if tc.want == nil {
+				if err != nil {
+					t.Fatalf("ожидался корректный запрос: %v", err)
+				}
+				return
+			}
+			var errs Errors
+			if !errors.As(err, &errs) {
+				t.Fatalf("ошибка %v, ожидались Errors", err)
+			}
+			if !reflect.DeepEqual(errs, tc.want) {
+				t.Fatalf("ошибки %+v, ожидались %+v", errs, tc.want)
+			}
+		})
+	}
+}
+
+func TestUnknownRulePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("неизвестное правило не вызвало панику")
+		}
+	}()
+	_ = Struct(struct {
+		Name string `binding:"required,uuid"`
+	}{Name: "x"})
+}
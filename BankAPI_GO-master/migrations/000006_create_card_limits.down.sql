@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_card_payments_card_id_created_at;
+DROP TABLE IF EXISTS card_payments;
+DROP TABLE IF EXISTS card_limits;
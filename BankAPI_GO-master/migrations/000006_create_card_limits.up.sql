@@ -0,0 +1,21 @@
+CREATE TABLE card_limits
+(
+    card_id            BIGINT PRIMARY KEY REFERENCES cards (id) ON DELETE CASCADE,
+    per_transaction    NUMERIC(12, 2),
+    daily              NUMERIC(12, 2),
+    monthly            NUMERIC(12, 2),
+    blocked_categories TEXT[]      NOT NULL DEFAULT '{}',
+    updated_at         TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE card_payments
+(
+    id                BIGINT PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    card_id           BIGINT         NOT NULL REFERENCES cards (id) ON DELETE CASCADE,
+    amount            NUMERIC(12, 2) NOT NULL,
+    merchant_category VARCHAR(4)     NOT NULL DEFAULT '',
+    status            VARCHAR(20)    NOT NULL,
+    created_at        TIMESTAMPTZ    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_card_payments_card_id_created_at ON card_payments (card_id, created_at);
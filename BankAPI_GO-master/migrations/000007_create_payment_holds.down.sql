@@ -0,0 +1,18 @@
+DROP INDEX IF EXISTS idx_card_payments_expires_at;
+
+UPDATE card_payments SET status = 'COMPLETED' WHERE status = 'CAPTURED';
+ALTER TABLE card_payments
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS expires_at,
+    DROP COLUMN IF EXISTS initiator_id,
+    DROP COLUMN IF EXISTS transaction_id,
+    DROP COLUMN IF EXISTS account_id;
+
+ALTER TABLE cards
+    DROP COLUMN IF EXISTS account_id;
+
+ALTER TABLE accounts
+    DROP COLUMN IF EXISTS available_balance;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS role;
@@ -0,0 +1,19 @@
+ALTER TABLE users
+    ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'CUSTOMER';
+
+ALTER TABLE accounts
+    ADD COLUMN available_balance NUMERIC(12, 2) NOT NULL DEFAULT 0.00;
+UPDATE accounts SET available_balance = balance;
+
+ALTER TABLE cards
+    ADD COLUMN account_id BIGINT REFERENCES accounts (id) ON DELETE SET NULL;
+
+ALTER TABLE card_payments
+    ADD COLUMN account_id     BIGINT REFERENCES accounts (id) ON DELETE CASCADE,
+    ADD COLUMN transaction_id BIGINT REFERENCES transactions (id) ON DELETE SET NULL,
+    ADD COLUMN initiator_id   BIGINT REFERENCES users (id) ON DELETE SET NULL,
+    ADD COLUMN expires_at     TIMESTAMPTZ,
+    ADD COLUMN updated_at     TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP;
+UPDATE card_payments SET status = 'CAPTURED' WHERE status = 'COMPLETED';
+
+CREATE INDEX idx_card_payments_expires_at ON card_payments (expires_at) WHERE status = 'AUTHORIZED';
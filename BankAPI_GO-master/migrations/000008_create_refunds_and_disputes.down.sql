@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_payment_disputes_active;
+DROP INDEX IF EXISTS idx_payment_disputes_status;
+DROP INDEX IF EXISTS idx_payment_disputes_payment_id;
+DROP TABLE IF EXISTS payment_disputes;
+
+ALTER TABLE card_payments
+    DROP COLUMN IF EXISTS refunded_amount;
+
+ALTER TABLE transactions
+    DROP COLUMN IF EXISTS original_transaction_id;
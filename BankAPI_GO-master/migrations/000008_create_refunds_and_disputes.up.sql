@@ -0,0 +1,26 @@
+ALTER TABLE transactions
+    ADD COLUMN original_transaction_id BIGINT REFERENCES transactions (id) ON DELETE SET NULL;
+
+ALTER TABLE card_payments
+    ADD COLUMN refunded_amount NUMERIC(12, 2) NOT NULL DEFAULT 0.00;
+
+CREATE TABLE payment_disputes
+(
+    id                    BIGINT PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    payment_id            BIGINT         NOT NULL REFERENCES card_payments (id) ON DELETE CASCADE,
+    opened_by             BIGINT         NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    amount                NUMERIC(12, 2) NOT NULL,
+    reason                TEXT           NOT NULL,
+    status                VARCHAR(20)    NOT NULL,
+    resolution_comment    TEXT           NOT NULL DEFAULT '',
+    resolved_by           BIGINT REFERENCES users (id) ON DELETE SET NULL,
+    refund_transaction_id BIGINT REFERENCES transactions (id) ON DELETE SET NULL,
+    created_at            TIMESTAMPTZ    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at            TIMESTAMPTZ    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_payment_disputes_payment_id ON payment_disputes (payment_id);
+CREATE INDEX idx_payment_disputes_status ON payment_disputes (status);
+-- По платежу может быть не более одного незакрытого спора
+CREATE UNIQUE INDEX idx_payment_disputes_active ON payment_disputes (payment_id)
+    WHERE status IN ('OPEN', 'UNDER_REVIEW');
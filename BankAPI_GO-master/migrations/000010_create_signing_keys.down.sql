@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_jwt_signing_keys_expires_at;
+DROP TABLE IF EXISTS jwt_signing_keys;
@@ -0,0 +1,10 @@
+CREATE TABLE jwt_signing_keys
+(
+    kid         VARCHAR(64) PRIMARY KEY,
+    algorithm   VARCHAR(10) NOT NULL,
+    private_key BYTEA       NOT NULL, -- PKCS#8 PEM, зашифрован PGP (pgcrypto)
+    public_key  TEXT        NOT NULL, -- PKIX PEM
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at  TIMESTAMPTZ NOT NULL  -- После этого момента ключ не используется даже для проверки
+);
+CREATE INDEX idx_jwt_signing_keys_expires_at ON jwt_signing_keys (expires_at);
@@ -0,0 +1,14 @@
+ALTER TABLE users
+    ADD COLUMN totp_secret    BYTEA,                          -- Base32-секрет TOTP, зашифрован PGP (pgcrypto)
+    ADD COLUMN totp_enabled   BOOLEAN NOT NULL DEFAULT FALSE, -- 2FA включается после подтверждения кода
+    ADD COLUMN totp_last_step BIGINT;                         -- Последний принятый временной шаг (защита от повтора кода)
+
+CREATE TABLE recovery_codes
+(
+    id         BIGINT PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    user_id    BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    code_hash  CHAR(64)    NOT NULL, -- SHA-256 хеш кода
+    used_at    TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_recovery_codes_user_id ON recovery_codes (user_id);
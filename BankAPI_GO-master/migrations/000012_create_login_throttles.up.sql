@@ -0,0 +1,19 @@
+CREATE TABLE login_throttles
+(
+    key             VARCHAR(320) PRIMARY KEY, -- email:<адрес>, ip:<адрес> или 2fa:<ID пользователя>
+    failures        INT          NOT NULL DEFAULT 0,
+    locked_until    TIMESTAMPTZ,
+    last_failure_at TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE security_events
+(
+    id         BIGINT PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    user_id    BIGINT REFERENCES users (id) ON DELETE SET NULL,
+    event_type VARCHAR(32)  NOT NULL,
+    email      VARCHAR(255) NOT NULL DEFAULT '',
+    ip         VARCHAR(64)  NOT NULL DEFAULT '',
+    details    TEXT         NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_security_events_user_id ON security_events (user_id, created_at);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS users_username_key;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS username;
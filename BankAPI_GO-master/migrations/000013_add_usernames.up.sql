@@ -0,0 +1,12 @@
+ALTER TABLE users
+    ADD COLUMN username   VARCHAR(32),
+    ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+-- У существующих пользователей имени нет: выдаем временное, его можно сменить через PATCH /api/me
+UPDATE users SET username = 'user' || id WHERE username IS NULL;
+
+ALTER TABLE users
+    ALTER COLUMN username SET NOT NULL;
+
+-- Имена уникальны без учета регистра
+CREATE UNIQUE INDEX users_username_key ON users (LOWER(username));
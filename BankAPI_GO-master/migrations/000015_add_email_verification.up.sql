@@ -0,0 +1,6 @@
+ALTER TABLE users
+    ADD COLUMN email_verified_at          TIMESTAMPTZ,
+    ADD COLUMN email_verification_sent_at TIMESTAMPTZ;
+
+-- Существующие пользователи регистрировались до появления проверки email и считаются подтвержденными
+UPDATE users SET email_verified_at = created_at;
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS admin_audit_log;
+
+ALTER TABLE cards
+    DROP COLUMN IF EXISTS status;
+
+ALTER TABLE accounts
+    DROP COLUMN IF EXISTS status;
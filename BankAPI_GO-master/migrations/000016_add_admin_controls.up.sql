@@ -0,0 +1,19 @@
+ALTER TABLE accounts
+    ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'ACTIVE';
+
+ALTER TABLE cards
+    ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'ACTIVE';
+
+-- Журнал действий администраторов и сотрудников поддержки
+CREATE TABLE admin_audit_log
+(
+    id          BIGINT PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    actor_id    BIGINT       NOT NULL REFERENCES users (id),
+    action      VARCHAR(64)  NOT NULL,
+    target_type VARCHAR(32)  NOT NULL,
+    target_id   BIGINT       NOT NULL,
+    details     TEXT         NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_admin_audit_log_target ON admin_audit_log (target_type, target_id, created_at);
+CREATE INDEX idx_admin_audit_log_actor ON admin_audit_log (actor_id, created_at);
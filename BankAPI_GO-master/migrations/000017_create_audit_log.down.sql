@@ -0,0 +1,23 @@
+CREATE TABLE admin_audit_log
+(
+    id          BIGINT PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    actor_id    BIGINT       NOT NULL REFERENCES users (id),
+    action      VARCHAR(64)  NOT NULL,
+    target_type VARCHAR(32)  NOT NULL,
+    target_id   BIGINT       NOT NULL,
+    details     TEXT         NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_admin_audit_log_target ON admin_audit_log (target_type, target_id, created_at);
+CREATE INDEX idx_admin_audit_log_actor ON admin_audit_log (actor_id, created_at);
+
+INSERT INTO admin_audit_log (actor_id, action, target_type, target_id, details, created_at)
+SELECT actor_id, action, target_type, target_id, details, created_at
+FROM audit_log
+WHERE actor_id IS NOT NULL
+  AND target_id IS NOT NULL
+  AND action IN ('VIEW_USER', 'CHANGE_ROLE', 'FREEZE_ACCOUNT', 'UNFREEZE_ACCOUNT',
+                 'BLOCK_CARD', 'UNBLOCK_CARD', 'VIEW_TRANSACTIONS');
+
+DROP TABLE IF EXISTS audit_log;
+DROP FUNCTION IF EXISTS audit_log_append_only();
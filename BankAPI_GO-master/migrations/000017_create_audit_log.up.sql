@@ -0,0 +1,84 @@
+-- Неизменяемый журнал аудита. Каждая запись содержит хеш предыдущей записи и
+-- SHA-256 от своих полей, поэтому изменение или удаление записи обнаруживается
+-- проверкой цепочки (GET /api/admin/audit/verify).
+CREATE TABLE audit_log
+(
+    id          BIGINT PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    actor_id    BIGINT,                               -- Кто выполнил действие (NULL - аноним или система)
+    action      VARCHAR(64)  NOT NULL,
+    target_type VARCHAR(32)  NOT NULL DEFAULT '',
+    target_id   BIGINT,
+    request_id  VARCHAR(64)  NOT NULL DEFAULT '',
+    ip          VARCHAR(64)  NOT NULL DEFAULT '',
+    user_agent  VARCHAR(512) NOT NULL DEFAULT '',
+    details     TEXT         NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ  NOT NULL,
+    prev_hash   CHAR(64)     NOT NULL,
+    hash        CHAR(64)     NOT NULL UNIQUE
+);
+CREATE INDEX idx_audit_log_actor ON audit_log (actor_id, id);
+CREATE INDEX idx_audit_log_target ON audit_log (target_type, target_id, id);
+CREATE INDEX idx_audit_log_action ON audit_log (action, id);
+CREATE INDEX idx_audit_log_created_at ON audit_log (created_at);
+
+-- Журнал только дополняется: изменение и удаление записей запрещены
+CREATE FUNCTION audit_log_append_only() RETURNS TRIGGER AS
+$$
+BEGIN
+    RAISE EXCEPTION 'audit_log доступен только для добавления записей';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_no_update_delete
+    BEFORE UPDATE OR DELETE
+    ON audit_log
+    FOR EACH ROW
+EXECUTE FUNCTION audit_log_append_only();
+
+CREATE TRIGGER audit_log_no_truncate
+    BEFORE TRUNCATE
+    ON audit_log
+    FOR EACH STATEMENT
+EXECUTE FUNCTION audit_log_append_only();
+
+-- Перенос журнала действий администраторов в общую цепочку.
+-- Хеш вычисляется так же, как в service.auditHash: SHA-256 (hex) от конкатенации
+-- полей в формате "<длина в байтах>:<значение>".
+CREATE FUNCTION pg_temp.audit_field(v TEXT) RETURNS TEXT AS
+$$
+SELECT octet_length(v)::TEXT || ':' || v
+$$ LANGUAGE SQL IMMUTABLE;
+
+DO
+$$
+    DECLARE
+        rec       RECORD;
+        prev      TEXT := repeat('0', 64);
+        next_hash TEXT;
+        ts        TIMESTAMPTZ;
+    BEGIN
+        FOR rec IN SELECT * FROM admin_audit_log ORDER BY id
+            LOOP
+                ts := date_trunc('microseconds', rec.created_at);
+                next_hash := encode(digest(
+                                            pg_temp.audit_field(prev) ||
+                                            pg_temp.audit_field((EXTRACT(EPOCH FROM ts) * 1000000)::BIGINT::TEXT) ||
+                                            pg_temp.audit_field(rec.actor_id::TEXT) ||
+                                            pg_temp.audit_field(rec.action) ||
+                                            pg_temp.audit_field(rec.target_type) ||
+                                            pg_temp.audit_field(rec.target_id::TEXT) ||
+                                            pg_temp.audit_field('') ||
+                                            pg_temp.audit_field('') ||
+                                            pg_temp.audit_field('') ||
+                                            pg_temp.audit_field(rec.details),
+                                            'sha256'), 'hex');
+
+                INSERT INTO audit_log (actor_id, action, target_type, target_id, details, created_at, prev_hash, hash)
+                VALUES (rec.actor_id, rec.action, rec.target_type, rec.target_id, rec.details, ts, prev, next_hash);
+
+                prev := next_hash;
+            END LOOP;
+    END
+$$;
+
+DROP TABLE admin_audit_log;
@@ -0,0 +1,6 @@
+UPDATE accounts
+SET status = 'FROZEN'
+WHERE status = 'CLOSED';
+
+ALTER TABLE accounts
+    DROP COLUMN closed_at;
@@ -0,0 +1,3 @@
+-- Момент закрытия счета владельцем (NULL для открытых счетов)
+ALTER TABLE accounts
+    ADD COLUMN closed_at TIMESTAMPTZ;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_accounts_number;
+
+ALTER TABLE accounts
+    DROP COLUMN number;
@@ -0,0 +1,28 @@
+-- Публичный 20-значный номер счета (см. account.GenerateNumber)
+ALTER TABLE accounts
+    ADD COLUMN number CHAR(20);
+
+-- Номер по ID с контрольным ключом по методике Банка России;
+-- '987' — последние три цифры БИК банка (account.BankBIK)
+CREATE FUNCTION pg_temp.account_number(account_id BIGINT, currency CHAR(3)) RETURNS CHAR(20) AS
+$$
+DECLARE
+    code    TEXT := CASE currency WHEN 'USD' THEN '840' WHEN 'EUR' THEN '978' ELSE '810' END;
+    digits  TEXT := '987' || '40817' || code || '0' || lpad(account_id::TEXT, 11, '0');
+    weights INT[] := ARRAY [7, 1, 3];
+    total   INT := 0;
+BEGIN
+    FOR i IN 1..length(digits)
+        LOOP
+            total := total + substr(digits, i, 1)::INT * weights[(i - 1) % 3 + 1] % 10;
+        END LOOP;
+    RETURN '40817' || code || (total % 10 * 3 % 10)::TEXT || lpad(account_id::TEXT, 11, '0');
+END;
+$$ LANGUAGE plpgsql;
+
+UPDATE accounts
+SET number = pg_temp.account_number(id, currency);
+
+ALTER TABLE accounts
+    ALTER COLUMN number SET NOT NULL;
+CREATE UNIQUE INDEX idx_accounts_number ON accounts (number);
@@ -0,0 +1,38 @@
+-- Отложенные и регулярные переводы
+CREATE TABLE scheduled_transfers
+(
+    id                BIGINT PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    user_id           BIGINT         NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    from_account_id   BIGINT         NOT NULL REFERENCES accounts (id) ON DELETE CASCADE,
+    to_account_id     BIGINT REFERENCES accounts (id) ON DELETE CASCADE,
+    to_account_number CHAR(20),
+    to_email          VARCHAR(320),
+    amount            NUMERIC(12, 2) NOT NULL CHECK (amount > 0),
+    frequency         VARCHAR(16)    NOT NULL,
+    cron_expr         VARCHAR(100),
+    start_at          TIMESTAMPTZ    NOT NULL,
+    end_at            TIMESTAMPTZ,
+    scheduled_at      TIMESTAMPTZ    NOT NULL,
+    next_run_at       TIMESTAMPTZ    NOT NULL,
+    attempts          INT            NOT NULL DEFAULT 0,
+    status            VARCHAR(16)    NOT NULL DEFAULT 'ACTIVE',
+    last_error        TEXT,
+    created_at        TIMESTAMPTZ    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at        TIMESTAMPTZ    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (num_nonnulls(to_account_id, to_account_number, to_email) = 1)
+);
+CREATE INDEX idx_scheduled_transfers_user_id ON scheduled_transfers (user_id);
+CREATE INDEX idx_scheduled_transfers_due ON scheduled_transfers (next_run_at) WHERE status = 'ACTIVE';
+
+-- История попыток исполнения
+CREATE TABLE scheduled_transfer_runs
+(
+    id                    BIGINT PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    scheduled_transfer_id BIGINT      NOT NULL REFERENCES scheduled_transfers (id) ON DELETE CASCADE,
+    scheduled_at          TIMESTAMPTZ NOT NULL,
+    attempt               INT         NOT NULL,
+    status                VARCHAR(16) NOT NULL,
+    error                 TEXT,
+    created_at            TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_scheduled_transfer_runs_transfer ON scheduled_transfer_runs (scheduled_transfer_id, id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_card_payments_transaction_id;
+DROP INDEX IF EXISTS idx_transactions_account_created;
+DROP TABLE IF EXISTS transfer_limits;
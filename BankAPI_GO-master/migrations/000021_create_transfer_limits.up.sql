@@ -0,0 +1,19 @@
+-- Индивидуальные лимиты исходящих переводов и списаний пользователя или счета.
+-- Незаданные поля наследуются от лимитов по умолчанию (TRANSFER_LIMIT_*).
+CREATE TABLE transfer_limits
+(
+    id           BIGINT PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    user_id      BIGINT UNIQUE REFERENCES users (id) ON DELETE CASCADE,
+    account_id   BIGINT UNIQUE REFERENCES accounts (id) ON DELETE CASCADE,
+    single_max   NUMERIC(12, 2) CHECK (single_max >= 0),
+    daily_max    NUMERIC(12, 2) CHECK (daily_max >= 0),
+    monthly_max  NUMERIC(12, 2) CHECK (monthly_max >= 0),
+    hourly_count INT CHECK (hourly_count >= 0),
+    updated_by   BIGINT REFERENCES users (id) ON DELETE SET NULL,
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (num_nonnulls(user_id, account_id) = 1)
+);
+
+-- Подсчет исходящих операций за период; списания по картам в лимиты переводов не входят
+CREATE INDEX idx_transactions_account_created ON transactions (account_id, created_at);
+CREATE INDEX idx_card_payments_transaction_id ON card_payments (transaction_id);
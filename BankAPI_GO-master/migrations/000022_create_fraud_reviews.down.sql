@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS fraud_reviews;
+DROP TABLE IF EXISTS card_verification_failures;
+
+DROP INDEX IF EXISTS idx_transactions_counterparty;
+ALTER TABLE transactions
+    DROP COLUMN IF EXISTS counterparty_account_id;
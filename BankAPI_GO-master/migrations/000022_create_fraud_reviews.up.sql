@@ -0,0 +1,38 @@
+-- Счет второй стороны перевода: нужен антифрод-правилам о новых получателях
+ALTER TABLE transactions
+    ADD COLUMN counterparty_account_id BIGINT REFERENCES accounts (id) ON DELETE SET NULL;
+
+CREATE INDEX idx_transactions_counterparty ON transactions (account_id, counterparty_account_id)
+    WHERE counterparty_account_id IS NOT NULL;
+
+-- Неудачные проверки CVV при оплате картой
+CREATE TABLE card_verification_failures
+(
+    id         BIGINT PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    card_id    BIGINT      NOT NULL REFERENCES cards (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_card_verification_failures_card ON card_verification_failures (card_id, created_at);
+
+-- Операции, задержанные антифрод-проверкой до решения администратора
+CREATE TABLE fraud_reviews
+(
+    id             BIGINT PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    kind           VARCHAR(20)    NOT NULL,
+    user_id        BIGINT         NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    account_id     BIGINT         NOT NULL REFERENCES accounts (id) ON DELETE CASCADE,
+    to_account_id  BIGINT REFERENCES accounts (id) ON DELETE SET NULL,
+    transaction_id BIGINT REFERENCES transactions (id) ON DELETE SET NULL,
+    payment_id     BIGINT REFERENCES card_payments (id) ON DELETE SET NULL,
+    amount         NUMERIC(12, 2) NOT NULL,
+    score          INT            NOT NULL,
+    reasons        TEXT[]         NOT NULL,
+    status         VARCHAR(20)    NOT NULL DEFAULT 'PENDING',
+    reviewed_by    BIGINT REFERENCES users (id) ON DELETE SET NULL,
+    comment        TEXT           NOT NULL DEFAULT '',
+    reviewed_at    TIMESTAMPTZ,
+    created_at     TIMESTAMPTZ    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_fraud_reviews_status ON fraud_reviews (status, created_at);
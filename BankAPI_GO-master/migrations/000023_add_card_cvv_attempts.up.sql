@@ -0,0 +1,3 @@
+-- Неудачные проверки CVV подряд; при достижении порога карта блокируется
+ALTER TABLE cards
+    ADD COLUMN failed_cvv_attempts INT NOT NULL DEFAULT 0;
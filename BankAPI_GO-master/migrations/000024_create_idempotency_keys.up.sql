@@ -0,0 +1,13 @@
+CREATE TABLE idempotency_keys
+(
+    user_id       BIGINT       NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    key           VARCHAR(255) NOT NULL,
+    request_hash  CHAR(64)     NOT NULL, -- SHA-256 метода, пути и тела запроса
+    status_code   INT,                   -- NULL, пока запрос выполняется
+    content_type  VARCHAR(255) NOT NULL DEFAULT '',
+    response_body BYTEA,
+    created_at    TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, key)
+);
+
+CREATE INDEX idx_idempotency_keys_created_at ON idempotency_keys (created_at);
@@ -0,0 +1,43 @@
+-- Подписки пользователей на события
+CREATE TABLE webhooks
+(
+    id         BIGINT PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    user_id    BIGINT        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    url        VARCHAR(2048) NOT NULL,
+    secret     BYTEA         NOT NULL, -- Секрет подписи, зашифрован pgp_sym_encrypt
+    events     TEXT[]        NOT NULL CHECK (cardinality(events) > 0),
+    created_at TIMESTAMPTZ   NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_webhooks_user_id ON webhooks (user_id);
+
+-- События, поставленные в очередь доставки (по одной строке на подписку)
+CREATE TABLE webhook_deliveries
+(
+    id               BIGINT PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    webhook_id       BIGINT      NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+    event_id         VARCHAR(64) NOT NULL,
+    event_type       VARCHAR(64) NOT NULL,
+    payload          TEXT        NOT NULL, -- Тело запроса; подписывается как есть
+    status           VARCHAR(16) NOT NULL DEFAULT 'PENDING',
+    attempts         INT         NOT NULL DEFAULT 0,
+    next_attempt_at  TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_status_code INT,
+    last_error       TEXT,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    delivered_at     TIMESTAMPTZ
+);
+CREATE INDEX idx_webhook_deliveries_webhook ON webhook_deliveries (webhook_id, id);
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries (next_attempt_at) WHERE status = 'PENDING';
+
+-- Журнал попыток доставки
+CREATE TABLE webhook_delivery_attempts
+(
+    id          BIGINT PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    delivery_id BIGINT      NOT NULL REFERENCES webhook_deliveries (id) ON DELETE CASCADE,
+    attempt     INT         NOT NULL,
+    status_code INT,
+    error       TEXT,
+    duration_ms INT         NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_webhook_delivery_attempts_delivery ON webhook_delivery_attempts (delivery_id, id);
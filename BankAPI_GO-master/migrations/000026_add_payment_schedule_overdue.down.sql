@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_schedule_overdue;
+ALTER TABLE payment_schedules
+    DROP COLUMN IF EXISTS overdue_notified_at;
@@ -0,0 +1,6 @@
+-- Время постановки в очередь события credit.overdue по просроченному платежу;
+-- NULL, пока о просрочке не сообщалось
+ALTER TABLE payment_schedules
+    ADD COLUMN overdue_notified_at TIMESTAMPTZ;
+
+CREATE INDEX idx_schedule_overdue ON payment_schedules (due_date) WHERE NOT paid AND overdue_notified_at IS NULL;
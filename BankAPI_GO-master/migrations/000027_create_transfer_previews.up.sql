@@ -0,0 +1,9 @@
+-- Запросы предпросмотра получателя перевода для ограничения их частоты
+CREATE TABLE transfer_previews
+(
+    id         BIGINT PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    user_id    BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_transfer_previews_user ON transfer_previews (user_id, created_at);
@@ -0,0 +1,87 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/shopspring/decimal"
+)
+
+// CreateAccount открывает счет в указанной валюте
+func (c *Client) CreateAccount(ctx context.Context, currency Currency) (*Account, error) {
+	var account Account
+	err := c.do(ctx, call{
+		method: http.MethodPost,
+		path:   "/api/accounts",
+		body:   createAccountRequest{Currency: currency},
+		out:    &account,
+		auth:   true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// Accounts возвращает счета пользователя
+func (c *Client) Accounts(ctx context.Context) ([]Account, error) {
+	var resp struct {
+		Accounts []Account `json:"accounts"`
+	}
+	if err := c.do(ctx, call{method: http.MethodGet, path: "/api/accounts", out: &resp, auth: true}); err != nil {
+		return nil, err
+	}
+	return resp.Accounts, nil
+}
+
+// Transactions возвращает транзакции по счету
+func (c *Client) Transactions(ctx context.Context, accountID int64) ([]Transaction, error) {
+	var resp struct {
+		Transactions []Transaction `json:"transactions"`
+	}
+	err := c.do(ctx, call{
+		method: http.MethodGet,
+		path:   pathID("/api/accounts", accountID, "/transactions"),
+		out:    &resp,
+		auth:   true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Transactions, nil
+}
+
+// UpdateBalance пополняет счет (положительная сумма) или списывает с него (отрицательная)
+func (c *Client) UpdateBalance(ctx context.Context, accountID int64, amount decimal.Decimal, opts ...RequestOption) (*Account, error) {
+	var account Account
+	err := c.do(ctx, call{
+		method: http.MethodPatch,
+		path:   pathID("/api/accounts", accountID, "/balance"),
+		body:   updateBalanceRequest{Amount: amount},
+		out:    &account,
+		auth:   true,
+		opts:   opts,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// CloseAccount закрывает счет. Ненулевой остаток переводится на счет transferTo
+// (nil — если остаток нулевой).
+func (c *Client) CloseAccount(ctx context.Context, accountID int64, transferTo *int64, opts ...RequestOption) (*Account, error) {
+	var account Account
+	err := c.do(ctx, call{
+		method: http.MethodPost,
+		path:   pathID("/api/accounts", accountID, "/close"),
+		body:   closeAccountRequest{TransferToAccountID: transferTo},
+		out:    &account,
+		auth:   true,
+		opts:   opts,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
@@ -0,0 +1,75 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// Register регистрирует пользователя и возвращает его ID. Для операций с деньгами
+// пользователь должен подтвердить email по ссылке из письма.
+func (c *Client) Register(ctx context.Context, req RegisterRequest) (int64, error) {
+	var resp registerResponse
+	err := c.do(ctx, call{method: http.MethodPost, path: "/api/register", body: req, out: &resp})
+	return resp.UserID, err
+}
+
+// Login выполняет вход и сохраняет токены в клиенте. Если включена 2FA, токены не выдаются,
+// и вход нужно завершить вызовом LoginTwoFactor.
+func (c *Client) Login(ctx context.Context, email, password string) (*LoginResult, error) {
+	var resp authResponse
+	err := c.do(ctx, call{
+		method: http.MethodPost,
+		path:   "/api/login",
+		body:   loginRequest{Email: email, Password: password},
+		out:    &resp,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.TwoFactorRequired {
+		return &LoginResult{TwoFactorRequired: true, ChallengeToken: resp.ChallengeToken}, nil
+	}
+	c.storeTokens(resp)
+	return &LoginResult{}, nil
+}
+
+// LoginTwoFactor завершает вход кодом TOTP или кодом восстановления и сохраняет токены в клиенте
+func (c *Client) LoginTwoFactor(ctx context.Context, challengeToken, code string) error {
+	var resp authResponse
+	err := c.do(ctx, call{
+		method: http.MethodPost,
+		path:   "/api/login/2fa",
+		body:   twoFactorLoginRequest{ChallengeToken: challengeToken, Code: code},
+		out:    &resp,
+	})
+	if err != nil {
+		return err
+	}
+
+	c.storeTokens(resp)
+	return nil
+}
+
+// RefreshTokens принудительно обновляет пару токенов
+func (c *Client) RefreshTokens(ctx context.Context) error {
+	return c.refresh(ctx, c.Tokens().AccessToken)
+}
+
+// Logout отзывает текущую сессию и очищает токены в клиенте
+func (c *Client) Logout(ctx context.Context) error {
+	if err := c.do(ctx, call{method: http.MethodPost, path: "/api/logout", auth: true}); err != nil {
+		return err
+	}
+	c.SetTokens(Tokens{})
+	return nil
+}
+
+// Me возвращает профиль текущего пользователя
+func (c *Client) Me(ctx context.Context) (*Profile, error) {
+	var profile Profile
+	if err := c.do(ctx, call{method: http.MethodGet, path: "/api/me", out: &profile, auth: true}); err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
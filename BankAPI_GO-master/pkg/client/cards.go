@@ -0,0 +1,80 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// CreateCard выпускает карту к счету. Реквизиты в ответе зашифрованы открытым ключом pgpKey.
+func (c *Client) CreateCard(ctx context.Context, accountID int64, pgpKey string) (*IssuedCard, error) {
+	var card IssuedCard
+	err := c.do(ctx, call{
+		method: http.MethodPost,
+		path:   "/api/cards",
+		body:   createCardRequest{AccountID: accountID, PGPKey: pgpKey},
+		out:    &card,
+		auth:   true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &card, nil
+}
+
+// Cards возвращает карты пользователя
+func (c *Client) Cards(ctx context.Context) ([]Card, error) {
+	var resp struct {
+		Cards []Card `json:"cards"`
+	}
+	if err := c.do(ctx, call{method: http.MethodGet, path: "/api/cards", out: &resp, auth: true}); err != nil {
+		return nil, err
+	}
+	return resp.Cards, nil
+}
+
+// CardDetails возвращает реквизиты карты, зашифрованные открытым ключом pgpKey
+func (c *Client) CardDetails(ctx context.Context, cardID int64, pgpKey string) (*CardDetails, error) {
+	var details CardDetails
+	err := c.do(ctx, call{
+		method: http.MethodGet,
+		path:   pathID("/api/cards", cardID, ""),
+		query:  url.Values{"pgp_key": {pgpKey}},
+		out:    &details,
+		auth:   true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &details, nil
+}
+
+// CardLimits возвращает лимиты карты
+func (c *Client) CardLimits(ctx context.Context, cardID int64) (*CardLimits, error) {
+	var limits CardLimits
+	if err := c.do(ctx, call{method: http.MethodGet, path: pathID("/api/cards", cardID, "/limits"), out: &limits, auth: true}); err != nil {
+		return nil, err
+	}
+	return &limits, nil
+}
+
+// UpdateCardLimits заменяет лимиты карты. Поле CardID в limits не используется.
+func (c *Client) UpdateCardLimits(ctx context.Context, cardID int64, limits CardLimits) (*CardLimits, error) {
+	var updated CardLimits
+	err := c.do(ctx, call{
+		method: http.MethodPut,
+		path:   pathID("/api/cards", cardID, "/limits"),
+		body: cardLimitsRequest{
+			PerTransaction:    limits.PerTransaction,
+			Daily:             limits.Daily,
+			Monthly:           limits.Monthly,
+			BlockedCategories: limits.BlockedCategories,
+		},
+		out:  &updated,
+		auth: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
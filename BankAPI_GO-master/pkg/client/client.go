@@ -0,0 +1,309 @@
+// Package client — типизированный клиент REST API банковского сервиса.
+//
+// Клиент покрывает регистрацию и вход, счета, переводы (в том числе регулярные),
+// карты и платежи картой. Токены хранятся в клиенте и обновляются автоматически:
+// заранее, когда срок жизни access-токена подходит к концу, и повторно после ответа 401.
+// Операции с движением денег принимают ключ идемпотентности (WithIdempotencyKey),
+// операции на крупную сумму — код TOTP (WithTOTPCode). Ошибки API возвращаются как *Error
+// с кодом из того же каталога, что и на сервере, и сравниваются через errors.Is:
+//
+//	if errors.Is(err, client.INSUFFICIENT_FUNDS) { ... }
+//
+// Кредиты в клиенте не представлены: в сервисе пока нет API кредитов.
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	idempotencyKeyHeader = "Idempotency-Key"
+	totpCodeHeader       = "X-TOTP-Code"
+)
+
+// refreshLeeway за сколько до истечения access-токена клиент обновляет его заранее
+const refreshLeeway = 30 * time.Second
+
+// Tokens пара токенов пользователя. ExpiresAt — момент истечения access-токена
+// (нулевое значение означает, что срок неизвестен и токен обновляется только после 401).
+type Tokens struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresAt    time.Time
+}
+
+// Client клиент API. Безопасен для использования из нескольких горутин.
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	language   string
+	onTokens   func(Tokens)
+
+	mu     sync.Mutex
+	tokens Tokens
+
+	// refreshMu не дает обновить токены параллельно: refresh-токен одноразовый
+	refreshMu sync.Mutex
+}
+
+// Option настройка клиента
+type Option func(*Client)
+
+// WithHTTPClient задает HTTP-клиент (таймауты, транспорт). По умолчанию — клиент с таймаутом 30 секунд.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithLanguage задает язык сообщений об ошибках (заголовок Accept-Language), например "en"
+func WithLanguage(language string) Option {
+	return func(c *Client) {
+		c.language = language
+	}
+}
+
+// WithTokens задает сохраненные ранее токены, чтобы не выполнять вход заново
+func WithTokens(tokens Tokens) Option {
+	return func(c *Client) {
+		c.tokens = tokens
+	}
+}
+
+// WithTokenHandler задает функцию, которая вызывается при каждом получении новых токенов
+// (вход и обновление) — например, чтобы сохранить их. Refresh-токен одноразовый:
+// после обновления прежний токен недействителен.
+func WithTokenHandler(fn func(Tokens)) Option {
+	return func(c *Client) {
+		c.onTokens = fn
+	}
+}
+
+// New создает клиент API. baseURL — адрес сервиса без префикса /api, например "https://bank.example.com".
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimRight(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("неверный адрес сервиса: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("неверный адрес сервиса %q: нужна схема http или https", baseURL)
+	}
+
+	c := &Client{
+		baseURL:    u,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// Tokens возвращает текущие токены
+func (c *Client) Tokens() Tokens {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tokens
+}
+
+// SetTokens заменяет текущие токены
+func (c *Client) SetTokens(tokens Tokens) {
+	c.mu.Lock()
+	c.tokens = tokens
+	c.mu.Unlock()
+}
+
+// RequestOption настройка отдельного запроса
+type RequestOption func(*http.Request)
+
+// WithIdempotencyKey передает ключ идемпотентности. Повтор запроса с тем же ключом и телом
+// в течение 24 часов возвращает сохраненный ответ и не выполняет операцию второй раз.
+// Ключ учитывается в операциях с движением денег; для каждой новой операции нужен новый ключ.
+func WithIdempotencyKey(key string) RequestOption {
+	return func(r *http.Request) {
+		r.Header.Set(idempotencyKeyHeader, key)
+	}
+}
+
+// WithTOTPCode передает код TOTP для подтверждения операции на крупную сумму
+func WithTOTPCode(code string) RequestOption {
+	return func(r *http.Request) {
+		r.Header.Set(totpCodeHeader, code)
+	}
+}
+
+// NewIdempotencyKey генерирует случайный ключ идемпотентности
+func NewIdempotencyKey() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("client: ошибка генерации ключа идемпотентности: %v", err))
+	}
+	return hex.EncodeToString(b)
+}
+
+// call описание запроса к API
+type call struct {
+	method string
+	path   string
+	query  url.Values
+	body   any  // Тело запроса в JSON; nil — без тела
+	out    any  // Куда разобрать ответ; nil — ответ не разбирается
+	auth   bool // Запрос с access-токеном
+	opts   []RequestOption
+}
+
+// do выполняет запрос. Для запроса с авторизацией токен обновляется заранее, если истекает,
+// и однократно после ответа 401, после чего запрос повторяется.
+func (c *Client) do(ctx context.Context, cl call) error {
+	var body []byte
+	if cl.body != nil {
+		var err error
+		if body, err = json.Marshal(cl.body); err != nil {
+			return fmt.Errorf("ошибка кодирования запроса: %w", err)
+		}
+	}
+
+	if cl.auth {
+		if err := c.refreshIfExpiring(ctx); err != nil {
+			return err
+		}
+	}
+
+	token := c.Tokens().AccessToken
+	resp, err := c.send(ctx, cl, body, token)
+	if err != nil {
+		return err
+	}
+
+	if cl.auth && resp.StatusCode == http.StatusUnauthorized && c.Tokens().RefreshToken != "" {
+		resp.Body.Close()
+		if err := c.refresh(ctx, token); err != nil {
+			return err
+		}
+		if resp, err = c.send(ctx, cl, body, c.Tokens().AccessToken); err != nil {
+			return err
+		}
+	}
+	defer resp.Body.Close()
+
+	return decodeResponse(resp, cl.out)
+}
+
+// send отправляет запрос с заданным access-токеном
+func (c *Client) send(ctx context.Context, cl call, body []byte, token string) (*http.Response, error) {
+	u := c.baseURL.JoinPath(cl.path)
+	if len(cl.query) > 0 {
+		u.RawQuery = cl.query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, cl.method, u.String(), reader)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания запроса: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.language != "" {
+		req.Header.Set("Accept-Language", c.language)
+	}
+	if cl.auth && token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	for _, opt := range cl.opts {
+		opt(req)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка запроса %s %s: %w", cl.method, cl.path, err)
+	}
+	return resp, nil
+}
+
+// decodeResponse разбирает успешный ответ в out или возвращает *Error
+func decodeResponse(resp *http.Response, out any) error {
+	if resp.StatusCode >= http.StatusBadRequest {
+		return parseError(resp)
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("ошибка разбора ответа: %w", err)
+	}
+	return nil
+}
+
+// refreshIfExpiring обновляет токены, если access-токен истекает в ближайшие refreshLeeway
+func (c *Client) refreshIfExpiring(ctx context.Context) error {
+	tokens := c.Tokens()
+	if tokens.RefreshToken == "" || tokens.ExpiresAt.IsZero() || time.Until(tokens.ExpiresAt) > refreshLeeway {
+		return nil
+	}
+	return c.refresh(ctx, tokens.AccessToken)
+}
+
+// refresh обновляет пару токенов. staleToken — access-токен, который нужно заменить:
+// если другая горутина уже заменила его, повторное обновление не выполняется.
+func (c *Client) refresh(ctx context.Context, staleToken string) error {
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+
+	tokens := c.Tokens()
+	if tokens.AccessToken != staleToken {
+		return nil
+	}
+
+	var resp authResponse
+	err := c.do(ctx, call{
+		method: http.MethodPost,
+		path:   "/api/token/refresh",
+		body:   refreshRequest{RefreshToken: tokens.RefreshToken},
+		out:    &resp,
+	})
+	if err != nil {
+		return fmt.Errorf("ошибка обновления токенов: %w", err)
+	}
+
+	c.storeTokens(resp)
+	return nil
+}
+
+// storeTokens сохраняет токены из ответа входа или обновления
+func (c *Client) storeTokens(resp authResponse) {
+	tokens := Tokens{
+		AccessToken:  resp.Token,
+		RefreshToken: resp.RefreshToken,
+	}
+	if resp.ExpiresIn > 0 {
+		tokens.ExpiresAt = time.Now().Add(time.Duration(resp.ExpiresIn) * time.Second)
+	}
+
+	c.SetTokens(tokens)
+	if c.onTokens != nil {
+		c.onTokens(tokens)
+	}
+}
+
+// pathID формирует путь с числовым идентификатором, например /api/cards/42/limits
+func pathID(prefix string, id int64, suffix string) string {
+	return fmt.Sprintf("%s/%d%s", prefix, id, suffix)
+}
@@ -0,0 +1,501 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestLogin(t *testing.T) {
+	srv, _ := newTestServer(t)
+	ctx := context.Background()
+
+	var stored []Tokens
+	c := newTestClient(t, srv, WithTokenHandler(func(tokens Tokens) { stored = append(stored, tokens) }))
+
+	res, err := c.Login(ctx, customerEmail, testPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.TwoFactorRequired {
+		t.Fatal("вход без 2FA не должен требовать второй шаг")
+	}
+
+	tokens := c.Tokens()
+	if tokens.AccessToken == "" || tokens.RefreshToken == "" {
+		t.Fatalf("токены не сохранены: %+v", tokens)
+	}
+	if until := time.Until(tokens.ExpiresAt); until <= 0 || until > testAccessTokenTTL {
+		t.Fatalf("неверный срок действия access-токена: %v", tokens.ExpiresAt)
+	}
+	if len(stored) != 1 || stored[0] != tokens {
+		t.Fatalf("WithTokenHandler получил %+v, ожидались %+v", stored, tokens)
+	}
+
+	if _, err := c.Accounts(ctx); err != nil {
+		t.Fatalf("запрос с токеном после входа: %v", err)
+	}
+}
+
+func TestLoginInvalidCredentials(t *testing.T) {
+	srv, _ := newTestServer(t)
+	c := newTestClient(t, srv)
+
+	_, err := c.Login(context.Background(), customerEmail, "wrong-password")
+	if !errors.Is(err, INVALID_CREDENTIALS) {
+		t.Fatalf("ожидалась ошибка INVALID_CREDENTIALS, получено %v", err)
+	}
+	if c.Tokens() != (Tokens{}) {
+		t.Fatalf("после неудачного входа токены не должны сохраняться: %+v", c.Tokens())
+	}
+}
+
+// После ответа 401 клиент обновляет токены и повторяет запрос один раз
+func TestRefreshAfterUnauthorized(t *testing.T) {
+	srv, bank := newTestServer(t)
+	ctx := context.Background()
+
+	var stored []Tokens
+	c := newTestClient(t, srv, WithTokenHandler(func(tokens Tokens) { stored = append(stored, tokens) }))
+	login(t, c, customerEmail)
+	before := c.Tokens()
+
+	bank.expireAccessTokens()
+	if _, err := c.CreateAccount(ctx, RUB); err != nil {
+		t.Fatalf("запрос после истечения access-токена: %v", err)
+	}
+
+	if n := bank.refreshCount(); n != 1 {
+		t.Fatalf("токены обновлены %d раз, ожидался 1", n)
+	}
+	if n := bank.executedCount("POST /api/accounts"); n != 1 {
+		t.Fatalf("операция выполнена %d раз, ожидался 1", n)
+	}
+	after := c.Tokens()
+	if after.AccessToken == before.AccessToken || after.RefreshToken == before.RefreshToken {
+		t.Fatalf("токены не заменены: было %+v, стало %+v", before, after)
+	}
+	if len(stored) != 2 || stored[1] != after {
+		t.Fatalf("WithTokenHandler не получил новые токены: %+v", stored)
+	}
+}
+
+// Access-токен, который скоро истечет, обновляется до запроса
+func TestRefreshBeforeExpiry(t *testing.T) {
+	srv, bank := newTestServer(t)
+	c := newTestClient(t, srv)
+	login(t, c, customerEmail)
+
+	tokens := c.Tokens()
+	tokens.ExpiresAt = time.Now().Add(refreshLeeway / 2)
+	c.SetTokens(tokens)
+
+	if _, err := c.Accounts(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := bank.refreshCount(); n != 1 {
+		t.Fatalf("токены обновлены %d раз, ожидался 1", n)
+	}
+	if c.Tokens().AccessToken == tokens.AccessToken {
+		t.Fatal("access-токен не обновлен")
+	}
+}
+
+// Если обновить токены не удалось, возвращается ошибка обновления, а запрос не повторяется
+func TestRefreshRejected(t *testing.T) {
+	srv, bank := newTestServer(t)
+	c := newTestClient(t, srv)
+	login(t, c, customerEmail)
+
+	tokens := c.Tokens()
+	tokens.RefreshToken = "revoked"
+	c.SetTokens(tokens)
+	bank.expireAccessTokens()
+
+	_, err := c.Accounts(context.Background())
+	if !errors.Is(err, INVALID_REFRESH_TOKEN) {
+		t.Fatalf("ожидалась ошибка INVALID_REFRESH_TOKEN, получено %v", err)
+	}
+}
+
+func TestAccounts(t *testing.T) {
+	srv, _ := newTestServer(t)
+	ctx := context.Background()
+	c := newTestClient(t, srv)
+	login(t, c, customerEmail)
+
+	acc, err := c.CreateAccount(ctx, RUB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if acc.ID == 0 || acc.Number == "" || acc.Currency != RUB || acc.Status != ACCOUNT_STATUS_ACTIVE || acc.CreatedAt.IsZero() {
+		t.Fatalf("неверный счет: %+v", acc)
+	}
+
+	acc, err = c.UpdateBalance(ctx, acc.ID, decimal.RequireFromString("150.50"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	acc, err = c.UpdateBalance(ctx, acc.ID, decimal.RequireFromString("-50.50"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !acc.Balance.Equal(decimal.NewFromInt(100)) || !acc.AvailableBalance.Equal(decimal.NewFromInt(100)) {
+		t.Fatalf("неверный баланс: %s (доступно %s)", acc.Balance, acc.AvailableBalance)
+	}
+
+	_, err = c.UpdateBalance(ctx, acc.ID, decimal.NewFromInt(-1000))
+	if !errors.Is(err, INSUFFICIENT_FUNDS) {
+		t.Fatalf("ожидалась ошибка INSUFFICIENT_FUNDS, получено %v", err)
+	}
+
+	accounts, err := c.Accounts(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accounts) != 1 || accounts[0].ID != acc.ID || !accounts[0].Balance.Equal(acc.Balance) {
+		t.Fatalf("неверный список счетов: %+v", accounts)
+	}
+
+	txs, err := c.Transactions(ctx, acc.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txs) != 2 || txs[0].Type != TRANSACTION_DEPOSIT || txs[1].Type != TRANSACTION_WITHDRAWAL ||
+		!txs[1].Amount.Equal(decimal.RequireFromString("-50.50")) || txs[1].Status != TRANSACTION_COMPLETED {
+		t.Fatalf("неверные транзакции: %+v", txs)
+	}
+
+	_, err = c.Transactions(ctx, acc.ID+100)
+	if !errors.Is(err, ACCOUNT_NOT_FOUND) {
+		t.Fatalf("ожидалась ошибка ACCOUNT_NOT_FOUND, получено %v", err)
+	}
+}
+
+// Операции с деньгами недоступны до подтверждения email
+func TestEmailNotVerified(t *testing.T) {
+	srv, _ := newTestServer(t)
+	ctx := context.Background()
+	c := newTestClient(t, srv)
+	login(t, c, unverifiedEmail)
+
+	acc, err := c.CreateAccount(ctx, RUB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.UpdateBalance(ctx, acc.ID, decimal.NewFromInt(10))
+	if !errors.Is(err, EMAIL_NOT_VERIFIED) {
+		t.Fatalf("ожидалась ошибка EMAIL_NOT_VERIFIED, получено %v", err)
+	}
+}
+
+func TestTransfers(t *testing.T) {
+	srv, _ := newTestServer(t)
+	ctx := context.Background()
+	c := newTestClient(t, srv)
+	login(t, c, customerEmail)
+
+	from, err := c.CreateAccount(ctx, RUB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	to, err := c.CreateAccount(ctx, RUB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.UpdateBalance(ctx, from.ID, decimal.NewFromInt(300)); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := c.Transfer(ctx, TransferRequest{
+		FromAccountID: from.ID,
+		Recipient:     Recipient{AccountID: to.ID},
+		Amount:        decimal.NewFromInt(100),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Status != TRANSFER_COMPLETED || res.TransactionID == 0 {
+		t.Fatalf("неверный результат перевода: %+v", res)
+	}
+
+	if _, err := c.Transfer(ctx, TransferRequest{
+		FromAccountID: from.ID,
+		Recipient:     Recipient{AccountNumber: to.Number},
+		Amount:        decimal.NewFromInt(50),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = c.Transfer(ctx, TransferRequest{
+		FromAccountID: from.ID,
+		Recipient:     Recipient{AccountID: to.ID},
+		Amount:        decimal.NewFromInt(1000),
+	})
+	if !errors.Is(err, INSUFFICIENT_FUNDS) {
+		t.Fatalf("ожидалась ошибка INSUFFICIENT_FUNDS, получено %v", err)
+	}
+
+	accounts, err := c.Accounts(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accounts) != 2 || !accounts[0].Balance.Equal(decimal.NewFromInt(150)) || !accounts[1].Balance.Equal(decimal.NewFromInt(150)) {
+		t.Fatalf("неверные балансы после переводов: %+v", accounts)
+	}
+}
+
+func TestCards(t *testing.T) {
+	srv, _ := newTestServer(t)
+	ctx := context.Background()
+	c := newTestClient(t, srv)
+	login(t, c, customerEmail)
+
+	acc, err := c.CreateAccount(ctx, RUB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issued, err := c.CreateCard(ctx, acc.ID, "public-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if issued.ID == 0 || issued.AccountID != acc.ID || issued.CardNumber == "" || issued.CVV == "" {
+		t.Fatalf("неверная выпущенная карта: %+v", issued)
+	}
+
+	cards, err := c.Cards(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cards) != 1 || cards[0].ID != issued.ID || cards[0].Status != CARD_STATUS_ACTIVE ||
+		cards[0].AccountID == nil || *cards[0].AccountID != acc.ID {
+		t.Fatalf("неверный список карт: %+v", cards)
+	}
+
+	limits, err := c.UpdateCardLimits(ctx, issued.ID, CardLimits{
+		Daily:             decimal.NewNullDecimal(decimal.NewFromInt(5000)),
+		BlockedCategories: []string{"7995"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if limits.CardID != issued.ID || !limits.Daily.Valid || !limits.Daily.Decimal.Equal(decimal.NewFromInt(5000)) || limits.PerTransaction.Valid {
+		t.Fatalf("неверные лимиты: %+v", limits)
+	}
+
+	got, err := c.CardLimits(ctx, issued.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.BlockedCategories) != 1 || got.BlockedCategories[0] != "7995" || !got.Daily.Decimal.Equal(limits.Daily.Decimal) {
+		t.Fatalf("лимиты не сохранены: %+v", got)
+	}
+
+	_, err = c.CardLimits(ctx, issued.ID+100)
+	if !errors.Is(err, CARD_NOT_FOUND) {
+		t.Fatalf("ожидалась ошибка CARD_NOT_FOUND, получено %v", err)
+	}
+}
+
+func TestPayments(t *testing.T) {
+	srv, _ := newTestServer(t)
+	ctx := context.Background()
+	customer := newTestClient(t, srv)
+	login(t, customer, customerEmail)
+	support := newTestClient(t, srv)
+	login(t, support, supportEmail)
+
+	acc, err := customer.CreateAccount(ctx, RUB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := customer.UpdateBalance(ctx, acc.ID, decimal.NewFromInt(1000)); err != nil {
+		t.Fatal(err)
+	}
+	card, err := customer.CreateCard(ctx, acc.ID, "public-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pay := func() *Payment {
+		t.Helper()
+		p, err := customer.Pay(ctx, PaymentRequest{
+			CardID:           card.ID,
+			Amount:           decimal.NewFromInt(200),
+			CVV:              "123",
+			PGPKey:           "public-key",
+			MerchantCategory: "5411",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+
+	p := pay()
+	if !p.Success || p.PaymentID == 0 || p.Status != PAYMENT_AUTHORIZED || p.ExpiresAt == nil {
+		t.Fatalf("неверный платеж: %+v", p)
+	}
+
+	// Списание и возврат доступны только SUPPORT и ADMIN
+	_, err = customer.CapturePayment(ctx, p.PaymentID, decimal.NullDecimal{})
+	if !errors.Is(err, FORBIDDEN) {
+		t.Fatalf("ожидалась ошибка FORBIDDEN при списании клиентом, получено %v", err)
+	}
+
+	captured, err := support.CapturePayment(ctx, p.PaymentID, decimal.NullDecimal{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if captured.Status != PAYMENT_CAPTURED || captured.PaymentID != p.PaymentID {
+		t.Fatalf("неверный списанный платеж: %+v", captured)
+	}
+
+	refund, err := support.RefundPayment(ctx, p.PaymentID, decimal.NewNullDecimal(decimal.NewFromInt(50)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refund.Status != PAYMENT_PARTIALLY_REFUNDED || !refund.RefundAmount.Equal(decimal.NewFromInt(50)) ||
+		!refund.RefundedAmount.Equal(decimal.NewFromInt(50)) || refund.RefundTransactionID == 0 {
+		t.Fatalf("неверный возврат: %+v", refund)
+	}
+	refund, err = support.RefundPayment(ctx, p.PaymentID, decimal.NullDecimal{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refund.Status != PAYMENT_REFUNDED || !refund.RefundAmount.Equal(decimal.NewFromInt(150)) {
+		t.Fatalf("неверный полный возврат: %+v", refund)
+	}
+
+	voided, err := customer.VoidPayment(ctx, pay().PaymentID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if voided.Status != PAYMENT_VOIDED {
+		t.Fatalf("неверный отмененный платеж: %+v", voided)
+	}
+	_, err = customer.VoidPayment(ctx, voided.PaymentID)
+	if !errors.Is(err, PAYMENT_NOT_AUTHORIZED) {
+		t.Fatalf("ожидалась ошибка PAYMENT_NOT_AUTHORIZED, получено %v", err)
+	}
+
+	accounts, err := customer.Accounts(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !accounts[0].Balance.Equal(decimal.NewFromInt(1000)) || !accounts[0].AvailableBalance.Equal(decimal.NewFromInt(1000)) {
+		t.Fatalf("неверный баланс после возврата и отмены: %+v", accounts[0])
+	}
+}
+
+// Повтор запроса с тем же ключом идемпотентности возвращает сохраненный ответ
+// и не выполняет операцию второй раз
+func TestIdempotencyKeyReplay(t *testing.T) {
+	srv, bank := newTestServer(t)
+	ctx := context.Background()
+	c := newTestClient(t, srv)
+	login(t, c, customerEmail)
+
+	from, err := c.CreateAccount(ctx, RUB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	to, err := c.CreateAccount(ctx, RUB)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key := NewIdempotencyKey()
+	first, err := c.UpdateBalance(ctx, from.ID, decimal.NewFromInt(500), WithIdempotencyKey(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := c.UpdateBalance(ctx, from.ID, decimal.NewFromInt(500), WithIdempotencyKey(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := bank.executedCount("PATCH /api/accounts/{id}/balance"); n != 1 {
+		t.Fatalf("пополнение выполнено %d раз, ожидался 1", n)
+	}
+	if !first.Balance.Equal(decimal.NewFromInt(500)) || !second.Balance.Equal(first.Balance) {
+		t.Fatalf("повтор вернул другой ответ: %+v и %+v", first, second)
+	}
+
+	// Тот же ключ с другим запросом отклоняется
+	_, err = c.UpdateBalance(ctx, from.ID, decimal.NewFromInt(700), WithIdempotencyKey(key))
+	if !errors.Is(err, IDEMPOTENCY_KEY_REUSED) {
+		t.Fatalf("ожидалась ошибка IDEMPOTENCY_KEY_REUSED, получено %v", err)
+	}
+
+	transfer := TransferRequest{FromAccountID: from.ID, Recipient: Recipient{AccountID: to.ID}, Amount: decimal.NewFromInt(100)}
+	transferKey := NewIdempotencyKey()
+	res1, err := c.Transfer(ctx, transfer, WithIdempotencyKey(transferKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res2, err := c.Transfer(ctx, transfer, WithIdempotencyKey(transferKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res1.TransactionID != res2.TransactionID || bank.executedCount("POST /api/transfer") != 1 {
+		t.Fatalf("перевод выполнен повторно: %+v и %+v", res1, res2)
+	}
+
+	// После ответа с ошибкой ключ освобождается, и запрос можно повторить
+	failedKey := NewIdempotencyKey()
+	big := TransferRequest{FromAccountID: from.ID, Recipient: Recipient{AccountID: to.ID}, Amount: decimal.NewFromInt(1000)}
+	if _, err := c.Transfer(ctx, big, WithIdempotencyKey(failedKey)); !errors.Is(err, INSUFFICIENT_FUNDS) {
+		t.Fatalf("ожидалась ошибка INSUFFICIENT_FUNDS, получено %v", err)
+	}
+	if _, err := c.UpdateBalance(ctx, from.ID, decimal.NewFromInt(1000)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Transfer(ctx, big, WithIdempotencyKey(failedKey)); err != nil {
+		t.Fatalf("повтор с ключом после ошибки: %v", err)
+	}
+
+	_, err = c.UpdateBalance(ctx, from.ID, decimal.NewFromInt(1), WithIdempotencyKey("ключ с пробелом"))
+	if !errors.Is(err, INVALID_IDEMPOTENCY_KEY) {
+		t.Fatalf("ожидалась ошибка INVALID_IDEMPOTENCY_KEY, получено %v", err)
+	}
+
+	accounts, err := c.Accounts(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !accounts[0].Balance.Equal(decimal.NewFromInt(400)) || !accounts[1].Balance.Equal(decimal.NewFromInt(1100)) {
+		t.Fatalf("неверные балансы: %s и %s", accounts[0].Balance, accounts[1].Balance)
+	}
+}
+
+// Повтор после 401 использует тот же ключ идемпотентности: операция выполняется один раз
+func TestIdempotencyKeyWithRefresh(t *testing.T) {
+	srv, bank := newTestServer(t)
+	ctx := context.Background()
+	c := newTestClient(t, srv)
+	login(t, c, customerEmail)
+
+	acc, err := c.CreateAccount(ctx, RUB)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key := NewIdempotencyKey()
+	if _, err := c.UpdateBalance(ctx, acc.ID, decimal.NewFromInt(10), WithIdempotencyKey(key)); err != nil {
+		t.Fatal(err)
+	}
+	bank.expireAccessTokens()
+	replayed, err := c.UpdateBalance(ctx, acc.ID, decimal.NewFromInt(10), WithIdempotencyKey(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bank.refreshCount() != 1 || bank.executedCount("PATCH /api/accounts/{id}/balance") != 1 {
+		t.Fatalf("обновлений токенов %d, пополнений %d; ожидалось по одному",
+			bank.refreshCount(), bank.executedCount("PATCH /api/accounts/{id}/balance"))
+	}
+	if !replayed.Balance.Equal(decimal.NewFromInt(10)) {
+		t.Fatalf("неверный повторенный ответ: %+v", replayed)
+	}
+}
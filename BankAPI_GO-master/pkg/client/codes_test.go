@@ -0,0 +1,73 @@
+package client
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"maps"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+// catalogFile каталог кодов ошибок сервиса
+const catalogFile = "../../internal/problem/catalog.go"
+
+// Коды клиента должны совпадать с каталогом сервиса: код, добавленный только на сервере,
+// клиент не сможет сравнить через errors.Is
+func TestCodesMatchCatalog(t *testing.T) {
+	client := declaredCodes(t, "codes.go")
+	catalog := declaredCodes(t, catalogFile)
+
+	for _, name := range slices.Sorted(maps.Keys(catalog)) {
+		if value, ok := client[name]; !ok {
+			t.Errorf("код %s есть в %s, но отсутствует в codes.go", name, catalogFile)
+		} else if value != catalog[name] {
+			t.Errorf("значение кода %s: %q в codes.go, %q в каталоге", name, value, catalog[name])
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(client)) {
+		if _, ok := catalog[name]; !ok {
+			t.Errorf("код %s есть в codes.go, но отсутствует в %s", name, catalogFile)
+		}
+	}
+}
+
+// declaredCodes возвращает константы типа Code, объявленные в файле, с их значениями
+func declaredCodes(t *testing.T, path string) map[string]string {
+	t.Helper()
+
+	file, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	codes := make(map[string]string)
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			vs := spec.(*ast.ValueSpec)
+			if ident, ok := vs.Type.(*ast.Ident); !ok || ident.Name != "Code" {
+				continue
+			}
+			for i, name := range vs.Names {
+				lit, ok := vs.Values[i].(*ast.BasicLit)
+				if !ok || lit.Kind != token.STRING {
+					t.Fatalf("%s: значение кода %s должно быть строковым литералом", path, name.Name)
+				}
+				value, err := strconv.Unquote(lit.Value)
+				if err != nil {
+					t.Fatal(err)
+				}
+				codes[name.Name] = value
+			}
+		}
+	}
+	if len(codes) == 0 {
+		t.Fatalf("в %s не найдены коды ошибок", path)
+	}
+	return codes
+}
@@ -0,0 +1,117 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxErrorBodySize сколько байт ответа с ошибкой читается для разбора
+const maxErrorBodySize = 64 << 10
+
+// Code код ошибки API. Код реализует error, чтобы ошибки можно было
+// сравнивать через errors.Is(err, client.ACCOUNT_NOT_FOUND).
+type Code string
+
+func (c Code) Error() string {
+	return string(c)
+}
+
+// FieldError ошибка поля запроса в ответе VALIDATION_FAILED
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Error ошибка, возвращенная API (RFC 7807). Если ответ не в формате problem+json
+// (например, от прокси), Code пустой, а Detail содержит начало тела ответа.
+type Error struct {
+	StatusCode int
+	Code       Code
+	Title      string
+	Detail     string
+	Instance   string
+	RequestID  string
+
+	// Fields ошибки полей для VALIDATION_FAILED
+	Fields []FieldError
+	// Extensions остальные поля ответа (например, параметры превышенного лимита)
+	Extensions map[string]json.RawMessage
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("bank api: %d", e.StatusCode)
+	if e.Code != "" {
+		msg += " " + string(e.Code)
+	}
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+	if e.RequestID != "" {
+		msg += " (request_id " + e.RequestID + ")"
+	}
+	return msg
+}
+
+// Is сообщает, что ошибка имеет код target
+func (e *Error) Is(target error) bool {
+	code, ok := target.(Code)
+	return ok && e.Code != "" && e.Code == code
+}
+
+// Extension разбирает дополнительное поле ответа в v; возвращает false, если поля нет
+func (e *Error) Extension(name string, v any) bool {
+	raw, ok := e.Extensions[name]
+	if !ok {
+		return false
+	}
+	return json.Unmarshal(raw, v) == nil
+}
+
+// parseError разбирает ответ с ошибкой
+func parseError(resp *http.Response) error {
+	apiErr := &Error{
+		StatusCode: resp.StatusCode,
+		Title:      http.StatusText(resp.StatusCode),
+		RequestID:  resp.Header.Get("X-Request-ID"),
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	if err != nil {
+		return apiErr
+	}
+
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(body, &fields) != nil {
+		apiErr.Detail = strings.TrimSpace(string(body[:min(len(body), 512)]))
+		return apiErr
+	}
+
+	known := map[string]any{
+		"code":       &apiErr.Code,
+		"title":      &apiErr.Title,
+		"detail":     &apiErr.Detail,
+		"instance":   &apiErr.Instance,
+		"request_id": &apiErr.RequestID,
+		"errors":     &apiErr.Fields,
+		"status":     nil,
+		"type":       nil,
+	}
+	for name, raw := range fields {
+		dst, ok := known[name]
+		if !ok {
+			if apiErr.Extensions == nil {
+				apiErr.Extensions = make(map[string]json.RawMessage)
+			}
+			apiErr.Extensions[name] = raw
+			continue
+		}
+		if dst != nil {
+			_ = json.Unmarshal(raw, dst)
+		}
+	}
+	return apiErr
+}
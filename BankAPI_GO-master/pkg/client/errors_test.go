@@ -0,0 +1,121 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/therealadik/bank-api/internal/problem"
+)
+
+// Каждый код каталога приходит клиенту как *Error с тем же кодом и HTTP-статусом
+// и сравнивается через errors.Is только со своим кодом
+func TestTypedErrors(t *testing.T) {
+	srv, bank := newTestServer(t)
+	ctx := context.Background()
+	c := newTestClient(t, srv, WithLanguage("en"))
+	login(t, c, customerEmail)
+
+	// Без refresh-токена ответ 401 возвращается как есть, без обновления и повтора
+	tokens := c.Tokens()
+	tokens.RefreshToken = ""
+	c.SetTokens(tokens)
+
+	codes := declaredCodes(t, "codes.go")
+	for _, name := range slices.Sorted(maps.Keys(codes)) {
+		code := Code(codes[name])
+		t.Run(name, func(t *testing.T) {
+			bank.fail(problem.Code(code))
+
+			_, err := c.Accounts(ctx)
+			var apiErr *Error
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("ожидалась ошибка *Error, получено %v", err)
+			}
+			if apiErr.Code != code {
+				t.Fatalf("код %q, ожидался %q", apiErr.Code, code)
+			}
+			if want := problem.New(problem.Code(code)).Status; apiErr.StatusCode != want {
+				t.Fatalf("статус %d, ожидался %d", apiErr.StatusCode, want)
+			}
+			if apiErr.Detail == "" || apiErr.RequestID == "" || apiErr.Instance != "/api/accounts" {
+				t.Fatalf("неполная ошибка: %+v", apiErr)
+			}
+			if !errors.Is(err, code) {
+				t.Fatalf("errors.Is(err, %s) == false", code)
+			}
+			if other := INTERNAL_ERROR; code != other && errors.Is(err, other) {
+				t.Fatalf("ошибка %s совпала с кодом %s", code, other)
+			}
+		})
+	}
+}
+
+// Ошибки полей VALIDATION_FAILED разбираются в Fields
+func TestValidationErrorFields(t *testing.T) {
+	srv, _ := newTestServer(t)
+	c := newTestClient(t, srv, WithLanguage("en"))
+
+	_, err := c.Login(context.Background(), "not-an-email", testPassword)
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || !errors.Is(err, VALIDATION_FAILED) {
+		t.Fatalf("ожидалась ошибка VALIDATION_FAILED, получено %v", err)
+	}
+	if apiErr.StatusCode != http.StatusBadRequest || len(apiErr.Fields) != 1 ||
+		apiErr.Fields[0].Field != "email" || apiErr.Fields[0].Rule == "" || apiErr.Fields[0].Message == "" {
+		t.Fatalf("неверные ошибки полей: %+v", apiErr.Fields)
+	}
+}
+
+// Дополнительные поля ответа доступны через Extension
+func TestErrorExtensions(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		problem.New(problem.REQUEST_BODY_TOO_LARGE).With("max_bytes", 65536).Write(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	c := newTestClient(t, srv)
+
+	_, err := c.Register(context.Background(), RegisterRequest{Email: "user@example.com"})
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.Code != REQUEST_BODY_TOO_LARGE {
+		t.Fatalf("ожидалась ошибка REQUEST_BODY_TOO_LARGE, получено %v", err)
+	}
+	var maxBytes int64
+	if !apiErr.Extension("max_bytes", &maxBytes) || maxBytes != 65536 {
+		t.Fatalf("неверное поле max_bytes: %d", maxBytes)
+	}
+	if apiErr.Extension("missing", &maxBytes) {
+		t.Fatal("отсутствующее поле найдено")
+	}
+}
+
+// Ответ не в формате problem+json (например, от прокси) возвращается как *Error без кода
+func TestNonProblemError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-ID", "proxy-request")
+		http.Error(w, "upstream unavailable", http.StatusBadGateway)
+	}))
+	t.Cleanup(srv.Close)
+	c := newTestClient(t, srv)
+
+	_, err := c.Register(context.Background(), RegisterRequest{Email: "user@example.com"})
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("ожидалась ошибка *Error, получено %v", err)
+	}
+	if apiErr.StatusCode != http.StatusBadGateway || apiErr.Code != "" || apiErr.Detail != "upstream unavailable" ||
+		apiErr.RequestID != "proxy-request" {
+		t.Fatalf("неверная ошибка: %+v", apiErr)
+	}
+	if errors.Is(err, INTERNAL_ERROR) {
+		t.Fatal("ошибка без кода не должна совпадать ни с одним кодом")
+	}
+	if !strings.Contains(err.Error(), "502") {
+		t.Fatalf("в тексте ошибки нет статуса: %q", err.Error())
+	}
+}
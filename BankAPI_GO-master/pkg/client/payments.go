@@ -0,0 +1,65 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/shopspring/decimal"
+)
+
+// Pay авторизует платеж картой: сумма блокируется (холд) до списания через CapturePayment.
+// Статус PAYMENT_PENDING_REVIEW означает, что платеж задержан антифрод-проверкой.
+// Для безопасного повтора передайте WithIdempotencyKey.
+func (c *Client) Pay(ctx context.Context, req PaymentRequest, opts ...RequestOption) (*Payment, error) {
+	var p Payment
+	err := c.do(ctx, call{method: http.MethodPost, path: "/api/payments", body: req, out: &p, auth: true, opts: opts})
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// CapturePayment списывает сумму по холду; невалидная amount — вся сумма холда.
+// Доступно ролям SUPPORT и ADMIN.
+func (c *Client) CapturePayment(ctx context.Context, paymentID int64, amount decimal.NullDecimal, opts ...RequestOption) (*Payment, error) {
+	var p Payment
+	err := c.do(ctx, call{
+		method: http.MethodPost,
+		path:   pathID("/api/payments", paymentID, "/capture"),
+		body:   amountRequest{Amount: amount},
+		out:    &p,
+		auth:   true,
+		opts:   opts,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// VoidPayment отменяет холд
+func (c *Client) VoidPayment(ctx context.Context, paymentID int64) (*Payment, error) {
+	var p Payment
+	if err := c.do(ctx, call{method: http.MethodPost, path: pathID("/api/payments", paymentID, "/void"), out: &p, auth: true}); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// RefundPayment возвращает деньги по списанному платежу; невалидная amount — весь не возвращенный остаток.
+// Доступно ролям SUPPORT и ADMIN.
+func (c *Client) RefundPayment(ctx context.Context, paymentID int64, amount decimal.NullDecimal, opts ...RequestOption) (*Refund, error) {
+	var refund Refund
+	err := c.do(ctx, call{
+		method: http.MethodPost,
+		path:   pathID("/api/payments", paymentID, "/refund"),
+		body:   amountRequest{Amount: amount},
+		out:    &refund,
+		auth:   true,
+		opts:   opts,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &refund, nil
+}
@@ -0,0 +1,784 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/therealadik/bank-api/internal/config"
+	"github.com/therealadik/bank-api/internal/dto"
+	"github.com/therealadik/bank-api/internal/handler"
+	"github.com/therealadik/bank-api/internal/middleware"
+	"github.com/therealadik/bank-api/internal/models"
+	"github.com/therealadik/bank-api/internal/models/account"
+	"github.com/therealadik/bank-api/internal/models/payment"
+	"github.com/therealadik/bank-api/internal/models/transaction"
+	"github.com/therealadik/bank-api/internal/problem"
+	"github.com/therealadik/bank-api/internal/repository"
+	"github.com/therealadik/bank-api/internal/router"
+	"github.com/therealadik/bank-api/internal/service"
+	"github.com/therealadik/bank-api/internal/validate"
+)
+
+// Тестовый сервер собирается из настоящей таблицы маршрутов (router.New) и настоящих middleware
+// аутентификации, ролей, подтверждения email и ключей идемпотентности. Вход и обновление токенов
+// обрабатывает настоящий AuthHandler. Сервисы, которым нужна PostgreSQL, заменены состоянием в памяти:
+// обработчики счетов, переводов, карт и платежей подменяются в маршрутах поддельными, которые
+// разбирают запрос в те же DTO с теми же правилами проверки и отвечают теми же DTO и кодами ошибок.
+
+const (
+	testPassword       = "Passw0rd123"
+	customerEmail      = "customer@example.com"
+	supportEmail       = "support@example.com"
+	unverifiedEmail    = "unverified@example.com"
+	testAccessTokenTTL = 15 * time.Minute
+)
+
+// testUser пользователь тестового сервера
+type testUser struct {
+	id       int64
+	role     models.Role
+	verified bool
+}
+
+// testPayment платеж картой тестового сервера
+type testPayment struct {
+	id          int64
+	userID      int64
+	accountID   int64
+	amount      decimal.Decimal
+	captured    decimal.Decimal
+	refunded    decimal.Decimal
+	status      payment.Status
+	authorizeAt time.Time
+}
+
+// testBank состояние тестового сервера
+type testBank struct {
+	mu sync.Mutex
+
+	users         map[string]*testUser
+	accessTokens  map[string]*service.TokenClaims
+	refreshTokens map[string]int64
+	tokenSeq      int
+	refreshes     int
+
+	accounts     map[int64]*account.Account
+	transactions map[int64][]dto.TransactionResponse
+	cards        map[int64]*dto.CardResponse
+	cardLimits   map[int64]dto.CardLimitResponse
+	payments     map[int64]*testPayment
+	seq          int64
+
+	// executed сколько раз выполнена операция, по шаблону маршрута
+	executed map[string]int
+	// failCode код ошибки, которым отвечают поддельные обработчики (пустой — обычная работа)
+	failCode problem.Code
+}
+
+// newTestServer запускает тестовый сервер и возвращает его вместе с состоянием
+func newTestServer(t *testing.T) (*httptest.Server, *testBank) {
+	t.Helper()
+
+	bank := &testBank{
+		users: map[string]*testUser{
+			customerEmail:   {id: 1, role: models.CUSTOMER, verified: true},
+			supportEmail:    {id: 2, role: models.SUPPORT, verified: true},
+			unverifiedEmail: {id: 3, role: models.CUSTOMER},
+		},
+		accessTokens:  make(map[string]*service.TokenClaims),
+		refreshTokens: make(map[string]int64),
+		accounts:      make(map[int64]*account.Account),
+		transactions:  make(map[int64][]dto.TransactionResponse),
+		cards:         make(map[int64]*dto.CardResponse),
+		cardLimits:    make(map[int64]dto.CardLimitResponse),
+		payments:      make(map[int64]*testPayment),
+		executed:      make(map[string]int),
+	}
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	idempotencyService := service.NewIdempotencyService(newMemoryIdempotencyRepository(),
+		config.IdempotencyConfig{TTL: time.Hour, CleanupInterval: time.Hour})
+	r := router.New(router.Handlers{
+		Auth: handler.NewAuthHandler(testAuthService{bank: bank}, logger),
+	}, router.Middlewares{
+		JWT:           middleware.NewJWTMiddleware(testAuthService{bank: bank}, logger),
+		Role:          middleware.NewRoleMiddleware(logger),
+		VerifiedEmail: middleware.NewVerifiedEmailMiddleware(testUserRepository{bank: bank}, logger),
+		Idempotency:   middleware.NewIdempotencyMiddleware(idempotencyService, logger),
+	})
+
+	fakes := map[string]http.HandlerFunc{
+		"POST /api/accounts":                  bank.createAccount,
+		"GET /api/accounts":                   bank.listAccounts,
+		"GET /api/accounts/{id}/transactions": bank.listTransactions,
+		"PATCH /api/accounts/{id}/balance":    bank.updateBalance,
+		"POST /api/transfer":                  bank.transfer,
+		"POST /api/cards":                     bank.createCard,
+		"GET /api/cards":                      bank.listCards,
+		"GET /api/cards/{id}/limits":          bank.getCardLimits,
+		"PUT /api/cards/{id}/limits":          bank.updateCardLimits,
+		"POST /api/payments":                  bank.pay,
+		"POST /api/payments/{id}/capture":     bank.capture,
+		"POST /api/payments/{id}/void":        bank.void,
+		"POST /api/payments/{id}/refund":      bank.refund,
+	}
+	err := r.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		tpl, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+		for _, method := range methods {
+			name := method + " " + tpl
+			if fake, ok := fakes[name]; ok {
+				route.Handler(bank.wrap(name, fake))
+				delete(fakes, name)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Подменяются только зарегистрированные маршруты: клиент не может обращаться к несуществующим
+	for name := range fakes {
+		t.Fatalf("маршрут %s не зарегистрирован в router.New", name)
+	}
+
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	return srv, bank
+}
+
+// newTestClient создает клиент тестового сервера
+func newTestClient(t *testing.T, srv *httptest.Server, opts ...Option) *Client {
+	t.Helper()
+	c, err := New(srv.URL, append([]Option{WithHTTPClient(srv.Client())}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// login выполняет вход пользователя
+func login(t *testing.T, c *Client, email string) {
+	t.Helper()
+	if _, err := c.Login(context.Background(), email, testPassword); err != nil {
+		t.Fatalf("ошибка входа %s: %v", email, err)
+	}
+}
+
+// expireAccessTokens отзывает все access-токены, как если бы истек их срок действия
+func (b *testBank) expireAccessTokens() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	clear(b.accessTokens)
+}
+
+// fail задает код ошибки, которым отвечают поддельные обработчики
+func (b *testBank) fail(code problem.Code) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failCode = code
+}
+
+// executedCount сколько раз выполнена операция маршрута name
+func (b *testBank) executedCount(name string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.executed[name]
+}
+
+// refreshCount сколько раз обновлялись токены
+func (b *testBank) refreshCount() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.refreshes
+}
+
+// wrap выполняет поддельный обработчик под блокировкой состояния и считает успешные операции
+func (b *testBank) wrap(name string, fake http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		if b.failCode != "" {
+			problem.Write(w, r, b.failCode)
+			return
+		}
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		fake(rec, r)
+		if rec.status < http.StatusBadRequest {
+			b.executed[name]++
+		}
+	})
+}
+
+// statusRecorder запоминает статус ответа
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (rec *statusRecorder) WriteHeader(status int) {
+	rec.status = status
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+// testAuthService выдает токены пользователям тестового сервера
+type testAuthService struct {
+	service.AuthService
+	bank *testBank
+}
+
+func (s testAuthService) Login(_ context.Context, req dto.LoginRequest, _ string) (*dto.AuthResponse, error) {
+	s.bank.mu.Lock()
+	defer s.bank.mu.Unlock()
+
+	user, ok := s.bank.users[req.Email]
+	if !ok || req.Password != testPassword {
+		return nil, service.ErrInvalidCredentials
+	}
+	return s.bank.issueTokens(user), nil
+}
+
+func (s testAuthService) Refresh(_ context.Context, refreshToken string) (*dto.AuthResponse, error) {
+	s.bank.mu.Lock()
+	defer s.bank.mu.Unlock()
+
+	userID, ok := s.bank.refreshTokens[refreshToken]
+	if !ok {
+		return nil, service.ErrInvalidRefreshToken
+	}
+	delete(s.bank.refreshTokens, refreshToken)
+	s.bank.refreshes++
+	for _, user := range s.bank.users {
+		if user.id == userID {
+			return s.bank.issueTokens(user), nil
+		}
+	}
+	return nil, service.ErrInvalidRefreshToken
+}
+
+func (s testAuthService) ValidateToken(_ context.Context, tokenString string) (*service.TokenClaims, error) {
+	s.bank.mu.Lock()
+	defer s.bank.mu.Unlock()
+
+	claims, ok := s.bank.accessTokens[tokenString]
+	if !ok {
+		return nil, service.ErrTokenRevoked
+	}
+	return claims, nil
+}
+
+// issueTokens выдает новую пару токенов. Вызывается под b.mu.
+func (b *testBank) issueTokens(user *testUser) *dto.AuthResponse {
+	b.tokenSeq++
+	access := fmt.Sprintf("access-%d", b.tokenSeq)
+	refresh := fmt.Sprintf("refresh-%d", b.tokenSeq)
+	b.accessTokens[access] = &service.TokenClaims{
+		UserID:    user.id,
+		Role:      user.role,
+		JTI:       access,
+		ExpiresAt: time.Now().Add(testAccessTokenTTL),
+	}
+	b.refreshTokens[refresh] = user.id
+	return &dto.AuthResponse{
+		Token:        access,
+		RefreshToken: refresh,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(testAccessTokenTTL / time.Second),
+	}
+}
+
+// testUserRepository отдает пользователей тестового сервера для проверки подтверждения email
+type testUserRepository struct {
+	repository.UserRepository
+	bank *testBank
+}
+
+func (r testUserRepository) GetByID(_ context.Context, id int64) (*models.User, error) {
+	r.bank.mu.Lock()
+	defer r.bank.mu.Unlock()
+
+	for email, user := range r.bank.users {
+		if user.id != id {
+			continue
+		}
+		u := &models.User{ID: user.id, Email: email, Role: user.role}
+		if user.verified {
+			now := time.Now()
+			u.EmailVerifiedAt = &now
+		}
+		return u, nil
+	}
+	return nil, errors.New("пользователь не найден")
+}
+
+// memoryIdempotencyRepository хранилище ключей идемпотентности в памяти
+type memoryIdempotencyRepository struct {
+	mu   sync.Mutex
+	keys map[string]*models.IdempotencyKey
+}
+
+func newMemoryIdempotencyRepository() *memoryIdempotencyRepository {
+	return &memoryIdempotencyRepository{keys: make(map[string]*models.IdempotencyKey)}
+}
+
+func idempotencyMapKey(userID int64, key string) string {
+	return strconv.FormatInt(userID, 10) + "/" + key
+}
+
+func (r *memoryIdempotencyRepository) Reserve(_ context.Context, userID int64, key, requestHash string, expiredBefore time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k := idempotencyMapKey(userID, key)
+	if existing, ok := r.keys[k]; ok && !existing.CreatedAt.Before(expiredBefore) {
+		return false, nil
+	}
+	r.keys[k] = &models.IdempotencyKey{UserID: userID, Key: key, RequestHash: requestHash, CreatedAt: time.Now()}
+	return true, nil
+}
+
+func (r *memoryIdempotencyRepository) Get(_ context.Context, userID int64, key string) (*models.IdempotencyKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record, ok := r.keys[idempotencyMapKey(userID, key)]
+	if !ok {
+		return nil, repository.ErrIdempotencyKeyNotFound
+	}
+	copied := *record
+	return &copied, nil
+}
+
+func (r *memoryIdempotencyRepository) Complete(_ context.Context, userID int64, key string, statusCode int, contentType string, body []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record, ok := r.keys[idempotencyMapKey(userID, key)]
+	if !ok {
+		return repository.ErrIdempotencyKeyNotFound
+	}
+	record.StatusCode = &statusCode
+	record.ContentType = contentType
+	record.ResponseBody = append([]byte(nil), body...)
+	return nil
+}
+
+func (r *memoryIdempotencyRepository) Delete(_ context.Context, userID int64, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.keys, idempotencyMapKey(userID, key))
+	return nil
+}
+
+func (r *memoryIdempotencyRepository) DeleteExpired(_ context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted int64
+	for k, record := range r.keys {
+		if record.CreatedAt.Before(before) {
+			delete(r.keys, k)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// Поддельные обработчики. Вызываются через wrap под b.mu.
+
+func (b *testBank) createAccount(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateAccountRequest
+	if !decodeTestRequest(w, r, &req) {
+		return
+	}
+	if req.Currency != account.RUB && req.Currency != account.USD && req.Currency != account.EUR {
+		problem.Write(w, r, problem.UNSUPPORTED_CURRENCY)
+		return
+	}
+
+	b.seq++
+	acc := &account.Account{
+		ID:        b.seq,
+		Number:    fmt.Sprintf("40817810%012d", b.seq),
+		UserID:    testUserID(r),
+		Currency:  req.Currency,
+		Status:    account.ACTIVE,
+		CreatedAt: time.Now().UTC(),
+	}
+	b.accounts[acc.ID] = acc
+	writeTestJSON(w, http.StatusCreated, testAccountResponse(acc))
+}
+
+func (b *testBank) listAccounts(w http.ResponseWriter, r *http.Request) {
+	resp := dto.AccountsListResponse{Accounts: []dto.AccountResponse{}}
+	for id := int64(1); id <= b.seq; id++ {
+		if acc, ok := b.accounts[id]; ok && acc.UserID == testUserID(r) {
+			resp.Accounts = append(resp.Accounts, testAccountResponse(acc))
+		}
+	}
+	writeTestJSON(w, http.StatusOK, resp)
+}
+
+func (b *testBank) listTransactions(w http.ResponseWriter, r *http.Request) {
+	acc, ok := b.ownAccount(w, r, testPathID(r))
+	if !ok {
+		return
+	}
+	writeTestJSON(w, http.StatusOK, dto.TransactionListResponse{Transactions: append([]dto.TransactionResponse{}, b.transactions[acc.ID]...)})
+}
+
+func (b *testBank) updateBalance(w http.ResponseWriter, r *http.Request) {
+	var req dto.UpdateBalanceRequest
+	if !decodeTestRequest(w, r, &req) {
+		return
+	}
+	acc, ok := b.ownAccount(w, r, testPathID(r))
+	if !ok {
+		return
+	}
+	if acc.AvailableBalance.Add(req.Amount).IsNegative() {
+		problem.Write(w, r, problem.INSUFFICIENT_FUNDS)
+		return
+	}
+
+	txType := transaction.DEPOSIT
+	if req.Amount.IsNegative() {
+		txType = transaction.WITHDRAWAL
+	}
+	acc.Balance = acc.Balance.Add(req.Amount)
+	acc.AvailableBalance = acc.AvailableBalance.Add(req.Amount)
+	b.addTransaction(acc.ID, req.Amount, txType)
+	writeTestJSON(w, http.StatusOK, testAccountResponse(acc))
+}
+
+func (b *testBank) transfer(w http.ResponseWriter, r *http.Request) {
+	var req dto.TransferRequest
+	if !decodeTestRequest(w, r, &req) {
+		return
+	}
+	from, ok := b.ownAccount(w, r, req.FromAccountID)
+	if !ok {
+		return
+	}
+	var to *account.Account
+	for _, acc := range b.accounts {
+		if (req.ToAccountID != 0 && acc.ID == req.ToAccountID) || (req.ToAccountNumber != "" && acc.Number == req.ToAccountNumber) {
+			to = acc
+		}
+	}
+	if to == nil || to.ID == from.ID {
+		problem.Write(w, r, problem.ACCOUNT_NOT_FOUND)
+		return
+	}
+	if from.AvailableBalance.LessThan(req.Amount) {
+		problem.Write(w, r, problem.INSUFFICIENT_FUNDS)
+		return
+	}
+
+	from.Balance = from.Balance.Sub(req.Amount)
+	from.AvailableBalance = from.AvailableBalance.Sub(req.Amount)
+	to.Balance = to.Balance.Add(req.Amount)
+	to.AvailableBalance = to.AvailableBalance.Add(req.Amount)
+	txID := b.addTransaction(from.ID, req.Amount.Neg(), transaction.TRANSFER)
+	b.addTransaction(to.ID, req.Amount, transaction.DEPOSIT)
+	writeTestJSON(w, http.StatusOK, dto.TransferResponse{Status: "success", TransactionID: txID})
+}
+
+func (b *testBank) createCard(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateCardRequest
+	if !decodeTestRequest(w, r, &req) {
+		return
+	}
+	acc, ok := b.ownAccount(w, r, req.AccountID)
+	if !ok {
+		return
+	}
+
+	b.seq++
+	card := &dto.CardResponse{
+		ID:        b.seq,
+		UserID:    acc.UserID,
+		AccountID: &acc.ID,
+		Status:    models.CARD_ACTIVE,
+		CreatedAt: time.Now().UTC().Format("2006-01-02T15:04:05Z"),
+	}
+	b.cards[card.ID] = card
+	b.cardLimits[card.ID] = dto.CardLimitResponse{CardID: card.ID, BlockedCategories: []string{}}
+	writeTestJSON(w, http.StatusCreated, dto.CreateCardResponse{
+		ID:         card.ID,
+		UserID:     card.UserID,
+		AccountID:  acc.ID,
+		CreatedAt:  card.CreatedAt,
+		CardNumber: "pgp:number",
+		Expire:     "pgp:expire",
+		CVV:        "pgp:cvv",
+	})
+}
+
+func (b *testBank) listCards(w http.ResponseWriter, r *http.Request) {
+	resp := dto.CardListResponse{Cards: []dto.CardResponse{}}
+	for id := int64(1); id <= b.seq; id++ {
+		if card, ok := b.cards[id]; ok && card.UserID == testUserID(r) {
+			resp.Cards = append(resp.Cards, *card)
+		}
+	}
+	writeTestJSON(w, http.StatusOK, resp)
+}
+
+func (b *testBank) getCardLimits(w http.ResponseWriter, r *http.Request) {
+	card, ok := b.ownCard(w, r, testPathID(r))
+	if !ok {
+		return
+	}
+	writeTestJSON(w, http.StatusOK, b.cardLimits[card.ID])
+}
+
+func (b *testBank) updateCardLimits(w http.ResponseWriter, r *http.Request) {
+	var req dto.CardLimitRequest
+	if !decodeTestRequest(w, r, &req) {
+		return
+	}
+	card, ok := b.ownCard(w, r, testPathID(r))
+	if !ok {
+		return
+	}
+
+	limits := dto.CardLimitResponse{
+		CardID:            card.ID,
+		PerTransaction:    req.PerTransaction,
+		Daily:             req.Daily,
+		Monthly:           req.Monthly,
+		BlockedCategories: append([]string{}, req.BlockedCategories...),
+	}
+	b.cardLimits[card.ID] = limits
+	writeTestJSON(w, http.StatusOK, limits)
+}
+
+func (b *testBank) pay(w http.ResponseWriter, r *http.Request) {
+	var req dto.CardPaymentRequest
+	if !decodeTestRequest(w, r, &req) {
+		return
+	}
+	card, ok := b.ownCard(w, r, req.CardID)
+	if !ok {
+		return
+	}
+	acc := b.accounts[*card.AccountID]
+	if acc.AvailableBalance.LessThan(req.Amount) {
+		problem.Write(w, r, problem.INSUFFICIENT_FUNDS)
+		return
+	}
+
+	b.seq++
+	p := &testPayment{
+		id:          b.seq,
+		userID:      card.UserID,
+		accountID:   acc.ID,
+		amount:      req.Amount,
+		status:      payment.AUTHORIZED,
+		authorizeAt: time.Now().UTC(),
+	}
+	b.payments[p.id] = p
+	acc.AvailableBalance = acc.AvailableBalance.Sub(req.Amount)
+	writeTestPayment(w, http.StatusCreated, p)
+}
+
+func (b *testBank) capture(w http.ResponseWriter, r *http.Request) {
+	var req dto.CapturePaymentRequest
+	if !decodeTestRequest(w, r, &req) {
+		return
+	}
+	p, ok := b.payments[testPathID(r)]
+	if !ok {
+		problem.Write(w, r, problem.PAYMENT_NOT_FOUND)
+		return
+	}
+	if p.status != payment.AUTHORIZED {
+		problem.Write(w, r, problem.PAYMENT_NOT_AUTHORIZED)
+		return
+	}
+
+	amount := p.amount
+	if req.Amount.Valid {
+		amount = req.Amount.Decimal
+	}
+	acc := b.accounts[p.accountID]
+	acc.Balance = acc.Balance.Sub(amount)
+	acc.AvailableBalance = acc.AvailableBalance.Add(p.amount).Sub(amount)
+	p.captured = amount
+	p.status = payment.CAPTURED
+	b.addTransaction(acc.ID, amount.Neg(), transaction.WITHDRAWAL)
+	writeTestPayment(w, http.StatusOK, p)
+}
+
+func (b *testBank) void(w http.ResponseWriter, r *http.Request) {
+	p, ok := b.payments[testPathID(r)]
+	if !ok || p.userID != testUserID(r) {
+		problem.Write(w, r, problem.PAYMENT_NOT_FOUND)
+		return
+	}
+	if p.status != payment.AUTHORIZED {
+		problem.Write(w, r, problem.PAYMENT_NOT_AUTHORIZED)
+		return
+	}
+
+	acc := b.accounts[p.accountID]
+	acc.AvailableBalance = acc.AvailableBalance.Add(p.amount)
+	p.status = payment.VOIDED
+	writeTestPayment(w, http.StatusOK, p)
+}
+
+func (b *testBank) refund(w http.ResponseWriter, r *http.Request) {
+	var req dto.RefundPaymentRequest
+	if !decodeTestRequest(w, r, &req) {
+		return
+	}
+	p, ok := b.payments[testPathID(r)]
+	if !ok {
+		problem.Write(w, r, problem.PAYMENT_NOT_FOUND)
+		return
+	}
+	if p.status != payment.CAPTURED && p.status != payment.PARTIALLY_REFUNDED {
+		problem.Write(w, r, problem.PAYMENT_NOT_CAPTURED)
+		return
+	}
+
+	amount := p.captured.Sub(p.refunded)
+	if req.Amount.Valid {
+		amount = req.Amount.Decimal
+	}
+	p.refunded = p.refunded.Add(amount)
+	p.status = payment.PARTIALLY_REFUNDED
+	if p.refunded.Equal(p.captured) {
+		p.status = payment.REFUNDED
+	}
+	acc := b.accounts[p.accountID]
+	acc.Balance = acc.Balance.Add(amount)
+	acc.AvailableBalance = acc.AvailableBalance.Add(amount)
+	txID := b.addTransaction(acc.ID, amount, transaction.REFUND)
+	writeTestJSON(w, http.StatusOK, dto.RefundPaymentResponse{
+		PaymentID:           strconv.FormatInt(p.id, 10),
+		Status:              p.status,
+		Amount:              p.captured,
+		RefundedAmount:      p.refunded,
+		RefundTransactionID: txID,
+		RefundAmount:        amount,
+	})
+}
+
+// ownAccount возвращает счет пользователя запроса или отвечает ошибкой
+func (b *testBank) ownAccount(w http.ResponseWriter, r *http.Request, id int64) (*account.Account, bool) {
+	acc, ok := b.accounts[id]
+	if !ok {
+		problem.Write(w, r, problem.ACCOUNT_NOT_FOUND)
+		return nil, false
+	}
+	if acc.UserID != testUserID(r) {
+		problem.Write(w, r, problem.ACCOUNT_FORBIDDEN)
+		return nil, false
+	}
+	return acc, true
+}
+
+// ownCard возвращает карту пользователя запроса или отвечает ошибкой
+func (b *testBank) ownCard(w http.ResponseWriter, r *http.Request, id int64) (*dto.CardResponse, bool) {
+	card, ok := b.cards[id]
+	if !ok || card.UserID != testUserID(r) {
+		problem.Write(w, r, problem.CARD_NOT_FOUND)
+		return nil, false
+	}
+	return card, true
+}
+
+// addTransaction добавляет проведенную транзакцию по счету и возвращает ее ID
+func (b *testBank) addTransaction(accountID int64, amount decimal.Decimal, txType transaction.Type) int64 {
+	b.seq++
+	b.transactions[accountID] = append(b.transactions[accountID], dto.TransactionResponse{
+		ID:        b.seq,
+		AccountID: accountID,
+		Amount:    amount,
+		Type:      txType,
+		Status:    transaction.COMPLETED,
+		CreatedAt: time.Now().UTC().Format("2006-01-02T15:04:05Z"),
+	})
+	return b.seq
+}
+
+// decodeTestRequest разбирает тело запроса так же строго, как обработчики сервиса:
+// неизвестные поля запрещены, поля проверяются по правилам binding
+func decodeTestRequest(w http.ResponseWriter, r *http.Request, dst any) bool {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(dst); err != nil {
+		problem.Write(w, r, problem.INVALID_REQUEST_BODY)
+		return false
+	}
+	if err := validate.Struct(dst); err != nil {
+		problem.Write(w, r, problem.VALIDATION_FAILED)
+		return false
+	}
+	return true
+}
+
+func writeTestJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeTestPayment(w http.ResponseWriter, status int, p *testPayment) {
+	resp := dto.CardPaymentResponse{
+		Success:   true,
+		PaymentID: strconv.FormatInt(p.id, 10),
+		Status:    p.status,
+		Amount:    p.amount,
+	}
+	if p.status == payment.AUTHORIZED {
+		resp.ExpiresAt = p.authorizeAt.Add(7 * 24 * time.Hour).Format("2006-01-02T15:04:05Z")
+	}
+	writeTestJSON(w, status, resp)
+}
+
+func testAccountResponse(acc *account.Account) dto.AccountResponse {
+	return dto.AccountResponse{
+		ID:               acc.ID,
+		Number:           acc.Number,
+		UserID:           acc.UserID,
+		Balance:          acc.Balance,
+		AvailableBalance: acc.AvailableBalance,
+		Currency:         acc.Currency,
+		Status:           acc.Status,
+		CreatedAt:        acc.CreatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+}
+
+func testUserID(r *http.Request) int64 {
+	userID, _ := middleware.GetUserID(r.Context())
+	return userID
+}
+
+func testPathID(r *http.Request) int64 {
+	id, _ := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	return id
+}
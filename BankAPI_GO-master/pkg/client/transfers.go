@@ -0,0 +1,84 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// Transfer переводит деньги. Статус TRANSFER_PENDING_REVIEW означает, что перевод задержан
+// антифрод-проверкой и сумма удерживается до решения администратора.
+// Для безопасного повтора передайте WithIdempotencyKey.
+func (c *Client) Transfer(ctx context.Context, req TransferRequest, opts ...RequestOption) (*TransferResult, error) {
+	var result TransferResult
+	err := c.do(ctx, call{method: http.MethodPost, path: "/api/transfer", body: req, out: &result, auth: true, opts: opts})
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// PreviewTransfer проверяет получателя перед переводом и возвращает его замаскированные данные
+func (c *Client) PreviewTransfer(ctx context.Context, fromAccountID int64, to Recipient) (*TransferPreview, error) {
+	var preview TransferPreview
+	err := c.do(ctx, call{
+		method: http.MethodPost,
+		path:   "/api/transfer/preview",
+		body:   transferPreviewRequest{FromAccountID: fromAccountID, Recipient: to},
+		out:    &preview,
+		auth:   true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &preview, nil
+}
+
+// CreateScheduledTransfer создает отложенный или регулярный перевод
+func (c *Client) CreateScheduledTransfer(ctx context.Context, req ScheduledTransferRequest, opts ...RequestOption) (*ScheduledTransfer, error) {
+	var st ScheduledTransfer
+	err := c.do(ctx, call{method: http.MethodPost, path: "/api/scheduled-transfers", body: req, out: &st, auth: true, opts: opts})
+	if err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+// ScheduledTransfers возвращает регулярные переводы пользователя
+func (c *Client) ScheduledTransfers(ctx context.Context) ([]ScheduledTransfer, error) {
+	var resp struct {
+		ScheduledTransfers []ScheduledTransfer `json:"scheduled_transfers"`
+	}
+	if err := c.do(ctx, call{method: http.MethodGet, path: "/api/scheduled-transfers", out: &resp, auth: true}); err != nil {
+		return nil, err
+	}
+	return resp.ScheduledTransfers, nil
+}
+
+// ScheduledTransfer возвращает регулярный перевод
+func (c *Client) ScheduledTransfer(ctx context.Context, id int64) (*ScheduledTransfer, error) {
+	var st ScheduledTransfer
+	if err := c.do(ctx, call{method: http.MethodGet, path: pathID("/api/scheduled-transfers", id, ""), out: &st, auth: true}); err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+// CancelScheduledTransfer отменяет регулярный перевод
+func (c *Client) CancelScheduledTransfer(ctx context.Context, id int64) (*ScheduledTransfer, error) {
+	var st ScheduledTransfer
+	if err := c.do(ctx, call{method: http.MethodDelete, path: pathID("/api/scheduled-transfers", id, ""), out: &st, auth: true}); err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+// ScheduledTransferRuns возвращает историю исполнений регулярного перевода
+func (c *Client) ScheduledTransferRuns(ctx context.Context, id int64) ([]ScheduledTransferRun, error) {
+	var resp struct {
+		Runs []ScheduledTransferRun `json:"runs"`
+	}
+	if err := c.do(ctx, call{method: http.MethodGet, path: pathID("/api/scheduled-transfers", id, "/runs"), out: &resp, auth: true}); err != nil {
+		return nil, err
+	}
+	return resp.Runs, nil
+}
@@ -0,0 +1,349 @@
+package client
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Currency валюта счета
+type Currency string
+
+const (
+	RUB Currency = "RUB"
+	USD Currency = "USD"
+	EUR Currency = "EUR"
+)
+
+// AccountStatus статус счета
+type AccountStatus string
+
+const (
+	ACCOUNT_STATUS_ACTIVE AccountStatus = "ACTIVE"
+	ACCOUNT_STATUS_FROZEN AccountStatus = "FROZEN"
+	ACCOUNT_STATUS_CLOSED AccountStatus = "CLOSED"
+)
+
+// TransactionType тип транзакции
+type TransactionType string
+
+const (
+	TRANSACTION_DEPOSIT    TransactionType = "DEPOSIT"
+	TRANSACTION_WITHDRAWAL TransactionType = "WITHDRAWAL"
+	TRANSACTION_TRANSFER   TransactionType = "TRANSFER"
+	TRANSACTION_REFUND     TransactionType = "REFUND"
+	TRANSACTION_CHARGEBACK TransactionType = "CHARGEBACK"
+)
+
+// TransactionStatus статус транзакции
+type TransactionStatus string
+
+const (
+	TRANSACTION_PENDING   TransactionStatus = "PENDING"
+	TRANSACTION_COMPLETED TransactionStatus = "COMPLETED"
+	TRANSACTION_FAILED    TransactionStatus = "FAILED"
+)
+
+// TransferStatus результат перевода
+type TransferStatus string
+
+const (
+	TRANSFER_COMPLETED      TransferStatus = "success"        // Перевод проведен
+	TRANSFER_PENDING_REVIEW TransferStatus = "pending_review" // Перевод задержан антифрод-проверкой
+)
+
+// Frequency периодичность регулярного перевода
+type Frequency string
+
+const (
+	FREQUENCY_ONCE    Frequency = "ONCE"
+	FREQUENCY_DAILY   Frequency = "DAILY"
+	FREQUENCY_WEEKLY  Frequency = "WEEKLY"
+	FREQUENCY_MONTHLY Frequency = "MONTHLY"
+	FREQUENCY_CRON    Frequency = "CRON"
+)
+
+// ScheduledTransferStatus статус регулярного перевода
+type ScheduledTransferStatus string
+
+const (
+	SCHEDULED_ACTIVE    ScheduledTransferStatus = "ACTIVE"
+	SCHEDULED_COMPLETED ScheduledTransferStatus = "COMPLETED"
+	SCHEDULED_CANCELLED ScheduledTransferStatus = "CANCELLED"
+	SCHEDULED_FAILED    ScheduledTransferStatus = "FAILED"
+)
+
+// RunStatus результат попытки исполнения регулярного перевода
+type RunStatus string
+
+const (
+	RUN_SUCCEEDED RunStatus = "SUCCEEDED"
+	RUN_RETRYING  RunStatus = "RETRYING"
+	RUN_FAILED    RunStatus = "FAILED"
+)
+
+// CardStatus статус карты
+type CardStatus string
+
+const (
+	CARD_STATUS_ACTIVE  CardStatus = "ACTIVE"
+	CARD_STATUS_BLOCKED CardStatus = "BLOCKED"
+)
+
+// PaymentStatus статус платежа картой
+type PaymentStatus string
+
+const (
+	PAYMENT_AUTHORIZED         PaymentStatus = "AUTHORIZED"
+	PAYMENT_PENDING_REVIEW     PaymentStatus = "PENDING_REVIEW"
+	PAYMENT_CAPTURED           PaymentStatus = "CAPTURED"
+	PAYMENT_PARTIALLY_REFUNDED PaymentStatus = "PARTIALLY_REFUNDED"
+	PAYMENT_REFUNDED           PaymentStatus = "REFUNDED"
+	PAYMENT_VOIDED             PaymentStatus = "VOIDED"
+	PAYMENT_EXPIRED            PaymentStatus = "EXPIRED"
+	PAYMENT_DECLINED           PaymentStatus = "DECLINED"
+)
+
+// RegisterRequest данные регистрации
+type RegisterRequest struct {
+	Email    string `json:"email"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// LoginResult результат входа. Если у пользователя включена 2FA, токены не выдаются:
+// TwoFactorRequired равен true, и вход завершается вызовом LoginTwoFactor с ChallengeToken.
+type LoginResult struct {
+	TwoFactorRequired bool
+	ChallengeToken    string
+}
+
+// Profile профиль пользователя
+type Profile struct {
+	ID               int64     `json:"id"`
+	Email            string    `json:"email"`
+	Username         string    `json:"username"`
+	Role             string    `json:"role"`
+	TwoFactorEnabled bool      `json:"two_factor_enabled"`
+	EmailVerified    bool      `json:"email_verified"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// Account счет
+type Account struct {
+	ID               int64           `json:"id"`
+	Number           string          `json:"number"`
+	UserID           int64           `json:"user_id"`
+	Balance          decimal.Decimal `json:"balance"`
+	AvailableBalance decimal.Decimal `json:"available_balance"` // Баланс за вычетом холдов
+	Currency         Currency        `json:"currency"`
+	Status           AccountStatus   `json:"status"`
+	CreatedAt        time.Time       `json:"created_at"`
+	ClosedAt         *time.Time      `json:"closed_at,omitempty"`
+}
+
+// Transaction транзакция по счету
+type Transaction struct {
+	ID                    int64             `json:"id"`
+	AccountID             int64             `json:"account_id"`
+	Amount                decimal.Decimal   `json:"amount"`
+	Type                  TransactionType   `json:"type"`
+	Status                TransactionStatus `json:"status"`
+	OriginalTransactionID *int64            `json:"original_transaction_id,omitempty"` // Исходная транзакция возврата
+	CreatedAt             time.Time         `json:"created_at"`
+}
+
+// Recipient получатель перевода: ровно одно из полей.
+// AccountID допускается только для собственных счетов.
+type Recipient struct {
+	AccountID     int64  `json:"to_account_id,omitempty"`
+	AccountNumber string `json:"to_account_number,omitempty"`
+	Email         string `json:"to_email,omitempty"`
+}
+
+// TransferRequest перевод между счетами
+type TransferRequest struct {
+	FromAccountID int64 `json:"from_account_id"`
+	Recipient
+	Amount decimal.Decimal `json:"amount"`
+}
+
+// TransferResult результат перевода
+type TransferResult struct {
+	Status        TransferStatus `json:"status"`
+	TransactionID int64          `json:"transaction_id"`
+}
+
+// TransferPreview замаскированные данные получателя перевода
+type TransferPreview struct {
+	RecipientName string   `json:"recipient_name"`
+	AccountNumber string   `json:"account_number"`
+	Currency      Currency `json:"currency"`
+}
+
+// ScheduledTransferRequest отложенный или регулярный перевод
+type ScheduledTransferRequest struct {
+	FromAccountID int64 `json:"from_account_id"`
+	Recipient
+	Amount    decimal.Decimal `json:"amount"`
+	Frequency Frequency       `json:"frequency"`
+	Cron      string          `json:"cron,omitempty"`     // Для FREQUENCY_CRON: пять полей, UTC
+	StartAt   *time.Time      `json:"start_at,omitempty"` // По умолчанию — сейчас
+	EndAt     *time.Time      `json:"end_at,omitempty"`
+}
+
+// ScheduledTransfer регулярный перевод
+type ScheduledTransfer struct {
+	ID              int64                   `json:"id"`
+	FromAccountID   int64                   `json:"from_account_id"`
+	ToAccountID     *int64                  `json:"to_account_id,omitempty"`
+	ToAccountNumber *string                 `json:"to_account_number,omitempty"`
+	ToEmail         *string                 `json:"to_email,omitempty"`
+	Amount          decimal.Decimal         `json:"amount"`
+	Frequency       Frequency               `json:"frequency"`
+	Cron            *string                 `json:"cron,omitempty"`
+	StartAt         time.Time               `json:"start_at"`
+	EndAt           *time.Time              `json:"end_at,omitempty"`
+	NextRunAt       time.Time               `json:"next_run_at"`
+	Attempts        int                     `json:"attempts"`
+	Status          ScheduledTransferStatus `json:"status"`
+	LastError       *string                 `json:"last_error,omitempty"`
+	CreatedAt       time.Time               `json:"created_at"`
+}
+
+// ScheduledTransferRun попытка исполнения регулярного перевода
+type ScheduledTransferRun struct {
+	ID          int64     `json:"id"`
+	ScheduledAt time.Time `json:"scheduled_at"`
+	Attempt     int       `json:"attempt"`
+	Status      RunStatus `json:"status"`
+	Error       *string   `json:"error,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// IssuedCard выпущенная карта. Номер, срок действия и CVV зашифрованы PGP-ключом из запроса.
+type IssuedCard struct {
+	ID         int64     `json:"id"`
+	UserID     int64     `json:"user_id"`
+	AccountID  int64     `json:"account_id"`
+	CreatedAt  time.Time `json:"created_at"`
+	CardNumber string    `json:"card_number"`
+	Expire     string    `json:"expire"`
+	CVV        string    `json:"cvv"`
+}
+
+// Card карта без секретных данных
+type Card struct {
+	ID        int64      `json:"id"`
+	UserID    int64      `json:"user_id"`
+	AccountID *int64     `json:"account_id"`
+	Status    CardStatus `json:"status"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// CardDetails реквизиты карты, зашифрованные PGP-ключом из запроса
+type CardDetails struct {
+	ID         int64  `json:"id"`
+	CardNumber string `json:"card_number"`
+	Expire     string `json:"expire"`
+}
+
+// CardLimits лимиты карты; невалидное значение (Valid == false) означает отсутствие ограничения
+type CardLimits struct {
+	CardID            int64               `json:"card_id"`
+	PerTransaction    decimal.NullDecimal `json:"per_transaction"`
+	Daily             decimal.NullDecimal `json:"daily"`
+	Monthly           decimal.NullDecimal `json:"monthly"`
+	BlockedCategories []string            `json:"blocked_categories"` // Запрещенные MCC
+}
+
+// PaymentRequest оплата картой
+type PaymentRequest struct {
+	CardID           int64           `json:"card_id"`
+	Amount           decimal.Decimal `json:"amount"`
+	CVV              string          `json:"cvv"`
+	PGPKey           string          `json:"pgp_key"`
+	MerchantCategory string          `json:"merchant_category,omitempty"` // MCC торговца
+}
+
+// Payment платеж картой
+type Payment struct {
+	Success     bool            `json:"success"`
+	PaymentID   int64           `json:"payment_id,string,omitempty"`
+	Status      PaymentStatus   `json:"status,omitempty"`
+	Amount      decimal.Decimal `json:"amount"`
+	ExpiresAt   *time.Time      `json:"expires_at,omitempty"` // Срок холда
+	Description string          `json:"description,omitempty"`
+}
+
+// Refund результат возврата по платежу
+type Refund struct {
+	PaymentID           int64           `json:"payment_id,string"`
+	Status              PaymentStatus   `json:"status"`
+	Amount              decimal.Decimal `json:"amount"`
+	RefundedAmount      decimal.Decimal `json:"refunded_amount"` // Всего возвращено по платежу
+	RefundTransactionID int64           `json:"refund_transaction_id"`
+	RefundAmount        decimal.Decimal `json:"refund_amount"` // Сумма этого возврата
+}
+
+// Тела запросов и ответов, не видимые пользователю клиента
+
+type authResponse struct {
+	Token             string `json:"token"`
+	RefreshToken      string `json:"refresh_token"`
+	TwoFactorRequired bool   `json:"two_factor_required"`
+	ChallengeToken    string `json:"challenge_token"`
+	ExpiresIn         int64  `json:"expires_in"`
+}
+
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type loginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type twoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+}
+
+type registerResponse struct {
+	UserID int64 `json:"user_id"`
+}
+
+type createAccountRequest struct {
+	Currency Currency `json:"currency"`
+}
+
+type updateBalanceRequest struct {
+	Amount decimal.Decimal `json:"amount"`
+}
+
+type closeAccountRequest struct {
+	TransferToAccountID *int64 `json:"transfer_to_account_id,omitempty"`
+}
+
+type transferPreviewRequest struct {
+	FromAccountID int64 `json:"from_account_id"`
+	Recipient
+}
+
+type createCardRequest struct {
+	AccountID int64  `json:"account_id"`
+	PGPKey    string `json:"pgp_key"`
+}
+
+type amountRequest struct {
+	Amount decimal.NullDecimal `json:"amount"`
+}
+
+type cardLimitsRequest struct {
+	PerTransaction    decimal.NullDecimal `json:"per_transaction"`
+	Daily             decimal.NullDecimal `json:"daily"`
+	Monthly           decimal.NullDecimal `json:"monthly"`
+	BlockedCategories []string            `json:"blocked_categories"`
+}